@@ -0,0 +1,154 @@
+// Package bom loads the BOM to related products mapping into the related
+// products table.
+package bom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type Action string
+
+const (
+	ActionAdd    Action = "add"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change describes how one BOM differs from the table
+type Change struct {
+	Bom    string
+	Action Action
+	// Products lists the product level differences, one line per product
+	// prefixed with + for added, - for removed and ~ for renamed products
+	Products []string
+}
+
+// Importer replaces the contents of the related products table with a set
+// of BOMs
+type Importer struct {
+	Db dboperations.RelatedProductsStore
+	// Products are the product names BOMs may refer to
+	Products []string
+	// Delete removes BOMs that are in the table but not in the input
+	Delete bool
+	// DryRun computes the changes without writing them
+	DryRun bool
+	Log    *log.Entry
+}
+
+// Validate checks that every product of every BOM is a known product
+func (im *Importer) Validate(boms BOMs) error {
+	known := map[string]bool{}
+	for _, p := range im.Products {
+		known[p] = true
+	}
+
+	var errs []error
+	for _, bom := range sortedKeys(boms) {
+		for _, product := range sortedKeys(boms[bom]) {
+			if !known[product] {
+				errs = append(errs, fmt.Errorf("BOM %s refers to unknown product %s", bom, product))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Plan returns the changes needed to bring the table in line with boms,
+// sorted by BOM
+func (im *Importer) Plan(ctx context.Context, boms BOMs) ([]Change, error) {
+	current, err := im.Db.ListRelatedProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := BOMs{}
+	for _, item := range current {
+		existing[item.Bom] = item.Products
+	}
+
+	var changes []Change
+	for _, bom := range sortedKeys(boms) {
+		old, ok := existing[bom]
+		diff := diffProducts(old, boms[bom])
+		switch {
+		case !ok:
+			changes = append(changes, Change{Bom: bom, Action: ActionAdd, Products: diff})
+		case len(diff) > 0:
+			changes = append(changes, Change{Bom: bom, Action: ActionUpdate, Products: diff})
+		}
+	}
+	if im.Delete {
+		for _, bom := range sortedKeys(existing) {
+			if _, ok := boms[bom]; !ok {
+				changes = append(changes, Change{Bom: bom, Action: ActionDelete, Products: diffProducts(existing[bom], nil)})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Bom < changes[j].Bom })
+	return changes, nil
+}
+
+// Import validates boms and applies the changes to the table. The changes
+// are returned even on a dry run.
+func (im *Importer) Import(ctx context.Context, boms BOMs) ([]Change, error) {
+	if err := im.Validate(boms); err != nil {
+		return nil, err
+	}
+	changes, err := im.Plan(ctx, boms)
+	if err != nil {
+		return nil, err
+	}
+	if im.DryRun {
+		return changes, nil
+	}
+
+	for _, change := range changes {
+		var err error
+		if change.Action == ActionDelete {
+			err = im.Db.DeleteRelatedProducts(ctx, change.Bom)
+		} else {
+			err = im.Db.PutRelatedProducts(ctx, models.RelatedProducts{Bom: change.Bom, Products: boms[change.Bom]})
+		}
+		if err != nil {
+			return changes, fmt.Errorf("failed to %s BOM %s: %w", change.Action, change.Bom, err)
+		}
+		im.Log.WithField("bom", change.Bom).WithField("action", change.Action).Info("Applied BOM change")
+	}
+	return changes, nil
+}
+
+func diffProducts(old, new map[string]string) []string {
+	var lines []string
+	for _, product := range sortedKeys(new) {
+		previous, ok := old[product]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", product, new[product]))
+		case previous != new[product]:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", product, previous, new[product]))
+		}
+	}
+	for _, product := range sortedKeys(old) {
+		if _, ok := new[product]; !ok {
+			lines = append(lines, fmt.Sprintf("- %s: %s", product, old[product]))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
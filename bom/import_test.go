@@ -0,0 +1,130 @@
+package bom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *log.Entry {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	return log.NewEntry(logger)
+}
+
+func TestImporter_Import(t *testing.T) {
+	stored := []models.RelatedProducts{
+		{Bom: "BOM-1", Products: map[string]string{"chef": "Chef Infra Client", "inspec": "InSpec"}},
+		{Bom: "BOM-2", Products: map[string]string{"chef": "Chef"}},
+		{Bom: "BOM-OLD", Products: map[string]string{"manage": "Chef Manage"}},
+	}
+	input := BOMs{
+		"BOM-1": {"chef": "Chef Infra Client", "inspec": "InSpec"},
+		"BOM-2": {"chef": "Chef Infra Client", "habitat": "Chef Habitat"},
+		"BOM-3": {"automate": "Chef Automate"},
+	}
+	products := []string{"automate", "chef", "habitat", "inspec", "manage"}
+
+	tests := []struct {
+		name    string
+		delete  bool
+		dryRun  bool
+		want    []Change
+		puts    []string
+		deletes []string
+	}{
+		{
+			name: "writes changed BOMs",
+			want: []Change{
+				{Bom: "BOM-2", Action: ActionUpdate, Products: []string{"~ chef: Chef -> Chef Infra Client", "+ habitat: Chef Habitat"}},
+				{Bom: "BOM-3", Action: ActionAdd, Products: []string{"+ automate: Chef Automate"}},
+			},
+			puts: []string{"BOM-2", "BOM-3"},
+		},
+		{
+			name:   "deletes removed BOMs",
+			delete: true,
+			want: []Change{
+				{Bom: "BOM-2", Action: ActionUpdate, Products: []string{"~ chef: Chef -> Chef Infra Client", "+ habitat: Chef Habitat"}},
+				{Bom: "BOM-3", Action: ActionAdd, Products: []string{"+ automate: Chef Automate"}},
+				{Bom: "BOM-OLD", Action: ActionDelete, Products: []string{"- manage: Chef Manage"}},
+			},
+			puts:    []string{"BOM-2", "BOM-3"},
+			deletes: []string{"BOM-OLD"},
+		},
+		{
+			name:   "dry run",
+			delete: true,
+			dryRun: true,
+			want: []Change{
+				{Bom: "BOM-2", Action: ActionUpdate, Products: []string{"~ chef: Chef -> Chef Infra Client", "+ habitat: Chef Habitat"}},
+				{Bom: "BOM-3", Action: ActionAdd, Products: []string{"+ automate: Chef Automate"}},
+				{Bom: "BOM-OLD", Action: ActionDelete, Products: []string{"- manage: Chef Manage"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var puts, deletes []string
+			db := &dboperations.MockIDbOperations{
+				ListRelatedProductsfunc: func(ctx context.Context) ([]models.RelatedProducts, error) {
+					return stored, nil
+				},
+				PutRelatedProductsfunc: func(ctx context.Context, item models.RelatedProducts) error {
+					assert.Equal(t, input[item.Bom], item.Products)
+					puts = append(puts, item.Bom)
+					return nil
+				},
+				DeleteRelatedProductsfunc: func(ctx context.Context, bom string) error {
+					deletes = append(deletes, bom)
+					return nil
+				},
+			}
+			im := &Importer{Db: db, Products: products, Delete: tt.delete, DryRun: tt.dryRun, Log: testLogger()}
+
+			got, err := im.Import(context.Background(), input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.puts, puts)
+			assert.Equal(t, tt.deletes, deletes)
+		})
+	}
+}
+
+func TestImporter_Validate(t *testing.T) {
+	written := false
+	im := &Importer{
+		Db: &dboperations.MockIDbOperations{
+			PutRelatedProductsfunc: func(ctx context.Context, item models.RelatedProducts) error {
+				written = true
+				return nil
+			},
+		},
+		Products: []string{"chef"},
+		Log:      testLogger(),
+	}
+
+	_, err := im.Import(context.Background(), BOMs{"BOM-1": {"chef": "Chef", "chef-360": "Chef 360"}, "BOM-2": {"courier": "Courier"}})
+	assert.EqualError(t, err, "BOM BOM-1 refers to unknown product chef-360\nBOM BOM-2 refers to unknown product courier")
+	assert.False(t, written)
+}
+
+func TestImporter_ListError(t *testing.T) {
+	im := &Importer{
+		Db: &dboperations.MockIDbOperations{
+			ListRelatedProductsfunc: func(ctx context.Context) ([]models.RelatedProducts, error) {
+				return nil, errors.New("scan failed")
+			},
+		},
+		Log: testLogger(),
+	}
+	_, err := im.Import(context.Background(), BOMs{})
+	assert.EqualError(t, err, "scan failed")
+}
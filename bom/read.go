@@ -0,0 +1,165 @@
+package bom
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSheet is the sheet of the SKU workbook that maps software to BOMs
+const DefaultSheet = "SKU Data - Software "
+
+// Column headers of the SKU sheet
+const (
+	softwareHeader   = "Software"
+	uiNewHeader      = "UI - Presentable Software Name (NEW)"
+	uiOldHeader      = "UI - Presentable Software Name (OLD)"
+	mappedSkusHeader = "Mapped SKUs"
+)
+
+// BOMs maps each BOM to its products, keyed by product name with the
+// display name as value
+type BOMs map[string]map[string]string
+
+// ReadFile reads BOMs from an xlsx workbook, a CSV export of its sheet or a
+// YAML file, chosen by the file extension
+func ReadFile(path string, sheet string) (BOMs, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		return ParseRows(rows)
+	case ".csv":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadCSV(f)
+	case ".yaml", ".yml":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadYAML(f)
+	default:
+		return nil, fmt.Errorf("unsupported BOM file %s, expected .xlsx, .csv or .yaml", path)
+	}
+}
+
+// ReadCSV reads a CSV export of the SKU sheet
+func ReadCSV(r io.Reader) (BOMs, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return ParseRows(rows)
+}
+
+// ReadYAML reads BOMs written as a map of BOM to product to display name
+func ReadYAML(r io.Reader) (BOMs, error) {
+	boms := BOMs{}
+	if err := yaml.NewDecoder(r).Decode(&boms); err != nil && err != io.EOF {
+		return nil, err
+	}
+	for bom, products := range boms {
+		if products == nil {
+			boms[bom] = map[string]string{}
+		}
+	}
+	return boms, nil
+}
+
+// ParseRows reads the rows of the SKU sheet. The header row names the
+// Software, display name and Mapped SKUs columns; every column after Mapped
+// SKUs is a BOM, and an x in it marks the software of that row as part of
+// the BOM.
+func ParseRows(rows [][]string) (BOMs, error) {
+	headerRow := -1
+	for i, row := range rows {
+		if indexOf(row, softwareHeader) != -1 && indexOf(row, mappedSkusHeader) != -1 {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow == -1 {
+		return nil, fmt.Errorf("header row with %q and %q columns not found", softwareHeader, mappedSkusHeader)
+	}
+
+	header := rows[headerRow]
+	softwareCol := indexOf(header, softwareHeader)
+	uiNewCol := indexOf(header, uiNewHeader)
+	uiOldCol := indexOf(header, uiOldHeader)
+	mappedSkusCol := indexOf(header, mappedSkusHeader)
+
+	boms := BOMs{}
+	for col := mappedSkusCol + 1; col < len(header); col++ {
+		bom := strings.TrimSpace(header[col])
+		if bom == "" {
+			continue
+		}
+		products := map[string]string{}
+		for _, row := range rows[headerRow+1:] {
+			if !strings.EqualFold(cell(row, col), "x") {
+				continue
+			}
+			key := productKey(cell(row, softwareCol))
+			if key == "" {
+				continue
+			}
+			name := cell(row, uiNewCol)
+			if name == "" {
+				name = cell(row, uiOldCol)
+			}
+			if name == "" {
+				name = key
+			}
+			products[key] = name
+		}
+		// BOMs without products are kept so they can be served as empty
+		boms[bom] = products
+	}
+	return boms, nil
+}
+
+// productKey turns the software column into a product name: the first
+// word, lower cased
+func productKey(software string) string {
+	fields := strings.Fields(software)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+func indexOf(row []string, header string) int {
+	for i, col := range row {
+		if strings.TrimSpace(col) == header {
+			return i
+		}
+	}
+	return -1
+}
+
+func cell(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[col])
+}
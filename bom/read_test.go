@@ -0,0 +1,84 @@
+package bom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var sheetRows = [][]string{
+	{"SKU Data"},
+	{},
+	{"Software", "UI - Presentable Software Name (NEW)", "UI - Presentable Software Name (OLD)", "Mapped SKUs", "BOM-1", "BOM-2", "BOM-3"},
+	{"Chef Infra Client", "Chef Infra Client", "", "", "x", "X", ""},
+	{"InSpec (Legacy)", "", "InSpec", "", "x", "", ""},
+	{"habitat", "", "", "", "", "x"},
+}
+
+var sheetBOMs = BOMs{
+	"BOM-1": {"chef": "Chef Infra Client", "inspec": "InSpec"},
+	"BOM-2": {"chef": "Chef Infra Client", "habitat": "habitat"},
+	"BOM-3": {},
+}
+
+func TestParseRows(t *testing.T) {
+	got, err := ParseRows(sheetRows)
+	require.NoError(t, err)
+	assert.Equal(t, sheetBOMs, got)
+
+	_, err = ParseRows([][]string{{"Software", "Name"}})
+	assert.ErrorContains(t, err, "header row")
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	xlsx := filepath.Join(dir, "skus.xlsx")
+	f := excelize.NewFile()
+	_, err := f.NewSheet(DefaultSheet)
+	require.NoError(t, err)
+	for i, row := range sheetRows {
+		cells := make([]interface{}, len(row))
+		for j, v := range row {
+			cells[j] = v
+		}
+		cellName, err := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow(DefaultSheet, cellName, &cells))
+	}
+	require.NoError(t, f.SaveAs(xlsx))
+
+	var csvRows []string
+	for _, row := range sheetRows {
+		csvRows = append(csvRows, strings.Join(row, ","))
+	}
+	csvFile := filepath.Join(dir, "skus.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte(strings.Join(csvRows, "\n")), 0o644))
+
+	yamlFile := filepath.Join(dir, "boms.yaml")
+	require.NoError(t, os.WriteFile(yamlFile, []byte(`
+BOM-1:
+  chef: Chef Infra Client
+  inspec: InSpec
+BOM-2:
+  chef: Chef Infra Client
+  habitat: habitat
+BOM-3:
+`), 0o644))
+
+	for _, path := range []string{xlsx, csvFile, yamlFile} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			got, err := ReadFile(path, DefaultSheet)
+			require.NoError(t, err)
+			assert.Equal(t, sheetBOMs, got)
+		})
+	}
+
+	_, err = ReadFile(filepath.Join(dir, "boms.json"), DefaultSheet)
+	assert.ErrorContains(t, err, "unsupported BOM file")
+}
@@ -0,0 +1,27 @@
+package catalog
+
+import (
+	"context"
+	"io"
+)
+
+type MockBucket struct {
+	ExistsFunc func(ctx context.Context, key string) (bool, error)
+	OpenFunc   func(ctx context.Context, key string) (io.ReadCloser, error)
+	DirsFunc   func(ctx context.Context, prefix string) ([]string, error)
+}
+
+func (m *MockBucket) Exists(ctx context.Context, key string) (bool, error) {
+	return m.ExistsFunc(ctx, key)
+}
+
+func (m *MockBucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return m.OpenFunc(ctx, key)
+}
+
+func (m *MockBucket) Dirs(ctx context.Context, prefix string) ([]string, error) {
+	if m.DirsFunc == nil {
+		return nil, nil
+	}
+	return m.DirsFunc(ctx, prefix)
+}
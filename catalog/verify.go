@@ -0,0 +1,267 @@
+// Package catalog checks that the catalog kept in DynamoDB agrees with the
+// files stored in S3 and with the products the service exposes.
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Kinds of findings
+const (
+	MissingFile      = "missing_file"
+	ChecksumMismatch = "checksum_mismatch"
+	OrphanVersion    = "orphan_version"
+	UnknownPlatform  = "unknown_platform"
+	UnknownProduct   = "unknown_product"
+)
+
+// Finding is a single inconsistency between the catalog and what it refers to
+type Finding struct {
+	Kind           string `json:"kind"`
+	Channel        string `json:"channel,omitempty"`
+	Product        string `json:"product,omitempty"`
+	Version        string `json:"version,omitempty"`
+	Platform       string `json:"platform,omitempty"`
+	Architecture   string `json:"architecture,omitempty"`
+	PackageManager string `json:"package_manager,omitempty"`
+	Bom            string `json:"bom,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Detail         string `json:"detail"`
+}
+
+// Report lists the findings of a run
+type Report struct {
+	// Files is the number of package files checked
+	Files    int       `json:"files"`
+	Findings []Finding `json:"findings"`
+}
+
+// Channel names a channel and the table holding its package details
+type Channel struct {
+	Name  string
+	Table string
+}
+
+// Database is what the verifier reads from the catalog
+type Database interface {
+	dboperations.CatalogLister
+	dboperations.RelatedProductsStore
+}
+
+// Verifier walks the infra package details of each channel and the related
+// products table
+type Verifier struct {
+	Db       Database
+	Bucket   Bucket
+	S3Config config.S3Config
+	Channels []Channel
+	// Products are the products exposed by /products. BOMs may only refer
+	// to these.
+	Products []string
+	// Checksums streams every file to compare its SHA256 with the catalog
+	// instead of only checking that it exists
+	Checksums bool
+	Log       *log.Entry
+}
+
+// Run checks the whole catalog. Errors reaching the database or bucket stop
+// the run; inconsistencies are reported as findings.
+func (v *Verifier) Run(ctx context.Context) (*Report, error) {
+	report := &Report{Findings: []Finding{}}
+	platforms := dbPlatforms()
+
+	for _, channel := range v.Channels {
+		details, err := v.Db.ListPackageDetails(ctx, channel.Table)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", channel.Table, err)
+		}
+		sort.Slice(details, func(i, j int) bool {
+			if details[i].Product != details[j].Product {
+				return details[i].Product < details[j].Product
+			}
+			return details[i].Version < details[j].Version
+		})
+
+		versions := map[string]map[string]bool{}
+		for _, d := range details {
+			if versions[d.Product] == nil {
+				versions[d.Product] = map[string]bool{}
+			}
+			versions[d.Product][d.Version] = true
+			if err := v.verifyPackages(ctx, channel.Name, d, platforms, report); err != nil {
+				return nil, err
+			}
+		}
+		if err := v.verifyOrphans(ctx, channel.Name, versions, report); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := v.verifyBoms(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (v *Verifier) verifyPackages(ctx context.Context, channel string, d models.PackageDetails, platforms map[string]bool, report *Report) error {
+	for _, platform := range sortedKeys(d.Metadata) {
+		if !platforms[platform] {
+			report.Findings = append(report.Findings, Finding{
+				Kind:     UnknownPlatform,
+				Channel:  channel,
+				Product:  d.Product,
+				Version:  d.Version,
+				Platform: platform,
+				Detail:   fmt.Sprintf("platform %s is not in the platform registry", platform),
+			})
+		}
+		archs := d.Metadata[platform]
+		for _, arch := range sortedKeys(archs) {
+			managers := archs[arch]
+			for _, pm := range sortedKeys(managers) {
+				pkg := managers[pm]
+				finding := Finding{
+					Channel:        channel,
+					Product:        d.Product,
+					Version:        d.Version,
+					Platform:       platform,
+					Architecture:   arch,
+					PackageManager: pm,
+					Key:            s3aws.InfraObjectKey(v.S3Config, channel, d.Product, d.Version, platform, arch, pkg.Filename),
+				}
+				report.Files++
+				if err := v.verifyFile(ctx, pkg, finding, report); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) verifyFile(ctx context.Context, pkg models.PackageType, finding Finding, report *Report) error {
+	if !v.Checksums {
+		exists, err := v.Bucket.Exists(ctx, finding.Key)
+		if err != nil {
+			return fmt.Errorf("checking %s: %w", finding.Key, err)
+		}
+		if !exists {
+			finding.Kind = MissingFile
+			finding.Detail = "no object at " + finding.Key
+			report.Findings = append(report.Findings, finding)
+		}
+		return nil
+	}
+
+	v.Log.WithField("key", finding.Key).Debug("Computing checksum")
+	body, err := v.Bucket.Open(ctx, finding.Key)
+	if err != nil {
+		exists, existsErr := v.Bucket.Exists(ctx, finding.Key)
+		if existsErr != nil || exists {
+			return fmt.Errorf("reading %s: %w", finding.Key, err)
+		}
+		finding.Kind = MissingFile
+		finding.Detail = "no object at " + finding.Key
+		report.Findings = append(report.Findings, finding)
+		return nil
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return fmt.Errorf("reading %s: %w", finding.Key, err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(sum, pkg.SHA256) {
+		finding.Kind = ChecksumMismatch
+		finding.Detail = fmt.Sprintf("catalog sha256 %s, object sha256 %s", pkg.SHA256, sum)
+		report.Findings = append(report.Findings, finding)
+	}
+	return nil
+}
+
+// verifyOrphans reports versions stored in the bucket that the catalog of
+// the channel does not list
+func (v *Verifier) verifyOrphans(ctx context.Context, channel string, versions map[string]map[string]bool, report *Report) error {
+	for _, product := range sortedKeys(versions) {
+		prefix := s3aws.ChannelPath(v.S3Config, channel) + "/" + product + "/"
+		dirs, err := v.Bucket.Dirs(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", prefix, err)
+		}
+		sort.Strings(dirs)
+		for _, version := range dirs {
+			if versions[product][version] {
+				continue
+			}
+			report.Findings = append(report.Findings, Finding{
+				Kind:    OrphanVersion,
+				Channel: channel,
+				Product: product,
+				Version: version,
+				Key:     prefix + version + "/",
+				Detail:  fmt.Sprintf("version %s is stored but not in the catalog", version),
+			})
+		}
+	}
+	return nil
+}
+
+// verifyBoms reports BOMs referring to products /products does not expose
+func (v *Verifier) verifyBoms(ctx context.Context, report *Report) error {
+	boms, err := v.Db.ListRelatedProducts(ctx)
+	if err != nil {
+		return fmt.Errorf("listing related products: %w", err)
+	}
+	sort.Slice(boms, func(i, j int) bool { return boms[i].Bom < boms[j].Bom })
+
+	exposed := map[string]bool{}
+	for _, p := range v.Products {
+		exposed[p] = true
+	}
+	for _, bom := range boms {
+		for _, product := range sortedKeys(bom.Products) {
+			if exposed[product] {
+				continue
+			}
+			report.Findings = append(report.Findings, Finding{
+				Kind:    UnknownProduct,
+				Product: product,
+				Bom:     bom.Bom,
+				Detail:  fmt.Sprintf("BOM %s refers to %s, which /products does not expose", bom.Bom, product),
+			})
+		}
+	}
+	return nil
+}
+
+// dbPlatforms returns the platform keys packages may be stored under
+func dbPlatforms() map[string]bool {
+	platforms := map[string]bool{}
+	for _, p := range omnitruck.BuildPlatformCatalog(nil) {
+		platforms[p.DbPlatform] = true
+	}
+	return platforms
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
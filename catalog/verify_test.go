@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sum(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+func TestVerifier_Run(t *testing.T) {
+	objects := map[string]string{
+		"files/stable/chef-ice/19.1.27/linux/x86_64/chef-ice.rpm":   "rpm",
+		"files/stable/chef-ice/19.1.27/windows/x86_64/chef-ice.msi": "tampered",
+	}
+	details := []models.PackageDetails{
+		{
+			Product: "chef-ice",
+			Version: "19.1.27",
+			Metadata: map[string]models.Platform{
+				"linux":   {"x86_64": {"rpm": {Filename: "chef-ice.rpm", SHA256: sum("rpm")}, "deb": {Filename: "chef-ice.deb", SHA256: sum("deb")}}},
+				"windows": {"x86_64": {"msi": {Filename: "chef-ice.msi", SHA256: sum("msi")}}},
+				"beos":    {},
+			},
+		},
+	}
+	db := &dboperations.MockIDbOperations{
+		ListPackageDetailsfunc: func(ctx context.Context, tableName string) ([]models.PackageDetails, error) {
+			if tableName == "stable-table" {
+				return details, nil
+			}
+			return nil, nil
+		},
+		ListRelatedProductsfunc: func(ctx context.Context) ([]models.RelatedProducts, error) {
+			return []models.RelatedProducts{
+				{Bom: "BOM-1", Products: map[string]string{"chef-ice": "Chef Infra Client Enterprise", "courier": "Courier"}},
+			}, nil
+		},
+	}
+	bucket := &MockBucket{
+		ExistsFunc: func(ctx context.Context, key string) (bool, error) {
+			_, ok := objects[key]
+			return ok, nil
+		},
+		OpenFunc: func(ctx context.Context, key string) (io.ReadCloser, error) {
+			content, ok := objects[key]
+			if !ok {
+				return nil, errors.New("NoSuchKey")
+			}
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+		DirsFunc: func(ctx context.Context, prefix string) ([]string, error) {
+			assert.Equal(t, "files/stable/chef-ice/", prefix)
+			return []string{"19.1.27", "19.0.1"}, nil
+		},
+	}
+
+	missingDeb := Finding{
+		Kind: MissingFile, Channel: "stable", Product: "chef-ice", Version: "19.1.27", Platform: "linux", Architecture: "x86_64", PackageManager: "deb",
+		Key:    "files/stable/chef-ice/19.1.27/linux/x86_64/chef-ice.deb",
+		Detail: "no object at files/stable/chef-ice/19.1.27/linux/x86_64/chef-ice.deb",
+	}
+	unknownPlatform := Finding{
+		Kind: UnknownPlatform, Channel: "stable", Product: "chef-ice", Version: "19.1.27", Platform: "beos",
+		Detail: "platform beos is not in the platform registry",
+	}
+	orphan := Finding{
+		Kind: OrphanVersion, Channel: "stable", Product: "chef-ice", Version: "19.0.1",
+		Key:    "files/stable/chef-ice/19.0.1/",
+		Detail: "version 19.0.1 is stored but not in the catalog",
+	}
+	unknownProduct := Finding{
+		Kind: UnknownProduct, Product: "courier", Bom: "BOM-1",
+		Detail: "BOM BOM-1 refers to courier, which /products does not expose",
+	}
+	mismatch := Finding{
+		Kind: ChecksumMismatch, Channel: "stable", Product: "chef-ice", Version: "19.1.27", Platform: "windows", Architecture: "x86_64", PackageManager: "msi",
+		Key:    "files/stable/chef-ice/19.1.27/windows/x86_64/chef-ice.msi",
+		Detail: "catalog sha256 " + sum("msi") + ", object sha256 " + sum("tampered"),
+	}
+
+	tests := []struct {
+		name      string
+		checksums bool
+		want      []Finding
+	}{
+		{name: "existence", want: []Finding{unknownPlatform, missingDeb, orphan, unknownProduct}},
+		{name: "checksums", checksums: true, want: []Finding{unknownPlatform, missingDeb, mismatch, orphan, unknownProduct}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Verifier{
+				Db:        db,
+				Bucket:    bucket,
+				S3Config:  config.S3Config{CurrentPath: "files/current", StablePath: "files/stable"},
+				Channels:  []Channel{{Name: "current", Table: "current-table"}, {Name: "stable", Table: "stable-table"}},
+				Products:  []string{"chef-ice"},
+				Checksums: tt.checksums,
+				Log:       log.NewEntry(log.New()),
+			}
+			report, err := v.Run(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 3, report.Files)
+			assert.Equal(t, tt.want, report.Findings)
+		})
+	}
+}
+
+func TestVerifier_RunErrors(t *testing.T) {
+	v := &Verifier{
+		Db: &dboperations.MockIDbOperations{
+			ListPackageDetailsfunc: func(ctx context.Context, tableName string) ([]models.PackageDetails, error) {
+				return nil, errors.New("scan failed")
+			},
+		},
+		Channels: []Channel{{Name: "stable", Table: "stable-table"}},
+		Log:      log.NewEntry(log.New()),
+	}
+	_, err := v.Run(context.Background())
+	assert.EqualError(t, err, "listing stable-table: scan failed")
+
+	v.Db = &dboperations.MockIDbOperations{
+		ListPackageDetailsfunc: func(ctx context.Context, tableName string) ([]models.PackageDetails, error) {
+			return []models.PackageDetails{{Product: "chef-ice", Version: "19.1.27", Metadata: map[string]models.Platform{
+				"linux": {"x86_64": {"rpm": {Filename: "chef-ice.rpm"}}},
+			}}}, nil
+		},
+	}
+	v.Bucket = &MockBucket{ExistsFunc: func(ctx context.Context, key string) (bool, error) {
+		return false, errors.New("access denied")
+	}}
+	_, err = v.Run(context.Background())
+	assert.EqualError(t, err, "checking /chef-ice/19.1.27/linux/x86_64/chef-ice.rpm: access denied")
+}
@@ -1,9 +1,11 @@
 package clients
 
+import "context"
+
 type ILicense interface {
-	Get(url string) *Request
-	Validate(id, licenseServiceUrl string, data *Response) *Request
-	GetReplicatedCustomerEmail(licenseId, licenseServiceUrl string, data *Response) *Request
+	Get(ctx context.Context, url string) *Request
+	Validate(ctx context.Context, id, licenseServiceUrl string, data *Response) *Request
+	GetReplicatedCustomerEmail(ctx context.Context, licenseId, licenseServiceUrl string, data *Response) *Request
 	IsTrial(l string) bool
 	IsFree(l string) bool
 }
@@ -1,12 +1,14 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/chef/omnitruck-service/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -41,28 +43,39 @@ func NewLicenseClient() ILicense {
 	}
 }
 
-func (c *License) Get(url string) *Request {
+func (c *License) Get(ctx context.Context, url string) *Request {
 	request := Request{
 		Url: url,
 	}
 
-	req, err := http.NewRequest("GET", request.Url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", request.Url, nil)
 
 	if err != nil {
-		return request.Failure(fiber.StatusBadRequest, utils.LicenseReqError)
+		return request.Failure(fiber.StatusBadRequest, utils.LicenseReqError).WithErrorCode(apierror.CodeLicenseUnavailable)
 	}
 
 	req.Header.Add("Accept", "application/json")
 	resp, err := c.client.Do(req)
-	request.Code = resp.StatusCode
-
 	if err != nil {
-		return request.Failure(request.Code, utils.LicenseApiError)
+		// The license service could not be reached
+		return request.Failure(fiber.StatusServiceUnavailable, utils.LicenseApiError).WithErrorCode(apierror.CodeLicenseUnavailable)
 	}
+	defer resp.Body.Close()
+	request.Code = resp.StatusCode
 
 	request.Body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return request.Failure(fiber.StatusBadRequest, utils.LicenseApiError)
+		return request.Failure(fiber.StatusServiceUnavailable, utils.LicenseApiError).WithErrorCode(apierror.CodeLicenseUnavailable)
+	}
+
+	// Failures of the license service itself are not passed on to the user
+	switch {
+	case request.Code == fiber.StatusTooManyRequests:
+		request.Body = nil
+		return request.Failure(request.Code, utils.ThrottledError).WithErrorCode(apierror.CodeThrottled)
+	case request.Code >= 500:
+		request.Body = nil
+		return request.Failure(fiber.StatusServiceUnavailable, utils.LicenseApiError).WithErrorCode(apierror.CodeLicenseUnavailable)
 	}
 
 	if request.Code != 200 {
@@ -74,15 +87,15 @@ func (c *License) Get(url string) *Request {
 	return request.Success()
 }
 
-func (c *License) Validate(id, licenseServiceUrl string, data *Response) *Request {
+func (c *License) Validate(ctx context.Context, id, licenseServiceUrl string, data *Response) *Request {
 	licenseApi := licenseServiceUrl
 	url := fmt.Sprintf("%s/v1/validate?licenseId=%s", licenseApi, id)
-	return c.Get(url).ParseLicenseResp(&data)
+	return c.Get(ctx, url).ParseLicenseResp(&data)
 }
 
-func (c *License) GetReplicatedCustomerEmail(licenseId, licenseServiceUrl string, data *Response) *Request {
+func (c *License) GetReplicatedCustomerEmail(ctx context.Context, licenseId, licenseServiceUrl string, data *Response) *Request {
 	requestUrl := fmt.Sprintf("%s/v1/getReplicatedCustomer?licenseId=%s", licenseServiceUrl, licenseId)
-	return c.Get(requestUrl).ParseLicenseResp(&data)
+	return c.Get(ctx, requestUrl).ParseLicenseResp(&data)
 }
 
 func (c *License) IsTrial(l string) bool {
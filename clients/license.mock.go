@@ -1,13 +1,14 @@
 package clients
 
 import (
+	"context"
 	"net/http"
 )
 
 type MockLicense struct {
-	GetFunc                        func(url string) *Request
-	ValidateFunc                   func(id, licenseServiceUrl string, data *Response) *Request
-	GetReplicatedCustomerEmailFunc func(licenseId, licenseServiceUrl string, data *Response) *Request
+	GetFunc                        func(ctx context.Context, url string) *Request
+	ValidateFunc                   func(ctx context.Context, id, licenseServiceUrl string, data *Response) *Request
+	GetReplicatedCustomerEmailFunc func(ctx context.Context, licenseId, licenseServiceUrl string, data *Response) *Request
 	IsTrialFunc                    func(l string) bool
 	IsFreeFunc                     func(l string) bool
 }
@@ -33,16 +34,16 @@ func NewMockLicenseClient() ILicense {
 	}
 }
 
-func (m *MockLicense) GetReplicatedCustomerEmail(licenseId, licenseServiceUrl string, data *Response) *Request {
-	return m.GetReplicatedCustomerEmailFunc(licenseId, licenseServiceUrl, data)
+func (m *MockLicense) GetReplicatedCustomerEmail(ctx context.Context, licenseId, licenseServiceUrl string, data *Response) *Request {
+	return m.GetReplicatedCustomerEmailFunc(ctx, licenseId, licenseServiceUrl, data)
 }
 
-func (m *MockLicense) Get(url string) *Request {
-	return m.GetFunc(url)
+func (m *MockLicense) Get(ctx context.Context, url string) *Request {
+	return m.GetFunc(ctx, url)
 }
 
-func (m *MockLicense) Validate(id, licenseServiceUrl string, data *Response) *Request {
-	return m.ValidateFunc(id, licenseServiceUrl, data)
+func (m *MockLicense) Validate(ctx context.Context, id, licenseServiceUrl string, data *Response) *Request {
+	return m.ValidateFunc(ctx, id, licenseServiceUrl, data)
 }
 
 func (m *MockLicense) IsTrial(l string) bool {
@@ -0,0 +1,78 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chef/omnitruck-service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicense_Validate(t *testing.T) {
+	respond := func(code int, body string) func(req *http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+	}
+	tests := []struct {
+		name     string
+		do       func(req *http.Request) (*http.Response, error)
+		wantCode int
+		wantMsg  string
+		wantOk   bool
+		wantData Response
+	}{
+		{
+			name:     "valid license",
+			do:       respond(200, `{"Data": true, "Message": "valid"}`),
+			wantCode: 200,
+			wantOk:   true,
+			wantData: Response{Data: true, Message: "valid"},
+		},
+		{
+			name:     "invalid license",
+			do:       respond(400, `{"Data": false, "Message": "license expired"}`),
+			wantCode: 400,
+			wantMsg:  `{"Data": false, "Message": "license expired"}`,
+			wantData: Response{Message: "license expired"},
+		},
+		{
+			name: "license service unreachable",
+			do: func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("dial tcp: connection refused")
+			},
+			wantCode: 503,
+			wantMsg:  utils.LicenseApiError,
+		},
+		{
+			name:     "license service failure",
+			do:       respond(500, "<html>Internal Server Error</html>"),
+			wantCode: 503,
+			wantMsg:  utils.LicenseApiError,
+		},
+		{
+			name:     "license service throttling",
+			do:       respond(429, "slow down"),
+			wantCode: 429,
+			wantMsg:  utils.ThrottledError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &License{client: &MockClient{DoFunc: tt.do}}
+			data := Response{}
+			request := client.Validate(context.Background(), "lic", "http://license.local", &data)
+			assert.Equal(t, tt.wantCode, request.Code)
+			assert.Equal(t, tt.wantOk, request.Ok)
+			assert.Equal(t, tt.wantData, data)
+			if !tt.wantOk {
+				assert.Equal(t, tt.wantMsg, request.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalLicense_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   config.LocalLicenseConfig
+		id       string
+		wantCode int
+		wantOk   bool
+	}{
+		{name: "any id", policy: config.LocalLicenseConfig{}, id: "abc", wantCode: 200, wantOk: true},
+		{name: "listed id", policy: config.LocalLicenseConfig{Ids: []string{"abc", "def"}}, id: "def", wantCode: 200, wantOk: true},
+		{name: "unlisted id", policy: config.LocalLicenseConfig{Ids: []string{"abc"}}, id: "xyz", wantCode: 403, wantOk: false},
+		{name: "empty id", policy: config.LocalLicenseConfig{}, id: "", wantCode: 403, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewLocalLicenseClient(tt.policy)
+			resp := Response{}
+			request := client.Validate(context.Background(), tt.id, "", &resp)
+			assert.Equal(t, tt.wantCode, request.Code)
+			assert.Equal(t, tt.wantOk, request.Ok)
+			assert.Equal(t, tt.wantOk, client.IsFree(tt.id))
+			assert.Equal(t, tt.wantOk, client.IsTrial(tt.id))
+		})
+	}
+}
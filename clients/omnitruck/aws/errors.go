@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/chef/omnitruck-service/internal/apierror"
+)
+
+var (
+	// ErrObjectNotFound is returned when the key does not exist in the store
+	ErrObjectNotFound = apierror.Wrap(apierror.ErrNotFound, errors.New("object not found"))
+	// ErrAccessDenied is returned when the store, or STS for the assumed
+	// role, refuses access. It is answered with 502 Bad Gateway.
+	ErrAccessDenied = apierror.Wrap(apierror.ErrAccessDenied, errors.New("access to the object store denied"))
+)
+
+// Metrics counts object store requests and failures. It is published through
+// expvar as "object_store".
+var Metrics = expvar.NewMap("object_store")
+
+const (
+	MetricS3Requests    = "s3_requests"
+	MetricS3Errors      = "s3_errors"
+	MetricSTSErrors     = "sts_errors"
+	MetricSessionErrors = "session_errors"
+	MetricNotFound      = "not_found"
+	MetricAccessDenied  = "access_denied"
+)
+
+// classifyS3Error counts a failed S3 request and wraps the error with
+// ErrObjectNotFound or ErrAccessDenied when it is one of those, or with the
+// apierror kind of any other failure.
+func classifyS3Error(err error) error {
+	sts := fromSTS(err)
+	if sts {
+		Metrics.Add(MetricSTSErrors, 1)
+	} else {
+		Metrics.Add(MetricS3Errors, 1)
+	}
+
+	code, status := "", 0
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		status = respErr.HTTPStatusCode()
+	}
+
+	switch {
+	case sts:
+		// Without credentials for the role nothing in the bucket is readable
+		Metrics.Add(MetricAccessDenied, 1)
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	case code == "NoSuchBucket":
+		// S3 answers a missing bucket with 404 as well, but it is a
+		// misconfiguration of the service rather than a missing object
+		return apierror.FromAWS(err)
+	case code == "NoSuchKey" || code == "NotFound" || status == http.StatusNotFound:
+		Metrics.Add(MetricNotFound, 1)
+		return fmt.Errorf("%w: %w", ErrObjectNotFound, err)
+	case code == "AccessDenied" || code == "InvalidAccessKeyId" || code == "SignatureDoesNotMatch" || status == http.StatusForbidden:
+		Metrics.Add(MetricAccessDenied, 1)
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	}
+	return apierror.FromAWS(err)
+}
+
+// fromSTS reports whether the error comes from assuming the role rather
+// than from S3 itself
+func fromSTS(err error) bool {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if op, ok := e.(*smithy.OperationError); ok && op.ServiceID == "STS" {
+			return true
+		}
+	}
+	return false
+}
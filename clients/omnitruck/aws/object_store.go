@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	omnitruckConfig "github.com/chef/omnitruck-service/config"
+)
+
+const (
+	ObjectStoreS3           = "s3"
+	ObjectStoreS3Compatible = "s3-compatible"
+	ObjectStoreLocal        = "local"
+)
+
+// Object is a package file read from an ObjectStore. ContentLength is -1 and
+// ContentType or ContentDisposition are empty when the store does not know them.
+type Object struct {
+	Body               io.ReadCloser
+	ContentType        string
+	ContentLength      int64
+	ContentDisposition string
+}
+
+// ObjectStore reads package files by key, the key being formulated by
+// InfraObjectKey
+type ObjectStore interface {
+	GetObject(ctx context.Context, key string) (*Object, error)
+}
+
+// NewObjectStore returns the object store selected by the S3 configuration
+func NewObjectStore(cfg omnitruckConfig.AWSConfig) (ObjectStore, error) {
+	if err := ValidateS3Config(cfg); err != nil {
+		return nil, err
+	}
+
+	switch cfg.S3Config.Type {
+	case ObjectStoreLocal:
+		return NewLocalObjectStore(cfg.S3Config.Dir), nil
+	case ObjectStoreS3Compatible:
+		return NewS3CompatibleObjectStore(cfg), nil
+	default:
+		return NewS3ObjectStore(cfg), nil
+	}
+}
+
+// S3ObjectStore reads objects from an S3 bucket, or from a bucket of an S3
+// compatible service, through a long-lived client
+type S3ObjectStore struct {
+	Bucket  string
+	Clients *S3ClientFactory
+}
+
+// NewS3ObjectStore reads from AWS S3 with credentials of the assumed role
+func NewS3ObjectStore(cfg omnitruckConfig.AWSConfig) *S3ObjectStore {
+	return &S3ObjectStore{
+		Bucket: cfg.S3Config.Bucket,
+		Clients: &S3ClientFactory{
+			Region:  cfg.Region,
+			RoleArn: cfg.S3Config.RoleArn,
+		},
+	}
+}
+
+// NewS3CompatibleObjectStore reads from an S3 compatible service such as
+// MinIO, using path style addressing and the configured access keys when set.
+func NewS3CompatibleObjectStore(cfg omnitruckConfig.AWSConfig) *S3ObjectStore {
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3ObjectStore{
+		Bucket: cfg.S3Config.Bucket,
+		Clients: &S3ClientFactory{
+			Region:       region,
+			RoleArn:      cfg.S3Config.RoleArn,
+			Endpoint:     cfg.S3Config.Endpoint,
+			UsePathStyle: true,
+			AccessKey:    cfg.AccessKey,
+			SecretKey:    cfg.SecretKey,
+		},
+	}
+}
+
+// GetObject fetches the object, reporting a missing key as ErrObjectNotFound
+// and refused access, to S3 or to the assumed role, as ErrAccessDenied
+func (s *S3ObjectStore) GetObject(ctx context.Context, key string) (*Object, error) {
+	client, err := s.Clients.Client()
+	if err != nil {
+		Metrics.Add(MetricSessionErrors, 1)
+		return nil, err
+	}
+	Metrics.Add(MetricS3Requests, 1)
+	result, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, classifyS3Error(err)
+	}
+
+	obj := &Object{
+		Body:               result.Body,
+		ContentType:        aws.ToString(result.ContentType),
+		ContentLength:      -1,
+		ContentDisposition: aws.ToString(result.ContentDisposition),
+	}
+	if result.ContentLength != nil {
+		obj.ContentLength = *result.ContentLength
+	}
+	return obj, nil
+}
+
+// LocalObjectStore reads objects from files under a directory, the key being
+// the path of the file relative to it
+type LocalObjectStore struct {
+	files fs.FS
+}
+
+func NewLocalObjectStore(dir string) *LocalObjectStore {
+	return &LocalObjectStore{files: os.DirFS(dir)}
+}
+
+func (s *LocalObjectStore) GetObject(ctx context.Context, key string) (*Object, error) {
+	name := strings.TrimPrefix(key, "/")
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid object key %q", key)
+	}
+	f, err := s.files.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrObjectNotFound, err)
+		}
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("object key %q is a directory", key)
+	}
+
+	return &Object{
+		Body:          f,
+		ContentType:   mime.TypeByExtension(path.Ext(name)),
+		ContentLength: info.Size(),
+	}, nil
+}
@@ -0,0 +1,13 @@
+package aws
+
+import (
+	"context"
+)
+
+type MockObjectStore struct {
+	GetObjectFunc func(ctx context.Context, key string) (*Object, error)
+}
+
+func (m *MockObjectStore) GetObject(ctx context.Context, key string) (*Object, error) {
+	return m.GetObjectFunc(ctx, key)
+}
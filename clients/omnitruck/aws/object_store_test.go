@@ -0,0 +1,189 @@
+package aws_test
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	omnitruckaws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
+	omnitruckConfig "github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewObjectStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      omnitruckConfig.AWSConfig
+		wantType interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "s3 by default",
+			cfg:      omnitruckConfig.AWSConfig{Region: "us-east-1", S3Config: omnitruckConfig.S3Config{Bucket: "bucket", RoleArn: "role"}},
+			wantType: &omnitruckaws.S3ObjectStore{},
+		},
+		{
+			name:     "s3 compatible",
+			cfg:      omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Type: omnitruckaws.ObjectStoreS3Compatible, Bucket: "bucket", Endpoint: "http://localhost:9000"}},
+			wantType: &omnitruckaws.S3ObjectStore{},
+		},
+		{
+			name:     "local",
+			cfg:      omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Type: omnitruckaws.ObjectStoreLocal, Dir: "/srv/packages"}},
+			wantType: &omnitruckaws.LocalObjectStore{},
+		},
+		{
+			name:    "incomplete s3",
+			cfg:     omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Bucket: "bucket"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			cfg:     omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Type: "gcs"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := omnitruckaws.NewObjectStore(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.wantType, store)
+		})
+	}
+}
+
+func TestNewS3CompatibleObjectStore(t *testing.T) {
+	store := omnitruckaws.NewS3CompatibleObjectStore(omnitruckConfig.AWSConfig{
+		AccessKey: "minio",
+		SecretKey: "secret",
+		S3Config:  omnitruckConfig.S3Config{Bucket: "bucket", Endpoint: "http://localhost:9000"},
+	})
+	assert.Equal(t, "us-east-1", store.Clients.Region)
+	assert.Equal(t, "http://localhost:9000", store.Clients.Endpoint)
+	assert.True(t, store.Clients.UsePathStyle)
+	assert.Equal(t, "minio", store.Clients.AccessKey)
+}
+
+func TestS3ClientFactory_Client(t *testing.T) {
+	factory := &omnitruckaws.S3ClientFactory{Region: "us-east-1", RoleArn: "arn:aws:iam::123456789012:role/test-role"}
+	first, err := factory.Client()
+	require.NoError(t, err)
+	second, err := factory.Client()
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+}
+
+func TestS3ObjectStore_GetObject(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Header().Set("Content-Type", "application/x-rpm")
+		w.Header().Set("Content-Length", "7")
+		w.Write([]byte("package"))
+	}))
+	defer server.Close()
+
+	store := omnitruckaws.NewS3CompatibleObjectStore(omnitruckConfig.AWSConfig{
+		AccessKey: "minio",
+		SecretKey: "secret",
+		S3Config:  omnitruckConfig.S3Config{Bucket: "bucket", Endpoint: server.URL},
+	})
+	obj, err := store.GetObject(context.Background(), "stable/chef-ice/chef-ice.rpm")
+	require.NoError(t, err)
+	defer obj.Body.Close()
+	content, err := io.ReadAll(obj.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "package", string(content))
+	assert.Equal(t, "/bucket/stable/chef-ice/chef-ice.rpm", requested)
+	assert.Equal(t, "application/x-rpm", obj.ContentType)
+	assert.Equal(t, int64(7), obj.ContentLength)
+}
+
+func TestS3ObjectStore_GetObjectErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		code       string
+		wantErr    error
+		wantStatus int
+		metric     string
+	}{
+		{name: "missing key", status: http.StatusNotFound, code: "NoSuchKey", wantErr: omnitruckaws.ErrObjectNotFound, wantStatus: http.StatusNotFound, metric: omnitruckaws.MetricNotFound},
+		{name: "missing bucket", status: http.StatusNotFound, code: "NoSuchBucket", wantStatus: http.StatusServiceUnavailable, metric: omnitruckaws.MetricS3Errors},
+		{name: "access denied", status: http.StatusForbidden, code: "AccessDenied", wantErr: omnitruckaws.ErrAccessDenied, wantStatus: http.StatusBadGateway, metric: omnitruckaws.MetricAccessDenied},
+		{name: "bad request", status: http.StatusBadRequest, code: "InvalidRequest", wantStatus: http.StatusBadRequest, metric: omnitruckaws.MetricS3Errors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(tt.status)
+				w.Write([]byte("<Error><Code>" + tt.code + "</Code><Message>failed</Message></Error>"))
+			}))
+			defer server.Close()
+
+			store := omnitruckaws.NewS3CompatibleObjectStore(omnitruckConfig.AWSConfig{
+				AccessKey: "minio",
+				SecretKey: "secret",
+				S3Config:  omnitruckConfig.S3Config{Bucket: "bucket", Endpoint: server.URL},
+			})
+			before := metricValue(tt.metric)
+			_, err := store.GetObject(context.Background(), "stable/chef-ice/chef-ice.rpm")
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NotErrorIs(t, err, omnitruckaws.ErrObjectNotFound)
+				assert.NotErrorIs(t, err, omnitruckaws.ErrAccessDenied)
+			}
+			status, _, _ := apierror.Status(err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, before+1, metricValue(tt.metric))
+		})
+	}
+}
+
+func metricValue(name string) int64 {
+	if v, ok := omnitruckaws.Metrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestLocalObjectStore_GetObject(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "stable", "chef-ice"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stable", "chef-ice", "chef-ice.json"), []byte("{}"), 0o644))
+	store := omnitruckaws.NewLocalObjectStore(dir)
+
+	obj, err := store.GetObject(context.Background(), "stable/chef-ice/chef-ice.json")
+	require.NoError(t, err)
+	defer obj.Body.Close()
+	assert.Equal(t, int64(2), obj.ContentLength)
+	assert.Equal(t, "application/json", obj.ContentType)
+
+	// Keys of channels without a path start with a slash
+	_, err = store.GetObject(context.Background(), "/stable/chef-ice/chef-ice.json")
+	assert.NoError(t, err)
+
+	_, err = store.GetObject(context.Background(), "stable/chef-ice/missing.rpm")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorIs(t, err, omnitruckaws.ErrObjectNotFound)
+	_, err = store.GetObject(context.Background(), "stable/../../etc/passwd")
+	assert.Error(t, err)
+	_, err = store.GetObject(context.Background(), "stable/chef-ice")
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3ClientFactory builds one S3 client and hands it out to every download.
+// Assumed role credentials are cached and refreshed before they expire, so
+// STS is only called once per credentials lifetime rather than per request.
+type S3ClientFactory struct {
+	Region       string
+	RoleArn      string
+	Endpoint     string
+	UsePathStyle bool
+	AccessKey    string
+	SecretKey    string
+
+	mu     sync.Mutex
+	client *s3.Client
+}
+
+// Client returns the shared client, creating it on first use. A failure to
+// load the AWS configuration is not remembered, the next call tries again.
+func (f *S3ClientFactory) Client() (*s3.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		return f.client, nil
+	}
+
+	cfg, err := NewS3Session(f.Region)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", err)
+	}
+	switch {
+	case f.AccessKey != "":
+		cfg.Credentials = credentials.NewStaticCredentialsProvider(f.AccessKey, f.SecretKey, "")
+	case f.RoleArn != "":
+		cfg.Credentials = aws.NewCredentialsCache(NewS3Credentials(cfg, f.RoleArn))
+	}
+
+	f.client = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if f.Endpoint != "" {
+			o.BaseEndpoint = aws.String(f.Endpoint)
+		}
+		o.UsePathStyle = f.UsePathStyle
+	})
+	return f.client, nil
+}
@@ -7,38 +7,57 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	omnitruckConfig "github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/constants"
 )
 
 // NewS3Session creates a new AWS session using aws-sdk-go-v2
-var NewS3Session = func(region string) (aws.Config, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
-	return cfg, err
+func NewS3Session(region string) (aws.Config, error) {
+	return config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
 }
 
 // NewS3Credentials returns credentials using STS AssumeRole with aws-sdk-go-v2
-var NewS3Credentials = func(cfg aws.Config, roleArn string) aws.CredentialsProvider {
+func NewS3Credentials(cfg aws.Config, roleArn string) aws.CredentialsProvider {
 	stsClient := sts.NewFromConfig(cfg)
 	return stscreds.NewAssumeRoleProvider(stsClient, roleArn)
 }
 
-// GetS3Object fetches an object from S3 using aws-sdk-go-v2
-var GetS3Object = func(ctx context.Context, cfg aws.Config, creds aws.CredentialsProvider, bucket, key string) (*s3.GetObjectOutput, error) {
-	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.Credentials = creds
-	})
-	getObjInput := &s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
+// ChannelPath returns the bucket path holding the packages of a channel
+func ChannelPath(cfg omnitruckConfig.S3Config, channel string) string {
+	switch channel {
+	case constants.CURRENT_CHANNEL:
+		return cfg.CurrentPath
+	case constants.STABLE_CHANNEL:
+		return cfg.StablePath
 	}
-	return s3Client.GetObject(ctx, getObjInput)
+	return ""
 }
 
-var ValidateS3Config = func(cfg omnitruckConfig.AWSConfig) error {
-	if cfg.Region == "" || cfg.S3Config.Bucket == "" || cfg.S3Config.RoleArn == "" {
-		return fmt.Errorf("AWS configuration is incomplete for S3 download")
+// InfraObjectKey returns the key of an infra package file, formulated as
+// path/product/version/platform/architecture/filename
+func InfraObjectKey(cfg omnitruckConfig.S3Config, channel, product, version, platform, arch, fileName string) string {
+	return ChannelPath(cfg, channel) + "/" + product + "/" + version + "/" + platform + "/" + arch + "/" + fileName
+}
+
+// ValidateS3Config checks that the configuration holds what the selected
+// object store type needs
+func ValidateS3Config(cfg omnitruckConfig.AWSConfig) error {
+	switch cfg.S3Config.Type {
+	case "", ObjectStoreS3:
+		if cfg.Region == "" || cfg.S3Config.Bucket == "" || cfg.S3Config.RoleArn == "" {
+			return fmt.Errorf("AWS configuration is incomplete for S3 download")
+		}
+	case ObjectStoreS3Compatible:
+		if cfg.S3Config.Endpoint == "" || cfg.S3Config.Bucket == "" {
+			return fmt.Errorf("S3 compatible object store needs an endpoint and a bucket")
+		}
+	case ObjectStoreLocal:
+		if cfg.S3Config.Dir == "" {
+			return fmt.Errorf("local object store needs a directory")
+		}
+	default:
+		return fmt.Errorf("unknown object store type %q", cfg.S3Config.Type)
 	}
 	return nil
 }
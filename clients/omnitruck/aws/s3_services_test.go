@@ -1,10 +1,8 @@
 package aws
 
 import (
-	"context"
 	"testing"
 
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	omnitruckConfig "github.com/chef/omnitruck-service/config"
 )
 
@@ -36,6 +34,20 @@ func TestValidateS3Config(t *testing.T) {
 	if err := ValidateS3Config(cfg); err == nil {
 		t.Error("expected error for missing role arn")
 	}
+
+	cfg = omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Type: ObjectStoreS3Compatible, Bucket: "bucket"}}
+	if err := ValidateS3Config(cfg); err == nil {
+		t.Error("expected error for missing endpoint")
+	}
+	cfg.S3Config.Endpoint = "http://localhost:9000"
+	if err := ValidateS3Config(cfg); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	cfg = omnitruckConfig.AWSConfig{S3Config: omnitruckConfig.S3Config{Type: ObjectStoreLocal}}
+	if err := ValidateS3Config(cfg); err == nil {
+		t.Error("expected error for missing directory")
+	}
 }
 
 func TestNewS3Session(t *testing.T) {
@@ -60,14 +72,19 @@ func TestNewS3Credentials(t *testing.T) {
 	}
 }
 
-func TestGetS3Object_Error(t *testing.T) {
-	cfg, err := NewS3Session("us-east-1")
-	if err != nil {
-		t.Fatalf("failed to create config: %v", err)
+func TestInfraObjectKey(t *testing.T) {
+	cfg := omnitruckConfig.S3Config{CurrentPath: "files/current", StablePath: "files/stable"}
+	tests := []struct {
+		channel string
+		want    string
+	}{
+		{channel: "current", want: "files/current/chef-ice/19.1.27/linux/x86_64/chef-ice.rpm"},
+		{channel: "stable", want: "files/stable/chef-ice/19.1.27/linux/x86_64/chef-ice.rpm"},
+		{channel: "unstable", want: "/chef-ice/19.1.27/linux/x86_64/chef-ice.rpm"},
 	}
-	creds := credentials.NewStaticCredentialsProvider("fake", "fake", "")
-	_, err = GetS3Object(context.Background(), cfg, creds, "fake-bucket", "fake-key")
-	if err == nil {
-		t.Error("expected error for fake bucket/key, got nil")
+	for _, tt := range tests {
+		if got := InfraObjectKey(cfg, tt.channel, "chef-ice", "19.1.27", "linux", "x86_64", "chef-ice.rpm"); got != tt.want {
+			t.Errorf("InfraObjectKey(%s) = %s, want %s", tt.channel, got, tt.want)
+		}
 	}
 }
@@ -0,0 +1,85 @@
+package omnitruck
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker is a circuit breaker for the upstream Omnitruck API. It opens after
+// Threshold consecutive failures and lets a single trial call through once
+// Cooldown has passed; the circuit closes again when the trial succeeds.
+// A Breaker is shared by the clients of every request. A Threshold of zero
+// never opens the circuit.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+	now      func() time.Time
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may go upstream. Every allowed call must be
+// followed by Success, Failure or Abort.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Threshold <= 0 || b.failures < b.Threshold {
+		return true
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.Cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Success closes the circuit
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure counts a failed call, opening the circuit once the threshold is
+// reached or again after a failed trial
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.Threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// Abort ends a call that tells nothing about the upstream, such as one
+// cancelled by the client, letting another trial call through
+func (b *Breaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Open reports whether calls are currently refused
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.Threshold > 0 && b.failures >= b.Threshold && (b.trial || b.now().Sub(b.openedAt) < b.Cooldown)
+}
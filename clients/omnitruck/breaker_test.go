@@ -0,0 +1,44 @@
+package omnitruck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.True(t, b.Allow(), "one failure keeps the circuit closed")
+	b.Failure()
+	assert.True(t, b.Open())
+	assert.False(t, b.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow(), "a trial call goes through after the cooldown")
+	assert.False(t, b.Allow(), "only one trial call at a time")
+	b.Failure()
+	assert.False(t, b.Allow(), "a failed trial opens the circuit again")
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	b.Abort()
+	assert.True(t, b.Allow(), "an aborted trial lets another one through")
+	b.Success()
+	assert.False(t, b.Open())
+	assert.True(t, b.Allow())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := NewBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+	assert.False(t, b.Open())
+	assert.True(t, b.Allow())
+}
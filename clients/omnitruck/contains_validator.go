@@ -3,12 +3,15 @@ package omnitruck
 import (
 	"fmt"
 	"reflect"
+
+	"github.com/chef/omnitruck-service/internal/apierror"
 )
 
 type ContainsValidator struct {
 	Field      string
 	Values     []string
 	Code       int
+	ErrorCode  string
 	AllowEmpty bool
 	Skip       func(c Context) bool
 }
@@ -43,6 +46,7 @@ func (fv *ContainsValidator) Validate(p *RequestParams, c Context) *ValidationEr
 				Value:       fieldValue,
 				Msg:         fmt.Sprintf("%s: cannot be empty", fv.Field),
 				Code:        fv.Code,
+				ErrorCode:   apierror.CodeParameterMissing,
 			}
 		}
 		if fieldValue != val {
@@ -51,9 +55,17 @@ func (fv *ContainsValidator) Validate(p *RequestParams, c Context) *ValidationEr
 				Value:       fieldValue,
 				Msg:         fmt.Sprintf("%s: %v must be one of %v", fv.Field, fieldValue, fv.Values),
 				Code:        fv.Code,
+				ErrorCode:   fv.errorCode(),
 			}
 		}
 	}
 
 	return nil
 }
+
+func (fv *ContainsValidator) errorCode() string {
+	if fv.ErrorCode != "" {
+		return fv.ErrorCode
+	}
+	return apierror.CodeRequestInvalid
+}
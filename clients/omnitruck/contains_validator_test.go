@@ -3,6 +3,7 @@ package omnitruck
 import (
 	"testing"
 
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,6 +30,26 @@ func TestContainsValidator_Validate(t *testing.T) {
 				Value:       "invalid",
 				Msg:         "Product: invalid must be one of [chef habitat]",
 				Code:        400,
+				ErrorCode:   apierror.CodeRequestInvalid,
+			},
+		},
+		{
+			name: "Invalid field value with error code",
+			validator: ContainsValidator{
+				Field:     "Channel",
+				Values:    []string{"stable"},
+				Code:      400,
+				ErrorCode: apierror.CodeChannelInvalid,
+			},
+			params: RequestParams{
+				Channel: "current",
+			},
+			wantErr: &ValidationError{
+				FailedField: "Channel",
+				Value:       "current",
+				Msg:         "Channel: current must be one of [stable]",
+				Code:        400,
+				ErrorCode:   apierror.CodeChannelInvalid,
 			},
 		},
 		{
@@ -60,6 +81,7 @@ func TestContainsValidator_Validate(t *testing.T) {
 				Value:       "",
 				Msg:         "Product: cannot be empty",
 				Code:        400,
+				ErrorCode:   apierror.CodeParameterMissing,
 			},
 		},
 		{
@@ -1,12 +1,15 @@
 package omnitruck
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/chef/omnitruck-service/models"
 	"github.com/chef/omnitruck-service/utils"
 	"github.com/gofiber/fiber/v2"
@@ -25,24 +28,49 @@ func NewDynamoServices(db dboperations.IDbOperations, log *log.Entry) DynamoServ
 	}
 }
 
+// dbError turns a catalog error into the error answered to the client. The
+// apierror kind decides the status; an unavailable catalog and unclassified
+// errors keep msg, the latter as a 500.
+func dbError(err error, msg string) error {
+	code, kindMsg, ok := apierror.Status(err)
+	switch {
+	case !ok:
+		return apierror.WithCode(apierror.CodeCatalogUnavailable, fiber.NewError(fiber.StatusInternalServerError, msg))
+	case errors.Is(err, apierror.ErrUnavailable) || errors.Is(err, apierror.ErrAccessDenied):
+		return apierror.WithCode(apierror.CodeCatalogUnavailable, fiber.NewError(code, msg))
+	case errors.Is(err, apierror.ErrNotFound):
+		return apierror.WithCode(apierror.CodeProductNotFound, fiber.NewError(code, kindMsg))
+	default:
+		return apierror.WithCode(apierror.CodeOf(err), fiber.NewError(code, kindMsg))
+	}
+}
+
+// notFoundError is answered when the catalog has no entry for the request
+func notFoundError() error {
+	return apierror.WithCode(apierror.CodeProductNotFound, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError))
+}
+
 func (svc *DynamoServices) SetDbInfo(table string, dbModelType reflect.Type) {
 	// Setting the dyanomo table
 	svc.db.SetDbInfo(table, dbModelType)
 }
 
-func (svc *DynamoServices) Products(products []string, eol string) []string {
+// Products adds the products served from the catalog to products, along with
+// extra ones such as the products feature flags route away from Omnitruck
+func (svc *DynamoServices) Products(products []string, eol string, extra ...string) []string {
 	productMap := make(map[string]bool)
 	for _, p := range products {
 		productMap[p] = true
 	}
 
-	toAdd := []string{constants.HABITAT_PRODUCT, constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT, constants.MIGRATE_ICE, constants.CHEF_INSPEC_ENTERPRISE_PRODUCT, constants.CHEF_WORKSTATION_ENTERPRISE}
+	toAdd := append([]string{constants.HABITAT_PRODUCT}, extra...)
 	if eol == "true" {
 		toAdd = append(toAdd, "automate-1")
 	}
 
 	for _, p := range toAdd {
 		if !productMap[p] {
+			productMap[p] = true
 			products = append(products, p)
 		}
 	}
@@ -51,14 +79,7 @@ func (svc *DynamoServices) Products(products []string, eol string) []string {
 	return products
 }
 
-func (svc *DynamoServices) Platforms(platforms PlatformList) PlatformList {
-	platforms["linux"] = "Linux"
-	platforms["linux-kernel2"] = "Linux Kernel 2"
-	platforms["darwin"] = "Darwin"
-	return platforms
-}
-
-func (svc *DynamoServices) ProductDownload(params *RequestParams) (string, error) {
+func (svc *DynamoServices) ProductDownload(ctx context.Context, params *RequestParams) (string, error) {
 	var url string
 	var err error
 
@@ -74,10 +95,10 @@ func (svc *DynamoServices) ProductDownload(params *RequestParams) (string, error
 		return "", fiber.NewError(requestParams.Code, requestParams.Message)
 	}
 	if params.Version == "" || params.Version == "latest" {
-		params.Version, err = svc.db.GetVersionLatest(params.Product)
+		params.Version, err = svc.db.GetVersionLatest(ctx, params.Product)
 		if err != nil {
 			svc.log.WithError(err).Error("Error while fetching latest version for download")
-			return "", fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+			return "", dbError(err, utils.DBError)
 		}
 	}
 	params.PlatformVersion = ""
@@ -86,13 +107,13 @@ func (svc *DynamoServices) ProductDownload(params *RequestParams) (string, error
 		params.Channel = constants.AUTOMATE_CHANNEL
 	}
 
-	details, err := svc.db.GetMetaData(params.Product, params.Version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
+	details, err := svc.db.GetMetaData(ctx, params.Product, params.Version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching filename")
-		return "", fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return "", dbError(err, utils.DBError)
 	}
 	if *details == (models.MetaData{}) {
-		return "", fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return "", notFoundError()
 	}
 
 	switch params.Product {
@@ -105,7 +126,7 @@ func (svc *DynamoServices) ProductDownload(params *RequestParams) (string, error
 	return url, nil
 }
 
-func (svc *DynamoServices) ProductMetadata(params *RequestParams) (PackageMetadata, error) {
+func (svc *DynamoServices) ProductMetadata(ctx context.Context, params *RequestParams) (PackageMetadata, error) {
 	var err error
 	version := params.Version
 
@@ -122,33 +143,39 @@ func (svc *DynamoServices) ProductMetadata(params *RequestParams) (PackageMetada
 	}
 
 	if params.Version == "" || params.Version == "latest" {
-		version, err = svc.db.GetVersionLatest(params.Product)
+		version, err = svc.db.GetVersionLatest(ctx, params.Product)
 		if err != nil {
 			svc.log.WithError(err).Error("Error while fetching latest version for metadata")
-			return PackageMetadata{}, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+			return PackageMetadata{}, dbError(err, utils.DBError)
 		}
 	}
 	params.PlatformVersion = ""
 
-	details, err := svc.db.GetMetaData(params.Product, version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
+	details, err := svc.db.GetMetaData(ctx, params.Product, version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching metadata")
-		return PackageMetadata{}, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return PackageMetadata{}, dbError(err, utils.DBError)
 	}
 	if reflect.DeepEqual(*details, models.MetaData{}) {
-		return PackageMetadata{}, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return PackageMetadata{}, notFoundError()
 	}
 
 	metadata := PackageMetadata{
-		Url:     "",
-		Sha1:    details.SHA1,
-		Sha256:  details.SHA256,
-		Version: version,
+		Url:            "",
+		Sha1:           details.SHA1,
+		Sha256:         details.SHA256,
+		Version:        version,
+		FileName:       details.FileName,
+		Size:           details.Size,
+		PackageManager: catalogPackageManager(details.PackageManager),
+		InstallMessage: details.InstallMessage,
+		SignatureUrl:   details.SignatureUrl,
+		SbomUrl:        details.SbomUrl,
 	}
 	return metadata, nil
 }
 
-func (svc *DynamoServices) ProductPackages(params *RequestParams) (PackageList, error) {
+func (svc *DynamoServices) ProductPackages(ctx context.Context, params *RequestParams) (PackageList, error) {
 	var err error
 	packageList := PackageList{}
 	flags := RequestParamsFlags{
@@ -161,30 +188,36 @@ func (svc *DynamoServices) ProductPackages(params *RequestParams) (PackageList,
 	}
 
 	if params.Version == "" || params.Version == "latest" {
-		params.Version, err = svc.db.GetVersionLatest(params.Product)
+		params.Version, err = svc.db.GetVersionLatest(ctx, params.Product)
 		if err != nil {
 			svc.log.WithError(err).Error("Error while fetching latest version for packages")
-			return PackageList{}, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+			return PackageList{}, dbError(err, utils.DBError)
 		}
 	}
 
-	data, err := svc.db.GetPackages(params.Product, params.Version)
+	data, err := svc.db.GetPackages(ctx, params.Product, params.Version)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching packages")
-		return PackageList{}, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return PackageList{}, dbError(err, utils.DBError)
 	}
 
 	switch v := data.(type) {
 	case *models.ProductDetails:
 		if len(v.MetaData) == 0 {
-			return PackageList{}, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+			return PackageList{}, notFoundError()
 		}
 		for _, meta := range v.MetaData {
 			updatePackageList(packageList, meta.Platform, constants.PLATFORM_VERSION_KEY, meta.Architecture, PackageMetadata{
-				Sha1:    meta.SHA1,
-				Sha256:  meta.SHA256,
-				Url:     "",
-				Version: v.Version,
+				Sha1:           meta.SHA1,
+				Sha256:         meta.SHA256,
+				Url:            "",
+				Version:        v.Version,
+				FileName:       meta.FileName,
+				Size:           meta.Size,
+				PackageManager: catalogPackageManager(meta.PackageManager),
+				InstallMessage: meta.InstallMessage,
+				SignatureUrl:   meta.SignatureUrl,
+				SbomUrl:        meta.SbomUrl,
 			})
 		}
 		return packageList, nil
@@ -194,10 +227,16 @@ func (svc *DynamoServices) ProductPackages(params *RequestParams) (PackageList,
 			for arch, pkgManagers := range archMap {
 				for pkgMgr, pkg := range pkgManagers {
 					updatePackageList(packageList, platform, arch, pkgMgr, PackageMetadata{
-						Sha1:    pkg.SHA1,
-						Sha256:  pkg.SHA256,
-						Url:     "",
-						Version: v.Version,
+						Sha1:           pkg.SHA1,
+						Sha256:         pkg.SHA256,
+						Url:            "",
+						Version:        v.Version,
+						FileName:       pkg.Filename,
+						Size:           pkg.Size,
+						PackageManager: pkgMgr,
+						InstallMessage: pkg.InstallMessage,
+						SignatureUrl:   pkg.SignatureUrl,
+						SbomUrl:        pkg.SbomUrl,
 					})
 				}
 			}
@@ -210,6 +249,15 @@ func (svc *DynamoServices) ProductPackages(params *RequestParams) (PackageList,
 	}
 }
 
+// catalogPackageManager hides the placeholder package manager used for
+// products that do not ship per package manager
+func catalogPackageManager(pm string) string {
+	if pm == constants.DUMMY_PACKAGE_MANAGER {
+		return ""
+	}
+	return pm
+}
+
 // Utility to update nested map safely
 func updatePackageList(pl PackageList, platform, versionKey, arch string, metadata PackageMetadata) {
 	if pl[platform] == nil {
@@ -221,7 +269,7 @@ func updatePackageList(pl PackageList, platform, versionKey, arch string, metada
 	pl[platform][versionKey][arch] = metadata
 }
 
-func (svc *DynamoServices) FetchLatestOsVersion(params *RequestParams) (string, error) {
+func (svc *DynamoServices) FetchLatestOsVersion(ctx context.Context, params *RequestParams) (string, error) {
 	flags := RequestParamsFlags{
 		Channel: true,
 	}
@@ -232,14 +280,14 @@ func (svc *DynamoServices) FetchLatestOsVersion(params *RequestParams) (string,
 	}
 
 	var version string
-	versions, err := svc.db.GetVersionAll(params.Product)
+	versions, err := svc.db.GetVersionAll(ctx, params.Product)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching the latest opensource version for the product.")
-		return version, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return version, dbError(err, utils.DBError)
 	}
 
 	if len(versions) == 0 {
-		return version, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return version, notFoundError()
 	}
 	sort.Strings(versions)
 	if params.Product == constants.HABITAT_PRODUCT {
@@ -256,7 +304,7 @@ func (svc *DynamoServices) FetchLatestOsVersion(params *RequestParams) (string,
 	return version, nil
 }
 
-func (svc *DynamoServices) VersionAll(params *RequestParams) ([]ProductVersion, error) {
+func (svc *DynamoServices) VersionAll(ctx context.Context, params *RequestParams) ([]ProductVersion, error) {
 	productVersions := []ProductVersion{}
 	flags := RequestParamsFlags{
 		Channel: true,
@@ -267,15 +315,15 @@ func (svc *DynamoServices) VersionAll(params *RequestParams) ([]ProductVersion,
 		return productVersions, fiber.NewError(requestParams.Code, requestParams.Message)
 	}
 
-	versions, err := svc.db.GetVersionAll(params.Product)
+	versions, err := svc.db.GetVersionAll(ctx, params.Product)
 
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching Versions")
-		return productVersions, fiber.NewError(fiber.StatusInternalServerError, utils.FetchVersionsError)
+		return productVersions, dbError(err, utils.FetchVersionsError)
 	}
 	if len(versions) == 0 {
 		svc.log.Error("Received empty version list while fetching Versions")
-		return productVersions, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return productVersions, notFoundError()
 	}
 
 	sort.Strings(versions)
@@ -286,7 +334,41 @@ func (svc *DynamoServices) VersionAll(params *RequestParams) ([]ProductVersion,
 	return productVersions, nil
 }
 
-func (svc *DynamoServices) VersionLatest(params *RequestParams) (ProductVersion, error) {
+// ReleaseDates returns the catalog release date of each version of the product.
+// Versions without a recorded date are left out.
+func (svc *DynamoServices) ReleaseDates(ctx context.Context, params *RequestParams) (map[string]string, error) {
+	dater, ok := svc.db.(dboperations.ReleaseDater)
+	if !ok {
+		return map[string]string{}, nil
+	}
+
+	dates, err := dater.GetReleaseDates(ctx, params.Product)
+	if err != nil {
+		svc.log.WithError(err).Error("Error while fetching release dates")
+		return nil, dbError(err, utils.FetchVersionsError)
+	}
+	return dates, nil
+}
+
+// ReleaseNotes returns the release notes stored in the catalog for a version,
+// or an empty string when the catalog has none.
+func (svc *DynamoServices) ReleaseNotes(ctx context.Context, params *RequestParams) (string, error) {
+	data, err := svc.db.GetPackages(ctx, params.Product, params.Version)
+	if err != nil {
+		svc.log.WithError(err).Error("Error while fetching release notes")
+		return "", dbError(err, utils.DBError)
+	}
+
+	switch v := data.(type) {
+	case *models.ProductDetails:
+		return v.ReleaseNotes, nil
+	case *models.PackageDetails:
+		return v.ReleaseNotes, nil
+	}
+	return "", nil
+}
+
+func (svc *DynamoServices) VersionLatest(ctx context.Context, params *RequestParams) (ProductVersion, error) {
 	flags := RequestParamsFlags{
 		Channel: true,
 	}
@@ -295,16 +377,16 @@ func (svc *DynamoServices) VersionLatest(params *RequestParams) (ProductVersion,
 		svc.log.Error(constants.ERR_VALIDATING, requestParams.Message)
 		return "", fiber.NewError(requestParams.Code, requestParams.Message)
 	}
-	version, err := svc.db.GetVersionLatest(params.Product)
+	version, err := svc.db.GetVersionLatest(ctx, params.Product)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching the latest version for the product.")
-		return "", fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return "", dbError(err, utils.DBError)
 	}
 
 	return ProductVersion(version), nil
 }
 
-func (svc *DynamoServices) GetRelatedProducts(params *RequestParams) (*models.RelatedProducts, error) {
+func (svc *DynamoServices) GetRelatedProducts(ctx context.Context, params *RequestParams) (*models.RelatedProducts, error) {
 	var relatedProducts *models.RelatedProducts
 	flags := RequestParamsFlags{
 		BOM: true,
@@ -315,24 +397,24 @@ func (svc *DynamoServices) GetRelatedProducts(params *RequestParams) (*models.Re
 		return relatedProducts, fiber.NewError(requestParams.Code, requestParams.Message)
 	}
 
-	relatedProducts, err := svc.db.GetRelatedProducts(params.BOM)
+	relatedProducts, err := svc.db.GetRelatedProducts(ctx, params.BOM)
 
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching related products for " + params.BOM)
 		//return relatedProducts, fiber.NewError(fiber.StatusInternalServerError, "Unable to retrieve related products for "+params.BOM)
-		return relatedProducts, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return relatedProducts, dbError(err, utils.DBError)
 	}
 
 	if relatedProducts.Products == nil {
 		svc.log.Error("No related products found for " + params.BOM)
 		//return &models.RelatedProducts{}, fiber.NewError(fiber.StatusBadRequest, "No related products found for BOM")
-		return relatedProducts, fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return relatedProducts, notFoundError()
 	}
 
 	return relatedProducts, err
 }
 
-func (svc *DynamoServices) GetFilename(params *RequestParams) (string, error) {
+func (svc *DynamoServices) GetFilename(ctx context.Context, params *RequestParams) (string, error) {
 	var err error
 	version := params.Version
 
@@ -349,33 +431,33 @@ func (svc *DynamoServices) GetFilename(params *RequestParams) (string, error) {
 	}
 
 	if params.Version == "" || params.Version == "latest" {
-		version, err = svc.db.GetVersionLatest(params.Product)
+		version, err = svc.db.GetVersionLatest(ctx, params.Product)
 		if err != nil {
 			svc.log.WithError(err).Error("Error while getting latest version for fetching fileName for " + params.Product)
-			return "", fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+			return "", dbError(err, utils.DBError)
 		}
 	}
 	params.PlatformVersion = ""
 
-	details, err := svc.db.GetMetaData(params.Product, version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
+	details, err := svc.db.GetMetaData(ctx, params.Product, version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
 	if err != nil {
 		svc.log.WithError(err).Error("Error while fetching fileName for " + params.Product)
-		return "", fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return "", dbError(err, utils.DBError)
 	}
 
 	if details == nil || details.FileName == "" {
 		svc.log.Error("Error while fetching fileName for " + params.Product + ":- unable to find the product information for given parameters")
-		return "", fiber.NewError(fiber.StatusBadRequest, utils.BadRequestError)
+		return "", notFoundError()
 	}
 
 	return details.FileName, nil
 }
 
-func (svc *DynamoServices) GetPackageManagers() ([]string, error) {
-	result, err := svc.db.GetPackageManagers()
+func (svc *DynamoServices) GetPackageManagers(ctx context.Context) ([]string, error) {
+	result, err := svc.db.GetPackageManagers(ctx)
 	if err != nil {
 		svc.log.WithError(err).Error("Failed to fetch package managers from DB")
-		return nil, fiber.NewError(fiber.StatusInternalServerError, utils.DBError)
+		return nil, dbError(err, utils.DBError)
 	}
 	return result, nil
 }
@@ -1,23 +1,25 @@
 package omnitruck
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/chef/omnitruck-service/models"
 )
 
 type MockDynamoServices struct {
-	ProductMetadataFunc      func(params *RequestParams) (PackageMetadata, error)
-	ProductPackagesFunc      func(params *RequestParams) (PackageList, error)
-	GetFilenameFunc          func(params *RequestParams) (string, error)
-	GetRelatedProductsFunc   func(params *RequestParams) (*models.RelatedProducts, error)
-	GetPackageManagersFunc   func() ([]string, error)
-	VersionLatestFunc        func(params *RequestParams) (ProductVersion, error)
-	VersionAllFunc           func(params *RequestParams) ([]ProductVersion, error)
-	ProductDownloadFunc      func(params *RequestParams) (string, error)
-	FetchLatestOsVersionFunc func(params *RequestParams) (string, error)
-	ProductsFunc             func(products []string, eol string) []string
-	PlatformsFunc            func(platforms PlatformList) PlatformList
+	ProductMetadataFunc      func(ctx context.Context, params *RequestParams) (PackageMetadata, error)
+	ProductPackagesFunc      func(ctx context.Context, params *RequestParams) (PackageList, error)
+	GetFilenameFunc          func(ctx context.Context, params *RequestParams) (string, error)
+	GetRelatedProductsFunc   func(ctx context.Context, params *RequestParams) (*models.RelatedProducts, error)
+	GetPackageManagersFunc   func(ctx context.Context) ([]string, error)
+	VersionLatestFunc        func(ctx context.Context, params *RequestParams) (ProductVersion, error)
+	VersionAllFunc           func(ctx context.Context, params *RequestParams) ([]ProductVersion, error)
+	ReleaseDatesFunc         func(ctx context.Context, params *RequestParams) (map[string]string, error)
+	ReleaseNotesFunc         func(ctx context.Context, params *RequestParams) (string, error)
+	ProductDownloadFunc      func(ctx context.Context, params *RequestParams) (string, error)
+	FetchLatestOsVersionFunc func(ctx context.Context, params *RequestParams) (string, error)
+	ProductsFunc             func(products []string, eol string, extra ...string) []string
 
 	SetDbInfoCalledWith []struct {
 		Table string
@@ -35,79 +37,86 @@ func (m *MockDynamoServices) SetDbInfo(table string, model reflect.Type) {
 	})
 }
 
-func (m *MockDynamoServices) ProductMetadata(params *RequestParams) (PackageMetadata, error) {
+func (m *MockDynamoServices) ProductMetadata(ctx context.Context, params *RequestParams) (PackageMetadata, error) {
 	if m.ProductMetadataFunc != nil {
-		return m.ProductMetadataFunc(params)
+		return m.ProductMetadataFunc(ctx, params)
 	}
 	return PackageMetadata{}, nil
 }
 
-func (m *MockDynamoServices) ProductPackages(params *RequestParams) (PackageList, error) {
+func (m *MockDynamoServices) ProductPackages(ctx context.Context, params *RequestParams) (PackageList, error) {
 	if m.ProductPackagesFunc != nil {
-		return m.ProductPackagesFunc(params)
+		return m.ProductPackagesFunc(ctx, params)
 	}
 	return PackageList{}, nil
 }
 
-func (m *MockDynamoServices) GetFilename(params *RequestParams) (string, error) {
+func (m *MockDynamoServices) GetFilename(ctx context.Context, params *RequestParams) (string, error) {
 	if m.GetFilenameFunc != nil {
-		return m.GetFilenameFunc(params)
+		return m.GetFilenameFunc(ctx, params)
 	}
 	return "", nil
 }
 
-func (m *MockDynamoServices) GetRelatedProducts(params *RequestParams) (*models.RelatedProducts, error) {
+func (m *MockDynamoServices) GetRelatedProducts(ctx context.Context, params *RequestParams) (*models.RelatedProducts, error) {
 	if m.GetRelatedProductsFunc != nil {
-		return m.GetRelatedProductsFunc(params)
+		return m.GetRelatedProductsFunc(ctx, params)
 	}
 	return nil, nil
 }
 
-func (m *MockDynamoServices) GetPackageManagers() ([]string, error) {
+func (m *MockDynamoServices) GetPackageManagers(ctx context.Context) ([]string, error) {
 	if m.GetPackageManagersFunc != nil {
-		return m.GetPackageManagersFunc()
+		return m.GetPackageManagersFunc(ctx)
 	}
 	return nil, nil
 }
 
-func (m *MockDynamoServices) VersionLatest(params *RequestParams) (ProductVersion, error) {
+func (m *MockDynamoServices) VersionLatest(ctx context.Context, params *RequestParams) (ProductVersion, error) {
 	if m.VersionLatestFunc != nil {
-		return m.VersionLatestFunc(params)
+		return m.VersionLatestFunc(ctx, params)
 	}
 	return "", nil
 }
 
-func (m *MockDynamoServices) VersionAll(params *RequestParams) ([]ProductVersion, error) {
+func (m *MockDynamoServices) VersionAll(ctx context.Context, params *RequestParams) ([]ProductVersion, error) {
 	if m.VersionAllFunc != nil {
-		return m.VersionAllFunc(params)
+		return m.VersionAllFunc(ctx, params)
 	}
 	return nil, nil
 }
 
-func (m *MockDynamoServices) ProductDownload(params *RequestParams) (string, error) {
+func (m *MockDynamoServices) ReleaseDates(ctx context.Context, params *RequestParams) (map[string]string, error) {
+	if m.ReleaseDatesFunc != nil {
+		return m.ReleaseDatesFunc(ctx, params)
+	}
+	return nil, nil
+}
+
+func (m *MockDynamoServices) ReleaseNotes(ctx context.Context, params *RequestParams) (string, error) {
+	if m.ReleaseNotesFunc != nil {
+		return m.ReleaseNotesFunc(ctx, params)
+	}
+	return "", nil
+}
+
+func (m *MockDynamoServices) ProductDownload(ctx context.Context, params *RequestParams) (string, error) {
 	if m.ProductDownloadFunc != nil {
-		return m.ProductDownloadFunc(params)
+		return m.ProductDownloadFunc(ctx, params)
 	}
 	return "", nil
 }
 
-func (m *MockDynamoServices) FetchLatestOsVersion(params *RequestParams) (string, error) {
+func (m *MockDynamoServices) FetchLatestOsVersion(ctx context.Context, params *RequestParams) (string, error) {
 	if m.FetchLatestOsVersionFunc != nil {
-		return m.FetchLatestOsVersionFunc(params)
+		return m.FetchLatestOsVersionFunc(ctx, params)
 	}
 	return "", nil
 }
 
-func (m *MockDynamoServices) Products(products []string, eol string) []string {
+func (m *MockDynamoServices) Products(products []string, eol string, extra ...string) []string {
 	if m.ProductsFunc != nil {
-		return m.ProductsFunc(products, eol)
+		return m.ProductsFunc(products, eol, extra...)
 	}
 	return products
 }
-
-func (m *MockDynamoServices) Platforms(platforms PlatformList) PlatformList {
-	if m.PlatformsFunc != nil {
-		return m.PlatformsFunc(platforms)
-	}
-	return platforms
-}
@@ -1,15 +1,19 @@
 package omnitruck
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/chef/omnitruck-service/models"
 	"github.com/chef/omnitruck-service/utils"
+	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDBServices(t *testing.T) {
@@ -46,8 +50,9 @@ func TestProducts(t *testing.T) {
 		log *logrus.Entry
 	}
 	type args struct {
-		p   []string
-		eol string
+		p     []string
+		eol   string
+		extra []string
 	}
 	tests := []struct {
 		name   string
@@ -65,7 +70,7 @@ func TestProducts(t *testing.T) {
 				p:   []string{"new"},
 				eol: "false",
 			},
-			want: []string{"chef-ice", "chef-workstation-enterprise", "habitat", "inspec-enterprise", "migrate-ice", "new"},
+			want: []string{"habitat", "new"},
 		},
 		{
 			name: "eol true",
@@ -77,47 +82,20 @@ func TestProducts(t *testing.T) {
 				p:   []string{"new"},
 				eol: "true",
 			},
-			want: []string{"automate-1", "chef-ice", "chef-workstation-enterprise", "habitat", "inspec-enterprise", "migrate-ice", "new"},
+			want: []string{"automate-1", "habitat", "new"},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			svc := &DynamoServices{
-				db:  tt.fields.db,
-				log: tt.fields.log,
-			}
-			if got := svc.Products(tt.args.p, tt.args.eol); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DynamoServices.Products() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestPlatforms(t *testing.T) {
-	mockDbService := new(dboperations.MockIDbOperations)
-	type fields struct {
-		db  dboperations.IDbOperations
-		log *logrus.Entry
-	}
-	type args struct {
-		pl PlatformList
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   PlatformList
-	}{
 		{
-			name: "success",
+			name: "extra products",
 			fields: fields{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			},
 			args: args{
-				pl: PlatformList{"new": "test"},
+				p:     []string{"new", "chef-ice"},
+				eol:   "false",
+				extra: []string{"chef-ice", "chef-next"},
 			},
-			want: PlatformList{"darwin": "Darwin", "linux": "Linux", "linux-kernel2": "Linux Kernel 2", "new": "test"},
+			want: []string{"chef-ice", "chef-next", "habitat", "new"},
 		},
 	}
 	for _, tt := range tests {
@@ -126,8 +104,8 @@ func TestPlatforms(t *testing.T) {
 				db:  tt.fields.db,
 				log: tt.fields.log,
 			}
-			if got := svc.Platforms(tt.args.pl); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DynamoServices.Platforms() = %v, want %v", got, tt.want)
+			if got := svc.Products(tt.args.p, tt.args.eol, tt.args.extra...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DynamoServices.Products() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -304,10 +282,10 @@ func TestProductDownload(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				return tt.metadata, tt.metadata_err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return tt.version, tt.version_err
 			}
 			svc := &DynamoServices{
@@ -315,7 +293,7 @@ func TestProductDownload(t *testing.T) {
 				log: logrus.NewEntry(logrus.New()),
 			}
 
-			got, err := svc.ProductDownload(tt.args.p)
+			got, err := svc.ProductDownload(context.Background(), tt.args.p)
 			if err != nil {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -368,10 +346,11 @@ func TestProductMetadata(t *testing.T) {
 			version:     "latest",
 			version_err: nil,
 			want: PackageMetadata{
-				Sha1:    "",
-				Sha256:  "1234",
-				Url:     "",
-				Version: "latest",
+				Sha1:     "",
+				Sha256:   "1234",
+				Url:      "",
+				Version:  "latest",
+				FileName: "automate-cli.zip",
 			},
 			wantErr:      false,
 			metadata_err: nil,
@@ -425,10 +404,11 @@ func TestProductMetadata(t *testing.T) {
 			version:     "latest",
 			version_err: nil,
 			want: PackageMetadata{
-				Sha1:    "",
-				Sha256:  "1234",
-				Url:     "",
-				Version: "1.2",
+				Sha1:           "",
+				Sha256:         "1234",
+				Url:            "",
+				Version:        "1.2",
+				PackageManager: "rpm",
 			},
 			wantErr:      false,
 			metadata_err: nil,
@@ -505,10 +485,11 @@ func TestProductMetadata(t *testing.T) {
 			version:     "1.6.862",
 			version_err: nil,
 			want: PackageMetadata{
-				Sha1:    "",
-				Sha256:  "1234",
-				Url:     "",
-				Version: "1.6.862",
+				Sha1:     "",
+				Sha256:   "1234",
+				Url:      "",
+				Version:  "1.6.862",
+				FileName: "hab-x86_64-linux.tar.gz",
 			},
 			wantErr:      false,
 			metadata_err: nil,
@@ -563,10 +544,12 @@ func TestProductMetadata(t *testing.T) {
 			version:     "1.6.862",
 			version_err: nil,
 			want: PackageMetadata{
-				Sha1:    "",
-				Sha256:  "1234",
-				Url:     "",
-				Version: "1.6.862",
+				Sha1:           "",
+				Sha256:         "1234",
+				Url:            "",
+				Version:        "1.6.862",
+				FileName:       "chef-ice-x86_64-linux.tar.gz",
+				PackageManager: "tar",
 			},
 			wantErr:      false,
 			metadata_err: nil,
@@ -598,17 +581,17 @@ func TestProductMetadata(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				return tt.metadata, tt.metadata_err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return tt.version, tt.version_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.ProductMetadata(tt.args.p)
+			got, err := svc.ProductMetadata(context.Background(), tt.args.p)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -668,9 +651,10 @@ func TestProductPackages(t *testing.T) {
 				"darwin": {
 					"pv": ArchList{
 						"aarch64": PackageMetadata{
-							Sha1:    "abcde",
-							Sha256:  "079e5",
-							Version: "1.6.826",
+							Sha1:     "abcde",
+							Sha256:   "079e5",
+							Version:  "1.6.826",
+							FileName: "hab-aarch64-darwin.zip",
 						},
 					},
 				},
@@ -801,10 +785,12 @@ func TestProductPackages(t *testing.T) {
 				"linux": {
 					"x86_64": ArchList{
 						"deb": PackageMetadata{
-							Sha1:    "sha1value",
-							Sha256:  "sha256value",
-							Url:     "",
-							Version: "2.0.0",
+							Sha1:           "sha1value",
+							Sha256:         "sha256value",
+							Url:            "",
+							Version:        "2.0.0",
+							FileName:       "hab.deb",
+							PackageManager: "deb",
 						},
 					},
 				},
@@ -839,17 +825,17 @@ func TestProductPackages(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetPackagesfunc = func(partitionValue, sortValue string) (interface{}, error) {
+			mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
 				return tt.packages, tt.package_err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return tt.version, tt.version_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.ProductPackages(tt.args.params)
+			got, err := svc.ProductPackages(context.Background(), tt.args.params)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -957,14 +943,14 @@ func TestFetchLatestOsVersion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return tt.versions, tt.versions_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.FetchLatestOsVersion(tt.args.params)
+			got, err := svc.FetchLatestOsVersion(context.Background(), tt.args.params)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				//return
@@ -1071,14 +1057,14 @@ func TestVersionAll(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return tt.versions, tt.versions_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.VersionAll(tt.args.p)
+			got, err := svc.VersionAll(context.Background(), tt.args.p)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -1090,6 +1076,46 @@ func TestVersionAll(t *testing.T) {
 	}
 }
 
+func TestReleaseDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		dates   map[string]string
+		dbErr   error
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "Success",
+			dates: map[string]string{"0.70.0": "2024-01-15"},
+			want:  map[string]string{"0.70.0": "2024-01-15"},
+		},
+		{
+			name:    "Fail",
+			dbErr:   errors.New("ResourceNotFoundException: Requested resource not found"),
+			wantErr: "Error while fetching product versions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDbService := new(dboperations.MockIDbOperations)
+			mockDbService.GetReleaseDatesfunc = func(ctx context.Context, partitionValue string) (map[string]string, error) {
+				return tt.dates, tt.dbErr
+			}
+			svc := &DynamoServices{
+				db:  mockDbService,
+				log: logrus.NewEntry(logrus.New()),
+			}
+			got, err := svc.ReleaseDates(context.Background(), &RequestParams{Channel: "stable", Product: "habitat"})
+			if tt.wantErr != "" {
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestVersionLatest(t *testing.T) {
 	type args struct {
 		p *RequestParams
@@ -1170,14 +1196,14 @@ func TestVersionLatest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
 
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return tt.version, tt.version_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.VersionLatest(tt.args.p)
+			got, err := svc.VersionLatest(context.Background(), tt.args.p)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -1189,6 +1215,37 @@ func TestVersionLatest(t *testing.T) {
 	}
 }
 
+func TestDbError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{name: "unclassified", err: errors.New("boom"), wantCode: 500, wantMsg: utils.DBError},
+		{name: "not found", err: apierror.Wrap(apierror.ErrNotFound, errors.New("no versions")), wantCode: 404, wantMsg: utils.OmnitruckDataNotFoundError},
+		{name: "throttled", err: apierror.Wrap(apierror.ErrThrottled, errors.New("ProvisionedThroughputExceededException")), wantCode: 429, wantMsg: utils.ThrottledError},
+		{name: "unavailable", err: apierror.Wrap(apierror.ErrUnavailable, errors.New("dial tcp: i/o timeout")), wantCode: 503, wantMsg: utils.DBError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDbService := new(dboperations.MockIDbOperations)
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+				return "", tt.err
+			}
+			svc := &DynamoServices{
+				db:  mockDbService,
+				log: logrus.NewEntry(logrus.New()),
+			}
+			_, err := svc.VersionLatest(context.Background(), &RequestParams{Channel: "stable", Product: "chef-ice"})
+			var fiberErr *fiber.Error
+			require.ErrorAs(t, err, &fiberErr)
+			assert.Equal(t, tt.wantCode, fiberErr.Code)
+			assert.Equal(t, tt.wantMsg, fiberErr.Message)
+		})
+	}
+}
+
 func TestGetRelatedProducts(t *testing.T) {
 	type args struct {
 		params *RequestParams
@@ -1299,14 +1356,14 @@ func TestGetRelatedProducts(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetRelatedProductsfunc = func(partitionValue string) (*models.RelatedProducts, error) {
+			mockDbService.GetRelatedProductsfunc = func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 				return tt.getRelatedProducts, tt.getRelatedProducts_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.GetRelatedProducts(tt.args.params)
+			got, err := svc.GetRelatedProducts(context.Background(), tt.args.params)
 			if tt.wantErr {
 				assert.Equal(t, tt.errMsg, err.Error())
 				return
@@ -1539,17 +1596,17 @@ func TestGetFilename(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				return tt.metadata, tt.metadata_err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return tt.version, tt.version_err
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.GetFilename(tt.args.params)
+			got, err := svc.GetFilename(context.Background(), tt.args.params)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tt.errMsg)
@@ -1589,14 +1646,14 @@ func TestGetPackageManagers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockDbService.GetPackageManagersfunc = func() ([]string, error) {
+			mockDbService.GetPackageManagersfunc = func(ctx context.Context) ([]string, error) {
 				return tt.mockResult, tt.mockError
 			}
 			svc := &DynamoServices{
 				db:  mockDbService,
 				log: logrus.NewEntry(logrus.New()),
 			}
-			got, err := svc.GetPackageManagers()
+			got, err := svc.GetPackageManagers(context.Background())
 			if tt.expectError {
 				assert.Error(t, err)
 			} else {
@@ -2,6 +2,8 @@ package omnitruck
 
 import (
 	"fmt"
+
+	"github.com/chef/omnitruck-service/internal/apierror"
 )
 
 type EolVersionValidator struct {
@@ -37,5 +39,6 @@ func (fv *EolVersionValidator) Validate(p *RequestParams, c Context) *Validation
 		Value:       p.Version,
 		Msg:         fmt.Sprintf("%s %s %v is EOL, must be %s", p.Product, fv.GetField(), p.Version, minVer),
 		Code:        fv.Code,
+		ErrorCode:   apierror.CodeVersionEol,
 	}
 }
@@ -0,0 +1,48 @@
+package omnitruck
+
+import (
+	"fmt"
+
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/flags"
+)
+
+// FeatureFlagValidator rejects requests for products that are gated by a
+// disabled, blocking feature flag
+type FeatureFlagValidator struct {
+	Flags flags.FeatureFlags
+	Mode  constants.ApiType
+	Code  int
+}
+
+func (fv *FeatureFlagValidator) GetField() string {
+	return "product"
+}
+
+func (fv *FeatureFlagValidator) GetValues() interface{} {
+	return nil
+}
+
+func (fv *FeatureFlagValidator) GetCode() int {
+	return fv.Code
+}
+
+func (fv *FeatureFlagValidator) Validate(p *RequestParams, c Context) *ValidationError {
+	if p.Product == "" || fv.Flags == nil {
+		return nil
+	}
+
+	if flag, blocked := fv.Flags.BlockedBy(p.Product, fv.Mode); blocked {
+		return &ValidationError{
+			FailedField: "product",
+			Value:       p.Product,
+			Tag:         flag,
+			Msg:         fmt.Sprintf("product: %s is not available", p.Product),
+			Code:        fv.Code,
+			ErrorCode:   apierror.CodeProductNotFound,
+		}
+	}
+
+	return nil
+}
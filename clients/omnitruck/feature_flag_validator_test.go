@@ -0,0 +1,60 @@
+package omnitruck
+
+import (
+	"testing"
+
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlagValidator_Validate(t *testing.T) {
+	registry := flags.New(
+		flags.Flag{Name: "next", Products: []string{"chef-next"}, Block: true, Modes: map[string]bool{"commercial": true}},
+		flags.Flag{Name: flags.Infra19, Products: []string{"chef-ice"}},
+	)
+
+	tests := []struct {
+		name    string
+		mode    constants.ApiType
+		params  RequestParams
+		wantErr *ValidationError
+	}{
+		{
+			name:   "blocked product in a disabled mode",
+			mode:   constants.Trial,
+			params: RequestParams{Product: "chef-next"},
+			wantErr: &ValidationError{
+				FailedField: "product",
+				Value:       "chef-next",
+				Tag:         "next",
+				Msg:         "product: chef-next is not available",
+				Code:        404,
+				ErrorCode:   apierror.CodeProductNotFound,
+			},
+		},
+		{
+			name:   "blocked product in an enabled mode",
+			mode:   constants.Commercial,
+			params: RequestParams{Product: "chef-next"},
+		},
+		{
+			name:   "gated product without blocking",
+			mode:   constants.Trial,
+			params: RequestParams{Product: "chef-ice"},
+		},
+		{
+			name:   "ungated product",
+			mode:   constants.Trial,
+			params: RequestParams{Product: "chef"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := FeatureFlagValidator{Flags: registry, Mode: tt.mode, Code: 404}
+			assert.Equal(t, tt.wantErr, v.Validate(&tt.params, Context{}))
+		})
+	}
+}
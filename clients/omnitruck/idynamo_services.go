@@ -1,22 +1,24 @@
 package omnitruck
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/chef/omnitruck-service/models"
 )
 
 type IDynamoServices interface {
-	VersionLatest(params *RequestParams) (ProductVersion, error)
-	VersionAll(params *RequestParams) ([]ProductVersion, error)
-	ProductPackages(params *RequestParams) (PackageList, error)
-	ProductMetadata(params *RequestParams) (PackageMetadata, error)
-	GetFilename(params *RequestParams) (string, error)
-	GetRelatedProducts(params *RequestParams) (*models.RelatedProducts, error)
-	GetPackageManagers() ([]string, error)
+	VersionLatest(ctx context.Context, params *RequestParams) (ProductVersion, error)
+	VersionAll(ctx context.Context, params *RequestParams) ([]ProductVersion, error)
+	ReleaseDates(ctx context.Context, params *RequestParams) (map[string]string, error)
+	ReleaseNotes(ctx context.Context, params *RequestParams) (string, error)
+	ProductPackages(ctx context.Context, params *RequestParams) (PackageList, error)
+	ProductMetadata(ctx context.Context, params *RequestParams) (PackageMetadata, error)
+	GetFilename(ctx context.Context, params *RequestParams) (string, error)
+	GetRelatedProducts(ctx context.Context, params *RequestParams) (*models.RelatedProducts, error)
+	GetPackageManagers(ctx context.Context) ([]string, error)
 	SetDbInfo(table string, model reflect.Type)
-	ProductDownload(params *RequestParams) (string, error)
-	FetchLatestOsVersion(params *RequestParams) (string, error)
-	Products(products []string, eol string) []string
-	Platforms(platforms PlatformList) PlatformList
+	ProductDownload(ctx context.Context, params *RequestParams) (string, error)
+	FetchLatestOsVersion(ctx context.Context, params *RequestParams) (string, error)
+	Products(products []string, eol string, extra ...string) []string
 }
@@ -0,0 +1,111 @@
+package omnitruck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// metadataFields holds the json name of each PackageMetadata field, in order
+var metadataFields = func() []string {
+	t := reflect.TypeOf(PackageMetadata{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}()
+
+// ParseMetadataFields validates a comma separated fields= selector such as
+// "sha256,url". An empty selector returns nil, which selects the full metadata.
+func ParseMetadataFields(selector string) ([]string, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	fields := []string{}
+	for _, f := range strings.Split(selector, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !containsField(metadataFields, f) {
+			return nil, fmt.Errorf("unknown field %s, valid fields are %s", f, strings.Join(metadataFields, ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func containsField(fields []string, name string) bool {
+	for _, f := range fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedMetadata encodes only the selected fields of a PackageMetadata.
+// Selected fields are always present, even when empty, and keep the
+// PackageMetadata field order whatever order they were requested in.
+type SelectedMetadata struct {
+	Metadata PackageMetadata
+	Fields   []string
+}
+
+func (m PackageMetadata) SelectFields(fields []string) SelectedMetadata {
+	return SelectedMetadata{Metadata: m, Fields: fields}
+}
+
+func (s SelectedMetadata) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Package URLs carry query strings, keep their & readable for shell parsers
+	enc.SetEscapeHTML(false)
+
+	v := reflect.ValueOf(s.Metadata)
+	buf.WriteByte('{')
+	first := true
+	for i, name := range metadataFields {
+		if !containsField(s.Fields, name) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		if err := enc.Encode(name); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(v.Field(i).Interface()); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// SelectedPackageList is a PackageList reduced to the selected metadata fields
+type SelectedPackageList map[string]map[string]map[string]SelectedMetadata
+
+func (pl PackageList) SelectFields(fields []string) SelectedPackageList {
+	selected := SelectedPackageList{}
+	for platform, versions := range pl {
+		selected[platform] = map[string]map[string]SelectedMetadata{}
+		for pv, archs := range versions {
+			selected[platform][pv] = map[string]SelectedMetadata{}
+			for arch, meta := range archs {
+				selected[platform][pv][arch] = meta.SelectFields(fields)
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,95 @@
+package omnitruck
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadataFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     []string
+		wantErr  string
+	}{
+		{name: "empty selects everything", selector: "", want: nil},
+		{name: "install.sh fields", selector: "sha1,sha256,url,version", want: []string{"sha1", "sha256", "url", "version"}},
+		{name: "spaces and empty entries", selector: " url, ,install_message ", want: []string{"url", "install_message"}},
+		{name: "unknown field", selector: "url,md5", wantErr: "unknown field md5, valid fields are sha1, sha256, url, version, filename, size, package_manager, install_message, signature_url, sbom_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMetadataFields(tt.selector)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPackageMetadata_SelectFields(t *testing.T) {
+	meta := PackageMetadata{
+		Sha1:           "abc",
+		Sha256:         "def",
+		Url:            "https://example.com/download?p=ubuntu&v=18",
+		Version:        "18.0.0",
+		FileName:       "chef_18.0.0-1_amd64.deb",
+		InstallMessage: "Run chef-client",
+	}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{
+			name:   "legacy order is kept",
+			fields: []string{"version", "url", "sha256", "sha1"},
+			want:   `{"sha1":"abc","sha256":"def","url":"https://example.com/download?p=ubuntu&v=18","version":"18.0.0"}`,
+		},
+		{
+			name:   "selected empty fields are present",
+			fields: []string{"install_message", "size", "sbom_url"},
+			want:   `{"size":0,"install_message":"Run chef-client","sbom_url":""}`,
+		},
+		{
+			name:   "nothing selected",
+			fields: []string{},
+			want:   `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, encodeUnescaped(t, meta.SelectFields(tt.fields)))
+		})
+	}
+
+	full, err := json.Marshal(meta)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sha1":"abc","sha256":"def","url":"https://example.com/download?p=ubuntu&v=18","version":"18.0.0","filename":"chef_18.0.0-1_amd64.deb","install_message":"Run chef-client"}`, string(full))
+}
+
+func TestPackageList_SelectFields(t *testing.T) {
+	pl := PackageList{"ubuntu": {"20.04": {"x86_64": {Sha256: "def", Url: "u", Version: "18.0.0", FileName: "f"}}}}
+
+	got, err := json.Marshal(pl.SelectFields([]string{"url", "filename"}))
+	require.NoError(t, err)
+	assert.Equal(t, `{"ubuntu":{"20.04":{"x86_64":{"url":"u","filename":"f"}}}}`, string(got))
+}
+
+// encodeUnescaped encodes v the way the api handlers do
+func encodeUnescaped(t *testing.T, v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	require.NoError(t, enc.Encode(v))
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -1,6 +1,8 @@
 package omnitruck
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +11,9 @@ import (
 	"time"
 
 	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/cache"
 	"github.com/chef/omnitruck-service/utils"
 	"github.com/gofiber/fiber/v2"
 	version "github.com/hashicorp/go-version"
@@ -20,8 +25,28 @@ type Omnitruck struct {
 	omnitruckUrl string
 	client       *http.Client
 	log          *logrus.Entry
+	catalog      *cache.Cache
+	retries      int
+	backoff      time.Duration
+	breaker      *Breaker
+	stale        *cache.Cache
 }
 
+const (
+	// DefaultTimeout bounds a single attempt of an upstream call
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetries is the number of extra attempts of a failed GET
+	DefaultRetries = 2
+	// DefaultBreakerThreshold is the number of consecutive failures opening the circuit
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long the circuit stays open
+	DefaultBreakerCooldown = 30 * time.Second
+	// DefaultStaleTTL is how long the last good answers are kept
+	DefaultStaleTTL = 24 * time.Hour
+
+	retryBackoff = 200 * time.Millisecond
+)
+
 type FiberContext interface {
 	Params(string, ...string) string
 	Query(string, ...string) string
@@ -34,11 +59,54 @@ type PackageList map[string]PlatformVersionList
 type PlatformVersionList map[string]ArchList
 type ArchList map[string]PackageMetadata
 type ProductVersion string
+
+// PackageMetadata describes a single package. The first four fields keep
+// their order because older install.sh scripts parse the response by position.
 type PackageMetadata struct {
-	Sha1    string `json:"sha1"`
-	Sha256  string `json:"sha256"`
-	Url     string `json:"url"`
-	Version string `json:"version"`
+	Sha1           string `json:"sha1"`
+	Sha256         string `json:"sha256"`
+	Url            string `json:"url"`
+	Version        string `json:"version"`
+	FileName       string `json:"filename,omitempty"`
+	Size           int64  `json:"size,omitempty"`
+	PackageManager string `json:"package_manager,omitempty"`
+	InstallMessage string `json:"install_message,omitempty"`
+	SignatureUrl   string `json:"signature_url,omitempty"`
+	SbomUrl        string `json:"sbom_url,omitempty"`
+}
+
+// VersionDetail describes a single product version
+type VersionDetail struct {
+	Version     ProductVersion `json:"version"`
+	ReleaseDate string         `json:"release_date,omitempty"`
+	Eol         bool           `json:"eol"`
+	OpenSource  bool           `json:"open_source"`
+	Prerelease  bool           `json:"prerelease"`
+	Platforms   []string       `json:"platforms"`
+}
+
+// VersionDetailsList is one page of version details
+type VersionDetailsList struct {
+	Versions []VersionDetail `json:"versions"`
+	Page     int             `json:"page"`
+	PerPage  int             `json:"per_page"`
+	Total    int             `json:"total"`
+}
+
+// ReleaseNote holds the release notes of a single version
+type ReleaseNote struct {
+	Version ProductVersion `json:"version"`
+	Notes   string         `json:"notes"`
+}
+
+// ReleaseNotesList holds the notes of every version after From up to and
+// including To, newest first, along with their concatenation
+type ReleaseNotesList struct {
+	Product  string         `json:"product"`
+	From     ProductVersion `json:"from"`
+	To       ProductVersion `json:"to"`
+	Versions []ReleaseNote  `json:"versions"`
+	Notes    string         `json:"notes"`
 }
 
 type RequestParams struct {
@@ -70,12 +138,12 @@ type RequestParamsFlags struct {
 }
 
 type IOmnitruck interface {
-	LatestVersion(params *RequestParams) *clients.Request
-	ProductVersions(params *RequestParams) *clients.Request
-	ProductPackages(params *RequestParams) *clients.Request
-	ProductMetadata(params *RequestParams) *clients.Request
-	ProductDownload(params *RequestParams) *clients.Request
-	Architectures() *clients.Request
+	LatestVersion(ctx context.Context, params *RequestParams) *clients.Request
+	ProductVersions(ctx context.Context, params *RequestParams) *clients.Request
+	ProductPackages(ctx context.Context, params *RequestParams) *clients.Request
+	ProductMetadata(ctx context.Context, params *RequestParams) *clients.Request
+	ProductDownload(ctx context.Context, params *RequestParams) *clients.Request
+	Architectures(ctx context.Context) *clients.Request
 }
 
 type PackageListUpdater func(platform string, platformVersion string, arch string, meta PackageMetadata) PackageMetadata
@@ -122,12 +190,51 @@ func New(log *log.Entry, omnitruckUrl string) Omnitruck {
 	return Omnitruck{
 		omnitruckUrl: omnitruckUrl,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: DefaultTimeout,
 		},
-		log: log.WithField("pkg", "client/omnitruck"),
+		log:     log.WithField("pkg", "client/omnitruck"),
+		retries: DefaultRetries,
+		backoff: retryBackoff,
 	}
 }
 
+// Configure applies the timeout and retry settings of the config, keeping
+// the defaults for unset values. A negative Retries disables retrying.
+func (ot *Omnitruck) Configure(cfg config.OmnitruckClientConfig) {
+	if cfg.Timeout > 0 {
+		ot.client.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.Retries > 0 {
+		ot.retries = cfg.Retries
+	} else if cfg.Retries < 0 {
+		ot.retries = 0
+	}
+}
+
+// NewBreakerFromConfig returns the breaker shared by the Omnitruck clients
+func NewBreakerFromConfig(cfg config.OmnitruckClientConfig) *Breaker {
+	threshold := cfg.BreakerThreshold
+	if threshold == 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	cooldown := DefaultBreakerCooldown
+	if cfg.BreakerCooldown > 0 {
+		cooldown = time.Duration(cfg.BreakerCooldown) * time.Second
+	}
+	return NewBreaker(threshold, cooldown)
+}
+
+// SetBreaker guards the upstream calls with a circuit breaker
+func (ot *Omnitruck) SetBreaker(b *Breaker) {
+	ot.breaker = b
+}
+
+// SetStaleCache keeps the last good answer of every call, served when the
+// upstream is failing or the circuit is open
+func (ot *Omnitruck) SetStaleCache(c *cache.Cache) {
+	ot.stale = c
+}
+
 func (ot *Omnitruck) logRequestError(msg string, request *clients.Request, err error) {
 	ot.log.WithError(err).
 		WithField("status", request.Code).
@@ -135,70 +242,187 @@ func (ot *Omnitruck) logRequestError(msg string, request *clients.Request, err e
 		Error(msg)
 }
 
-func (ot *Omnitruck) Get(url string) *clients.Request {
+// Get fetches url from the upstream API. Upstream failures are answered with
+// our own messages rather than the upstream body: a missing resource as
+// OmnitruckDataNotFoundError, throttling as ThrottledError and anything else
+// as OmnitruckApiError. When the upstream is failing the last good answer is
+// served instead, if there is one. The call is abandoned once ctx is
+// cancelled or its deadline passes.
+func (ot *Omnitruck) Get(ctx context.Context, url string) *clients.Request {
 	request := clients.Request{
 		Url: url,
 	}
 
-	req, err := http.NewRequest("GET", request.Url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", request.Url, nil)
 
 	if err != nil {
 		ot.logRequestError("Error creating request", &request, err)
-		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckReqError)
+		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckReqError).WithErrorCode(apierror.CodeUpstreamUnavailable)
+	}
+	req.Header.Add("Accept", "application/json")
+
+	if ot.breaker != nil && !ot.breaker.Allow() {
+		ot.log.WithField("url", url).Warn("Omnitruck circuit is open, not calling upstream")
+		return ot.fallback(request.Failure(fiber.StatusServiceUnavailable, utils.OmnitruckApiError).WithErrorCode(apierror.CodeUpstreamUnavailable))
 	}
 
 	ot.log.Infof("Fetching data from %s", url)
-	req.Header.Add("Accept", "application/json")
-	resp, err := ot.client.Do(req)
-	request.Code = resp.StatusCode
+	request.Code, request.Body, err = ot.fetch(req)
 
-	if err != nil {
+	switch {
+	case err != nil:
 		ot.logRequestError("Error fetching omnitruck data", &request, err)
-		return request.Failure(request.Code, utils.OmnitruckApiError)
+		if errors.Is(err, context.Canceled) {
+			ot.breakerAbort()
+		} else {
+			ot.breakerFailure()
+		}
+		return ot.fallback(request.Failure(fiber.StatusServiceUnavailable, utils.OmnitruckApiError).WithErrorCode(apierror.CodeUpstreamUnavailable))
+	case request.Code == fiber.StatusNotFound:
+		ot.breakerSuccess()
+		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
+		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckDataNotFoundError).WithErrorCode(apierror.CodeProductNotFound)
+	case request.Code == fiber.StatusTooManyRequests:
+		ot.breakerFailure()
+		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
+		return ot.fallback(request.Failure(fiber.StatusTooManyRequests, utils.ThrottledError).WithErrorCode(apierror.CodeThrottled))
+	case request.Code >= 500:
+		ot.breakerFailure()
+		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
+		return ot.fallback(request.Failure(fiber.StatusServiceUnavailable, utils.OmnitruckApiError).WithErrorCode(apierror.CodeUpstreamUnavailable))
+	case request.Code >= 400:
+		ot.breakerSuccess()
+		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
+		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckDataNotFoundError).WithErrorCode(apierror.CodeProductNotFound)
 	}
 
-	request.Body, err = io.ReadAll(resp.Body)
+	ot.breakerSuccess()
+	if ot.stale != nil {
+		ot.stale.Set(url, request.Body)
+	}
+	return request.Success()
+}
+
+// fetch sends the GET request, retrying transport failures and 5xx or 429
+// answers with a doubling delay while the request context allows it
+func (ot *Omnitruck) fetch(req *http.Request) (int, []byte, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		code, body, err := ot.do(req)
+		retry := err != nil || code >= 500 || code == fiber.StatusTooManyRequests
+		if !retry || attempt >= ot.retries || ctx.Err() != nil {
+			return code, body, err
+		}
+
+		ot.log.WithError(err).WithField("status", code).WithField("attempt", attempt+1).Warn("Retrying omnitruck request")
+		select {
+		case <-ctx.Done():
+			return code, body, ctx.Err()
+		case <-time.After(ot.backoff << attempt):
+		}
+	}
+}
+
+func (ot *Omnitruck) do(req *http.Request) (int, []byte, error) {
+	resp, err := ot.client.Do(req)
 	if err != nil {
-		ot.logRequestError("Error reading response body from omnitruck api", &request, err)
-		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckApiError)
+		return 0, nil, err
 	}
+	defer resp.Body.Close()
 
-	if request.Code == 404 {
-		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
-		// Set our response message to what the server responsed with
-		// so we pass on the omnitruck error message to the user
-		return request.Failure(fiber.StatusBadRequest, utils.OmnitruckDataNotFoundError)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
 	}
+	return resp.StatusCode, body, nil
+}
 
-	if request.Code >= 400 {
-		ot.logRequestError(fmt.Sprintf("Omnitruck returned failure response %d", request.Code), &request, nil)
-		// Set our response message to what the server responsed with
-		// so we pass on the omnitruck error message to the user
-		return request.Failure(request.Code, string(request.Body))
+// fallback answers a failed request with the last good answer of its url
+// when there is one
+func (ot *Omnitruck) fallback(request *clients.Request) *clients.Request {
+	if ot.stale == nil {
+		return request
+	}
+	body, ok := ot.stale.Get(request.Url)
+	if !ok {
+		return request
 	}
 
+	ot.log.WithField("url", request.Url).Warn("Serving stale omnitruck data")
+	request.Code = fiber.StatusOK
+	request.Body = body.([]byte)
+	request.Message = ""
+	request.ErrorCode = ""
 	return request.Success()
 }
 
-func (ot *Omnitruck) Products(p *RequestParams, data clients.RequestDataInterface) *clients.Request {
+func (ot *Omnitruck) breakerSuccess() {
+	if ot.breaker != nil {
+		ot.breaker.Success()
+	}
+}
+
+func (ot *Omnitruck) breakerFailure() {
+	if ot.breaker != nil {
+		ot.breaker.Failure()
+	}
+}
+
+func (ot *Omnitruck) breakerAbort() {
+	if ot.breaker != nil {
+		ot.breaker.Abort()
+	}
+}
+
+// SetCatalogCache enables caching of the products, platforms and architectures
+// lists, which rarely change upstream
+func (ot *Omnitruck) SetCatalogCache(c *cache.Cache) {
+	ot.catalog = c
+}
+
+// getCatalog fetches url through the catalog cache when one is set.
+// Only the raw body of successful responses is cached so callers always
+// parse a fresh copy.
+func (ot *Omnitruck) getCatalog(ctx context.Context, url string) *clients.Request {
+	if ot.catalog == nil {
+		return ot.Get(ctx, url)
+	}
+
+	if body, ok := ot.catalog.Get(url); ok {
+		request := clients.Request{
+			Url:  url,
+			Code: fiber.StatusOK,
+			Body: body.([]byte),
+		}
+		return request.Success()
+	}
+
+	request := ot.Get(ctx, url)
+	if request.Ok {
+		ot.catalog.Set(url, request.Body)
+	}
+	return request
+}
+
+func (ot *Omnitruck) Products(ctx context.Context, p *RequestParams, data clients.RequestDataInterface) *clients.Request {
 	url := fmt.Sprintf("%s/products", ot.omnitruckUrl)
 
-	return ot.Get(url).ParseData(data)
+	return ot.getCatalog(ctx, url).ParseData(data)
 }
 
-func (ot *Omnitruck) Platforms() *clients.Request {
+func (ot *Omnitruck) Platforms(ctx context.Context) *clients.Request {
 	url := fmt.Sprintf("%s/platforms", ot.omnitruckUrl)
 
-	return ot.Get(url)
+	return ot.getCatalog(ctx, url)
 }
 
-func (ot *Omnitruck) Architectures() *clients.Request {
+func (ot *Omnitruck) Architectures(ctx context.Context) *clients.Request {
 	url := fmt.Sprintf("%s/architectures", ot.omnitruckUrl)
 
-	return ot.Get(url)
+	return ot.getCatalog(ctx, url)
 }
 
-func (ot *Omnitruck) LatestVersion(p *RequestParams) *clients.Request {
+func (ot *Omnitruck) LatestVersion(ctx context.Context, p *RequestParams) *clients.Request {
 	flags := RequestParamsFlags{
 		Channel: true,
 	}
@@ -209,10 +433,10 @@ func (ot *Omnitruck) LatestVersion(p *RequestParams) *clients.Request {
 	}
 	url := fmt.Sprintf("%s/%s/%s/versions/latest", ot.omnitruckUrl, p.Channel, p.Product)
 
-	return ot.Get(url)
+	return ot.Get(ctx, url)
 }
 
-func (ot *Omnitruck) ProductVersions(p *RequestParams) *clients.Request {
+func (ot *Omnitruck) ProductVersions(ctx context.Context, p *RequestParams) *clients.Request {
 	flags := RequestParamsFlags{
 		Channel: true,
 	}
@@ -223,10 +447,10 @@ func (ot *Omnitruck) ProductVersions(p *RequestParams) *clients.Request {
 	}
 	url := fmt.Sprintf("%s/%s/%s/versions/all", ot.omnitruckUrl, p.Channel, p.Product)
 
-	return ot.Get(url)
+	return ot.Get(ctx, url)
 }
 
-func (ot *Omnitruck) ProductPackages(p *RequestParams) *clients.Request {
+func (ot *Omnitruck) ProductPackages(ctx context.Context, p *RequestParams) *clients.Request {
 	flags := RequestParamsFlags{
 		Channel: true,
 	}
@@ -237,10 +461,10 @@ func (ot *Omnitruck) ProductPackages(p *RequestParams) *clients.Request {
 	}
 	url := fmt.Sprintf("%s/%s/%s/packages?v=%s", ot.omnitruckUrl, p.Channel, p.Product, p.Version)
 
-	return ot.Get(url)
+	return ot.Get(ctx, url)
 }
 
-func (ot *Omnitruck) ProductMetadata(p *RequestParams) *clients.Request {
+func (ot *Omnitruck) ProductMetadata(ctx context.Context, p *RequestParams) *clients.Request {
 	flags := RequestParamsFlags{
 		Channel:         true,
 		Platform:        true,
@@ -261,14 +485,14 @@ func (ot *Omnitruck) ProductMetadata(p *RequestParams) *clients.Request {
 		p.Architecture,
 	)
 
-	return ot.Get(url)
+	return ot.Get(ctx, url)
 }
 
 // Product Download needs to fetch the metadata record instead of the Omnitruck download API
 // The Omnitruck API normall redirects the user to the download URL and we need to do this
 // ourselves.
-func (ot *Omnitruck) ProductDownload(p *RequestParams) *clients.Request {
-	return ot.ProductMetadata(p)
+func (ot *Omnitruck) ProductDownload(ctx context.Context, p *RequestParams) *clients.Request {
+	return ot.ProductMetadata(ctx, p)
 }
 
 func ValidateRequest(p *RequestParams, flags RequestParamsFlags) *clients.Request {
@@ -276,31 +500,31 @@ func ValidateRequest(p *RequestParams, flags RequestParamsFlags) *clients.Reques
 
 	if flags.Channel {
 		if !(p.Channel == "stable" || p.Channel == "current") {
-			request.Failure(fiber.StatusBadRequest, utils.ChannelParamsError)
+			request.Failure(fiber.StatusBadRequest, utils.ChannelParamsError).WithErrorCode(apierror.CodeChannelInvalid)
 			return &request
 		}
 	}
 	if flags.Architecture {
 		if p.Architecture == "" {
-			request.Failure(fiber.StatusBadRequest, utils.ArchitectureParamsError)
+			request.Failure(fiber.StatusBadRequest, utils.ArchitectureParamsError).WithErrorCode(apierror.CodeParameterMissing)
 			return &request
 		}
 	}
 	if flags.BOM {
 		if p.BOM == "" {
-			request.Failure(fiber.StatusBadRequest, utils.BOMParamsError)
+			request.Failure(fiber.StatusBadRequest, utils.BOMParamsError).WithErrorCode(apierror.CodeParameterMissing)
 			return &request
 		}
 	}
 	if flags.Platform {
 		if p.Platform == "" {
-			request.Failure(fiber.StatusBadRequest, utils.PlatformParamsError)
+			request.Failure(fiber.StatusBadRequest, utils.PlatformParamsError).WithErrorCode(apierror.CodeParameterMissing)
 			return &request
 		}
 	}
 	if flags.PlatformVersion {
 		if p.PlatformVersion == "" {
-			request.Failure(fiber.StatusBadRequest, utils.PlatformVersionParamsError)
+			request.Failure(fiber.StatusBadRequest, utils.PlatformVersionParamsError).WithErrorCode(apierror.CodeParameterMissing)
 			return &request
 		}
 	}
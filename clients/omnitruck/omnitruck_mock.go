@@ -1,49 +1,53 @@
 package omnitruck
 
-import "github.com/chef/omnitruck-service/clients"
+import (
+	"context"
+
+	"github.com/chef/omnitruck-service/clients"
+)
 
 type MockOmnitruck struct {
-	LatestVersionFunc   func(params *RequestParams) *clients.Request
-	ProductVersionsFunc func(params *RequestParams) *clients.Request
-	ProductPackagesFunc func(params *RequestParams) *clients.Request
-	ProductMetadataFunc func(params *RequestParams) *clients.Request
-	ProductDownloadFunc func(params *RequestParams) *clients.Request
-	ArchtechtureFunc    func() *clients.Request
+	LatestVersionFunc   func(ctx context.Context, params *RequestParams) *clients.Request
+	ProductVersionsFunc func(ctx context.Context, params *RequestParams) *clients.Request
+	ProductPackagesFunc func(ctx context.Context, params *RequestParams) *clients.Request
+	ProductMetadataFunc func(ctx context.Context, params *RequestParams) *clients.Request
+	ProductDownloadFunc func(ctx context.Context, params *RequestParams) *clients.Request
+	ArchtechtureFunc    func(ctx context.Context) *clients.Request
 }
 
-func (m *MockOmnitruck) LatestVersion(params *RequestParams) *clients.Request {
+func (m *MockOmnitruck) LatestVersion(ctx context.Context, params *RequestParams) *clients.Request {
 	if m.LatestVersionFunc != nil {
-		return m.LatestVersionFunc(params)
+		return m.LatestVersionFunc(ctx, params)
 	}
 	return nil
 }
-func (m *MockOmnitruck) ProductVersions(params *RequestParams) *clients.Request {
+func (m *MockOmnitruck) ProductVersions(ctx context.Context, params *RequestParams) *clients.Request {
 	if m.ProductVersionsFunc != nil {
-		return m.ProductVersionsFunc(params)
+		return m.ProductVersionsFunc(ctx, params)
 	}
 	return nil
 }
-func (m *MockOmnitruck) ProductPackages(params *RequestParams) *clients.Request {
+func (m *MockOmnitruck) ProductPackages(ctx context.Context, params *RequestParams) *clients.Request {
 	if m.ProductPackagesFunc != nil {
-		return m.ProductPackagesFunc(params)
+		return m.ProductPackagesFunc(ctx, params)
 	}
 	return nil
 }
-func (m *MockOmnitruck) ProductMetadata(params *RequestParams) *clients.Request {
+func (m *MockOmnitruck) ProductMetadata(ctx context.Context, params *RequestParams) *clients.Request {
 	if m.ProductMetadataFunc != nil {
-		return m.ProductMetadataFunc(params)
+		return m.ProductMetadataFunc(ctx, params)
 	}
 	return nil
 }
-func (m *MockOmnitruck) ProductDownload(params *RequestParams) *clients.Request {
+func (m *MockOmnitruck) ProductDownload(ctx context.Context, params *RequestParams) *clients.Request {
 	if m.ProductDownloadFunc != nil {
-		return m.ProductDownloadFunc(params)
+		return m.ProductDownloadFunc(ctx, params)
 	}
 	return nil
 }
-func (m *MockOmnitruck) Architectures() *clients.Request {
+func (m *MockOmnitruck) Architectures(ctx context.Context) *clients.Request {
 	if m.ArchtechtureFunc != nil {
-		return m.ArchtechtureFunc()
+		return m.ArchtechtureFunc(ctx)
 	}
 	return nil
 }
\ No newline at end of file
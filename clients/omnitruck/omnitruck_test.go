@@ -1,12 +1,17 @@
 package omnitruck
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/chef/omnitruck-service/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -97,9 +102,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "Platform Version (pv) params cannot be empty",
-				Ok:      false,
+				Code:      400,
+				Message:   "Platform Version (pv) params cannot be empty",
+				ErrorCode: apierror.CodeParameterMissing,
+				Ok:        false,
 			},
 		},
 		{
@@ -121,9 +127,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "Channel can only be stable or current",
-				Ok:      false,
+				Code:      400,
+				Message:   "Channel can only be stable or current",
+				ErrorCode: apierror.CodeChannelInvalid,
+				Ok:        false,
 			},
 		},
 		{
@@ -145,9 +152,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "Channel can only be stable or current",
-				Ok:      false,
+				Code:      400,
+				Message:   "Channel can only be stable or current",
+				ErrorCode: apierror.CodeChannelInvalid,
+				Ok:        false,
 			},
 		},
 		{
@@ -169,9 +177,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "Platform (p) params cannot be empty",
-				Ok:      false,
+				Code:      400,
+				Message:   "Platform (p) params cannot be empty",
+				ErrorCode: apierror.CodeParameterMissing,
+				Ok:        false,
 			},
 		},
 		{
@@ -193,9 +202,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "BOM (bom) params cannot be empty",
-				Ok:      false,
+				Code:      400,
+				Message:   "BOM (bom) params cannot be empty",
+				ErrorCode: apierror.CodeParameterMissing,
+				Ok:        false,
 			},
 		},
 		{
@@ -217,9 +227,10 @@ func TestValidateRequest(t *testing.T) {
 				},
 			},
 			want: &clients.Request{
-				Code:    400,
-				Message: "Architecture (m) params cannot be empty",
-				Ok:      false,
+				Code:      400,
+				Message:   "Architecture (m) params cannot be empty",
+				ErrorCode: apierror.CodeParameterMissing,
+				Ok:        false,
 			},
 		},
 	}
@@ -270,7 +281,7 @@ func TestOmnitruck_Get_Success(t *testing.T) {
 	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
 	ot.client = ts.Client()
 
-	resp := ot.Get(ts.URL)
+	resp := ot.Get(context.Background(), ts.URL)
 	assert.True(t, resp.Ok)
 	assert.Equal(t, 200, resp.Code)
 }
@@ -285,7 +296,7 @@ func TestOmnitruck_Get_NotFound(t *testing.T) {
 	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
 	ot.client = ts.Client()
 
-	resp := ot.Get(ts.URL)
+	resp := ot.Get(context.Background(), ts.URL)
 	assert.False(t, resp.Ok)
 	assert.Equal(t, 400, resp.Code)
 }
@@ -299,10 +310,163 @@ func TestOmnitruck_Get_ServerError(t *testing.T) {
 
 	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
 	ot.client = ts.Client()
+	ot.backoff = time.Millisecond
 
-	resp := ot.Get(ts.URL)
+	resp := ot.Get(context.Background(), ts.URL)
 	assert.False(t, resp.Ok)
-	assert.Equal(t, 500, resp.Code)
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, utils.OmnitruckApiError, resp.Message)
+}
+
+func TestOmnitruck_Get_Upstream(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int
+		wantOk    bool
+		wantCode  int
+		wantMsg   string
+		wantCalls int
+	}{
+		{
+			name:      "retries server errors",
+			responses: []int{502, 500, 200},
+			wantOk:    true,
+			wantCode:  200,
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after the retries",
+			responses: []int{500, 500, 500, 500},
+			wantCode:  503,
+			wantMsg:   utils.OmnitruckApiError,
+			wantCalls: 3,
+		},
+		{
+			name:      "throttled",
+			responses: []int{429, 429, 429},
+			wantCode:  429,
+			wantMsg:   utils.ThrottledError,
+			wantCalls: 3,
+		},
+		{
+			name:      "client errors are not retried and hide the body",
+			responses: []int{422},
+			wantCode:  400,
+			wantMsg:   utils.OmnitruckDataNotFoundError,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.responses[calls])
+				w.Write([]byte(`upstream stack trace`))
+				calls++
+			}))
+			defer ts.Close()
+
+			ot := New(logrus.NewEntry(logrus.New()), ts.URL)
+			ot.client = ts.Client()
+			ot.backoff = time.Millisecond
+
+			resp := ot.Get(context.Background(), ts.URL)
+			assert.Equal(t, tt.wantOk, resp.Ok)
+			assert.Equal(t, tt.wantCode, resp.Code)
+			assert.Equal(t, tt.wantMsg, resp.Message)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestOmnitruck_Get_TransportError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := ts.URL
+	ts.Close()
+
+	ot := New(logrus.NewEntry(logrus.New()), url)
+	ot.backoff = time.Millisecond
+
+	resp := ot.Get(context.Background(), url+"/products")
+	assert.False(t, resp.Ok)
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, utils.OmnitruckApiError, resp.Message)
+}
+
+func TestOmnitruck_Get_Context(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	breaker := NewBreaker(1, time.Minute)
+
+	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
+	ot.client = ts.Client()
+	ot.SetBreaker(breaker)
+
+	resp := ot.Get(ctx, ts.URL)
+	assert.False(t, resp.Ok)
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, 0, calls)
+	// A request cancelled by the client says nothing about the upstream
+	assert.False(t, breaker.Open())
+}
+
+func TestOmnitruck_Get_Stale(t *testing.T) {
+	up := true
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !up {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte(`["chef"]`))
+	}))
+	defer ts.Close()
+
+	breaker := NewBreaker(2, time.Minute)
+	stale := cache.New("stale", time.Hour, 0)
+	client := func() Omnitruck {
+		ot := New(logrus.NewEntry(logrus.New()), ts.URL)
+		ot.client = ts.Client()
+		ot.retries = 0
+		ot.SetBreaker(breaker)
+		ot.SetStaleCache(stale)
+		return ot
+	}
+
+	ot := client()
+	assert.True(t, ot.Get(context.Background(), ts.URL+"/products").Ok)
+
+	up = false
+	for i := 0; i < 2; i++ {
+		ot = client()
+		resp := ot.Get(context.Background(), ts.URL+"/products")
+		assert.True(t, resp.Ok)
+		assert.Equal(t, `["chef"]`, string(resp.Body))
+	}
+	assert.Equal(t, 3, calls)
+	assert.True(t, breaker.Open())
+
+	// With the circuit open upstream is not called at all
+	ot = client()
+	resp := ot.Get(context.Background(), ts.URL+"/products")
+	assert.True(t, resp.Ok)
+	assert.Equal(t, `["chef"]`, string(resp.Body))
+	assert.Equal(t, 3, calls)
+
+	// Without a stale answer the failure is reported
+	resp = ot.Get(context.Background(), ts.URL+"/platforms")
+	assert.False(t, resp.Ok)
+	assert.Equal(t, 503, resp.Code)
+	assert.Equal(t, utils.OmnitruckApiError, resp.Message)
+	assert.Equal(t, 3, calls)
 }
 
 func TestOmnitruck_Platforms(t *testing.T) {
@@ -316,7 +480,7 @@ func TestOmnitruck_Platforms(t *testing.T) {
 	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
 	ot.client = ts.Client()
 
-	req := ot.Platforms()
+	req := ot.Platforms(context.Background())
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
@@ -332,7 +496,7 @@ func TestOmnitruck_Architectures(t *testing.T) {
 	ot := New(logrus.NewEntry(logrus.New()), ts.URL)
 	ot.client = ts.Client()
 
-	req := ot.Architectures()
+	req := ot.Architectures(context.Background())
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
@@ -353,7 +517,7 @@ func TestOmnitruck_LatestVersion(t *testing.T) {
 		Product: "test-product",
 	}
 
-	req := ot.LatestVersion(p)
+	req := ot.LatestVersion(context.Background(), p)
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
@@ -374,7 +538,7 @@ func TestOmnitruck_ProductVersions(t *testing.T) {
 		Product: "test-product",
 	}
 
-	req := ot.ProductVersions(p)
+	req := ot.ProductVersions(context.Background(), p)
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
@@ -397,7 +561,7 @@ func TestOmnitruck_ProductPackages(t *testing.T) {
 		Version: "1.2.3",
 	}
 
-	req := ot.ProductPackages(p)
+	req := ot.ProductPackages(context.Background(), p)
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
@@ -426,7 +590,7 @@ func TestOmnitruck_ProductMetadata(t *testing.T) {
 		Architecture:    "x86_64",
 	}
 
-	req := ot.ProductMetadata(p)
+	req := ot.ProductMetadata(context.Background(), p)
 	assert.True(t, req.Ok)
 	assert.Equal(t, 200, req.Code)
 }
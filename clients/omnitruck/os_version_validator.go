@@ -2,6 +2,8 @@ package omnitruck
 
 import (
 	"fmt"
+
+	"github.com/chef/omnitruck-service/internal/apierror"
 )
 
 type OsVersionValidator struct {
@@ -37,5 +39,6 @@ func (fv *OsVersionValidator) Validate(p *RequestParams, c Context) *ValidationE
 		Value:       p.Version,
 		Msg:         fmt.Sprintf("%s %s %v is not opensource, must be %s", p.Product, fv.GetField(), p.Version, minVer),
 		Code:        fv.Code,
+		ErrorCode:   apierror.CodeVersionUnsupportedForMode,
 	}
 }
@@ -0,0 +1,89 @@
+package omnitruck
+
+import "sort"
+
+// PackageKeys names the combination a package in a PackageList belongs to.
+// Backends nest packages under different keys, so each strategy translates
+// the three map keys into these fields.
+type PackageKeys struct {
+	Platform        string
+	PlatformVersion string
+	Architecture    string
+	PackageManager  string
+}
+
+// PackageKeysFunc translates the three keys of a PackageList entry
+type PackageKeysFunc func(platform string, second string, third string) PackageKeys
+
+// Package is a single package of a PackageList with its combination
+// spelled out as typed fields instead of map keys
+type Package struct {
+	Platform        string `json:"platform"`
+	PlatformVersion string `json:"platform_version"`
+	Architecture    string `json:"arch"`
+	PackageManager  string `json:"package_manager"`
+	Version         string `json:"version"`
+	Url             string `json:"url"`
+	FileName        string `json:"filename,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+	Sha1            string `json:"sha1,omitempty"`
+	Sha256          string `json:"sha256"`
+	InstallMessage  string `json:"install_message,omitempty"`
+	SignatureUrl    string `json:"signature_url,omitempty"`
+	SbomUrl         string `json:"sbom_url,omitempty"`
+}
+
+// Packages is the v2 packages response of a product version
+type Packages struct {
+	Product  string         `json:"product"`
+	Version  ProductVersion `json:"version"`
+	Packages []Package      `json:"packages"`
+}
+
+// Packages returns one Package per entry of the list, sorted by platform,
+// platform version, architecture and package manager. When the keys do not
+// name a package manager the one recorded in the metadata is used.
+func (pl PackageList) Packages(keys PackageKeysFunc) []Package {
+	packages := []Package{}
+	for platform, seconds := range pl {
+		for second, thirds := range seconds {
+			for third, meta := range thirds {
+				k := keys(platform, second, third)
+				pm := k.PackageManager
+				if pm == "" {
+					pm = meta.PackageManager
+				}
+				packages = append(packages, Package{
+					Platform:        k.Platform,
+					PlatformVersion: k.PlatformVersion,
+					Architecture:    k.Architecture,
+					PackageManager:  pm,
+					Version:         meta.Version,
+					Url:             meta.Url,
+					FileName:        meta.FileName,
+					Size:            meta.Size,
+					Sha1:            meta.Sha1,
+					Sha256:          meta.Sha256,
+					InstallMessage:  meta.InstallMessage,
+					SignatureUrl:    meta.SignatureUrl,
+					SbomUrl:         meta.SbomUrl,
+				})
+			}
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		if a.PlatformVersion != b.PlatformVersion {
+			return a.PlatformVersion < b.PlatformVersion
+		}
+		if a.Architecture != b.Architecture {
+			return a.Architecture < b.Architecture
+		}
+		return a.PackageManager < b.PackageManager
+	})
+	return packages
+}
@@ -0,0 +1,58 @@
+package omnitruck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageList_Packages(t *testing.T) {
+	tests := []struct {
+		name string
+		list PackageList
+		keys PackageKeysFunc
+		want []Package
+	}{
+		{
+			name: "empty list",
+			list: PackageList{},
+			keys: func(platform, pv, arch string) PackageKeys {
+				return PackageKeys{Platform: platform, PlatformVersion: pv, Architecture: arch}
+			},
+			want: []Package{},
+		},
+		{
+			name: "metadata package manager fills the gap",
+			list: PackageList{
+				"linux": {"pv": {
+					"x86_64":  {Version: "4.13.0", Url: "http://example.com/x86", PackageManager: "tar", Sha256: "x"},
+					"aarch64": {Version: "4.13.0", Url: "http://example.com/arm", Sha256: "a", Size: 42},
+				}},
+			},
+			keys: func(platform, _, arch string) PackageKeys {
+				return PackageKeys{Platform: platform, Architecture: arch}
+			},
+			want: []Package{
+				{Platform: "linux", Architecture: "aarch64", Version: "4.13.0", Url: "http://example.com/arm", Size: 42, Sha256: "a"},
+				{Platform: "linux", Architecture: "x86_64", PackageManager: "tar", Version: "4.13.0", Url: "http://example.com/x86", Sha256: "x"},
+			},
+		},
+		{
+			name: "keys win over metadata",
+			list: PackageList{
+				"windows": {"x86_64": {"msi": {Version: "19.0.0", PackageManager: "ignored", FileName: "chef-ice.msi", SignatureUrl: "s", SbomUrl: "b", InstallMessage: "m"}}},
+			},
+			keys: func(platform, arch, pm string) PackageKeys {
+				return PackageKeys{Platform: platform, Architecture: arch, PackageManager: pm}
+			},
+			want: []Package{
+				{Platform: "windows", Architecture: "x86_64", PackageManager: "msi", Version: "19.0.0", FileName: "chef-ice.msi", SignatureUrl: "s", SbomUrl: "b", InstallMessage: "m"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.list.Packages(tt.keys))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package omnitruck
+
+import "strings"
+
+// Platform describes a platform key accepted by the p query param
+type Platform struct {
+	Name             string   `json:"name"`
+	Aliases          []string `json:"aliases,omitempty"`
+	DbPlatform       string   `json:"db_platform"`
+	PackageManager   string   `json:"package_manager,omitempty"`
+	PlatformVersions []string `json:"platform_versions,omitempty"`
+	Architectures    []string `json:"architectures,omitempty"`
+}
+
+// PlatformCatalog is the /platforms response keyed by platform key
+type PlatformCatalog map[string]Platform
+
+// supportedPlatforms is the platform registry. Aliases resolve to their
+// platform, and DbPlatform is the platform key packages are stored under in
+// the database.
+var supportedPlatforms = map[string]Platform{
+	"aix": {
+		Name:             "AIX",
+		DbPlatform:       "aix",
+		PackageManager:   "bff",
+		PlatformVersions: []string{"7.1", "7.2", "7.3"},
+		Architectures:    []string{"powerpc"},
+	},
+	"amazon": {
+		Name:             "Amazon Linux",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"2", "2023"},
+		Architectures:    []string{"x86_64", "aarch64"},
+	},
+	"darwin": {
+		Name:           "Darwin",
+		DbPlatform:     "darwin",
+		PackageManager: "dmg",
+		Architectures:  []string{"x86_64", "aarch64"},
+	},
+	"debian": {
+		Name:             "Debian GNU/Linux",
+		DbPlatform:       "linux",
+		PackageManager:   "deb",
+		PlatformVersions: []string{"10", "11", "12"},
+		Architectures:    []string{"x86_64", "aarch64"},
+	},
+	"el": {
+		Name:             "Red Hat Enterprise Linux/CentOS",
+		Aliases:          []string{"centos", "redhat", "rocky"},
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"7", "8", "9"},
+		Architectures:    []string{"x86_64", "aarch64", "ppc64le", "s390x"},
+	},
+	"fedora": {
+		Name:           "Fedora",
+		DbPlatform:     "linux",
+		PackageManager: "rpm",
+		Architectures:  []string{"x86_64", "aarch64"},
+	},
+	"freebsd": {
+		Name:             "FreeBSD",
+		DbPlatform:       "freebsd",
+		PackageManager:   "sh",
+		PlatformVersions: []string{"12", "13"},
+		Architectures:    []string{"x86_64"},
+	},
+	"ios_xr": {
+		Name:             "Cisco IOS-XR",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"6"},
+		Architectures:    []string{"x86_64"},
+	},
+	"linux": {
+		Name:           "Linux",
+		DbPlatform:     "linux",
+		PackageManager: "rpm",
+		Architectures:  []string{"x86_64", "aarch64"},
+	},
+	"linux-kernel2": {
+		Name:           "Linux Kernel 2",
+		DbPlatform:     "linux",
+		PackageManager: "rpm",
+		Architectures:  []string{"x86_64"},
+	},
+	"mac_os_x": {
+		Name:             "macOS",
+		DbPlatform:       "darwin",
+		PackageManager:   "dmg",
+		PlatformVersions: []string{"11", "12", "13", "14"},
+		Architectures:    []string{"x86_64", "aarch64"},
+	},
+	"nexus": {
+		Name:             "Cisco NX-OS",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"7"},
+		Architectures:    []string{"x86_64"},
+	},
+	"opensuseleap": {
+		Name:             "openSUSE Leap",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"15"},
+		Architectures:    []string{"x86_64", "aarch64"},
+	},
+	"sles": {
+		Name:             "SUSE Linux Enterprise Server",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"12", "15"},
+		Architectures:    []string{"x86_64", "aarch64", "s390x"},
+	},
+	"solaris2": {
+		Name:             "Solaris",
+		DbPlatform:       "solaris2",
+		PackageManager:   "p5p",
+		PlatformVersions: []string{"5.11"},
+		Architectures:    []string{"i386", "sparc"},
+	},
+	"suse": {
+		Name:             "openSUSE",
+		DbPlatform:       "linux",
+		PackageManager:   "rpm",
+		PlatformVersions: []string{"15"},
+		Architectures:    []string{"x86_64"},
+	},
+	"ubuntu": {
+		Name:             "Ubuntu Linux",
+		Aliases:          []string{"linuxmint"},
+		DbPlatform:       "linux",
+		PackageManager:   "deb",
+		PlatformVersions: []string{"18.04", "20.04", "22.04", "24.04"},
+		Architectures:    []string{"x86_64", "aarch64"},
+	},
+	"windows": {
+		Name:             "Windows",
+		DbPlatform:       "windows",
+		PackageManager:   "msi",
+		PlatformVersions: []string{"2016", "2019", "2022", "10", "11"},
+		Architectures:    []string{"x86_64", "i386"},
+	},
+}
+
+// platformAliases maps every alias in the registry to its platform key
+var platformAliases = func() map[string]string {
+	aliases := map[string]string{}
+	for key, platform := range supportedPlatforms {
+		for _, alias := range platform.Aliases {
+			aliases[alias] = key
+		}
+	}
+	return aliases
+}()
+
+// LookupPlatform resolves a platform key or alias to its registry key and entry
+func LookupPlatform(platform string) (string, Platform, bool) {
+	key := strings.ToLower(strings.TrimSpace(platform))
+	if alias, ok := platformAliases[key]; ok {
+		key = alias
+	}
+	p, ok := supportedPlatforms[key]
+	return key, p, ok
+}
+
+func NormalizePlatformForDatabase(platform string) string {
+	if _, p, ok := LookupPlatform(platform); ok {
+		return p.DbPlatform
+	}
+	// Return the normalized platform if no mapping is found
+	return strings.ToLower(strings.TrimSpace(platform))
+}
+
+func DerivePackageManager(platform string) string {
+	_, p, _ := LookupPlatform(platform)
+	return p.PackageManager
+}
+
+// BuildPlatformCatalog returns the registry merged with the platforms known to
+// the upstream Omnitruck API. Upstream platforms missing from the registry are
+// listed with their name only.
+func BuildPlatformCatalog(upstream PlatformList) PlatformCatalog {
+	catalog := PlatformCatalog{}
+	for key, platform := range supportedPlatforms {
+		catalog[key] = platform
+	}
+	for key, name := range upstream {
+		if _, ok := catalog[key]; ok {
+			continue
+		}
+		if _, ok := platformAliases[key]; ok {
+			continue
+		}
+		catalog[key] = Platform{Name: name, DbPlatform: key}
+	}
+	return catalog
+}
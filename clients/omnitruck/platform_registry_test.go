@@ -1,6 +1,10 @@
 package omnitruck
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestDerivePackageManager(t *testing.T) {
 	tests := []struct {
@@ -85,3 +89,59 @@ func TestNormalizePlatformForDatabase(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		wantKey  string
+		wantOk   bool
+	}{
+		{name: "platform key", platform: "ubuntu", wantKey: "ubuntu", wantOk: true},
+		{name: "alias", platform: "centos", wantKey: "el", wantOk: true},
+		{name: "alias with mixed case", platform: " Rocky ", wantKey: "el", wantOk: true},
+		{name: "unknown", platform: "plan9", wantKey: "plan9", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _, ok := LookupPlatform(tt.platform)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestBuildPlatformCatalog(t *testing.T) {
+	catalog := BuildPlatformCatalog(PlatformList{
+		"ubuntu":  "Ubuntu (upstream)",
+		"centos":  "CentOS",
+		"haiku":   "Haiku",
+		"windows": "Windows",
+	})
+
+	assert.Len(t, catalog, len(supportedPlatforms)+1)
+	assert.Equal(t, "Ubuntu Linux", catalog["ubuntu"].Name, "registry names win over upstream")
+	assert.Equal(t, []string{"linuxmint"}, catalog["ubuntu"].Aliases)
+	assert.Equal(t, "deb", catalog["ubuntu"].PackageManager)
+	assert.Contains(t, catalog["ubuntu"].PlatformVersions, "22.04")
+	assert.NotContains(t, catalog, "centos", "aliases are listed under their platform")
+	assert.Equal(t, Platform{Name: "Haiku", DbPlatform: "haiku"}, catalog["haiku"])
+	assert.Contains(t, catalog, "linux-kernel2")
+
+	assert.Len(t, BuildPlatformCatalog(nil), len(supportedPlatforms))
+}
+
+func TestPlatformRegistryAliases(t *testing.T) {
+	seen := map[string]string{}
+	for key, platform := range supportedPlatforms {
+		assert.NotEmpty(t, platform.Name, key)
+		assert.NotEmpty(t, platform.DbPlatform, key)
+		for _, alias := range platform.Aliases {
+			_, clash := supportedPlatforms[alias]
+			assert.False(t, clash, "alias %s of %s is also a platform key", alias, key)
+			assert.NotContains(t, seen, alias, "alias %s is used by %s and %s", alias, seen[alias], key)
+			seen[alias] = key
+		}
+	}
+}
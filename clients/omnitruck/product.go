@@ -1,6 +1,8 @@
 package omnitruck
 
 import (
+	"sort"
+
 	version "github.com/hashicorp/go-version"
 )
 
@@ -97,6 +99,16 @@ var supportedProducts = map[string]Product{
 	},
 }
 
+// SupportedProducts returns the sorted names of every product with a support policy
+func SupportedProducts() []string {
+	names := make([]string, 0, len(supportedProducts))
+	for name := range supportedProducts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func SupportedVersion(product string) string {
 	p, ok := supportedProducts[product]
 	if ok {
@@ -145,7 +157,10 @@ func OsProductVersion(name string, v ProductVersion) bool {
 		return false
 	}
 
-	v1, _ := version.NewVersion(string(v))
+	v1, err := version.NewVersion(string(v))
+	if err != nil {
+		return false
+	}
 	return p.OpensourceVersion.Check(v1.Core())
 }
 
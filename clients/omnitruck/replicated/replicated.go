@@ -1,13 +1,14 @@
 package replicated
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/chef/omnitruck-service/models"
 )
 
 type IReplicated interface {
-	SearchCustomersByEmail(email string, requestId string) (customers []models.Customer, err error)
+	SearchCustomersByEmail(ctx context.Context, email string, requestId string) (customers []models.Customer, err error)
 	GetDowloadUrl(customer models.Customer, requestId string) (url string, err error)
-	DownloadFromReplicated(url, requestId, authorization string) (res *http.Response, err error)
+	DownloadFromReplicated(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error)
 }
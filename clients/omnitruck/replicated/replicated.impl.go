@@ -1,6 +1,7 @@
 package replicated
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -36,14 +37,14 @@ var ReadFile = func(r io.Reader) ([]byte, error) {
 	return io.ReadAll(r)
 }
 
-var NewRequest = func(method string, url string, payload io.Reader) (res *http.Request, err error) {
-	return http.NewRequest(method, url, payload)
+var NewRequest = func(ctx context.Context, method string, url string, payload io.Reader) (res *http.Request, err error) {
+	return http.NewRequestWithContext(ctx, method, url, payload)
 }
 
-func (r ReplicatedImpl) makeRequest(url, method, requestId string, payload io.Reader) (int, []byte, error) {
+func (r ReplicatedImpl) makeRequest(ctx context.Context, url, method, requestId string, payload io.Reader) (int, []byte, error) {
 	log := utils.AddLogFields("makeRequest", requestId, r.Logger)
 
-	req, err := NewRequest(method, url, payload)
+	req, err := NewRequest(ctx, method, url, payload)
 	if err != nil {
 		log.Errorln("error in creating new request.\n[ERROR] -", err.Error())
 		return 0, nil, err
@@ -68,8 +69,8 @@ func (r ReplicatedImpl) makeRequest(url, method, requestId string, payload io.Re
 	return res.StatusCode, body, nil
 }
 
-func (r ReplicatedImpl) DownloadFromReplicated(url, requestId, authorization string) (res *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (r ReplicatedImpl) DownloadFromReplicated(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +85,7 @@ func (r ReplicatedImpl) DownloadFromReplicated(url, requestId, authorization str
 	return resp, nil
 }
 
-func (r ReplicatedImpl) SearchCustomersByEmail(email string, requestId string) (customers []models.Customer, err error) {
+func (r ReplicatedImpl) SearchCustomersByEmail(ctx context.Context, email string, requestId string) (customers []models.Customer, err error) {
 	log := utils.AddLogFields("SearchCustomersByEmail", requestId, r.Logger)
 
 	url := fmt.Sprintf("%s/customers/search", r.ReplicatedConfig.URL)
@@ -101,7 +102,7 @@ func (r ReplicatedImpl) SearchCustomersByEmail(email string, requestId string) (
 	  "query": "email:%s"
   	}`, r.ReplicatedConfig.AppID, email))
 
-	respStatusCode, respBody, err := r.makeRequest(url, method, requestId, payload)
+	respStatusCode, respBody, err := r.makeRequest(ctx, url, method, requestId, payload)
 	if err != nil {
 		log.Errorln("failed to search the customer: ", err.Error())
 		return nil, err
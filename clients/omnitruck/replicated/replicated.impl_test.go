@@ -2,6 +2,7 @@ package replicated_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -49,7 +50,7 @@ func TestSearchCustomerByEmailSuccess(t *testing.T) {
 		ReplicatedConfig: config.ReplicatedConfig{},
 		Logger:           logger.NewLogrusStandardLogger(),
 	}
-	customers, err := repImp.SearchCustomersByEmail("s-no@progress.com", "")
+	customers, err := repImp.SearchCustomersByEmail(context.Background(), "s-no@progress.com", "")
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(customers))
 
@@ -65,7 +66,7 @@ func TestSearchCustomerByEmailError(t *testing.T) {
 		ReplicatedConfig: config.ReplicatedConfig{},
 		Logger:           logger.NewLogrusStandardLogger(),
 	}
-	_, err := repImp.SearchCustomersByEmail("s-no@progress.com", "")
+	_, err := repImp.SearchCustomersByEmail(context.Background(), "s-no@progress.com", "")
 	assert.Error(t, err)
 }
 
@@ -85,7 +86,7 @@ func TestSearchCustomerByEmail500(t *testing.T) {
 		ReplicatedConfig: config.ReplicatedConfig{},
 		Logger:           logger.NewLogrusStandardLogger(),
 	}
-	_, err := repImp.SearchCustomersByEmail("s-no@progress.com", "")
+	_, err := repImp.SearchCustomersByEmail(context.Background(), "s-no@progress.com", "")
 	assert.Error(t, err)
 }
 
@@ -258,7 +259,7 @@ func TestReplicatedImpl_DownloadFromReplicated(t *testing.T) {
 				Client:           tt.fields.Client,
 				Logger:           tt.fields.Logger,
 			}
-			gotRes, err := r.DownloadFromReplicated(tt.args.url, tt.args.requestid, tt.args.authorization)
+			gotRes, err := r.DownloadFromReplicated(context.Background(), tt.args.url, tt.args.requestid, tt.args.authorization)
 
 			if tt.wantErr {
 				assert.Nil(t, gotRes)
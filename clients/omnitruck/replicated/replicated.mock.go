@@ -1,25 +1,26 @@
 package replicated
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/chef/omnitruck-service/models"
 )
 
 type MockReplicated struct {
-	SearchCustomersByEmailFunc func(email string, requestId string) (customers []models.Customer, err error)
+	SearchCustomersByEmailFunc func(ctx context.Context, email string, requestId string) (customers []models.Customer, err error)
 	GetDowloadUrlFunc          func(customer models.Customer, requestId string) (url string, err error)
-	DownloadFromReplicatedFunc func(url, requestId, authorization string) (res *http.Response, err error)
+	DownloadFromReplicatedFunc func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error)
 }
 
-func (m MockReplicated) SearchCustomersByEmail(email string, requestId string) (customers []models.Customer, err error) {
-	return m.SearchCustomersByEmailFunc(email, requestId)
+func (m MockReplicated) SearchCustomersByEmail(ctx context.Context, email string, requestId string) (customers []models.Customer, err error) {
+	return m.SearchCustomersByEmailFunc(ctx, email, requestId)
 }
 
 func (m MockReplicated) GetDowloadUrl(customer models.Customer, requestId string) (url string, err error) {
 	return m.GetDowloadUrlFunc(customer, requestId)
 }
 
-func (m MockReplicated) DownloadFromReplicated(url, requestId, authorization string) (res *http.Response, err error) {
-	return m.DownloadFromReplicatedFunc(url, requestId, authorization)
+func (m MockReplicated) DownloadFromReplicated(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
+	return m.DownloadFromReplicatedFunc(ctx, url, requestId, authorization)
 }
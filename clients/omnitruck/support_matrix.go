@@ -0,0 +1,37 @@
+package omnitruck
+
+// SupportMatrixEntry is a single supported combination of a product version
+type SupportMatrixEntry struct {
+	Platform        string `json:"platform"`
+	PlatformVersion string `json:"platform_version,omitempty"`
+	Architecture    string `json:"architecture"`
+	PackageManager  string `json:"package_manager,omitempty"`
+	FileName        string `json:"filename,omitempty"`
+	Sha1            string `json:"sha1,omitempty"`
+	Sha256          string `json:"sha256"`
+}
+
+// SupportMatrix lists every supported combination of a product version
+type SupportMatrix struct {
+	Product string               `json:"product"`
+	Version ProductVersion       `json:"version"`
+	Entries []SupportMatrixEntry `json:"entries"`
+}
+
+// Flatten returns one support matrix entry per package, in the order of Packages
+func (pl PackageList) Flatten(keys PackageKeysFunc) []SupportMatrixEntry {
+	packages := pl.Packages(keys)
+	entries := make([]SupportMatrixEntry, 0, len(packages))
+	for _, p := range packages {
+		entries = append(entries, SupportMatrixEntry{
+			Platform:        p.Platform,
+			PlatformVersion: p.PlatformVersion,
+			Architecture:    p.Architecture,
+			PackageManager:  p.PackageManager,
+			FileName:        p.FileName,
+			Sha1:            p.Sha1,
+			Sha256:          p.Sha256,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,69 @@
+package omnitruck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageList_Flatten(t *testing.T) {
+	versionKeys := func(platform, pv, arch string) PackageKeys {
+		return PackageKeys{Platform: platform, PlatformVersion: pv, Architecture: arch}
+	}
+	packageManagerKeys := func(platform, arch, pm string) PackageKeys {
+		return PackageKeys{Platform: platform, Architecture: arch, PackageManager: pm}
+	}
+
+	tests := []struct {
+		name string
+		list PackageList
+		keys PackageKeysFunc
+		want []SupportMatrixEntry
+	}{
+		{
+			name: "empty list",
+			list: PackageList{},
+			keys: versionKeys,
+			want: []SupportMatrixEntry{},
+		},
+		{
+			name: "platform version layout is sorted",
+			list: PackageList{
+				"ubuntu": {
+					"22.04": {"x86_64": {Sha256: "c", FileName: "chef_18.0.0-1_amd64.deb"}},
+					"20.04": {
+						"x86_64":  {Sha256: "b"},
+						"aarch64": {Sha256: "a", PackageManager: "deb"},
+					},
+				},
+				"el": {"8": {"x86_64": {Sha1: "s", Sha256: "d"}}},
+			},
+			keys: versionKeys,
+			want: []SupportMatrixEntry{
+				{Platform: "el", PlatformVersion: "8", Architecture: "x86_64", Sha1: "s", Sha256: "d"},
+				{Platform: "ubuntu", PlatformVersion: "20.04", Architecture: "aarch64", PackageManager: "deb", Sha256: "a"},
+				{Platform: "ubuntu", PlatformVersion: "20.04", Architecture: "x86_64", Sha256: "b"},
+				{Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64", FileName: "chef_18.0.0-1_amd64.deb", Sha256: "c"},
+			},
+		},
+		{
+			name: "package manager layout",
+			list: PackageList{
+				"linux": {"x86_64": {
+					"rpm": {Sha256: "r", FileName: "chef-ice.rpm"},
+					"deb": {Sha256: "d", FileName: "chef-ice.deb"},
+				}},
+			},
+			keys: packageManagerKeys,
+			want: []SupportMatrixEntry{
+				{Platform: "linux", Architecture: "x86_64", PackageManager: "deb", FileName: "chef-ice.deb", Sha256: "d"},
+				{Platform: "linux", Architecture: "x86_64", PackageManager: "rpm", FileName: "chef-ice.rpm", Sha256: "r"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.list.Flatten(tt.keys))
+		})
+	}
+}
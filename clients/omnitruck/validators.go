@@ -15,6 +15,7 @@ type ValidationError struct {
 	Tag         string
 	Msg         string
 	Code        int
+	ErrorCode   string
 }
 
 type Context struct {
@@ -35,7 +36,7 @@ type RequestValidator struct {
 
 type IRequestValidator interface {
 	Params(params *RequestParams, c Context) []*ValidationError
-	ErrorMessages(errors []*ValidationError) (string, int)
+	ErrorMessages(errors []*ValidationError) (string, int, string)
 }
 
 func NewValidator() RequestValidator {
@@ -65,16 +66,20 @@ func (o *RequestValidator) Params(params *RequestParams, c Context) []*Validatio
 	return errors
 }
 
-func (o *RequestValidator) ErrorMessages(errors []*ValidationError) (string, int) {
+// ErrorMessages joins the messages of errors and returns the highest status
+// with the error code of the error that has it
+func (o *RequestValidator) ErrorMessages(errors []*ValidationError) (string, int, string) {
 	var msgs []string
 	var code int
+	var errorCode string
 
 	for _, err := range errors {
 		if err.Code > code {
 			code = err.Code
+			errorCode = err.ErrorCode
 		}
 		msgs = append(msgs, err.Error())
 	}
 
-	return strings.Join(msgs, "\n"), code
+	return strings.Join(msgs, "\n"), code, errorCode
 }
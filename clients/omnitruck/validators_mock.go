@@ -2,7 +2,7 @@ package omnitruck
 
 type MockRequestValidator struct {
 	ParamsFunc        func(params *RequestParams, c Context) []*ValidationError
-	ErrorMessagesFunc func(errors []*ValidationError) (string, int)
+	ErrorMessagesFunc func(errors []*ValidationError) (string, int, string)
 }
 
 func (m *MockRequestValidator) Params(params *RequestParams, c Context) []*ValidationError {
@@ -12,9 +12,9 @@ func (m *MockRequestValidator) Params(params *RequestParams, c Context) []*Valid
 	return nil
 }
 
-func (m *MockRequestValidator) ErrorMessages(errors []*ValidationError) (string, int) {
+func (m *MockRequestValidator) ErrorMessages(errors []*ValidationError) (string, int, string) {
 	if m.ErrorMessagesFunc != nil {
 		return m.ErrorMessagesFunc(errors)
 	}
-	return "", 0
+	return "", 0, ""
 }
@@ -197,6 +197,7 @@ func TestRequestValidator_ErrorMessages(t *testing.T) {
 		args   args
 		want   string
 		want1  int
+		want2  string
 	}{
 		{
 			name: "should return error messages",
@@ -232,17 +233,20 @@ func TestRequestValidator_ErrorMessages(t *testing.T) {
 			args: args{
 				errors: []*ValidationError{
 					{
-						Code: 400,
-						Msg:  "failed",
+						Code:      400,
+						Msg:       "failed",
+						ErrorCode: "request.invalid",
 					},
 					{
-						Code: 500,
-						Msg:  "failed again",
+						Code:      500,
+						Msg:       "failed again",
+						ErrorCode: "internal.error",
 					},
 				},
 			},
 			want:  "failed\nfailed again",
 			want1: 500,
+			want2: "internal.error",
 		},
 	}
 	for _, tt := range tests {
@@ -250,13 +254,16 @@ func TestRequestValidator_ErrorMessages(t *testing.T) {
 			o := &RequestValidator{
 				validators: tt.fields.validators,
 			}
-			got, got1 := o.ErrorMessages(tt.args.errors)
+			got, got1, got2 := o.ErrorMessages(tt.args.errors)
 			if got != tt.want {
 				t.Errorf("RequestValidator.ErrorMessages() got = %v, want %v", got, tt.want)
 			}
 			if got1 != tt.want1 {
 				t.Errorf("RequestValidator.ErrorMessages() got1 = %v, want %v", got1, tt.want1)
 			}
+			if got2 != tt.want2 {
+				t.Errorf("RequestValidator.ErrorMessages() got2 = %v, want %v", got2, tt.want2)
+			}
 		})
 	}
 }
@@ -3,15 +3,19 @@ package clients
 import (
 	"encoding/json"
 
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/gofiber/fiber/v2"
 )
 
+// Request is the outcome of a call. A failure carries the status answered to
+// the client and, when known, the machine-readable ErrorCode of the /v2 API.
 type Request struct {
-	Url     string
-	Code    int
-	Body    []byte
-	Message string
-	Ok      bool
+	Url       string
+	Code      int
+	Body      []byte
+	Message   string
+	Ok        bool
+	ErrorCode string
 }
 
 type RequestDataInterface interface {
@@ -21,9 +25,25 @@ func (r *Request) Failure(code int, msg string) *Request {
 	r.Code = code
 	r.Message = msg
 	r.Ok = false
+	r.ErrorCode = ""
 	return r
 }
 
+// WithErrorCode sets the error code of a failure
+func (r *Request) WithErrorCode(code string) *Request {
+	r.ErrorCode = code
+	return r
+}
+
+// Err returns the failure as an error keeping its status and error code
+func (r *Request) Err() error {
+	err := fiber.NewError(r.Code, r.Message)
+	if r.ErrorCode == "" {
+		return err
+	}
+	return apierror.WithCode(r.ErrorCode, err)
+}
+
 func (r *Request) Success() *Request {
 	r.Ok = true
 	return r
@@ -41,6 +61,10 @@ func (r *Request) ParseData(data RequestDataInterface) *Request {
 }
 
 func (r *Request) ParseLicenseResp(data RequestDataInterface) *Request {
+	// Keep the failure of a request that got no answer to parse
+	if !r.Ok && r.Body == nil {
+		return r
+	}
 	err := json.Unmarshal(r.Body, &data)
 	if err != nil {
 		return r.Failure(fiber.StatusBadRequest, string(r.Body))
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/chef/omnitruck-service/bom"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/spf13/cobra"
+)
+
+type bomImportOptions struct {
+	file   string
+	sheet  string
+	table  string
+	region string
+	delete bool
+	dryRun bool
+}
+
+var bomImportOpts bomImportOptions
+
+var bomCmd = &cobra.Command{
+	Use:   "bom",
+	Short: "Manage the BOM to related products mapping",
+}
+
+var bomImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Load BOMs into the related products table",
+	Long: `Reads BOMs from the SKU workbook (.xlsx), a CSV export of its sheet or a
+YAML file mapping each BOM to its products, and writes the BOMs that
+changed to the related products table.
+
+Every product must be one /products can expose. The upstream Omnitruck URL
+is read from the service configuration in the secret named by the CONFIG
+environment variable, like the start command. With --delete, BOMs that are no
+longer in the input are removed from the table. With --dry-run the changes
+are printed without writing anything.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/bom")
+		o := bomImportOpts
+		serviceConfig, err := loadServiceConfig()
+		if err != nil {
+			return err
+		}
+		if o.table == "" {
+			o.table = serviceConfig.RelatedProductsTable
+		}
+		if o.file == "" || o.table == "" {
+			return fmt.Errorf("--file and --table are required")
+		}
+
+		boms, err := bom.ReadFile(o.file, o.sheet)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", o.file, err)
+		}
+
+		cfg, err := s3aws.NewS3Session(o.region)
+		if err != nil {
+			return err
+		}
+		db := dboperations.NewDbOperationsService(
+			&clientConnection{client: dynamodb.NewFromConfig(cfg)},
+			config.ServiceConfig{RelatedProductsTable: o.table},
+		)
+
+		products, err := knownProducts(cmd.Context(), serviceConfig, db, logger)
+		if err != nil {
+			return err
+		}
+
+		importer := &bom.Importer{
+			Db:       db,
+			Products: products,
+			Delete:   o.delete,
+			DryRun:   o.dryRun,
+			Log:      logger,
+		}
+		changes, err := importer.Import(cmd.Context(), boms)
+		printBomChanges(cmd, changes, o.dryRun)
+		return err
+	},
+}
+
+// clientConnection hands an existing client to the database services
+type clientConnection struct {
+	client *dynamodb.Client
+}
+
+func (c *clientConnection) GetDbConnection() *dynamodb.Client {
+	return c.client
+}
+
+func printBomChanges(cmd *cobra.Command, changes []bom.Change, dryRun bool) {
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return
+	}
+	for _, change := range changes {
+		action := string(change.Action)
+		if dryRun {
+			action = "would " + action
+		}
+		fmt.Fprintf(out, "%s: %s\n", change.Bom, action)
+		for _, line := range change.Products {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(bomCmd)
+	bomCmd.AddCommand(bomImportCmd)
+
+	flags := bomImportCmd.Flags()
+	flags.StringVar(&bomImportOpts.file, "file", "", "BOM file (.xlsx, .csv or .yaml)")
+	flags.StringVar(&bomImportOpts.sheet, "sheet", bom.DefaultSheet, "sheet of the workbook to read")
+	flags.StringVar(&bomImportOpts.table, "table", os.Getenv("RELATED_PRODUCTS_TABLE_NAME"), "related products table, defaults to the one of the service config")
+	flags.StringVar(&bomImportOpts.region, "region", os.Getenv("REGION"), "AWS region")
+	flags.BoolVar(&bomImportOpts.delete, "delete", false, "delete BOMs that are not in the file")
+	flags.BoolVar(&bomImportOpts.dryRun, "dry-run", false, "print the changes without writing them")
+}
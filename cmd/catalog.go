@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/chef/omnitruck-service/catalog"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/dboperations"
+	dbconnection "github.com/chef/omnitruck-service/middleware/db"
+	"github.com/chef/omnitruck-service/utils/awsutils"
+	"github.com/spf13/cobra"
+)
+
+type catalogVerifyOptions struct {
+	format    string
+	checksums bool
+}
+
+var catalogVerifyOpts catalogVerifyOptions
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Inspect the package catalog",
+}
+
+var catalogVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the catalog against S3 and the product list",
+	Long: `Walks the infra package details of the current and stable channels and
+checks that every package has an object in S3 at the key downloads use,
+that no version stored in S3 is missing from the catalog and that every
+platform is in the platform registry. Every BOM of the related products
+table must refer to products exposed by /products.
+
+With --checksums every object is streamed and its SHA256 compared with the
+catalog. The service configuration is read from the secret named by the
+CONFIG environment variable, like the start command.
+
+The command exits with an error when anything is found, so it can gate CI.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/catalog")
+		ctx := cmd.Context()
+
+		serviceConfig, err := loadServiceConfig()
+		if err != nil {
+			return err
+		}
+		awsConfig := serviceConfig.AWSConfig
+		if err := s3aws.ValidateS3Config(awsConfig); err != nil {
+			return err
+		}
+
+		db := dboperations.NewDbOperationsService(dbconnection.NewDbConnectionService(awsutils.NewAwsUtils(), serviceConfig), serviceConfig)
+		cfg, err := s3aws.NewS3Session(awsConfig.Region)
+		if err != nil {
+			return err
+		}
+		client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.Credentials = aws.NewCredentialsCache(s3aws.NewS3Credentials(cfg, awsConfig.S3Config.RoleArn))
+		})
+
+		products, err := knownProducts(ctx, serviceConfig, db, logger)
+		if err != nil {
+			return err
+		}
+
+		verifier := &catalog.Verifier{
+			Db:       db,
+			Bucket:   catalog.S3Bucket{Client: client, Name: awsConfig.S3Config.Bucket},
+			S3Config: awsConfig.S3Config,
+			Channels: []catalog.Channel{
+				{Name: constants.CURRENT_CHANNEL, Table: serviceConfig.PackageDetailsCurrentTable},
+				{Name: constants.STABLE_CHANNEL, Table: serviceConfig.PackageDetailsStableTable},
+			},
+			Products:  products,
+			Checksums: catalogVerifyOpts.checksums,
+			Log:       logger,
+		}
+		report, err := verifier.Run(ctx)
+		if err != nil {
+			return err
+		}
+		if err := printCatalogReport(cmd, report, catalogVerifyOpts.format); err != nil {
+			return err
+		}
+		if len(report.Findings) > 0 {
+			return fmt.Errorf("catalog verification found %d problems", len(report.Findings))
+		}
+		return nil
+	},
+}
+
+func printCatalogReport(cmd *cobra.Command, report *catalog.Report, format string) error {
+	out := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text":
+		for _, f := range report.Findings {
+			fmt.Fprintf(out, "%s\t%s\n", f.Kind, f.Detail)
+		}
+		fmt.Fprintf(out, "%d files checked, %d problems found\n", report.Files, len(report.Findings))
+		return nil
+	default:
+		return fmt.Errorf("unknown format %s, expected text or json", format)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(catalogCmd)
+	catalogCmd.AddCommand(catalogVerifyCmd)
+
+	flags := catalogVerifyCmd.Flags()
+	flags.StringVar(&catalogVerifyOpts.format, "format", "text", "output format, text or json")
+	flags.BoolVar(&catalogVerifyOpts.checksums, "checksums", false, "stream every object and compare its SHA256")
+}
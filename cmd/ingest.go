@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
+	"github.com/chef/omnitruck-service/ingest"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type ingestOptions struct {
+	dir     string
+	bucket  string
+	prefix  string
+	event   string
+	region  string
+	roleArn string
+	dryRun  bool
+	tables  ingest.Tables
+}
+
+var ingestOpts ingestOptions
+
+// ingestCmd builds catalog records from the files of a release
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Build catalog records from the checksums and metadata of a release",
+	Long: `Reads the .sha256sum files of Automate and Habitat releases, or the
+metadata.json files of infra products, from a bucket prefix or a local
+directory and writes the matching records to DynamoDB.
+
+With --dry-run the records are compared with the stored ones and the
+differences are printed without writing anything.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/ingest")
+		ctx := cmd.Context()
+
+		bucket, prefix := ingestOpts.bucket, ingestOpts.prefix
+		if ingestOpts.event != "" {
+			content, err := os.ReadFile(ingestOpts.event)
+			if err != nil {
+				return err
+			}
+			var event ingest.S3Event
+			if err := json.Unmarshal(content, &event); err != nil {
+				return fmt.Errorf("invalid event %s: %w", ingestOpts.event, err)
+			}
+			if bucket, prefix, err = event.Target(); err != nil {
+				return err
+			}
+		}
+		if (ingestOpts.dir == "") == (bucket == "") {
+			return fmt.Errorf("one of --dir, --bucket or --event is required")
+		}
+
+		in, err := ingestOpts.newIngester(ctx, bucket, logger)
+		if err != nil {
+			return err
+		}
+		report, err := in.Run(ctx, prefix)
+		if report != nil {
+			printReport(cmd, report, ingestOpts.dryRun)
+		}
+		return err
+	},
+}
+
+// ingestLambdaCmd runs the ingestion as the S3-to-DynamoDB lambda function
+var ingestLambdaCmd = &cobra.Command{
+	Use:   "lambda",
+	Short: "Run the ingestion as an AWS Lambda function",
+	Long: `Starts the AWS Lambda runtime and ingests the bucket prefix named by
+each S3 notification or manual trigger event the function is invoked with.
+
+Deploy the binary as a container image whose command is "ingest lambda",
+adding the region, role and table flags as needed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/ingest")
+
+		lambda.Start(ingest.LambdaHandler(func(ctx context.Context, bucket string) (*ingest.Ingester, error) {
+			return ingestOpts.newIngester(ctx, bucket, logger)
+		}))
+		return nil
+	},
+}
+
+func (o ingestOptions) newIngester(ctx context.Context, bucket string, logger *log.Entry) (*ingest.Ingester, error) {
+	cfg, err := s3aws.NewS3Session(o.region)
+	if err != nil {
+		return nil, err
+	}
+
+	var source ingest.Source = ingest.DirSource{Root: o.dir}
+	if bucket != "" {
+		client := s3.NewFromConfig(cfg, func(opts *s3.Options) {
+			if o.roleArn != "" {
+				opts.Credentials = aws.NewCredentialsCache(s3aws.NewS3Credentials(cfg, o.roleArn))
+			}
+		})
+		source = ingest.S3Source{Client: client, Bucket: bucket}
+	}
+
+	return &ingest.Ingester{
+		Source: source,
+		Store:  ingest.DynamoStore{Client: dynamodb.NewFromConfig(cfg)},
+		Tables: o.tables,
+		DryRun: o.dryRun,
+		Log:    logger,
+	}, nil
+}
+
+func printReport(cmd *cobra.Command, report *ingest.Report, dryRun bool) {
+	out := cmd.OutOrStdout()
+	for _, change := range report.Changes {
+		status := "unchanged"
+		switch {
+		case change.Written:
+			status = "written"
+		case len(change.Diff) > 0 && dryRun:
+			status = "would write"
+		}
+		fmt.Fprintf(out, "%s %s %s: %s\n", change.Table, change.Product, change.Version, status)
+		for _, line := range change.Diff {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(ingestCmd)
+	ingestCmd.AddCommand(ingestLambdaCmd)
+
+	local := ingestCmd.Flags()
+	local.StringVar(&ingestOpts.dir, "dir", "", "local directory laid out like the bucket")
+	local.StringVar(&ingestOpts.bucket, "bucket", "", "bucket to read the release from")
+	local.StringVar(&ingestOpts.prefix, "prefix", "", "prefix of the release to ingest")
+	local.StringVar(&ingestOpts.event, "event", "", "S3 event JSON file naming the bucket and prefix, as given to the lambda")
+
+	// Shared with the lambda command
+	flags := ingestCmd.PersistentFlags()
+	flags.StringVar(&ingestOpts.region, "region", os.Getenv("REGION"), "AWS region")
+	flags.StringVar(&ingestOpts.roleArn, "role-arn", "", "role to assume when reading the bucket")
+	flags.BoolVar(&ingestOpts.dryRun, "dry-run", false, "print the changes without writing them")
+	flags.StringSliceVar(&ingestOpts.tables.Metadata, "metadata-tables", []string{"metadata-production"}, "tables for Automate and Habitat records")
+	flags.StringSliceVar(&ingestOpts.tables.PackageDetailsCurrent, "current-tables", []string{"package-details-current-acceptance", "package-details-current-production"}, "tables for infra records of the current channel")
+	flags.StringSliceVar(&ingestOpts.tables.PackageDetailsStable, "stable-tables", []string{"package-details-stable-acceptance", "package-details-stable-production"}, "tables for infra records of the stable channel")
+	flags.StringSliceVar(&ingestOpts.tables.PackageManagers, "package-manager-tables", []string{"package-manager-production", "package-manager-acceptance"}, "tables for package managers")
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/httpserver"
+	"github.com/chef/omnitruck-service/mirror"
+	"github.com/spf13/cobra"
+)
+
+type mirrorSyncOptions struct {
+	url       string
+	license   string
+	dir       string
+	channel   string
+	products  []string
+	platforms []string
+	versions  string
+	timeout   time.Duration
+}
+
+var mirrorSyncOpts mirrorSyncOptions
+
+type mirrorServeOptions struct {
+	dir             string
+	listen          string
+	mode            string
+	licenseRequired bool
+	licenseIds      []string
+}
+
+var mirrorServeOpts mirrorServeOptions
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Maintain an offline mirror of packages",
+}
+
+var mirrorSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copy packages into a mirror directory",
+	Long: `Downloads the packages of the given products into a directory laid out as
+channel/product/version/platform/platform_version/architecture/filename and
+records them in index.json, so the directory can be carried into an
+air-gapped network.
+
+Versions and packages are resolved by the service at --url, so the same
+license, mode and EOL rules apply as for direct downloads. Every package is
+verified against its SHA256. Packages already in the mirror are kept, so a
+sync can be repeated to pick up new versions.`,
+	Example:      `  omnitruck-service mirror sync --license $LICENSE --products chef,inspec --platforms ubuntu,el --versions '~> 18' --dir ./mirror`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/mirror")
+		o := mirrorSyncOpts
+		if len(o.products) == 0 {
+			return fmt.Errorf("--products is required")
+		}
+
+		syncer := &mirror.Syncer{
+			Client: &mirror.Client{
+				BaseUrl:   o.url,
+				LicenseId: o.license,
+				HTTP:      &http.Client{Timeout: o.timeout},
+			},
+			Dir:       o.dir,
+			Channel:   o.channel,
+			Products:  o.products,
+			Platforms: o.platforms,
+			Versions:  o.versions,
+			Log:       logger,
+		}
+		result, err := syncer.Run(cmd.Context())
+		if result != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d downloaded, %d unchanged, %d failed\n", result.Downloaded, result.Unchanged, result.Failed)
+		}
+		return err
+	},
+}
+
+var mirrorServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a mirror directory",
+	Long: `Runs the API from a directory written by mirror sync, so install.sh and
+install.ps1 can be pointed at it inside a network without internet access.
+
+Versions, packages, metadata and files are read from the mirror only. The
+upstream Omnitruck API, DynamoDB, S3 and the license service are never
+called. License ids are checked against a local policy instead: with
+--license-ids only those ids are accepted, otherwise any id is, and
+--license-required rejects requests without one.
+
+Restart the server after a sync to pick up the new index.`,
+	Example:      `  omnitruck-service mirror serve --dir ./mirror --listen :3000 --license-required --license-ids $LICENSE`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := setupLogging().WithField("pkg", "cmd/mirror")
+		o := mirrorServeOpts
+		mode, err := parseMode(o.mode)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		server := httpserver.New(httpserver.Config{
+			Name:   "Mirror Omnitruck API",
+			Listen: o.listen,
+			Log:    logger.WithField("pkg", "mirror"),
+			Mode:   mode,
+			ServiceConfig: config.ServiceConfig{
+				Mirror: config.MirrorConfig{
+					Dir: o.dir,
+					License: config.LocalLicenseConfig{
+						Required: o.licenseRequired,
+						Ids:      o.licenseIds,
+					},
+				},
+			},
+		})
+		server.Start(&wg)
+		stopOnSignal(cmd.Context(), logger, server)
+		wg.Wait()
+		return nil
+	},
+}
+
+// parseMode returns the server mode with the given name
+func parseMode(name string) (constants.ApiType, error) {
+	for _, mode := range []constants.ApiType{constants.Opensource, constants.Trial, constants.Commercial} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q, expected opensource, trial or commercial", name)
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.AddCommand(mirrorSyncCmd)
+	mirrorCmd.AddCommand(mirrorServeCmd)
+
+	flags := mirrorSyncCmd.Flags()
+	flags.StringVar(&mirrorSyncOpts.url, "url", "https://chefdownload-commerical.chef.io", "omnitruck service to copy from")
+	flags.StringVar(&mirrorSyncOpts.license, "license", "", "license id sent with every request")
+	flags.StringVar(&mirrorSyncOpts.dir, "dir", "./mirror", "mirror directory")
+	flags.StringVar(&mirrorSyncOpts.channel, "channel", constants.STABLE_CHANNEL, "channel to copy")
+	flags.StringSliceVar(&mirrorSyncOpts.products, "products", nil, "products to copy")
+	flags.StringSliceVar(&mirrorSyncOpts.platforms, "platforms", nil, "platforms to copy, all when empty")
+	flags.StringVar(&mirrorSyncOpts.versions, "versions", "", "version prefix or constraint such as '~> 18', the latest version when empty")
+	flags.DurationVar(&mirrorSyncOpts.timeout, "timeout", 30*time.Minute, "timeout of a single request, including package downloads")
+
+	flags = mirrorServeCmd.Flags()
+	flags.StringVar(&mirrorServeOpts.dir, "dir", "./mirror", "mirror directory")
+	flags.StringVar(&mirrorServeOpts.listen, "listen", ":3000", "address to listen on")
+	flags.StringVar(&mirrorServeOpts.mode, "mode", constants.Commercial.String(), "server mode: opensource, trial or commercial")
+	flags.BoolVar(&mirrorServeOpts.licenseRequired, "license-required", false, "reject requests without a license id")
+	flags.StringSliceVar(&mirrorServeOpts.licenseIds, "license-ids", nil, "license ids to accept, any when empty")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/chef/omnitruck-service/internal/strategy"
+	"github.com/chef/omnitruck-service/utils/awsutils"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadServiceConfig reads the service configuration from the secret named
+// by the CONFIG environment variable
+func loadServiceConfig() (config.ServiceConfig, error) {
+	var serviceConfig config.ServiceConfig
+	secret := awsutils.GetSecret(os.Getenv("CONFIG"), os.Getenv("REGION"))
+	if err := json.Unmarshal([]byte(secret), &serviceConfig); err != nil {
+		return serviceConfig, fmt.Errorf("reading service config: %w", err)
+	}
+	return serviceConfig, nil
+}
+
+// knownProducts returns every product /products can expose, before the
+// filtering of a mode: the upstream products, the products served from the
+// catalog or routed by a feature flag, and the products a mode adds
+func knownProducts(ctx context.Context, serviceConfig config.ServiceConfig, db dboperations.IDbOperations, logger *log.Entry) ([]string, error) {
+	var products omnitruck.ItemList
+	ot := omnitruck.New(logger, serviceConfig.OmnitruckUrl)
+	ot.Configure(serviceConfig.OmnitruckClient)
+	if request := ot.Products(ctx, &omnitruck.RequestParams{}, &products); !request.Ok {
+		return nil, fmt.Errorf("fetching products: %s", request.Message)
+	}
+
+	dynamo := omnitruck.NewDynamoServices(db, logger)
+	products = dynamo.Products(products, "true", flags.FromConfig(serviceConfig).RoutedProducts()...)
+	// The commercial mode lists every product, including those it adds
+	products = (&strategy.CommercialModeStrategy{}).FilterProducts(products, true)
+
+	seen := map[string]bool{}
+	known := []string{}
+	for _, p := range products {
+		if !seen[p] {
+			seen[p] = true
+			known = append(known, p)
+		}
+	}
+	sort.Strings(known)
+	return known, nil
+}
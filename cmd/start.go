@@ -5,10 +5,13 @@ This file is part of Omnitruck API Wrapper
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
@@ -23,9 +26,15 @@ type CliConfig struct {
 	Opensource ServiceDef  `yaml:"opensource"`
 	Trial      ServiceDef  `yaml:"trial"`
 	Commercial ServiceDef  `yaml:"commercial"`
+	Admin      AdminDef    `yaml:"admin"`
 	Logging    LoggingConf `yaml:"logging"`
 }
 
+type AdminDef struct {
+	Enabled bool   `yaml:"enabled"`
+	Listen  string `yaml:"listen"`
+}
+
 type ServiceDef struct {
 	Name    string `yaml:"name"`
 	Enabled bool   `yaml:"enabled"`
@@ -55,6 +64,7 @@ to quickly create a Cobra application.`,
 		logger := setupLogging()
 
 		var wg sync.WaitGroup
+		var servers []*httpserver.ApiServer
 		var serviceConfig config.ServiceConfig
 		secret := awsutils.GetSecret(os.Getenv("CONFIG"), os.Getenv("REGION"))
 		err := json.Unmarshal([]byte(secret), &serviceConfig)
@@ -70,6 +80,7 @@ to quickly create a Cobra application.`,
 				ServiceConfig: serviceConfig,
 			})
 			os_api.Start(&wg)
+			servers = append(servers, os_api)
 		}
 		if cliConfig.Trial.Enabled {
 			trial_api := httpserver.New(httpserver.Config{
@@ -80,6 +91,7 @@ to quickly create a Cobra application.`,
 				ServiceConfig: serviceConfig,
 			})
 			trial_api.Start(&wg)
+			servers = append(servers, trial_api)
 		}
 		if cliConfig.Commercial.Enabled {
 			commercial_api := httpserver.New(httpserver.Config{
@@ -90,11 +102,49 @@ to quickly create a Cobra application.`,
 				ServiceConfig: serviceConfig,
 			})
 			commercial_api.Start(&wg)
+			servers = append(servers, commercial_api)
+		}
+		stoppers := []stopper{}
+		for _, server := range servers {
+			stoppers = append(stoppers, server)
+		}
+		if cliConfig.Admin.Enabled {
+			admin := httpserver.NewAdmin(httpserver.AdminConfig{
+				Listen: cliConfig.Admin.Listen,
+				Token:  serviceConfig.Admin.Token,
+				Log:    logger.WithField("pkg", "admin"),
+			}, servers...)
+			if err := admin.Start(&wg); err != nil {
+				logger.WithError(err).Error("Unable to start admin server")
+			} else {
+				stoppers = append(stoppers, admin)
+			}
 		}
+		stopOnSignal(cmd.Context(), logger, stoppers...)
 		wg.Wait()
 	},
 }
 
+type stopper interface {
+	Stop() error
+}
+
+// stopOnSignal stops the servers when the process is interrupted or
+// terminated, so they can drain requests and flush audit events
+func stopOnSignal(ctx context.Context, logger *log.Entry, servers ...stopper) {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		cancel()
+		logger.Info("Shutting down")
+		for _, server := range servers {
+			if err := server.Stop(); err != nil {
+				logger.WithError(err).Error("Unable to stop server")
+			}
+		}
+	}()
+}
+
 func setupLogging() *log.Entry {
 	log.SetOutput(os.Stdout)
 	if strings.ToLower(cliConfig.Logging.Format) == "json" {
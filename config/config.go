@@ -1,17 +1,29 @@
 package config
 
+import "net/url"
+
 type ServiceConfig struct {
-	LicenseServiceUrl          string           `json:"licenseServiceUrl"`
-	OmnitruckUrl               string           `json:"omnitruckUrl"`
-	RelatedProductsTable       string           `json:"relatedProductsTable"`
-	MetadataDetailsTable       string           `json:"metadataDetailsTable"`
-	AWSConfig                  AWSConfig        `json:"awsConfig"`
-	ReplicatedConfig           ReplicatedConfig `json:"replicatedConfig"`
-	ReadWriteTimeout           int64            `json:"readWriteTimeout"`
-	PackageManagersTable       string           `json:"packageManagersTable"`
-	PackageDetailsCurrentTable string           `json:"packageDetailsCurrentTable"`
-	PackageDetailsStableTable  string           `json:"packageDetailsStableTable"`
-	SupportInfra19             bool             `json:"supportInfra19"`
+	LicenseServiceUrl          string                       `json:"licenseServiceUrl"`
+	OmnitruckUrl               string                       `json:"omnitruckUrl"`
+	RelatedProductsTable       string                       `json:"relatedProductsTable"`
+	MetadataDetailsTable       string                       `json:"metadataDetailsTable"`
+	AWSConfig                  AWSConfig                    `json:"awsConfig"`
+	ReplicatedConfig           ReplicatedConfig             `json:"replicatedConfig"`
+	ReadWriteTimeout           int64                        `json:"readWriteTimeout"`
+	PackageManagersTable       string                       `json:"packageManagersTable"`
+	PackageDetailsCurrentTable string                       `json:"packageDetailsCurrentTable"`
+	PackageDetailsStableTable  string                       `json:"packageDetailsStableTable"`
+	SupportInfra19             bool                         `json:"supportInfra19"`
+	Audit                      AuditConfig                  `json:"audit"`
+	CatalogCacheTTL            int64                        `json:"catalogCacheTtl"`
+	CacheMaxEntries            int                          `json:"cacheMaxEntries"`
+	Admin                      AdminConfig                  `json:"admin"`
+	FeatureFlags               map[string]FeatureFlagConfig `json:"featureFlags"`
+	CacheControl               map[string]string            `json:"cacheControl"`
+	MetadataBatchConcurrency   int                          `json:"metadataBatchConcurrency"`
+	ReleaseNotes               ReleaseNotesConfig           `json:"releaseNotes"`
+	Mirror                     MirrorConfig                 `json:"mirror"`
+	OmnitruckClient            OmnitruckClientConfig        `json:"omnitruckClient"`
 }
 
 type ReplicatedConfig struct {
@@ -27,9 +39,135 @@ type AWSConfig struct {
 	S3Config  S3Config `json:"s3_config"`
 }
 
+// S3Config locates the infra package files. Type selects the object store:
+// "s3" (the default), "s3-compatible" for an S3 API served at Endpoint such
+// as MinIO, or "local" for files under Dir.
 type S3Config struct {
+	Type        string `json:"type"`
 	Bucket      string `json:"bucket"`
 	RoleArn     string `json:"role_arn"`
+	Endpoint    string `json:"endpoint"`
+	Dir         string `json:"dir"`
 	StablePath  string `json:"stable_path"`
 	CurrentPath string `json:"current_path"`
 }
+
+type AuditConfig struct {
+	Sinks []AuditSinkConfig `json:"sinks"`
+}
+
+type AuditSinkConfig struct {
+	Type       string            `json:"type"`
+	Path       string            `json:"path"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	BufferSize int               `json:"bufferSize"`
+}
+
+// FeatureFlagConfig defines a runtime feature flag. Modes overrides Enabled
+// per server mode (trial, opensource, commercial). Products lists the products
+// gated by the flag and Rewrites the product list entries replaced while it is
+// off. With Block set, requests for gated products are rejected while it is off.
+type FeatureFlagConfig struct {
+	Enabled  bool              `json:"enabled"`
+	Modes    map[string]bool   `json:"modes"`
+	Products []string          `json:"products"`
+	Rewrites map[string]string `json:"rewrites"`
+	Block    bool              `json:"block"`
+	Strategy string            `json:"strategy"`
+}
+
+// ReleaseNotesConfig configures where release notes are read from when the
+// catalog has none. UrlTemplate is a text/template with the Channel, Product
+// and Version fields. CacheTTL is in seconds, a negative value disables caching.
+type ReleaseNotesConfig struct {
+	UrlTemplate string `json:"urlTemplate"`
+	CacheTTL    int64  `json:"cacheTtl"`
+}
+
+// MirrorConfig serves every product from a mirror directory written by
+// mirror sync instead of the upstream services. License ids are then checked
+// against the local policy in License.
+type MirrorConfig struct {
+	Dir     string             `json:"dir"`
+	License LocalLicenseConfig `json:"license"`
+}
+
+// LocalLicenseConfig is a license policy that needs no license service. Unless
+// Required is set requests without a license id are accepted. With Ids set only
+// those license ids are accepted, otherwise any license id is.
+type LocalLicenseConfig struct {
+	Required bool     `json:"required"`
+	Ids      []string `json:"ids"`
+}
+
+// OmnitruckClientConfig tunes the calls to the upstream Omnitruck API.
+// Timeout bounds a single attempt in seconds and Retries is the number of
+// extra attempts of a failed GET. After BreakerThreshold consecutive failures
+// the circuit opens for BreakerCooldown seconds, during which the last good
+// answers, kept for StaleTTL seconds, are served instead. Zero values use the
+// defaults.
+type OmnitruckClientConfig struct {
+	Timeout          int64 `json:"timeout"`
+	Retries          int   `json:"retries"`
+	BreakerThreshold int   `json:"breakerThreshold"`
+	BreakerCooldown  int64 `json:"breakerCooldown"`
+	StaleTTL         int64 `json:"staleTtl"`
+}
+
+type AdminConfig struct {
+	Token string `json:"token"`
+}
+
+const redacted = "REDACTED"
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactUrl keeps the scheme and host of a URL and masks the rest, which may
+// carry a token. Values that are not absolute URLs are masked entirely.
+func redactUrl(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redact(value)
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
+
+// Redacted returns a copy of the config with credentials masked so it can be
+// shown to operators.
+func (c ServiceConfig) Redacted() ServiceConfig {
+	c.AWSConfig.AccessKey = redact(c.AWSConfig.AccessKey)
+	c.AWSConfig.SecretKey = redact(c.AWSConfig.SecretKey)
+	c.ReplicatedConfig.Token = redact(c.ReplicatedConfig.Token)
+	c.Admin.Token = redact(c.Admin.Token)
+	c.ReleaseNotes.UrlTemplate = redactUrl(c.ReleaseNotes.UrlTemplate)
+
+	if c.Mirror.License.Ids != nil {
+		ids := make([]string, len(c.Mirror.License.Ids))
+		for i, id := range c.Mirror.License.Ids {
+			ids[i] = redact(id)
+		}
+		c.Mirror.License.Ids = ids
+	}
+
+	sinks := make([]AuditSinkConfig, len(c.Audit.Sinks))
+	for i, sink := range c.Audit.Sinks {
+		sink.URL = redactUrl(sink.URL)
+		if sink.Headers != nil {
+			headers := make(map[string]string, len(sink.Headers))
+			for k, v := range sink.Headers {
+				headers[k] = redact(v)
+			}
+			sink.Headers = headers
+		}
+		sinks[i] = sink
+	}
+	c.Audit.Sinks = sinks
+
+	return c
+}
@@ -45,3 +45,15 @@ const (
 	Opensource
 	Commercial
 )
+
+func (t ApiType) String() string {
+	switch t {
+	case Trial:
+		return "trial"
+	case Opensource:
+		return "opensource"
+	case Commercial:
+		return "commercial"
+	}
+	return "unknown"
+}
@@ -15,23 +15,24 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	dbconnection "github.com/chef/omnitruck-service/middleware/db"
 	"github.com/chef/omnitruck-service/models"
 )
 
 type IDbOperations interface {
-	GetPackages(partitionValue string, sortValue string) (interface{}, error)
-	GetVersionAll(partitionValue string) ([]string, error)
-	GetMetaData(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error)
-	GetVersionLatest(partitionValue string) (string, error)
-	GetRelatedProducts(partitionValue string) (*models.RelatedProducts, error)
-	GetPackageManagers() ([]string, error)
+	GetPackages(ctx context.Context, partitionValue string, sortValue string) (interface{}, error)
+	GetVersionAll(ctx context.Context, partitionValue string) ([]string, error)
+	GetMetaData(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error)
+	GetVersionLatest(ctx context.Context, partitionValue string) (string, error)
+	GetRelatedProducts(ctx context.Context, partitionValue string) (*models.RelatedProducts, error)
+	GetPackageManagers(ctx context.Context) ([]string, error)
 	SetDbInfo(tableName string, dbModel reflect.Type)
 }
 
 type IDynamoDBOps interface {
-	GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
-	Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	GetItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
 }
 
 // Wrapper to adapt *dynamodb.Client to IDynamoDBOps
@@ -39,12 +40,27 @@ type DynamoDBOpsWrapper struct {
 	Client *dynamodb.Client
 }
 
-func (w *DynamoDBOpsWrapper) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
-	return w.Client.GetItem(context.TODO(), input)
+func (w *DynamoDBOpsWrapper) GetItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return w.Client.GetItem(ctx, input)
 }
 
-func (w *DynamoDBOpsWrapper) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-	return w.Client.Scan(context.TODO(), input)
+func (w *DynamoDBOpsWrapper) Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return w.Client.Scan(ctx, input)
+}
+
+// IDynamoDBWriter is implemented by database clients that can modify items.
+// It is kept apart from IDynamoDBOps so read-only clients need not provide it.
+type IDynamoDBWriter interface {
+	PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+}
+
+func (w *DynamoDBOpsWrapper) PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return w.Client.PutItem(ctx, input)
+}
+
+func (w *DynamoDBOpsWrapper) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return w.Client.DeleteItem(ctx, input)
 }
 
 type DbOperationsService struct {
@@ -67,7 +83,7 @@ type DbOperationsService struct {
 // - The "packages" attribute is a string containing the name of the package manager (e.g., "deb", "rpm", "msi").
 // - One package per item is stored.
 type PackageManagerItem struct {
-	Packages string `json:"packages"`
+	Packages string `json:"packages" dynamodbav:"packages"`
 }
 
 func NewDbOperationsService(dbConnection dbconnection.DbConnection, config config.ServiceConfig) *DbOperationsService {
@@ -82,13 +98,24 @@ func NewDbOperationsService(dbConnection dbconnection.DbConnection, config confi
 	}
 }
 
+// Cloner is implemented by database services that can hand out an
+// independent copy, so SetDbInfo on the copy does not affect the original.
+type Cloner interface {
+	Clone() IDbOperations
+}
+
+func (dbo *DbOperationsService) Clone() IDbOperations {
+	clone := *dbo
+	return &clone
+}
+
 func (dbo *DbOperationsService) SetDbInfo(tableName string, dbModelType reflect.Type) {
 	dbo.productTableName = tableName
 	dbo.dbModelType = dbModelType
 }
 
-func (dbo *DbOperationsService) GetPackages(partitionValue string, sortValue string) (interface{}, error) {
-	res, err := dbo.fetchDataValuesWithSortKey(partitionValue, sortValue)
+func (dbo *DbOperationsService) GetPackages(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+	res, err := dbo.fetchDataValuesWithSortKey(ctx, partitionValue, sortValue)
 	if err != nil {
 		return nil, err
 	}
@@ -105,8 +132,8 @@ func (dbo *DbOperationsService) GetPackages(partitionValue string, sortValue str
 	return nil, nil
 }
 
-func (dbo *DbOperationsService) GetVersionAll(partitionValue string) ([]string, error) {
-	res, err := dbo.fetchDataValues(partitionValue, dbo.productTableName, constants.PRODUCT_PARTITION_KEY)
+func (dbo *DbOperationsService) GetVersionAll(ctx context.Context, partitionValue string) ([]string, error) {
+	res, err := dbo.fetchDataValues(ctx, partitionValue, dbo.productTableName, constants.PRODUCT_PARTITION_KEY)
 	if err != nil {
 		log.Errorf("error in getting the Database value: %v", err)
 		return nil, err
@@ -129,8 +156,39 @@ func (dbo *DbOperationsService) GetVersionAll(partitionValue string) ([]string,
 	return versionsArray, nil
 }
 
-func (dbo *DbOperationsService) GetMetaData(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
-	res, err := dbo.fetchDataValuesWithSortKey(partitionValue, sortValue)
+// ReleaseDater is implemented by database services that record when each
+// version of a product was released.
+type ReleaseDater interface {
+	GetReleaseDates(ctx context.Context, partitionValue string) (map[string]string, error)
+}
+
+// GetReleaseDates returns the release date of every version of the product
+// that has one recorded in the catalog, keyed by version.
+func (dbo *DbOperationsService) GetReleaseDates(ctx context.Context, partitionValue string) (map[string]string, error) {
+	res, err := dbo.fetchDataValues(ctx, partitionValue, dbo.productTableName, constants.PRODUCT_PARTITION_KEY)
+	if err != nil {
+		log.Errorf("error in getting the Database value: %v", err)
+		return nil, err
+	}
+	dates := map[string]string{}
+	for _, i := range res.Items {
+		model := reflect.New(dbo.dbModelType).Interface()
+		if err := attributevalue.UnmarshalMap(i, &model); err != nil {
+			log.Errorf("Got error unmarshalling: %s", err)
+			return nil, err
+		}
+		if v, ok := model.(*models.ProductDetails); ok && v.ReleaseDate != "" {
+			dates[v.Version] = v.ReleaseDate
+		}
+		if v, ok := model.(*models.PackageDetails); ok && v.ReleaseDate != "" {
+			dates[v.Version] = v.ReleaseDate
+		}
+	}
+	return dates, nil
+}
+
+func (dbo *DbOperationsService) GetMetaData(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+	res, err := dbo.fetchDataValuesWithSortKey(ctx, partitionValue, sortValue)
 	if err != nil {
 		return nil, err
 	}
@@ -147,9 +205,14 @@ func (dbo *DbOperationsService) GetMetaData(partitionValue, sortValue, platform,
 				response.Architecture = architecture
 				response.Platform = platform
 				response.PlatformVersion = platformVersion
+				response.PackageManager = j.PackageManager
 				response.SHA1 = j.SHA1
 				response.SHA256 = j.SHA256
 				response.FileName = j.FileName
+				response.InstallMessage = j.InstallMessage
+				response.Size = j.Size
+				response.SignatureUrl = j.SignatureUrl
+				response.SbomUrl = j.SbomUrl
 			}
 		}
 		return &response, nil
@@ -165,6 +228,10 @@ func (dbo *DbOperationsService) GetMetaData(partitionValue, sortValue, platform,
 			response.SHA1 = resp.SHA1
 			response.SHA256 = resp.SHA256
 			response.FileName = resp.Filename
+			response.InstallMessage = resp.InstallMessage
+			response.Size = resp.Size
+			response.SignatureUrl = resp.SignatureUrl
+			response.SbomUrl = resp.SbomUrl
 		}
 		return &response, nil
 	default:
@@ -172,15 +239,18 @@ func (dbo *DbOperationsService) GetMetaData(partitionValue, sortValue, platform,
 	}
 }
 
-func (dbo *DbOperationsService) GetVersionLatest(partitionValue string) (string, error) {
-	versions, err := dbo.GetVersionAll(partitionValue)
+func (dbo *DbOperationsService) GetVersionLatest(ctx context.Context, partitionValue string) (string, error) {
+	versions, err := dbo.GetVersionAll(ctx, partitionValue)
 	if err != nil {
 		log.Errorf("Error in getting versions list: %v", err)
 		return "", err
 	}
+	if len(versions) == 0 {
+		return "", apierror.Wrap(apierror.ErrNotFound, fmt.Errorf("no versions found for %s", partitionValue))
+	}
 	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
 	sortValue := versions[0]
-	latestVersionDetails, err := dbo.GetPackages(partitionValue, sortValue)
+	latestVersionDetails, err := dbo.GetPackages(ctx, partitionValue, sortValue)
 	if err != nil {
 		log.Errorf("Error in fetching the latest version: %v", err)
 		return "", err
@@ -195,9 +265,9 @@ func (dbo *DbOperationsService) GetVersionLatest(partitionValue string) (string,
 	return version, nil
 }
 
-func (dbo *DbOperationsService) GetRelatedProducts(partitionValue string) (*models.RelatedProducts, error) {
+func (dbo *DbOperationsService) GetRelatedProducts(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 	var sku models.RelatedProducts
-	res, err := dbo.fetchDataValues(partitionValue, dbo.skuTableName, constants.SKU_PARTITION_KEY)
+	res, err := dbo.fetchDataValues(ctx, partitionValue, dbo.skuTableName, constants.SKU_PARTITION_KEY)
 	if err != nil {
 		log.Errorf("error in fetching the database values: %v", err)
 		return nil, err
@@ -224,7 +294,117 @@ func (dbo *DbOperationsService) GetRelatedProducts(partitionValue string) (*mode
 	return &sku, nil
 }
 
-func (dbo *DbOperationsService) fetchDataValues(partitionValue string, tableName string, partitionKey string) (*dynamodb.ScanOutput, error) {
+// CatalogLister is implemented by database services that can list every
+// record of an infra package details table.
+type CatalogLister interface {
+	ListPackageDetails(ctx context.Context, tableName string) ([]models.PackageDetails, error)
+}
+
+// ListPackageDetails returns every product version recorded in the table
+func (dbo *DbOperationsService) ListPackageDetails(ctx context.Context, tableName string) ([]models.PackageDetails, error) {
+	var details []models.PackageDetails
+	input := &dynamodb.ScanInput{TableName: &tableName}
+	for {
+		res, err := dbo.db.Scan(ctx, input)
+		if err != nil {
+			log.Errorf("Error scanning table %s: %v", tableName, err)
+			return nil, err
+		}
+		for _, item := range res.Items {
+			var d models.PackageDetails
+			if err := attributevalue.UnmarshalMap(item, &d); err != nil {
+				log.Errorf("Unmarshal error: %v", err)
+				return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+			}
+			details = append(details, d)
+		}
+		if len(res.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+	return details, nil
+}
+
+// RelatedProductsStore is implemented by database services that can list
+// and modify the BOM to related products mapping.
+type RelatedProductsStore interface {
+	ListRelatedProducts(ctx context.Context) ([]models.RelatedProducts, error)
+	PutRelatedProducts(ctx context.Context, item models.RelatedProducts) error
+	DeleteRelatedProducts(ctx context.Context, bom string) error
+}
+
+// ListRelatedProducts returns every BOM in the related products table
+func (dbo *DbOperationsService) ListRelatedProducts(ctx context.Context) ([]models.RelatedProducts, error) {
+	var boms []models.RelatedProducts
+	input := &dynamodb.ScanInput{TableName: &dbo.skuTableName}
+	for {
+		res, err := dbo.db.Scan(ctx, input)
+		if err != nil {
+			log.Errorf("Error scanning table %s: %v", dbo.skuTableName, err)
+			return nil, err
+		}
+		for _, item := range res.Items {
+			var sku models.RelatedProducts
+			if err := attributevalue.UnmarshalMap(item, &sku); err != nil {
+				log.Errorf("Unmarshal error: %v", err)
+				return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+			}
+			boms = append(boms, sku)
+		}
+		if len(res.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+	return boms, nil
+}
+
+// PutRelatedProducts writes a BOM and its products, replacing any existing entry
+func (dbo *DbOperationsService) PutRelatedProducts(ctx context.Context, sku models.RelatedProducts) error {
+	writer, err := dbo.writer()
+	if err != nil {
+		return err
+	}
+	item, err := attributevalue.MarshalMap(sku)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.PutItem(ctx, &dynamodb.PutItemInput{TableName: &dbo.skuTableName, Item: item}); err != nil {
+		log.Errorf("Error writing BOM %s: %v", sku.Bom, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteRelatedProducts removes a BOM from the related products table
+func (dbo *DbOperationsService) DeleteRelatedProducts(ctx context.Context, bom string) error {
+	writer, err := dbo.writer()
+	if err != nil {
+		return err
+	}
+	input := &dynamodb.DeleteItemInput{
+		TableName: &dbo.skuTableName,
+		Key: map[string]types.AttributeValue{
+			constants.SKU_PARTITION_KEY: &types.AttributeValueMemberS{Value: bom},
+		},
+	}
+	if _, err := writer.DeleteItem(ctx, input); err != nil {
+		log.Errorf("Error deleting BOM %s: %v", bom, err)
+		return err
+	}
+	return nil
+}
+
+func (dbo *DbOperationsService) writer() (IDynamoDBWriter, error) {
+	writer, ok := dbo.db.(IDynamoDBWriter)
+	if !ok {
+		return nil, errors.New("database connection is read only")
+	}
+	return writer, nil
+}
+
+func (dbo *DbOperationsService) fetchDataValues(ctx context.Context, partitionValue string, tableName string, partitionKey string) (*dynamodb.ScanOutput, error) {
 	filter := expression.Name(partitionKey).Equal(expression.Value(partitionValue))
 
 	expr, err := expression.NewBuilder().WithFilter(filter).Build()
@@ -237,15 +417,15 @@ func (dbo *DbOperationsService) fetchDataValues(partitionValue string, tableName
 		FilterExpression:          expr.Filter(),
 		TableName:                 &tableName,
 	}
-	res, err := dbo.db.Scan(params)
+	res, err := dbo.db.Scan(ctx, params)
 	if err != nil {
 		log.Errorf("error while using getting the dataBase values: %v", err)
-		return nil, err
+		return nil, apierror.FromAWS(err)
 	}
 	return res, nil
 }
 
-func (dbo *DbOperationsService) fetchDataValuesWithSortKey(partitionValue string, sortValue string) (*dynamodb.GetItemOutput, error) {
+func (dbo *DbOperationsService) fetchDataValuesWithSortKey(ctx context.Context, partitionValue string, sortValue string) (*dynamodb.GetItemOutput, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: &dbo.productTableName,
 		Key: map[string]types.AttributeValue{
@@ -253,25 +433,25 @@ func (dbo *DbOperationsService) fetchDataValuesWithSortKey(partitionValue string
 			constants.PRODUCT_SORT_KEY:      &types.AttributeValueMemberS{Value: sortValue},
 		},
 	}
-	res, err := dbo.db.GetItem(input)
+	res, err := dbo.db.GetItem(ctx, input)
 	if err != nil {
 		log.Errorf("error while using getting the dataBase values: %v", err)
-		return nil, err
+		return nil, apierror.FromAWS(err)
 	}
 	return res, nil
 }
 
-func (dbo *DbOperationsService) GetPackageManagers() ([]string, error) {
+func (dbo *DbOperationsService) GetPackageManagers(ctx context.Context) ([]string, error) {
 	tableName := dbo.packageManagersTable
 
 	input := &dynamodb.ScanInput{
 		TableName: &tableName,
 	}
 
-	res, err := dbo.db.Scan(input)
+	res, err := dbo.db.Scan(ctx, input)
 	if err != nil {
 		log.Errorf("Error scanning table %s: %v", tableName, err)
-		return nil, err
+		return nil, apierror.FromAWS(err)
 	}
 	if res == nil || res.Items == nil || len(res.Items) == 0 {
 		log.Errorf("Scan result is nil or missing Items from table %s", tableName)
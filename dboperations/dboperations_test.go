@@ -1,6 +1,7 @@
 package dboperations
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -9,22 +10,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	"github.com/chef/omnitruck-service/models"
 	"github.com/stretchr/testify/assert"
 )
 
 type MDB struct {
-	GetItemfunc func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
-	Scanfunc    func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	GetItemfunc func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	Scanfunc    func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
 }
 
 // Implement IDynamoDBOps interface from dboperations.go (v1 signatures)
-func (mdb *MDB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
-	return mdb.GetItemfunc(input)
+func (mdb *MDB) GetItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return mdb.GetItemfunc(ctx, input)
 }
 
-func (mdb *MDB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-	return mdb.Scanfunc(input)
+func (mdb *MDB) Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return mdb.Scanfunc(ctx, input)
 }
 
 func TestGetPackagesSuccess(t *testing.T) {
@@ -84,13 +86,13 @@ func TestGetPackagesSuccess(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+					GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 						return &dynamodb.GetItemOutput{Item: tt.mockItem}, nil
 					},
 				},
 				dbModelType: reflect.TypeOf(tt.model),
 			}
-			got, err := ser.GetPackages(tt.args.partitionValue, tt.args.sortValue)
+			got, err := ser.GetPackages(context.Background(), tt.args.partitionValue, tt.args.sortValue)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
@@ -129,11 +131,11 @@ func TestGetPackagesFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := &MDB{}
 			if tt.mockItem != nil {
-				mockDB.GetItemfunc = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+				mockDB.GetItemfunc = func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 					return &dynamodb.GetItemOutput{Item: tt.mockItem}, nil
 				}
 			} else {
-				mockDB.GetItemfunc = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+				mockDB.GetItemfunc = func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 					return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
 				}
 			}
@@ -143,7 +145,7 @@ func TestGetPackagesFailure(t *testing.T) {
 				dbModelType: reflect.TypeOf(tt.model),
 			}
 
-			got, err := ser.GetPackages(tt.args.partitionValue, tt.args.sortValue)
+			got, err := ser.GetPackages(context.Background(), tt.args.partitionValue, tt.args.sortValue)
 
 			if tt.expectError != "" {
 				assert.EqualError(t, err, tt.expectError)
@@ -211,7 +213,7 @@ func TestGetVersionAllSuccess(t *testing.T) {
 			if tt.args.partitionValue == "automate" {
 				ser = &DbOperationsService{
 					db: &MDB{
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -231,7 +233,7 @@ func TestGetVersionAllSuccess(t *testing.T) {
 			} else if tt.args.partitionValue == "chef-ice" {
 				ser = &DbOperationsService{
 					db: &MDB{
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -251,7 +253,7 @@ func TestGetVersionAllSuccess(t *testing.T) {
 			} else {
 				ser = &DbOperationsService{
 					db: &MDB{
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -269,7 +271,7 @@ func TestGetVersionAllSuccess(t *testing.T) {
 					dbModelType: reflect.TypeOf(models.PackageDetails{}),
 				}
 			}
-			got, _ := ser.GetVersionAll(tt.args.partitionValue)
+			got, _ := ser.GetVersionAll(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, got, tt.want)
 		})
 	}
@@ -300,19 +302,80 @@ func TestGetVersionAllFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 						return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 					},
 				},
 				dbModelType: reflect.TypeOf(models.ProductDetails{}),
 			}
-			got, err := ser.GetVersionAll(tt.args.partitionValue)
+			got, err := ser.GetVersionAll(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, got, tt.want)
 			assert.Equal(t, err.Error(), tt.wantErr.Error())
 		})
 	}
 }
 
+func TestGetReleaseDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelType reflect.Type
+		scanErr   error
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "product details",
+			modelType: reflect.TypeOf(models.ProductDetails{}),
+			want:      map[string]string{version4054: "2024-01-15"},
+		},
+		{
+			name:      "package details",
+			modelType: reflect.TypeOf(models.PackageDetails{}),
+			want:      map[string]string{version4054: "2024-01-15"},
+		},
+		{
+			name:      "scan failure",
+			modelType: reflect.TypeOf(models.ProductDetails{}),
+			scanErr:   errors.New("scan failed"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ser := &DbOperationsService{
+				db: &MDB{
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						if tt.scanErr != nil {
+							return nil, tt.scanErr
+						}
+						return &dynamodb.ScanOutput{
+							Items: []map[string]types.AttributeValue{
+								{
+									"product":      &types.AttributeValueMemberS{Value: "automate"},
+									"version":      &types.AttributeValueMemberS{Value: version4054},
+									"release_date": &types.AttributeValueMemberS{Value: "2024-01-15"},
+								},
+								{
+									"product": &types.AttributeValueMemberS{Value: "automate"},
+									"version": &types.AttributeValueMemberS{Value: version4091},
+								},
+							},
+						}, nil
+					},
+				},
+				dbModelType: tt.modelType,
+			}
+			got, err := ser.GetReleaseDates(context.Background(), "automate")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestGetMetaDataSuccess(t *testing.T) {
 	type args struct {
 		partitionValue  string
@@ -449,19 +512,65 @@ func TestGetMetaDataSuccess(t *testing.T) {
 			},
 			dbModelType: reflect.TypeOf(models.PackageDetails{}),
 		},
+		{
+			name: "Success with extended metadata",
+			args: args{
+				partitionValue:  "chef-ice",
+				sortValue:       "19.1.2",
+				platform:        "linux",
+				platformVersion: "",
+				architecture:    "x86_64",
+				packageManager:  "rpm",
+			},
+			want: &models.MetaData{
+				Architecture:   "x86_64",
+				Platform:       "linux",
+				SHA1:           "SHA1x86_64",
+				SHA256:         "SHA256x86_64",
+				FileName:       "chef-ice-19.1.2-1.x86_64.rpm",
+				PackageManager: "rpm",
+				InstallMessage: "Run chef-ice init to finish setup",
+				Size:           2048,
+				SignatureUrl:   "https://example.com/chef-ice-19.1.2-1.x86_64.rpm.asc",
+				SbomUrl:        "https://example.com/chef-ice-19.1.2-1.x86_64.rpm.spdx.json",
+			},
+			wantErr: false,
+			dynamodbResp: dynamodb.GetItemOutput{
+				Item: map[string]types.AttributeValue{
+					"product": &types.AttributeValueMemberS{Value: "chef-ice"},
+					"version": &types.AttributeValueMemberS{Value: "19.1.2"},
+					"metadata": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"linux": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+							"x86_64": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+								"rpm": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+									"filename":        &types.AttributeValueMemberS{Value: "chef-ice-19.1.2-1.x86_64.rpm"},
+									"install-message": &types.AttributeValueMemberS{Value: "Run chef-ice init to finish setup"},
+									"sha1":            &types.AttributeValueMemberS{Value: "SHA1x86_64"},
+									"sha256":          &types.AttributeValueMemberS{Value: "SHA256x86_64"},
+									"size":            &types.AttributeValueMemberN{Value: "2048"},
+									"signature_url":   &types.AttributeValueMemberS{Value: "https://example.com/chef-ice-19.1.2-1.x86_64.rpm.asc"},
+									"sbom_url":        &types.AttributeValueMemberS{Value: "https://example.com/chef-ice-19.1.2-1.x86_64.rpm.spdx.json"},
+								}},
+							}},
+						}},
+					}},
+				},
+			},
+			dbModelType: reflect.TypeOf(models.PackageDetails{}),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+					GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 						return &tt.dynamodbResp, nil
 					},
 				},
 				dbModelType: tt.dbModelType,
 			}
-			got, _ := ser.GetMetaData(tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
+			got, _ := ser.GetMetaData(context.Background(), tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -519,7 +628,7 @@ func TestGetMetaDataFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+					GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 						if tt.wantDBErr {
 							return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 						}
@@ -545,7 +654,7 @@ func TestGetMetaDataFailure(t *testing.T) {
 				},
 				dbModelType: tt.dbModelType,
 			}
-			got, err := ser.GetMetaData(tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
+			got, err := ser.GetMetaData(context.Background(), tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
 			assert.Nil(t, got)
 			assert.Equal(t, tt.errorMsg.Error(), err.Error())
 		})
@@ -554,7 +663,7 @@ func TestGetMetaDataFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+					GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 						if tt.wantDBErr {
 							return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 						}
@@ -580,7 +689,7 @@ func TestGetMetaDataFailure(t *testing.T) {
 				},
 				dbModelType: tt.dbModelType,
 			}
-			got, err := ser.GetMetaData(tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
+			got, err := ser.GetMetaData(context.Background(), tt.args.partitionValue, tt.args.sortValue, tt.args.platform, tt.args.platformVersion, tt.args.architecture, tt.args.packageManager)
 			assert.Nil(t, got)
 			assert.Equal(t, tt.errorMsg.Error(), err.Error())
 		})
@@ -633,7 +742,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 			if tt.args.partitionValue == "automate" {
 				ser = &DbOperationsService{
 					db: &MDB{
-						GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+						GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 							return &dynamodb.GetItemOutput{
 								Item: map[string]types.AttributeValue{
 									"product": &types.AttributeValueMemberS{Value: "automate"},
@@ -641,7 +750,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 								},
 							}, nil
 						},
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -657,7 +766,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 			} else if tt.args.partitionValue == "chef-ice" {
 				ser = &DbOperationsService{
 					db: &MDB{
-						GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+						GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 							return &dynamodb.GetItemOutput{
 								Item: map[string]types.AttributeValue{
 									"product": &types.AttributeValueMemberS{Value: "chef-ice"},
@@ -665,7 +774,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 								},
 							}, nil
 						},
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -681,7 +790,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 			} else {
 				ser = &DbOperationsService{
 					db: &MDB{
-						GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+						GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 							return &dynamodb.GetItemOutput{
 								Item: map[string]types.AttributeValue{
 									"product": &types.AttributeValueMemberS{Value: "migrate-ice"},
@@ -689,7 +798,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 								},
 							}, nil
 						},
-						Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+						Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 							return &dynamodb.ScanOutput{
 								Items: []map[string]types.AttributeValue{
 									{
@@ -703,7 +812,7 @@ func TestGetVersionLatestSuccess(t *testing.T) {
 					dbModelType: reflect.TypeOf(models.PackageDetails{}),
 				}
 			}
-			got, _ := ser.GetVersionLatest(tt.args.partitionValue)
+			got, _ := ser.GetVersionLatest(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -735,16 +844,16 @@ func TestGetVersionLatestFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					GetItemfunc: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+					GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 						return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 					},
-					Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 						return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 					},
 				},
 				dbModelType: tt.dbModelType,
 			}
-			got, err := ser.GetVersionLatest(tt.args.partitionValue)
+			got, err := ser.GetVersionLatest(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.wantErr.Error(), err.Error())
 		})
@@ -778,7 +887,7 @@ func TestGetRelatedProductsSuccess(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 						return &dynamodb.ScanOutput{
 							Items: []map[string]types.AttributeValue{
 								{
@@ -792,7 +901,7 @@ func TestGetRelatedProductsSuccess(t *testing.T) {
 					},
 				},
 			}
-			got, _ := ser.GetRelatedProducts(tt.args.partitionValue)
+			got, _ := ser.GetRelatedProducts(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -822,12 +931,12 @@ func TestGetRelatedProductsFailure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 						return nil, &types.ReplicaNotFoundException{Message: aws.String("Requested resource not found")}
 					},
 				},
 			}
-			got, _ := ser.GetRelatedProducts(tt.args.partitionValue)
+			got, _ := ser.GetRelatedProducts(context.Background(), tt.args.partitionValue)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -902,7 +1011,7 @@ func TestGetPackageManagers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ser := &DbOperationsService{
 				db: &MDB{
-					Scanfunc: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 						// Convert v1 mockScanOutput to v2 if needed
 						if tt.mockScanOutput != nil {
 							// Convert Items if they are v1
@@ -932,7 +1041,7 @@ func TestGetPackageManagers(t *testing.T) {
 				packageManagersTable: "package-manager-dev",
 			}
 
-			got, err := ser.GetPackageManagers()
+			got, err := ser.GetPackageManagers(context.Background())
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -946,3 +1055,140 @@ func TestGetPackageManagers(t *testing.T) {
 		})
 	}
 }
+
+type MDBWriter struct {
+	MDB
+	PutItemfunc    func(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	DeleteItemfunc func(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+}
+
+func (mdb *MDBWriter) PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return mdb.PutItemfunc(ctx, input)
+}
+
+func (mdb *MDBWriter) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return mdb.DeleteItemfunc(ctx, input)
+}
+
+func TestListRelatedProducts(t *testing.T) {
+	bomItem := func(bom, product string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"bom": &types.AttributeValueMemberS{Value: bom},
+			"products": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				product: &types.AttributeValueMemberS{Value: product},
+			}},
+		}
+	}
+	pages := []*dynamodb.ScanOutput{
+		{Items: []map[string]types.AttributeValue{bomItem("BOM-1", "chef")}, LastEvaluatedKey: map[string]types.AttributeValue{"bom": &types.AttributeValueMemberS{Value: "BOM-1"}}},
+		{Items: []map[string]types.AttributeValue{bomItem("BOM-2", "inspec")}},
+	}
+	calls := 0
+	ser := &DbOperationsService{
+		skuTableName: "related-products",
+		db: &MDB{
+			Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				assert.Equal(t, "related-products", aws.ToString(input.TableName))
+				if calls == 1 {
+					assert.Equal(t, pages[0].LastEvaluatedKey, input.ExclusiveStartKey)
+				}
+				calls++
+				return pages[calls-1], nil
+			},
+		},
+	}
+
+	got, err := ser.ListRelatedProducts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RelatedProducts{
+		{Bom: "BOM-1", Products: map[string]string{"chef": "chef"}},
+		{Bom: "BOM-2", Products: map[string]string{"inspec": "inspec"}},
+	}, got)
+
+	ser.db = &MDB{Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		return nil, errors.New("scan failed")
+	}}
+	_, err = ser.ListRelatedProducts(context.Background())
+	assert.EqualError(t, err, "scan failed")
+}
+
+func TestPutAndDeleteRelatedProducts(t *testing.T) {
+	var put *dynamodb.PutItemInput
+	var deleted *dynamodb.DeleteItemInput
+	ser := &DbOperationsService{
+		skuTableName: "related-products",
+		db: &MDBWriter{
+			PutItemfunc: func(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+				put = input
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			DeleteItemfunc: func(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+				deleted = input
+				return &dynamodb.DeleteItemOutput{}, nil
+			},
+		},
+	}
+
+	assert.NoError(t, ser.PutRelatedProducts(context.Background(), models.RelatedProducts{Bom: "BOM-1", Products: map[string]string{"chef": "Chef Infra Client"}}))
+	assert.Equal(t, "related-products", aws.ToString(put.TableName))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "BOM-1"}, put.Item["bom"])
+	assert.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"chef": &types.AttributeValueMemberS{Value: "Chef Infra Client"},
+	}}, put.Item["products"])
+
+	assert.NoError(t, ser.DeleteRelatedProducts(context.Background(), "BOM-1"))
+	assert.Equal(t, "related-products", aws.ToString(deleted.TableName))
+	assert.Equal(t, map[string]types.AttributeValue{"bom": &types.AttributeValueMemberS{Value: "BOM-1"}}, deleted.Key)
+
+	readOnly := &DbOperationsService{skuTableName: "related-products", db: &MDB{}}
+	assert.EqualError(t, readOnly.PutRelatedProducts(context.Background(), models.RelatedProducts{Bom: "BOM-1"}), "database connection is read only")
+	assert.EqualError(t, readOnly.DeleteRelatedProducts(context.Background(), "BOM-1"), "database connection is read only")
+}
+
+func TestListPackageDetails(t *testing.T) {
+	ser := &DbOperationsService{
+		db: &MDB{
+			Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				assert.Equal(t, "package-details-stable", aws.ToString(input.TableName))
+				return &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{{
+					"product": &types.AttributeValueMemberS{Value: "chef-ice"},
+					"version": &types.AttributeValueMemberS{Value: "19.1.27"},
+				}}}, nil
+			},
+		},
+	}
+
+	got, err := ser.ListPackageDetails(context.Background(), "package-details-stable")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PackageDetails{{Product: "chef-ice", Version: "19.1.27"}}, got)
+}
+
+func TestErrorKinds(t *testing.T) {
+	throttled := &MDB{
+		Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			return nil, &types.ProvisionedThroughputExceededException{Message: aws.String("Rate of requests exceeds the allowed throughput")}
+		},
+		GetItemfunc: func(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+		},
+	}
+	ser := &DbOperationsService{db: throttled, dbModelType: reflect.TypeOf(models.PackageDetails{})}
+
+	_, err := ser.GetVersionAll(context.Background(), "chef-ice")
+	assert.ErrorIs(t, err, apierror.ErrThrottled)
+	_, err = ser.GetPackageManagers(context.Background())
+	assert.ErrorIs(t, err, apierror.ErrThrottled)
+	_, err = ser.GetPackages(context.Background(), "chef-ice", "19.1.27")
+	assert.ErrorIs(t, err, apierror.ErrUnavailable)
+
+	empty := &DbOperationsService{
+		db: &MDB{
+			Scanfunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{}, nil
+			},
+		},
+		dbModelType: reflect.TypeOf(models.PackageDetails{}),
+	}
+	_, err = empty.GetVersionLatest(context.Background(), "chef-ice")
+	assert.ErrorIs(t, err, apierror.ErrNotFound)
+}
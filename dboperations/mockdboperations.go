@@ -1,45 +1,87 @@
 package dboperations
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/chef/omnitruck-service/models"
 )
 
 type MockIDbOperations struct {
-	GetPackagesfunc        func(partitionValue string, sortValue string) (interface{}, error)
-	GetVersionAllfunc      func(partitionValue string) ([]string, error)
-	GetMetaDatafunc        func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error)
-	GetVersionLatestfunc   func(partitionValue string) (string, error)
-	GetRelatedProductsfunc func(partitionValue string) (*models.RelatedProducts, error)
-	GetPackageManagersfunc func() ([]string, error)
+	GetPackagesfunc        func(ctx context.Context, partitionValue string, sortValue string) (interface{}, error)
+	GetVersionAllfunc      func(ctx context.Context, partitionValue string) ([]string, error)
+	GetMetaDatafunc        func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error)
+	GetVersionLatestfunc   func(ctx context.Context, partitionValue string) (string, error)
+	GetRelatedProductsfunc func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error)
+	GetPackageManagersfunc func(ctx context.Context) ([]string, error)
 	SetDbInfofunc          func(tableName string, dbModel reflect.Type)
+	GetReleaseDatesfunc    func(ctx context.Context, partitionValue string) (map[string]string, error)
+
+	ListRelatedProductsfunc   func(ctx context.Context) ([]models.RelatedProducts, error)
+	PutRelatedProductsfunc    func(ctx context.Context, item models.RelatedProducts) error
+	DeleteRelatedProductsfunc func(ctx context.Context, bom string) error
+	ListPackageDetailsfunc    func(ctx context.Context, tableName string) ([]models.PackageDetails, error)
 }
 
-func (mdbop *MockIDbOperations) GetPackages(partitionValue string, sortValue string) (interface{}, error) {
-	return mdbop.GetPackagesfunc(partitionValue, sortValue)
+func (mdbop *MockIDbOperations) GetPackages(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+	return mdbop.GetPackagesfunc(ctx, partitionValue, sortValue)
 }
 
-func (mdbop *MockIDbOperations) GetVersionAll(partitionValue string) ([]string, error) {
-	return mdbop.GetVersionAllfunc(partitionValue)
+func (mdbop *MockIDbOperations) GetVersionAll(ctx context.Context, partitionValue string) ([]string, error) {
+	return mdbop.GetVersionAllfunc(ctx, partitionValue)
 }
 
-func (mdbop *MockIDbOperations) GetMetaData(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
-	return mdbop.GetMetaDatafunc(partitionValue, sortValue, platform, platformVersion, architecture, packageManager)
+func (mdbop *MockIDbOperations) GetMetaData(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+	return mdbop.GetMetaDatafunc(ctx, partitionValue, sortValue, platform, platformVersion, architecture, packageManager)
 }
 
-func (mdbop *MockIDbOperations) GetVersionLatest(partitionValue string) (string, error) {
-	return mdbop.GetVersionLatestfunc(partitionValue)
+func (mdbop *MockIDbOperations) GetVersionLatest(ctx context.Context, partitionValue string) (string, error) {
+	return mdbop.GetVersionLatestfunc(ctx, partitionValue)
 }
 
-func (mdbop *MockIDbOperations) GetRelatedProducts(partitionValue string) (*models.RelatedProducts, error) {
-	return mdbop.GetRelatedProductsfunc(partitionValue)
+func (mdbop *MockIDbOperations) GetRelatedProducts(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
+	return mdbop.GetRelatedProductsfunc(ctx, partitionValue)
 }
 
-func (mdbop *MockIDbOperations) GetPackageManagers() ([]string, error) {
-	return mdbop.GetPackageManagersfunc()
+func (mdbop *MockIDbOperations) GetPackageManagers(ctx context.Context) ([]string, error) {
+	return mdbop.GetPackageManagersfunc(ctx)
 }
 
 func (mdbop *MockIDbOperations) SetDbInfo(tableName string, dbModel reflect.Type) {
 	mdbop.SetDbInfofunc(tableName, dbModel)
 }
+
+func (mdbop *MockIDbOperations) GetReleaseDates(ctx context.Context, partitionValue string) (map[string]string, error) {
+	if mdbop.GetReleaseDatesfunc == nil {
+		return map[string]string{}, nil
+	}
+	return mdbop.GetReleaseDatesfunc(ctx, partitionValue)
+}
+
+func (mdbop *MockIDbOperations) ListRelatedProducts(ctx context.Context) ([]models.RelatedProducts, error) {
+	if mdbop.ListRelatedProductsfunc == nil {
+		return nil, nil
+	}
+	return mdbop.ListRelatedProductsfunc(ctx)
+}
+
+func (mdbop *MockIDbOperations) PutRelatedProducts(ctx context.Context, item models.RelatedProducts) error {
+	if mdbop.PutRelatedProductsfunc == nil {
+		return nil
+	}
+	return mdbop.PutRelatedProductsfunc(ctx, item)
+}
+
+func (mdbop *MockIDbOperations) DeleteRelatedProducts(ctx context.Context, bom string) error {
+	if mdbop.DeleteRelatedProductsfunc == nil {
+		return nil
+	}
+	return mdbop.DeleteRelatedProductsfunc(ctx, bom)
+}
+
+func (mdbop *MockIDbOperations) ListPackageDetails(ctx context.Context, tableName string) ([]models.PackageDetails, error) {
+	if mdbop.ListPackageDetailsfunc == nil {
+		return nil, nil
+	}
+	return mdbop.ListPackageDetailsfunc(ctx, tableName)
+}
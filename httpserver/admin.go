@@ -0,0 +1,297 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/chef/omnitruck-service/internal/strategy"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
+	"github.com/gofiber/fiber/v2/middleware/keyauth"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	log "github.com/sirupsen/logrus"
+)
+
+type AdminConfig struct {
+	Listen string
+	Token  string
+	Log    *log.Entry
+}
+
+// AdminServer exposes introspection and maintenance endpoints for the running
+// api servers on its own listener. Every route requires the admin bearer token.
+type AdminServer struct {
+	Config  AdminConfig
+	Log     *log.Entry
+	App     *fiber.App
+	servers map[string]*ApiServer
+}
+
+type adminServerInfo struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`
+	Listen string `json:"listen"`
+}
+
+type adminValidatorInfo struct {
+	Type   string      `json:"type"`
+	Field  string      `json:"field,omitempty"`
+	Values interface{} `json:"values,omitempty"`
+	Code   int         `json:"code"`
+}
+
+type adminFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type adminFlagInfo struct {
+	flags.Flag
+	Active bool `json:"active"`
+}
+
+func NewAdmin(c AdminConfig, servers ...*ApiServer) *AdminServer {
+	admin := &AdminServer{
+		Config:  c,
+		Log:     c.Log,
+		servers: map[string]*ApiServer{},
+	}
+	for _, s := range servers {
+		admin.servers[s.Mode.String()] = s
+	}
+
+	admin.App = fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler:          adminErrorHandler,
+	})
+	admin.buildRouter()
+
+	return admin
+}
+
+func (admin *AdminServer) Name() string {
+	return "admin"
+}
+
+func (admin *AdminServer) Start(wg *sync.WaitGroup) error {
+	if admin.Config.Token == "" {
+		return errors.New("admin token is not configured")
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		admin.StartService()
+	}()
+
+	return nil
+}
+
+func (admin *AdminServer) StartService() {
+	admin.Log.Infof("Starting admin server at: %s", admin.Config.Listen)
+
+	err := admin.App.Listen(admin.Config.Listen)
+	if err != nil {
+		if err == http.ErrServerClosed {
+			admin.Log.WithError(err).Error("Unable to start admin service")
+		} else {
+			admin.Log.WithError(err).Fatal("Admin service stopped")
+		}
+	}
+}
+
+func (admin *AdminServer) Stop() error {
+	return admin.App.Shutdown()
+}
+
+func (admin *AdminServer) buildRouter() {
+	admin.App.Use(recover.New())
+	admin.App.Use(keyauth.New(keyauth.Config{
+		Validator: admin.validateToken,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		},
+	}))
+
+	// Counters published through expvar, such as the object store metrics
+	admin.App.Use(expvar.New())
+
+	admin.App.Get("/servers", admin.ListServers)
+
+	srv := admin.App.Group("/servers/:mode", admin.lookupServer)
+	srv.Get("/config", admin.GetConfig)
+	srv.Get("/validators", admin.GetValidators)
+	srv.Get("/strategies", admin.GetStrategies)
+	srv.Get("/caches", admin.GetCaches)
+	srv.Delete("/caches", admin.PurgeCaches)
+	srv.Delete("/caches/:name", admin.PurgeCaches)
+	srv.Post("/catalog/reload", admin.ReloadCatalog)
+	srv.Get("/flags", admin.GetFlags)
+	srv.Put("/flags/:flag", admin.SetFlag)
+}
+
+func (admin *AdminServer) validateToken(c *fiber.Ctx, key string) (bool, error) {
+	if admin.Config.Token != "" && subtle.ConstantTimeCompare([]byte(key), []byte(admin.Config.Token)) == 1 {
+		return true, nil
+	}
+	return false, keyauth.ErrMissingOrMalformedAPIKey
+}
+
+func adminErrorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		code = e.Code
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"code":    code,
+		"message": err.Error(),
+	})
+}
+
+func (admin *AdminServer) lookupServer(c *fiber.Ctx) error {
+	server, ok := admin.servers[c.Params("mode")]
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("no server running in %s mode", c.Params("mode")))
+	}
+	c.Locals("server", server)
+
+	return c.Next()
+}
+
+func adminServer(c *fiber.Ctx) *ApiServer {
+	return c.Locals("server").(*ApiServer)
+}
+
+func (admin *AdminServer) ListServers(c *fiber.Ctx) error {
+	servers := []adminServerInfo{}
+	for mode, s := range admin.servers {
+		servers = append(servers, adminServerInfo{
+			Name:   s.Config.Name,
+			Mode:   mode,
+			Listen: s.Config.Listen,
+		})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Mode < servers[j].Mode })
+
+	return c.JSON(servers)
+}
+
+func (admin *AdminServer) GetConfig(c *fiber.Ctx) error {
+	return c.JSON(adminServer(c).ServiceConfig().Redacted())
+}
+
+func (admin *AdminServer) GetValidators(c *fiber.Ctx) error {
+	server := adminServer(c)
+
+	validators := []adminValidatorInfo{}
+	for _, v := range server.Validator.GetValidators() {
+		info := adminValidatorInfo{
+			Type: fmt.Sprintf("%T", v),
+			Code: v.GetCode(),
+		}
+		if f, ok := v.(interface{ GetField() string }); ok {
+			info.Field = f.GetField()
+		}
+		if f, ok := v.(interface{ GetValues() interface{} }); ok {
+			info.Values = f.GetValues()
+		}
+		validators = append(validators, info)
+	}
+
+	return c.JSON(validators)
+}
+
+func (admin *AdminServer) GetStrategies(c *fiber.Ctx) error {
+	server := adminServer(c)
+
+	if server.Mirror != nil {
+		strategies := map[string]string{}
+		for _, product := range server.Mirror.Products() {
+			strategies[product] = strategy.StrategyMirror
+		}
+		return c.JSON(strategies)
+	}
+
+	strategies := map[string]string{
+		constants.PLATFORM_SERVICE_PRODUCT: strategy.ProductStrategyName(constants.PLATFORM_SERVICE_PRODUCT, server.Mode, server.Flags),
+	}
+	for _, product := range append(omnitruck.SupportedProducts(), server.Flags.RoutedProducts()...) {
+		strategies[product] = strategy.ProductStrategyName(product, server.Mode, server.Flags)
+	}
+
+	return c.JSON(strategies)
+}
+
+func (admin *AdminServer) GetCaches(c *fiber.Ctx) error {
+	return c.JSON(adminServer(c).Caches.Stats())
+}
+
+func (admin *AdminServer) PurgeCaches(c *fiber.Ctx) error {
+	server := adminServer(c)
+	name := c.Params("name")
+	if name != "" {
+		if _, ok := server.Caches.Get(name); !ok {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("unknown cache %s", name))
+		}
+	}
+
+	purged := server.Caches.Purge(name)
+	admin.Log.WithField("mode", server.Mode.String()).WithField("purged", purged).Info("Purged caches")
+
+	return c.JSON(fiber.Map{"purged": purged})
+}
+
+// ReloadCatalog drops the cached upstream catalog so the next request fetches
+// products, platforms and architectures again.
+func (admin *AdminServer) ReloadCatalog(c *fiber.Ctx) error {
+	server := adminServer(c)
+	if server.CatalogCache == nil {
+		return fiber.NewError(fiber.StatusConflict, "catalog caching is disabled")
+	}
+
+	purged := server.CatalogCache.Purge()
+	admin.Log.WithField("mode", server.Mode.String()).Info("Reloading catalog")
+
+	return c.JSON(fiber.Map{"purged": map[string]int{CatalogCacheName: purged}})
+}
+
+// GetFlags lists the feature flags along with whether each is active for the server's mode
+func (admin *AdminServer) GetFlags(c *fiber.Ctx) error {
+	server := adminServer(c)
+
+	list := []adminFlagInfo{}
+	for _, f := range server.Flags.List() {
+		list = append(list, adminFlagInfo{Flag: f, Active: f.EnabledFor(server.Mode)})
+	}
+
+	return c.JSON(list)
+}
+
+// SetFlag turns a feature flag on or off for the server's mode
+func (admin *AdminServer) SetFlag(c *fiber.Ctx) error {
+	server := adminServer(c)
+	name := c.Params("flag")
+
+	var req adminFlagRequest
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return fiber.NewError(fiber.StatusBadRequest, `request body must be {"enabled": true|false}`)
+	}
+
+	if err := server.Flags.Set(name, server.Mode, *req.Enabled); err != nil {
+		if errors.Is(err, flags.ErrUnknownFlag) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("unknown flag %s", name))
+		}
+		return err
+	}
+	admin.Log.WithField("mode", server.Mode.String()).WithField("flag", name).Infof("Set feature flag to %t", *req.Enabled)
+
+	return c.JSON(fiber.Map{name: *req.Enabled})
+}
@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/chef/omnitruck-service/internal/strategy"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAdmin() (*AdminServer, *ApiServer) {
+	server := &ApiServer{
+		Config: Config{
+			Name:   "commercial",
+			Listen: ":3000",
+			ServiceConfig: config.ServiceConfig{
+				OmnitruckUrl: "https://omnitruck.chef.io",
+				AWSConfig:    config.AWSConfig{AccessKey: "AKIA", SecretKey: "secret"},
+				Admin:        config.AdminConfig{Token: "s3cret"},
+				Audit: config.AuditConfig{Sinks: []config.AuditSinkConfig{
+					{Type: "webhook", URL: "https://hooks.example.com/services/T000/B000/XXXX?token=abc", Headers: map[string]string{"Authorization": "Bearer abc"}},
+				}},
+				ReleaseNotes: config.ReleaseNotesConfig{UrlTemplate: "https://notes.example.com/{{.Product}}/{{.Version}}?sig=abc"},
+			},
+		},
+		Mode:      constants.Commercial,
+		Validator: omnitruck.NewValidator(),
+		Caches:    cache.NewRegistry(),
+		Flags:     flags.New(flags.Flag{Name: flags.Infra19, Products: []string{constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT}, Strategy: strategy.StrategyS3}),
+	}
+	server.Validator.Add(&omnitruck.EolVersionValidator{})
+	server.CatalogCache = server.Caches.Register(cache.New(CatalogCacheName, time.Minute, 0))
+	server.CatalogCache.Set("https://omnitruck.chef.io/products", []byte(`["chef"]`))
+
+	admin := NewAdmin(AdminConfig{Token: "s3cret", Log: log.WithField("pkg", "admin")}, server)
+	return admin, server
+}
+
+func adminRequest(t *testing.T, admin *AdminServer, method, path, body, token string) (int, map[string]interface{}, []byte) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := admin.App.Test(req)
+	require.NoError(t, err)
+	raw, _ := io.ReadAll(resp.Body)
+	data := map[string]interface{}{}
+	_ = json.Unmarshal(raw, &data)
+	return resp.StatusCode, data, raw
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	admin, _ := newTestAdmin()
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "missing token", status: 401},
+		{name: "wrong token", token: "nope", status: 401},
+		{name: "valid token", token: "s3cret", status: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := adminRequest(t, admin, "GET", "/servers", "", tt.token)
+			assert.Equal(t, tt.status, code)
+		})
+	}
+}
+
+func TestAdmin_UnknownMode(t *testing.T) {
+	admin, _ := newTestAdmin()
+	code, data, _ := adminRequest(t, admin, "GET", "/servers/trial/config", "", "s3cret")
+	assert.Equal(t, 404, code)
+	assert.Equal(t, "no server running in trial mode", data["message"])
+}
+
+func TestAdmin_Metrics(t *testing.T) {
+	admin, _ := newTestAdmin()
+	code, _, _ := adminRequest(t, admin, "GET", "/debug/vars", "", "")
+	assert.Equal(t, 401, code)
+
+	code, data, _ := adminRequest(t, admin, "GET", "/debug/vars", "", "s3cret")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, data, "object_store")
+}
+
+func TestAdmin_ConfigIsRedacted(t *testing.T) {
+	admin, _ := newTestAdmin()
+	code, _, raw := adminRequest(t, admin, "GET", "/servers/commercial/config", "", "s3cret")
+	assert.Equal(t, 200, code)
+
+	var cfg config.ServiceConfig
+	require.NoError(t, json.Unmarshal(raw, &cfg))
+	assert.Equal(t, "https://omnitruck.chef.io", cfg.OmnitruckUrl)
+	assert.Equal(t, "REDACTED", cfg.AWSConfig.AccessKey)
+	assert.Equal(t, "REDACTED", cfg.AWSConfig.SecretKey)
+	assert.Equal(t, "REDACTED", cfg.Admin.Token)
+	require.Len(t, cfg.Audit.Sinks, 1)
+	assert.Equal(t, "https://hooks.example.com/REDACTED", cfg.Audit.Sinks[0].URL)
+	assert.Equal(t, "REDACTED", cfg.Audit.Sinks[0].Headers["Authorization"])
+	assert.Equal(t, "https://notes.example.com/REDACTED", cfg.ReleaseNotes.UrlTemplate)
+	assert.NotContains(t, string(raw), "abc")
+}
+
+func TestAdmin_Validators(t *testing.T) {
+	admin, _ := newTestAdmin()
+	code, _, raw := adminRequest(t, admin, "GET", "/servers/commercial/validators", "", "s3cret")
+	assert.Equal(t, 200, code)
+
+	var validators []adminValidatorInfo
+	require.NoError(t, json.Unmarshal(raw, &validators))
+	require.Len(t, validators, 1)
+	assert.Equal(t, "*omnitruck.EolVersionValidator", validators[0].Type)
+	assert.Equal(t, "version", validators[0].Field)
+}
+
+func TestAdmin_CachesAndCatalogReload(t *testing.T) {
+	admin, server := newTestAdmin()
+
+	code, _, raw := adminRequest(t, admin, "GET", "/servers/commercial/caches", "", "s3cret")
+	assert.Equal(t, 200, code)
+	var stats []cache.Stats
+	require.NoError(t, json.Unmarshal(raw, &stats))
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Entries)
+
+	code, _, _ = adminRequest(t, admin, "POST", "/servers/commercial/catalog/reload", "", "s3cret")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, 0, server.CatalogCache.Stats().Entries)
+
+	code, _, _ = adminRequest(t, admin, "DELETE", "/servers/commercial/caches/notes", "", "s3cret")
+	assert.Equal(t, 404, code)
+}
+
+func TestAdmin_FlagsAndStrategies(t *testing.T) {
+	admin, server := newTestAdmin()
+
+	code, data, _ := adminRequest(t, admin, "GET", "/servers/commercial/strategies", "", "s3cret")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "omnitruck", data["chef-ice"])
+	assert.Equal(t, "dynamo", data["automate"])
+
+	code, _, _ = adminRequest(t, admin, "PUT", "/servers/commercial/flags/infra19", `{"enabled": true}`, "s3cret")
+	assert.Equal(t, 200, code)
+	assert.True(t, server.Flags.Enabled(flags.Infra19, constants.Commercial))
+	assert.False(t, server.Flags.Enabled(flags.Infra19, constants.Trial))
+
+	_, data, _ = adminRequest(t, admin, "GET", "/servers/commercial/strategies", "", "s3cret")
+	assert.Equal(t, "s3", data["chef-ice"])
+
+	code, _, _ = adminRequest(t, admin, "PUT", "/servers/commercial/flags/infra19", `{}`, "s3cret")
+	assert.Equal(t, 400, code)
+	code, _, _ = adminRequest(t, admin, "PUT", "/servers/commercial/flags/unknown", `{"enabled": true}`, "s3cret")
+	assert.Equal(t, 404, code)
+}
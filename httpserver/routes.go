@@ -5,11 +5,18 @@ import (
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
 	"github.com/chef/omnitruck-service/internal/api/handler"
+	"github.com/chef/omnitruck-service/internal/audit"
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/chef/omnitruck-service/internal/notes"
+	"github.com/chef/omnitruck-service/internal/problem"
+	"github.com/chef/omnitruck-service/middleware/cachecontrol"
 	"github.com/chef/omnitruck-service/utils/template"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
@@ -23,6 +30,31 @@ import (
 // @license.name Apache 2.0
 // @license.url  http://www.apache.org/licenses/LICENSE-2.0.html
 
+// defaultCacheControl holds the Cache-Control policy per catalog route.
+// Version and package lists can depend on the license so they are never
+// cached by shared caches. Policies can be overridden with the cacheControl
+// service config, where an empty value disables the header for that route.
+var defaultCacheControl = map[string]string{
+	"products":         "public, max-age=300",
+	"platforms":        "public, max-age=300",
+	"architectures":    "public, max-age=300",
+	"package-managers": "public, max-age=300",
+	"versions":         "private, max-age=60",
+	"packages":         "private, max-age=60",
+	"support-matrix":   "private, max-age=60",
+}
+
+// cacheHeaders returns the middleware that adds the route's Cache-Control
+// policy and a strong ETag, answering matching If-None-Match requests with 304
+func (server *ApiServer) cacheHeaders(route string) fiber.Handler {
+	policy, ok := server.Config.ServiceConfig.CacheControl[route]
+	if !ok {
+		policy = defaultCacheControl[route]
+	}
+
+	return cachecontrol.New(cachecontrol.Config{Policy: policy, ETag: true})
+}
+
 // routes sets up all HTTP routes for the ApiService
 func (server *ApiServer) buildRouter() {
 	server.App.Static("/swagger", "./docs")
@@ -44,16 +76,25 @@ func (server *ApiServer) buildRouter() {
 	//New DownloadHandler
 	handler := handler.NewDownloadsHandler(server.Log)
 
+	// The versioned API comes first so its routes win over the v1 wildcards
+	server.buildV2Router(handler)
+
 	server.App.Get("/status", requestid.New(), server.HealthCheck)
-	server.App.Get("/products", requestid.New(), handler.ProductsHandler)
-	server.App.Get("/platforms", requestid.New(), handler.PlatformsHandler)
-	server.App.Get("/architectures", requestid.New(), handler.ArchitecturesHandler)
-	server.App.Get("/package-managers", requestid.New(), handler.PackageManagersHandler)
+	server.App.Get("/products", requestid.New(), server.cacheHeaders("products"), handler.ProductsHandler)
+	server.App.Get("/platforms", requestid.New(), server.cacheHeaders("platforms"), handler.PlatformsHandler)
+	server.App.Get("/architectures", requestid.New(), server.cacheHeaders("architectures"), handler.ArchitecturesHandler)
+	server.App.Get("/package-managers", requestid.New(), server.cacheHeaders("package-managers"), handler.PackageManagersHandler)
 	server.App.Get("/:channel/:product/versions/latest", requestid.New(), handler.LatestVersionHandler)
-	server.App.Get("/:channel/:product/versions/all", requestid.New(), handler.ProductVersionsHandler)
-	server.App.Get("/:channel/:product/packages", requestid.New(), handler.ProductPackagesHandler)
+	server.App.Get("/:channel/:product/versions/all", requestid.New(), server.cacheHeaders("versions"), handler.ProductVersionsHandler)
+	server.App.Get("/:channel/:product/versions", requestid.New(), server.cacheHeaders("versions"), handler.VersionDetailsHandler)
+	server.App.Get("/:channel/:product/versions/:version/notes", requestid.New(), handler.ReleaseNotesHandler)
+	server.App.Get("/:channel/:product/notes", requestid.New(), handler.ReleaseNotesRangeHandler)
+	server.App.Get("/:channel/:product/packages", requestid.New(), server.cacheHeaders("packages"), handler.ProductPackagesHandler)
+	server.App.Get("/:channel/:product/support-matrix", requestid.New(), server.cacheHeaders("support-matrix"), handler.SupportMatrixHandler)
 	server.App.Get("/:channel/:product/metadata", requestid.New(), handler.ProductMetadataHandler)
+	server.App.Post("/metadata/batch", requestid.New(), handler.ProductMetadataBatchHandler)
 	server.App.Get("/:channel/:product/download", requestid.New(), handler.ProductDownloadHandler)
+	server.App.Get("/:channel/:product/sbom", requestid.New(), handler.ProductSbomHandler)
 	server.App.Get("/files/:channel/:product/:version/:platform/*", requestid.New(), handler.ProductFilesDownloadHandler)
 	server.App.Get("/relatedProducts", requestid.New(), handler.RelatedProductsHandler)
 	server.App.Get("/:channel/:product/fileName", requestid.New(), handler.FileNameHandler)
@@ -61,6 +102,31 @@ func (server *ApiServer) buildRouter() {
 	server.App.Get("/install.ps1", requestid.New(), handler.DownloadWindowsScript)
 }
 
+// buildV2Router sets up the /v2 routes. They answer errors with problem
+// details and return packages as a typed list.
+func (server *ApiServer) buildV2Router(handler *handler.DownloadsHandler) {
+	v2 := server.App.Group(problem.Prefix)
+	v2.Get("/products", requestid.New(), server.cacheHeaders("products"), handler.ProductsHandler)
+	v2.Get("/platforms", requestid.New(), server.cacheHeaders("platforms"), handler.PlatformsHandler)
+	v2.Get("/architectures", requestid.New(), server.cacheHeaders("architectures"), handler.ArchitecturesHandler)
+	v2.Get("/package-managers", requestid.New(), server.cacheHeaders("package-managers"), handler.PackageManagersHandler)
+	v2.Get("/:channel/:product/versions/latest", requestid.New(), handler.LatestVersionHandler)
+	v2.Get("/:channel/:product/versions/all", requestid.New(), server.cacheHeaders("versions"), handler.ProductVersionsHandler)
+	v2.Get("/:channel/:product/versions", requestid.New(), server.cacheHeaders("versions"), handler.VersionDetailsHandler)
+	v2.Get("/:channel/:product/versions/:version/notes", requestid.New(), handler.ReleaseNotesHandler)
+	v2.Get("/:channel/:product/notes", requestid.New(), handler.ReleaseNotesRangeHandler)
+	v2.Get("/:channel/:product/packages", requestid.New(), server.cacheHeaders("packages"), handler.ProductPackagesV2Handler)
+	v2.Get("/:channel/:product/support-matrix", requestid.New(), server.cacheHeaders("support-matrix"), handler.SupportMatrixHandler)
+	v2.Get("/:channel/:product/metadata", requestid.New(), handler.ProductMetadataHandler)
+	v2.Get("/:channel/:product/download", requestid.New(), handler.ProductDownloadHandler)
+	v2.Get("/:channel/:product/sbom", requestid.New(), handler.ProductSbomHandler)
+	v2.Post("/metadata/batch", requestid.New(), handler.ProductMetadataBatchHandler)
+	v2.Get("/files/:channel/:product/:version/:platform/*", requestid.New(), handler.ProductFilesDownloadHandler)
+	v2.Get("/relatedProducts", requestid.New(), handler.RelatedProductsHandler)
+	v2.Get("/:channel/:product/fileName", requestid.New(), handler.FileNameHandler)
+	v2.Use(requestid.New(), problem.NotFound)
+}
+
 // Injector middleware now accepts ApiServer and sets dependencies in the injector
 func Injector(server *ApiServer) func(*fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
@@ -72,7 +138,27 @@ func Injector(server *ApiServer) func(*fiber.Ctx) error {
 		do.ProvideNamedValue[replicated.IReplicated](reqInjector, "replicated", server.Replicated)
 		do.ProvideNamedValue[clients.ILicense](reqInjector, "licenseClient", server.LicenseClient)
 		do.ProvideNamedValue[constants.ApiType](reqInjector, "mode", server.Mode)
-		do.ProvideNamedValue[config.ServiceConfig](reqInjector, "config", server.Config.ServiceConfig)
+		do.ProvideNamedValue[config.ServiceConfig](reqInjector, "config", server.ServiceConfig())
+		do.ProvideNamedValue[audit.Sink](reqInjector, "auditor", server.Auditor)
+		do.ProvideNamedValue[flags.FeatureFlags](reqInjector, "flags", server.Flags)
+		if server.CatalogCache != nil {
+			do.ProvideNamedValue[*cache.Cache](reqInjector, "catalogCache", server.CatalogCache)
+		}
+		if server.NotesCache != nil {
+			do.ProvideNamedValue[*cache.Cache](reqInjector, "notesCache", server.NotesCache)
+		}
+		if server.NotesSource != nil {
+			do.ProvideNamedValue[notes.Source](reqInjector, "notesSource", server.NotesSource)
+		}
+		if server.ObjectStore != nil {
+			do.ProvideNamedValue[s3aws.ObjectStore](reqInjector, "objectStore", server.ObjectStore)
+		}
+		if server.OmnitruckBreaker != nil {
+			do.ProvideNamedValue[*omnitruck.Breaker](reqInjector, "omnitruckBreaker", server.OmnitruckBreaker)
+		}
+		if server.StaleCache != nil {
+			do.ProvideNamedValue[*cache.Cache](reqInjector, "staleCache", server.StaleCache)
+		}
 		c.Locals("reqinjector", reqInjector)
 		err := c.Next()
 		reqInjector.Shutdown()
@@ -1,6 +1,8 @@
 package httpserver
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -8,13 +10,21 @@ import (
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/audit"
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/chef/omnitruck-service/internal/flags"
+	"github.com/chef/omnitruck-service/internal/notes"
+	"github.com/chef/omnitruck-service/internal/problem"
 	logrus "github.com/chef/omnitruck-service/logger"
 	dbconnection "github.com/chef/omnitruck-service/middleware/db"
 	"github.com/chef/omnitruck-service/middleware/license"
+	"github.com/chef/omnitruck-service/mirror"
 	"github.com/chef/omnitruck-service/utils/awsutils"
 	"github.com/chef/omnitruck-service/utils/template"
 	fiber "github.com/gofiber/fiber/v2"
@@ -50,9 +60,37 @@ type ApiServer struct {
 	TemplateRenderer template.TemplateRenderer
 	Replicated       replicated.IReplicated
 	LicenseClient    clients.ILicense
-	locals           map[string]interface{}
+	Auditor          audit.Sink
+	Caches           *cache.Registry
+	CatalogCache     *cache.Cache
+	Flags            *flags.Registry
+	NotesCache       *cache.Cache
+	NotesSource      notes.Source
+	// OmnitruckBreaker and StaleCache guard the upstream Omnitruck API for
+	// every request, serving the last good answers while it is down
+	OmnitruckBreaker *omnitruck.Breaker
+	StaleCache       *cache.Cache
+	// ObjectStore is shared by every infra download so the S3 client and
+	// assumed role credentials are reused
+	ObjectStore s3aws.ObjectStore
+	// Mirror is set when the server serves a mirror directory
+	Mirror *mirror.Catalog
+	locals map[string]interface{}
 }
 
+const (
+	CatalogCacheName = "catalog"
+	NotesCacheName   = "notes"
+	StaleCacheName   = "omnitruck_stale"
+
+	// Default lifetime of cached upstream catalog lists, in seconds
+	defaultCatalogCacheTTL = 300
+	// Default lifetime of cached release notes, in seconds
+	defaultNotesCacheTTL = 3600
+	// Default deadline of the upstream calls made for a request, in seconds
+	defaultRequestTimeout = 30
+)
+
 func New(c Config) *ApiServer {
 	service := ApiServer{}
 	service.Initialize(c)
@@ -65,12 +103,82 @@ func (server *ApiServer) Initialize(c Config) *ApiServer {
 	server.Config = c
 	server.Validator = omnitruck.NewValidator()
 	server.Mode = c.Mode
-	server.DatabaseService = dboperations.NewDbOperationsService(dbconnection.NewDbConnectionService(awsutils.NewAwsUtils(), c.ServiceConfig), c.ServiceConfig)
 	server.TemplateRenderer = template.NewTemplateRenderer()
 
 	engine := html.New("./views", ".html")
 	server.Replicated = replicated.NewReplicatedImpl(c.ServiceConfig.ReplicatedConfig, logrus.NewLogrusStandardLogger())
-	server.LicenseClient = clients.NewLicenseClient()
+
+	if dir := c.ServiceConfig.Mirror.Dir; dir != "" {
+		// Mirror mode reads everything from the mirror directory and checks
+		// licenses locally, so no AWS or license service is needed
+		catalog, err := mirror.OpenCatalog(dir)
+		if err != nil {
+			server.Log.WithError(err).WithField("dir", dir).Fatal("Unable to read the mirror directory")
+		}
+		server.Log.WithField("dir", dir).WithField("source", catalog.Source()).Info("Serving packages from mirror")
+		server.Mirror = catalog
+		server.DatabaseService = catalog
+		server.LicenseClient = clients.NewLocalLicenseClient(c.ServiceConfig.Mirror.License)
+	} else {
+		server.DatabaseService = dboperations.NewDbOperationsService(dbconnection.NewDbConnectionService(awsutils.NewAwsUtils(), c.ServiceConfig), c.ServiceConfig)
+		server.LicenseClient = clients.NewLicenseClient()
+
+		store, err := s3aws.NewObjectStore(c.ServiceConfig.AWSConfig)
+		if err != nil {
+			server.Log.WithError(err).Warn("Object store is not configured, infra package downloads will fail")
+		} else {
+			server.ObjectStore = store
+		}
+	}
+
+	auditor, err := audit.NewFromConfig(c.ServiceConfig.Audit)
+	if err != nil {
+		server.Log.WithError(err).Error("Unable to configure download audit sinks, auditing is disabled")
+		auditor = audit.NopSink{}
+	}
+	server.Auditor = auditor
+
+	server.Flags = flags.FromConfig(c.ServiceConfig)
+	server.Validator.Add(&omnitruck.FeatureFlagValidator{
+		Flags: server.Flags,
+		Mode:  c.Mode,
+		Code:  404,
+	})
+
+	server.Caches = cache.NewRegistry()
+	// A negative TTL disables catalog caching entirely
+	ttl := c.ServiceConfig.CatalogCacheTTL
+	if ttl == 0 {
+		ttl = defaultCatalogCacheTTL
+	}
+	if ttl > 0 {
+		server.CatalogCache = server.Caches.Register(cache.New(CatalogCacheName, time.Duration(ttl)*time.Second, c.ServiceConfig.CacheMaxEntries))
+	}
+
+	clientConfig := c.ServiceConfig.OmnitruckClient
+	server.OmnitruckBreaker = omnitruck.NewBreakerFromConfig(clientConfig)
+	staleTTL := omnitruck.DefaultStaleTTL
+	if clientConfig.StaleTTL > 0 {
+		staleTTL = time.Duration(clientConfig.StaleTTL) * time.Second
+	}
+	server.StaleCache = server.Caches.Register(cache.New(StaleCacheName, staleTTL, c.ServiceConfig.CacheMaxEntries))
+
+	notesConfig := c.ServiceConfig.ReleaseNotes
+	notesTTL := notesConfig.CacheTTL
+	if notesTTL == 0 {
+		notesTTL = defaultNotesCacheTTL
+	}
+	if notesTTL > 0 {
+		server.NotesCache = server.Caches.Register(cache.New(NotesCacheName, time.Duration(notesTTL)*time.Second, c.ServiceConfig.CacheMaxEntries))
+	}
+	if notesConfig.UrlTemplate != "" {
+		source, err := notes.NewURLSource(notesConfig.UrlTemplate)
+		if err != nil {
+			server.Log.WithError(err).Error("Unable to configure the release notes url, only catalog notes are served")
+		} else {
+			server.NotesSource = source
+		}
+	}
 
 	server.App = fiber.New(fiber.Config{
 		DisableStartupMessage: false,
@@ -78,6 +186,7 @@ func (server *ApiServer) Initialize(c Config) *ApiServer {
 		ReadTimeout:           time.Duration(c.ServiceConfig.ReadWriteTimeout) * time.Second,
 		WriteTimeout:          time.Duration(c.ServiceConfig.ReadWriteTimeout) * time.Second,
 		Views:                 engine,
+		ErrorHandler:          problem.ErrorHandler,
 	})
 
 	if c.Mode == constants.Trial || c.Mode == constants.Opensource {
@@ -85,6 +194,7 @@ func (server *ApiServer) Initialize(c Config) *ApiServer {
 			Field:      "Channel",
 			Values:     []string{"stable"},
 			Code:       400,
+			ErrorCode:  apierror.CodeChannelInvalid,
 			AllowEmpty: true,
 		}
 		server.Validator.Add(&channel)
@@ -115,11 +225,27 @@ func (server *ApiServer) Initialize(c Config) *ApiServer {
 
 func (server *ApiServer) Start(wg *sync.WaitGroup) error {
 	wg.Add(1)
-	go server.StartService()
+	go func() {
+		defer wg.Done()
+		server.StartService()
+	}()
 
 	return nil
 }
 
+// Stop shuts the server down, letting in-flight requests finish, then flushes
+// and closes the audit sinks so queued events are not lost
+func (server *ApiServer) Stop() error {
+	err := server.App.Shutdown()
+	if server.Auditor != nil {
+		if cerr := server.Auditor.Close(); cerr != nil {
+			server.Log.WithError(cerr).Error("Unable to close audit sinks")
+			err = errors.Join(err, cerr)
+		}
+	}
+	return err
+}
+
 func (server *ApiServer) StartService() {
 	// Setup io writer for the logger
 	// Needs to be in the method where we start the service
@@ -155,11 +281,13 @@ func (server *ApiServer) StartService() {
 	// This will catch panics in the app and prevent it from crashing the server
 	// TODO: Figure out if we can better handle logging these, currently it just returns a panic message to the user
 	server.App.Use(recover.New())
+	server.App.Use(server.requestDeadline)
 
 	server.App.Use(license.New(license.Config{
-		URL:      server.Config.ServiceConfig.LicenseServiceUrl,
-		Required: true,
-		Mode:     server.Mode,
+		URL:           server.ServiceConfig().LicenseServiceUrl,
+		Required:      server.licenseRequired(),
+		LicenseClient: server.LicenseClient,
+		Mode:          server.Mode,
 		Next: func(c *fiber.Ctx) bool {
 			switch c.Path() {
 			case "/status":
@@ -197,3 +325,32 @@ func (server *ApiServer) HealthCheck(c *fiber.Ctx) error {
 
 	return c.JSON(res)
 }
+
+// licenseRequired reports whether requests need a license id. Only the local
+// policy of a mirror can waive it.
+func (server *ApiServer) licenseRequired() bool {
+	if server.Mirror != nil {
+		return server.ServiceConfig().Mirror.License.Required
+	}
+	return true
+}
+
+// requestDeadline gives the user context of the request a deadline of
+// readWriteTimeout, so the upstream calls made for it end with the request
+func (server *ApiServer) requestDeadline(c *fiber.Ctx) error {
+	timeout := server.ServiceConfig().ReadWriteTimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	c.SetUserContext(ctx)
+	return c.Next()
+}
+
+// ServiceConfig returns the active service config
+func (server *ApiServer) ServiceConfig() config.ServiceConfig {
+	server.Lock()
+	defer server.Unlock()
+	return server.Config.ServiceConfig
+}
@@ -0,0 +1,56 @@
+package httpserver
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/internal/audit"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout int64
+		want    time.Duration
+	}{
+		{name: "configured", timeout: 5, want: 5 * time.Second},
+		{name: "default", timeout: 0, want: defaultRequestTimeout * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &ApiServer{Config: Config{ServiceConfig: config.ServiceConfig{ReadWriteTimeout: tt.timeout}}}
+
+			var remaining time.Duration
+			var ok bool
+			app := fiber.New()
+			app.Use(server.requestDeadline)
+			app.Get("/", func(c *fiber.Ctx) error {
+				var deadline time.Time
+				deadline, ok = c.UserContext().Deadline()
+				remaining = time.Until(deadline)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.True(t, ok)
+			assert.LessOrEqual(t, remaining, tt.want)
+			assert.Greater(t, remaining, tt.want-time.Second)
+		})
+	}
+}
+
+func TestStop_ClosesAuditor(t *testing.T) {
+	auditor := &audit.MockSink{}
+	server := &ApiServer{App: fiber.New(), Auditor: auditor, Log: log.WithField("pkg", "test")}
+
+	assert.NoError(t, server.Stop())
+	assert.True(t, auditor.Closed)
+}
@@ -0,0 +1,85 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Diff lists the fields that differ between two records, one line per field
+// prefixed with + for added, - for removed and ~ for changed values. Both
+// records are compared through their JSON form.
+func Diff(old interface{}, new interface{}) ([]string, error) {
+	before, err := flatten(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for field, value := range after {
+		previous, ok := before[field]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", field, value))
+		case previous != value:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", field, previous, value))
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok {
+			lines = append(lines, fmt.Sprintf("- %s: %s", field, value))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines, nil
+}
+
+// flatten maps the dotted path of every scalar in the JSON form of v to its
+// JSON encoded value. A nil record has no fields.
+func flatten(v interface{}) (map[string]string, error) {
+	fields := map[string]string{}
+	if v == nil {
+		return fields, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	flattenValue("", decoded, fields)
+	return fields, nil
+}
+
+func flattenValue(prefix string, v interface{}, fields map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			flattenValue(join(key), child, fields)
+		}
+	case []interface{}:
+		for i, child := range value {
+			flattenValue(join(strconv.Itoa(i)), child, fields)
+		}
+	default:
+		if value == nil || value == "" {
+			return
+		}
+		encoded, _ := json.Marshal(value)
+		fields[prefix] = string(encoded)
+	}
+}
@@ -0,0 +1,323 @@
+// Package ingest builds the catalog records the service reads from the files
+// a release publishes, and writes them to DynamoDB.
+//
+// Automate and Habitat publish one .sha256sum file per package, which become
+// a models.ProductDetails record. Infra products publish a metadata.json file
+// per release, which becomes a models.PackageDetails record per version along
+// with the package managers it uses.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// automateCutoff skips Automate checksums from before the catalog existed
+var automateCutoff = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Tables names the tables records are written to. Every record goes to
+// each table of its kind, so one run can fill several environments.
+type Tables struct {
+	Metadata              []string
+	PackageDetailsCurrent []string
+	PackageDetailsStable  []string
+	PackageManagers       []string
+}
+
+// Change is the outcome of one record
+type Change struct {
+	Table   string
+	Product string
+	Version string
+	// Diff lists the fields that differ from the stored record
+	Diff []string
+	// Written is false for dry runs and unchanged records
+	Written bool
+}
+
+// Report lists the outcome of every record of a run
+type Report struct {
+	Changes []Change
+}
+
+// Ingester builds records from a Source and writes them to a Store
+type Ingester struct {
+	Source Source
+	Store  Store
+	Tables Tables
+	// DryRun computes the changes without writing them
+	DryRun bool
+	Log    *log.Entry
+}
+
+// record is an item along with the table it goes to
+type record struct {
+	table   string
+	product string
+	version string
+	key     map[string]types.AttributeValue
+	item    interface{}
+}
+
+// Run ingests the release found under prefix. A prefix holding metadata.json
+// files is read as infra products, otherwise the product is told from the
+// prefix like the upload lambdas did.
+func (in *Ingester) Run(ctx context.Context, prefix string) (*Report, error) {
+	objects, err := in.Source.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", prefix, err)
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no files found under %s", prefix)
+	}
+
+	var records []record
+	switch {
+	case hasMetadataFile(objects):
+		records, err = in.packageDetailsRecords(ctx, objects)
+	case strings.Contains(prefix, constants.AUTOMATE_PRODUCT):
+		records, err = in.automateRecords(ctx, objects)
+	case strings.Contains(prefix, "hab"):
+		records, err = in.habitatRecords(ctx, objects)
+	default:
+		return nil, fmt.Errorf("unable to tell the product of %s", prefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	var errs []error
+	for _, r := range records {
+		change, err := in.apply(ctx, r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s %s: %w", r.table, r.product, r.version, err))
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	return report, errors.Join(errs...)
+}
+
+func hasMetadataFile(objects []Object) bool {
+	for _, o := range objects {
+		if strings.HasSuffix(o.Key, "metadata.json") {
+			return true
+		}
+	}
+	return false
+}
+
+func (in *Ingester) automateRecords(ctx context.Context, objects []Object) ([]record, error) {
+	details := models.ProductDetails{Product: constants.AUTOMATE_PRODUCT, Version: constants.LATEST}
+	for _, o := range objects {
+		if skipChecksum(o.Key) || o.LastModified.Before(automateCutoff) {
+			continue
+		}
+		meta, err := in.readChecksum(ctx, o.Key, AutomateMetaData)
+		if err != nil {
+			return nil, err
+		}
+		details.MetaData = append(details.MetaData, meta)
+	}
+	return in.productDetailsRecords([]models.ProductDetails{details})
+}
+
+// habitatRecords builds one record per release directory, named by the
+// version in its manifest.json
+func (in *Ingester) habitatRecords(ctx context.Context, objects []Object) ([]record, error) {
+	releases := map[string]*models.ProductDetails{}
+	var dirs []string
+	for _, o := range objects {
+		if skipChecksum(o.Key) {
+			continue
+		}
+		dir := releaseDir(o.Key)
+		details, ok := releases[dir]
+		if !ok {
+			manifest, err := in.Source.Read(ctx, dir+"manifest.json")
+			if err != nil {
+				return nil, fmt.Errorf("reading manifest of %s: %w", dir, err)
+			}
+			version, err := ParseManifestVersion(manifest)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", dir, err)
+			}
+			details = &models.ProductDetails{Product: constants.HABITAT_PRODUCT, Version: version}
+			releases[dir] = details
+			dirs = append(dirs, dir)
+		}
+		meta, err := in.readChecksum(ctx, o.Key, HabitatMetaData)
+		if err != nil {
+			return nil, err
+		}
+		details.MetaData = append(details.MetaData, meta)
+	}
+
+	all := make([]models.ProductDetails, 0, len(dirs))
+	for _, dir := range dirs {
+		all = append(all, *releases[dir])
+	}
+	return in.productDetailsRecords(all)
+}
+
+func (in *Ingester) readChecksum(ctx context.Context, key string, describe func(fileName, sum string) (models.MetaData, error)) (models.MetaData, error) {
+	content, err := in.Source.Read(ctx, key)
+	if err != nil {
+		return models.MetaData{}, fmt.Errorf("reading %s: %w", key, err)
+	}
+	sum, fileName, err := ParseSha256Sum(content)
+	if err != nil {
+		return models.MetaData{}, fmt.Errorf("%s: %w", key, err)
+	}
+	meta, err := describe(fileName, sum)
+	if err != nil {
+		return models.MetaData{}, fmt.Errorf("%s: %w", key, err)
+	}
+	in.Log.WithField("key", key).Debug("Read checksum")
+	return meta, nil
+}
+
+func (in *Ingester) productDetailsRecords(all []models.ProductDetails) ([]record, error) {
+	var records []record
+	for i := range all {
+		details := all[i]
+		sortMetaData(&details)
+		if err := details.Validate(); err != nil {
+			return nil, err
+		}
+		for _, table := range in.Tables.Metadata {
+			records = append(records, catalogRecord(table, details.Product, details.Version, &details))
+		}
+	}
+	return records, nil
+}
+
+func (in *Ingester) packageDetailsRecords(ctx context.Context, objects []Object) ([]record, error) {
+	var records []record
+	var all []models.PackageDetails
+	for _, o := range objects {
+		if !strings.HasSuffix(o.Key, "metadata.json") {
+			continue
+		}
+		var tables []string
+		switch ChannelFromKey(o.Key) {
+		case constants.CURRENT_CHANNEL:
+			tables = in.Tables.PackageDetailsCurrent
+		case constants.STABLE_CHANNEL:
+			tables = in.Tables.PackageDetailsStable
+		default:
+			in.Log.WithField("key", o.Key).Warn("Skipping metadata outside of a channel")
+			continue
+		}
+
+		content, err := in.Source.Read(ctx, o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", o.Key, err)
+		}
+		details, err := ParsePackageMetadata(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", o.Key, err)
+		}
+		for i := range details {
+			d := details[i]
+			if err := d.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", o.Key, err)
+			}
+			for _, table := range tables {
+				records = append(records, catalogRecord(table, d.Product, d.Version, &d))
+			}
+		}
+		all = append(all, details...)
+	}
+
+	for _, pm := range PackageManagers(all) {
+		for _, table := range in.Tables.PackageManagers {
+			records = append(records, record{
+				table:   table,
+				product: pm,
+				key:     map[string]types.AttributeValue{"packages": &types.AttributeValueMemberS{Value: pm}},
+				item:    &dboperations.PackageManagerItem{Packages: pm},
+			})
+		}
+	}
+	return records, nil
+}
+
+func catalogRecord(table, product, version string, item interface{}) record {
+	return record{
+		table:   table,
+		product: product,
+		version: version,
+		key: map[string]types.AttributeValue{
+			constants.PRODUCT_PARTITION_KEY: &types.AttributeValueMemberS{Value: product},
+			constants.PRODUCT_SORT_KEY:      &types.AttributeValueMemberS{Value: version},
+		},
+		item: item,
+	}
+}
+
+// apply compares the record with the stored one and writes it when it changed
+func (in *Ingester) apply(ctx context.Context, r record) (Change, error) {
+	change := Change{Table: r.table, Product: r.product, Version: r.version}
+
+	existing, err := in.Store.Get(ctx, r.table, r.key)
+	if err != nil {
+		return change, err
+	}
+	var stored interface{}
+	if existing != nil {
+		decoded := reflect.New(reflect.TypeOf(r.item).Elem()).Interface()
+		if err := attributevalue.UnmarshalMap(existing, decoded); err != nil {
+			return change, err
+		}
+		if details, ok := decoded.(*models.ProductDetails); ok {
+			sortMetaData(details)
+		}
+		stored = decoded
+	}
+	if change.Diff, err = Diff(stored, r.item); err != nil {
+		return change, err
+	}
+	if len(change.Diff) == 0 || in.DryRun {
+		return change, nil
+	}
+
+	item, err := attributevalue.MarshalMap(r.item)
+	if err != nil {
+		return change, err
+	}
+	if err := in.Store.Put(ctx, r.table, item); err != nil {
+		return change, err
+	}
+	change.Written = true
+	in.Log.WithField("table", r.table).WithField("product", r.product).WithField("version", r.version).Info("Wrote catalog record")
+	return change, nil
+}
+
+// sortMetaData orders packages so records compare the same regardless of
+// the order files were listed in
+func sortMetaData(details *models.ProductDetails) {
+	sort.Slice(details.MetaData, func(i, j int) bool {
+		a, b := details.MetaData[i], details.MetaData[j]
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		if a.Architecture != b.Architecture {
+			return a.Architecture < b.Architecture
+		}
+		return a.FileName < b.FileName
+	})
+}
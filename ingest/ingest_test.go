@@ -0,0 +1,289 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for key, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(key))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return root
+}
+
+func testLogger() *log.Entry {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	return log.NewEntry(logger)
+}
+
+// memoryStore keeps items in memory, keyed by table and the key values
+type memoryStore struct {
+	items map[string]map[string]types.AttributeValue
+	puts  int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func (m *memoryStore) id(table string, item map[string]types.AttributeValue) string {
+	id := table
+	for _, k := range []string{"packages", "product", "version"} {
+		if v, ok := item[k].(*types.AttributeValueMemberS); ok {
+			id += "/" + v.Value
+		}
+	}
+	return id
+}
+
+func (m *memoryStore) mock() *MockStore {
+	return &MockStore{
+		GetFunc: func(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+			return m.items[m.id(table, key)], nil
+		},
+		PutFunc: func(ctx context.Context, table string, item map[string]types.AttributeValue) error {
+			m.items[m.id(table, item)] = item
+			m.puts++
+			return nil
+		},
+	}
+}
+
+func TestIngester_Run(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"automate/latest/chef-automate_linux_amd64.zip.sha256sum":     testSum + "  chef-automate_linux_amd64.zip\n",
+		"automate/latest/chef-automate_linux_arm64.zip.sha256sum":     testSum + "  chef-automate_linux_arm64.zip\n",
+		"automate/latest/chef-automate-cli_linux_amd64.zip.sha256sum": testSum + "  chef-automate-cli_linux_amd64.zip\n",
+		"hab/1.6.826/hab-x86_64-linux.tar.gz.sha256sum":               testSum + "  hab-x86_64-linux.tar.gz\n",
+		"hab/1.6.826/hab-x86_64-linux-kernel2.tar.gz.sha256sum":       testSum + "  hab-x86_64-linux-kernel2.tar.gz\n",
+		"hab/1.6.826/manifest.json":                                   `{"version": "1.6.826"}`,
+		"files/stable/chef/18.2.7/metadata.json":                      `{"chef": {"18.2.7": {"linux": {"x86_64": {"deb": {"filename": "chef_18.2.7-1_amd64.deb", "sha256": "` + testSum + `"}}}}}}`,
+		"files/unknown/chef/18.2.7/metadata.json":                     `{}`,
+		"invalid/stable/chef/1.0/metadata.json":                       `{"chef": {"1.0": {"linux": {"x86_64": {"deb": {"filename": "chef.deb", "sha256": "nope"}}}}}}`,
+		"other/readme.md":                                             "nothing to ingest",
+	})
+	tables := Tables{
+		Metadata:             []string{"metadata"},
+		PackageDetailsStable: []string{"stable-a", "stable-b"},
+		PackageManagers:      []string{"pm"},
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		want    []Change
+		record  string
+		check   func(t *testing.T, item map[string]types.AttributeValue)
+		wantErr string
+	}{
+		{
+			name:   "automate",
+			prefix: "automate/latest/",
+			want:   []Change{{Table: "metadata", Product: "automate", Version: "latest", Written: true}},
+			record: "metadata/automate/latest",
+			check: func(t *testing.T, item map[string]types.AttributeValue) {
+				var details models.ProductDetails
+				require.NoError(t, attributevalue.UnmarshalMap(item, &details))
+				require.Len(t, details.MetaData, 2)
+				assert.Equal(t, "amd64", details.MetaData[0].Architecture)
+				assert.Equal(t, "arm64", details.MetaData[1].Architecture)
+			},
+		},
+		{
+			name:   "habitat",
+			prefix: "hab/1.6.826/",
+			want:   []Change{{Table: "metadata", Product: "habitat", Version: "1.6.826", Written: true}},
+			record: "metadata/habitat/1.6.826",
+			check: func(t *testing.T, item map[string]types.AttributeValue) {
+				var details models.ProductDetails
+				require.NoError(t, attributevalue.UnmarshalMap(item, &details))
+				require.Len(t, details.MetaData, 2)
+				assert.Equal(t, "linux", details.MetaData[0].Platform)
+				assert.Equal(t, "linux-kernel2", details.MetaData[1].Platform)
+			},
+		},
+		{
+			name:   "infra metadata",
+			prefix: "files/",
+			want: []Change{
+				{Table: "stable-a", Product: "chef", Version: "18.2.7", Written: true},
+				{Table: "stable-b", Product: "chef", Version: "18.2.7", Written: true},
+				{Table: "pm", Product: "deb", Written: true},
+			},
+			record: "stable-a/chef/18.2.7",
+			check: func(t *testing.T, item map[string]types.AttributeValue) {
+				var details models.PackageDetails
+				require.NoError(t, attributevalue.UnmarshalMap(item, &details))
+				assert.Equal(t, "chef_18.2.7-1_amd64.deb", details.Metadata["linux"]["x86_64"]["deb"].Filename)
+			},
+		},
+		{name: "invalid record", prefix: "invalid/", wantErr: "sha256 of chef.deb is not a hex encoded checksum"},
+		{name: "unknown product", prefix: "other/", wantErr: "unable to tell the product of other/"},
+		{name: "empty prefix", prefix: "missing/", wantErr: "no files found under missing/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			in := &Ingester{Source: DirSource{Root: root}, Store: store.mock(), Tables: tables, Log: testLogger()}
+
+			report, err := in.Run(context.Background(), tt.prefix)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, report.Changes, len(tt.want))
+			for i, want := range tt.want {
+				got := report.Changes[i]
+				assert.Equal(t, want.Table, got.Table)
+				assert.Equal(t, want.Product, got.Product)
+				assert.Equal(t, want.Version, got.Version)
+				assert.Equal(t, want.Written, got.Written)
+				assert.NotEmpty(t, got.Diff)
+			}
+			tt.check(t, store.items[tt.record])
+
+			// a second run finds nothing to change
+			report, err = in.Run(context.Background(), tt.prefix)
+			require.NoError(t, err)
+			for _, change := range report.Changes {
+				assert.Empty(t, change.Diff)
+				assert.False(t, change.Written)
+			}
+			assert.Equal(t, len(tt.want), store.puts)
+		})
+	}
+}
+
+func TestIngester_DryRun(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"hab/1.6.826/hab-x86_64-linux.tar.gz.sha256sum": testSum + "  hab-x86_64-linux.tar.gz\n",
+		"hab/1.6.826/manifest.json":                     `{"version": "1.6.826"}`,
+	})
+	stored, err := attributevalue.MarshalMap(&models.ProductDetails{
+		Product: "habitat",
+		Version: "1.6.826",
+		MetaData: []models.MetaData{
+			{Platform: "linux", Architecture: "x86_64", FileName: "hab-x86_64-linux.tar.gz", SHA256: "old"},
+			{Platform: "windows", Architecture: "x86_64", FileName: "hab-x86_64-windows.zip", SHA256: testSum},
+		},
+	})
+	require.NoError(t, err)
+
+	in := &Ingester{
+		Source: DirSource{Root: root},
+		Store: &MockStore{
+			GetFunc: func(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+				return stored, nil
+			},
+			PutFunc: func(ctx context.Context, table string, item map[string]types.AttributeValue) error {
+				t.Fatal("dry run wrote a record")
+				return nil
+			},
+		},
+		Tables: Tables{Metadata: []string{"metadata"}},
+		DryRun: true,
+		Log:    testLogger(),
+	}
+
+	report, err := in.Run(context.Background(), "hab/")
+	require.NoError(t, err)
+	require.Len(t, report.Changes, 1)
+	assert.False(t, report.Changes[0].Written)
+	assert.Equal(t, []string{
+		`~ metadata.0.sha256: "old" -> "` + testSum + `"`,
+		`- metadata.1.architecture: "x86_64"`,
+		`- metadata.1.filename: "hab-x86_64-windows.zip"`,
+		`- metadata.1.platform: "windows"`,
+		`- metadata.1.sha256: "` + testSum + `"`,
+	}, report.Changes[0].Diff)
+}
+
+func TestDiff(t *testing.T) {
+	lines, err := Diff(
+		&dboperations.PackageManagerItem{Packages: "deb"},
+		&dboperations.PackageManagerItem{Packages: "rpm"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`~ packages: "deb" -> "rpm"`}, lines)
+
+	lines, err = Diff(nil, &models.ProductDetails{Product: "habitat", Version: "1.6.826"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`+ product: "habitat"`, `+ version: "1.6.826"`}, lines)
+}
+
+func TestS3Event_Target(t *testing.T) {
+	var notification S3Event
+	notification.Records = make([]struct {
+		S3 struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	}, 1)
+	notification.Records[0].S3.Bucket.Name = "packages"
+	notification.Records[0].S3.Object.Key = "files/stable/chef/18.2.7/metadata.json"
+
+	tests := []struct {
+		name    string
+		event   S3Event
+		bucket  string
+		prefix  string
+		wantErr bool
+	}{
+		{name: "notification", event: notification, bucket: "packages", prefix: "files/stable/chef/18.2.7/"},
+		{name: "manual trigger", event: S3Event{ManualTrigger: true, S3Bucket: "packages", Key: "automate/latest/"}, bucket: "packages", prefix: "automate/latest/"},
+		{name: "manual trigger without key", event: S3Event{ManualTrigger: true, S3Bucket: "packages"}, wantErr: true},
+		{name: "no records", event: S3Event{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, prefix, err := tt.event.Target()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.bucket, bucket)
+			assert.Equal(t, tt.prefix, prefix)
+		})
+	}
+}
+
+func TestLambdaHandler(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"automate/latest/chef-automate_linux_amd64.zip.sha256sum": testSum + "  chef-automate_linux_amd64.zip\n",
+	})
+	handler := LambdaHandler(func(ctx context.Context, bucket string) (*Ingester, error) {
+		assert.Equal(t, "packages", bucket)
+		return &Ingester{Source: DirSource{Root: root}, Store: &MockStore{}, Tables: Tables{Metadata: []string{"metadata"}}, Log: testLogger()}, nil
+	})
+
+	resp, err := handler(context.Background(), S3Event{ManualTrigger: true, S3Bucket: "packages", Key: "automate/latest/"})
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, resp.Body, `"Product":"automate"`)
+
+	resp, err = handler(context.Background(), S3Event{})
+	assert.Error(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
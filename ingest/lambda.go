@@ -0,0 +1,73 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// S3Event is the event the ingestion lambda is invoked with: either an S3
+// notification or a manual trigger naming the bucket and prefix to ingest
+type S3Event struct {
+	Records []struct {
+		S3 struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+	ManualTrigger bool   `json:"manualTrigger"`
+	S3Bucket      string `json:"s3Bucket"`
+	Key           string `json:"key"`
+}
+
+// Target returns the bucket and prefix the event asks to ingest. An S3
+// notification ingests the directory of the object that changed.
+func (e S3Event) Target() (bucket string, prefix string, err error) {
+	if e.ManualTrigger {
+		if e.S3Bucket == "" || e.Key == "" {
+			return "", "", fmt.Errorf("manual trigger needs s3Bucket and key")
+		}
+		return e.S3Bucket, e.Key, nil
+	}
+	if len(e.Records) == 0 {
+		return "", "", fmt.Errorf("event has no records")
+	}
+	record := e.Records[0].S3
+	return record.Bucket.Name, path.Dir(record.Object.Key) + "/", nil
+}
+
+// LambdaResponse is returned to the lambda runtime
+type LambdaResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body"`
+}
+
+// LambdaHandler returns a handler with the signature expected by the lambda
+// runtime. newIngester builds the ingester reading from the event's bucket.
+func LambdaHandler(newIngester func(ctx context.Context, bucket string) (*Ingester, error)) func(context.Context, S3Event) (LambdaResponse, error) {
+	return func(ctx context.Context, event S3Event) (LambdaResponse, error) {
+		bucket, prefix, err := event.Target()
+		if err != nil {
+			return LambdaResponse{StatusCode: 400, Body: err.Error()}, err
+		}
+		in, err := newIngester(ctx, bucket)
+		if err != nil {
+			return LambdaResponse{StatusCode: 500, Body: err.Error()}, err
+		}
+
+		report, err := in.Run(ctx, prefix)
+		if err != nil {
+			return LambdaResponse{StatusCode: 500, Body: err.Error()}, err
+		}
+		body, err := json.Marshal(report)
+		if err != nil {
+			return LambdaResponse{StatusCode: 500, Body: err.Error()}, err
+		}
+		return LambdaResponse{StatusCode: 200, Body: string(body)}, nil
+	}
+}
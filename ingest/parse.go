@@ -0,0 +1,169 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chef/omnitruck-service/models"
+)
+
+// catalogPlatforms are the platforms kept from a metadata.json file
+var catalogPlatforms = map[string]bool{"linux": true, "windows": true, "darwin": true}
+
+// architectures are the keys that can appear where a package manager is
+// expected in a metadata.json file and must not be recorded as one
+var architectures = map[string]bool{
+	"aarch64": true, "armv7l": true, "i386": true, "powerpc": true, "ppc64": true,
+	"ppc64le": true, "s390x": true, "sparc": true, "universal": true, "x86_64": true,
+}
+
+var channelPattern = regexp.MustCompile(`\b(stable|current)\b`)
+
+// ParseSha256Sum reads a .sha256sum file, in the format written by sha256sum
+func ParseSha256Sum(content []byte) (sum string, fileName string, err error) {
+	fields := strings.Fields(string(content))
+	if len(fields) < 2 {
+		return "", "", fmt.Errorf("expected a checksum and a file name, got %q", strings.TrimSpace(string(content)))
+	}
+	return fields[0], strings.TrimPrefix(fields[len(fields)-1], "*"), nil
+}
+
+// AutomateMetaData describes an Automate package named like
+// chef-automate_linux_amd64.zip
+func AutomateMetaData(fileName string, sum string) (models.MetaData, error) {
+	parts := strings.Split(fileName, "_")
+	if len(parts) < 3 {
+		return models.MetaData{}, fmt.Errorf("unexpected automate file name %s", fileName)
+	}
+	return models.MetaData{
+		Platform:     parts[1],
+		Architecture: strings.Split(parts[len(parts)-1], ".")[0],
+		FileName:     fileName,
+		SHA256:       sum,
+	}, nil
+}
+
+// HabitatMetaData describes a Habitat package named like
+// hab-x86_64-linux.tar.gz or hab-x86_64-linux-kernel2.tar.gz
+func HabitatMetaData(fileName string, sum string) (models.MetaData, error) {
+	parts := strings.Split(fileName, "-")
+	if len(parts) < 3 {
+		return models.MetaData{}, fmt.Errorf("unexpected habitat file name %s", fileName)
+	}
+	platform := parts[len(parts)-1]
+	if strings.Contains(platform, "kernel2") {
+		platform = "linux-" + platform
+	}
+	return models.MetaData{
+		Platform:     strings.Split(platform, ".")[0],
+		Architecture: parts[1],
+		FileName:     fileName,
+		SHA256:       sum,
+	}, nil
+}
+
+// ParseManifestVersion returns the version recorded in a Habitat manifest.json
+func ParseManifestVersion(content []byte) (string, error) {
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return "", fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version == "" {
+		return "", fmt.Errorf("manifest has no version")
+	}
+	return manifest.Version, nil
+}
+
+// ParsePackageMetadata reads a metadata.json file, which maps product to
+// version to platform, architecture and package manager. Keys other than the
+// catalog platforms are ignored, except for the release date.
+func ParsePackageMetadata(content []byte) ([]models.PackageDetails, error) {
+	var products map[string]map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(content, &products); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	var details []models.PackageDetails
+	for product, versions := range products {
+		for version, fields := range versions {
+			d := models.PackageDetails{
+				Product:  product,
+				Version:  version,
+				Metadata: map[string]models.Platform{},
+			}
+			for key, raw := range fields {
+				switch {
+				case key == "release_date":
+					if err := json.Unmarshal(raw, &d.ReleaseDate); err != nil {
+						return nil, fmt.Errorf("%s %s: invalid release_date: %w", product, version, err)
+					}
+				case catalogPlatforms[strings.ToLower(key)]:
+					var platform models.Platform
+					if err := json.Unmarshal(raw, &platform); err != nil {
+						return nil, fmt.Errorf("%s %s: invalid %s metadata: %w", product, version, key, err)
+					}
+					d.Metadata[key] = platform
+				}
+			}
+			details = append(details, d)
+		}
+	}
+
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].Product != details[j].Product {
+			return details[i].Product < details[j].Product
+		}
+		return details[i].Version < details[j].Version
+	})
+	return details, nil
+}
+
+// PackageManagers returns the package managers used by the records, sorted
+func PackageManagers(details []models.PackageDetails) []string {
+	seen := map[string]bool{}
+	for _, d := range details {
+		for _, archs := range d.Metadata {
+			for _, managers := range archs {
+				for pm := range managers {
+					if !architectures[pm] {
+						seen[pm] = true
+					}
+				}
+			}
+		}
+	}
+
+	managers := make([]string, 0, len(seen))
+	for pm := range seen {
+		managers = append(managers, pm)
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+// ChannelFromKey returns the channel a metadata.json key belongs to, or ""
+func ChannelFromKey(key string) string {
+	return channelPattern.FindString(key)
+}
+
+// skipChecksum reports whether a .sha256sum file belongs to an artifact that
+// is not a package, like documentation, signatures or airgap bundles
+func skipChecksum(key string) bool {
+	for _, s := range []string{".txt", "documentation", ".asc", "manifest", "airgap", "cli"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return !strings.HasSuffix(key, ".sha256sum")
+}
+
+// releaseDir returns the directory of a key with a trailing slash
+func releaseDir(key string) string {
+	return path.Dir(key) + "/"
+}
@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/chef/omnitruck-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSum = "0f2a3b8c9d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8"
+
+func TestParseSha256Sum(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		sum      string
+		fileName string
+		wantErr  bool
+	}{
+		{name: "text mode", content: testSum + "  chef-automate_linux_amd64.zip\n", sum: testSum, fileName: "chef-automate_linux_amd64.zip"},
+		{name: "binary mode", content: testSum + " *hab-x86_64-linux.tar.gz", sum: testSum, fileName: "hab-x86_64-linux.tar.gz"},
+		{name: "missing file name", content: testSum, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, fileName, err := ParseSha256Sum([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.sum, sum)
+			assert.Equal(t, tt.fileName, fileName)
+		})
+	}
+}
+
+func TestMetaDataFromFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		describe func(string, string) (models.MetaData, error)
+		fileName string
+		want     models.MetaData
+		wantErr  bool
+	}{
+		{
+			name:     "automate",
+			describe: AutomateMetaData,
+			fileName: "chef-automate_linux_amd64.zip",
+			want:     models.MetaData{Platform: "linux", Architecture: "amd64", FileName: "chef-automate_linux_amd64.zip", SHA256: testSum},
+		},
+		{
+			name:     "habitat",
+			describe: HabitatMetaData,
+			fileName: "hab-x86_64-windows.zip",
+			want:     models.MetaData{Platform: "windows", Architecture: "x86_64", FileName: "hab-x86_64-windows.zip", SHA256: testSum},
+		},
+		{
+			name:     "habitat kernel2",
+			describe: HabitatMetaData,
+			fileName: "hab-x86_64-linux-kernel2.tar.gz",
+			want:     models.MetaData{Platform: "linux-kernel2", Architecture: "x86_64", FileName: "hab-x86_64-linux-kernel2.tar.gz", SHA256: testSum},
+		},
+		{name: "unexpected automate name", describe: AutomateMetaData, fileName: "automate.zip", wantErr: true},
+		{name: "unexpected habitat name", describe: HabitatMetaData, fileName: "hab.zip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.describe(tt.fileName, testSum)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePackageMetadata(t *testing.T) {
+	content := `{
+		"chef": {
+			"18.2.7": {
+				"release_date": "2023-05-01",
+				"linux": {"x86_64": {"deb": {"filename": "chef_18.2.7-1_amd64.deb", "sha256": "` + testSum + `"}}},
+				"notes": "ignored"
+			},
+			"18.1.0": {
+				"windows": {"x86_64": {"msi": {"filename": "chef-18.1.0-1-x64.msi", "sha256": "` + testSum + `"}}}
+			}
+		}
+	}`
+
+	details, err := ParsePackageMetadata([]byte(content))
+	require.NoError(t, err)
+	require.Len(t, details, 2)
+	assert.Equal(t, "18.1.0", details[0].Version)
+	assert.Equal(t, "18.2.7", details[1].Version)
+	assert.Equal(t, "2023-05-01", details[1].ReleaseDate)
+	assert.Equal(t, []string{"linux"}, keys(details[1].Metadata))
+	assert.Equal(t, "chef_18.2.7-1_amd64.deb", details[1].Metadata["linux"]["x86_64"]["deb"].Filename)
+	assert.Equal(t, []string{"deb", "msi"}, PackageManagers(details))
+
+	_, err = ParsePackageMetadata([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestChannelFromKey(t *testing.T) {
+	assert.Equal(t, "stable", ChannelFromKey("files/stable/chef/18.2.7/metadata.json"))
+	assert.Equal(t, "current", ChannelFromKey("files/current/chef/18.2.7/metadata.json"))
+	assert.Equal(t, "", ChannelFromKey("files/unstable/chef/18.2.7/metadata.json"))
+}
+
+func TestSkipChecksum(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "automate/latest/chef-automate_linux_amd64.zip.sha256sum", want: false},
+		{key: "automate/latest/chef-automate_linux_amd64.zip", want: true},
+		{key: "automate/latest/chef-automate-cli_linux_amd64.zip.sha256sum", want: true},
+		{key: "automate/latest/automate-airgap.aib.sha256sum", want: true},
+		{key: "automate/latest/manifest.json.sha256sum", want: true},
+		{key: "automate/latest/notes.txt", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, skipChecksum(tt.key))
+		})
+	}
+}
+
+func keys(m map[string]models.Platform) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Object is a file found under an ingestion prefix
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// Source lists and reads the files a release publishes
+type Source interface {
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Read(ctx context.Context, key string) ([]byte, error)
+}
+
+// DirSource reads releases from a local directory laid out like the bucket.
+// Keys are slash separated paths relative to Root.
+type DirSource struct {
+	Root string
+}
+
+func (d DirSource) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(d.Root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.Root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, LastModified: info.ModTime()})
+		return nil
+	})
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, err
+}
+
+func (d DirSource) Read(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.Root, filepath.FromSlash(key)))
+}
+
+// S3API is the part of the S3 client used by S3Source
+type S3API interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// S3Source reads releases from a bucket
+type S3Source struct {
+	Client S3API
+	Bucket string
+}
+
+func (s S3Source) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Contents {
+			objects = append(objects, Object{Key: aws.ToString(o.Key), LastModified: aws.ToTime(o.LastModified)})
+		}
+	}
+	return objects, nil
+}
+
+func (s S3Source) Read(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
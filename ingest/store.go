@@ -0,0 +1,39 @@
+package ingest
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store reads and writes catalog items
+type Store interface {
+	// Get returns the item with the given key, or nil when there is none
+	Get(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error)
+	Put(ctx context.Context, table string, item map[string]types.AttributeValue) error
+}
+
+// DynamoAPI is the part of the DynamoDB client used by DynamoStore
+type DynamoAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// DynamoStore keeps catalog items in DynamoDB
+type DynamoStore struct {
+	Client DynamoAPI
+}
+
+func (s DynamoStore) Get(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	out, err := s.Client.GetItem(ctx, &dynamodb.GetItemInput{TableName: &table, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	return out.Item, nil
+}
+
+func (s DynamoStore) Put(ctx context.Context, table string, item map[string]types.AttributeValue) error {
+	_, err := s.Client.PutItem(ctx, &dynamodb.PutItemInput{TableName: &table, Item: item})
+	return err
+}
@@ -0,0 +1,26 @@
+package ingest
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type MockStore struct {
+	GetFunc func(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error)
+	PutFunc func(ctx context.Context, table string, item map[string]types.AttributeValue) error
+}
+
+func (m *MockStore) Get(ctx context.Context, table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, table, key)
+	}
+	return nil, nil
+}
+
+func (m *MockStore) Put(ctx context.Context, table string, item map[string]types.AttributeValue) error {
+	if m.PutFunc != nil {
+		return m.PutFunc(ctx, table, item)
+	}
+	return nil
+}
@@ -4,13 +4,19 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/internal/problem"
 	"github.com/chef/omnitruck-service/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/samber/do"
@@ -34,6 +40,64 @@ type ErrorResponse struct {
 	Message    string `json:"message"`
 } //@name ErrorResponse
 
+// Maximum number of items accepted by the metadata batch endpoint
+const maxMetadataBatchItems = 100
+
+// MetadataBatchItem identifies one package, using the same names as the
+// query parameters of the metadata endpoint
+type MetadataBatchItem struct {
+	Channel         string `json:"channel"`
+	Product         string `json:"product"`
+	Version         string `json:"v,omitempty"`
+	Platform        string `json:"p"`
+	PlatformVersion string `json:"pv,omitempty"`
+	Architecture    string `json:"m"`
+	PackageManager  string `json:"pm,omitempty"`
+	Eol             bool   `json:"eol,omitempty"`
+} //@name MetadataBatchItem
+
+type MetadataBatchRequest struct {
+	Items []MetadataBatchItem `json:"items"`
+} //@name MetadataBatchRequest
+
+type MetadataBatchItemResult struct {
+	Index    int                        `json:"index"`
+	Request  MetadataBatchItem          `json:"request"`
+	Metadata *omnitruck.PackageMetadata `json:"metadata,omitempty"`
+	Error    *ErrorResponse             `json:"error,omitempty"`
+} //@name MetadataBatchItemResult
+
+type MetadataBatchResponse struct {
+	Results []MetadataBatchItemResult `json:"results"`
+} //@name MetadataBatchResponse
+
+func (item MetadataBatchItem) requestParams(licenseId string) *omnitruck.RequestParams {
+	eol := "false"
+	if item.Eol {
+		eol = "true"
+	}
+
+	return &omnitruck.RequestParams{
+		Channel:         item.Channel,
+		Product:         item.Product,
+		Version:         item.Version,
+		Platform:        item.Platform,
+		PlatformVersion: item.PlatformVersion,
+		Architecture:    item.Architecture,
+		PackageManager:  item.PackageManager,
+		LicenseId:       licenseId,
+		Eol:             eol,
+	}
+}
+
+func newErrorResponse(code int, msg string) *ErrorResponse {
+	return &ErrorResponse{
+		Code:       code,
+		StatusText: http.StatusText(code),
+		Message:    msg,
+	}
+}
+
 func (h *DownloadsHandler) JSON(c *fiber.Ctx, data interface{}) error {
 	var resultBytes bytes.Buffer
 	enc := json.NewEncoder(&resultBytes)
@@ -49,6 +113,9 @@ func (h *DownloadsHandler) SendResponse(c *fiber.Ctx, data interface{}) error {
 }
 
 func (h *DownloadsHandler) SendError(c *fiber.Ctx, request *clients.Request) error {
+	if problem.Enabled(c) {
+		return problem.Send(c, problem.FromRequest(request))
+	}
 
 	return c.Status(request.Code).JSON(ErrorResponse{
 		Code:       request.Code,
@@ -58,6 +125,10 @@ func (h *DownloadsHandler) SendError(c *fiber.Ctx, request *clients.Request) err
 }
 
 func (h *DownloadsHandler) SendErrorResponse(c *fiber.Ctx, code int, msg string) error {
+	if problem.Enabled(c) {
+		return problem.Send(c, problem.New(code, problem.CodeFor(code, ""), msg))
+	}
+
 	return c.Status(code).JSON(ErrorResponse{
 		Code:       code,
 		StatusText: http.StatusText(code),
@@ -86,7 +157,7 @@ func (h *DownloadsHandler) ProductsHandler(c *fiber.Ctx) error {
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.Products(params)
+	data, request := downloadService.Products(c.UserContext(), params)
 
 	if request.Ok {
 		return h.SendResponse(c, &data)
@@ -96,11 +167,12 @@ func (h *DownloadsHandler) ProductsHandler(c *fiber.Ctx) error {
 }
 
 // @Summary Get platform keys
-// @description Returns a valid list of valid platform keys along with full friendly names.
-// @description Any of these platform keys can be used in the p query string value in various endpoints below.
+// @description Returns the valid platform keys along with their friendly names, aliases, default package manager,
+// @description supported platform versions and architectures.
+// @description Any of these platform keys or aliases can be used in the p query string value in various endpoints below.
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} omnitruck.PlatformList
+// @Success     200 {object} omnitruck.PlatformCatalog
 // @Failure     500 {object} ErrorResponse
 // @Router      /platforms [get]
 func (h *DownloadsHandler) PlatformsHandler(c *fiber.Ctx) error {
@@ -110,12 +182,11 @@ func (h *DownloadsHandler) PlatformsHandler(c *fiber.Ctx) error {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
 	}
 	locals := setLocals(c)
-	var data omnitruck.PlatformList
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.Platforms()
+	data, request := downloadService.Platforms(c.UserContext())
 
 	if request.Ok {
 		return h.SendResponse(c, &data)
@@ -143,7 +214,7 @@ func (h *DownloadsHandler) ArchitecturesHandler(c *fiber.Ctx) error {
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.Architectures()
+	data, request := downloadService.Architectures(c.UserContext())
 
 	if request.Ok {
 		return h.SendResponse(c, &data)
@@ -171,9 +242,8 @@ func (h *DownloadsHandler) LatestVersionHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	params.Version = "latest"
 
@@ -181,7 +251,7 @@ func (h *DownloadsHandler) LatestVersionHandler(c *fiber.Ctx) error {
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.LatestVersion(params)
+	data, request := downloadService.LatestVersion(c.UserContext(), params)
 
 	if request.Ok {
 		return h.SendResponse(c, &data)
@@ -196,6 +266,7 @@ func (h *DownloadsHandler) LatestVersionHandler(c *fiber.Ctx) error {
 // @Produce     json
 // @Param       channel    path     string true  "Channel" Enums(current, stable)
 // @Param       product    path     string true  "Product"
+// @Param       v          query    string false "Only return versions matching a prefix such as `18.2` or a constraint such as `~> 18.2` or `>= 17, < 18`"
 // @Param       license_id query    string false "License ID"
 // @Param       eol        query    bool   false "EOL Products" Default(false)
 // @Success     200        {object} omnitruck.ItemList
@@ -210,15 +281,178 @@ func (h *DownloadsHandler) ProductVersionsHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	data, request := downloadService.ProductVersions(c.UserContext(), params)
+	if request.Ok {
+		return h.SendResponse(c, &data)
+	} else {
+		return h.SendError(c, request)
+	}
+}
+
+// @Summary Get version details of a product in a channel
+// @description Get a paginated list of the versions available for a particular channel and product combination.
+// @description Each version includes its release date when the catalog records one, whether it is EOL, open source or a pre-release, and the platforms it ships for.
+// @Accept      json
+// @Produce     json
+// @Param       channel    path     string true  "Channel" Enums(current, stable)
+// @Param       product    path     string true  "Product"
+// @Param       v          query    string false "Only return versions matching a prefix such as `18.2` or a constraint such as `~> 18.2` or `>= 17, < 18`"
+// @Param       opensource query    bool   false "Only return open source (true) or commercial (false) versions"
+// @Param       prerelease query    bool   false "Only return pre-release (true) or release (false) versions"
+// @Param       order      query    string false "Sort order" Enums(asc, desc) Default(asc)
+// @Param       page       query    int    false "Page number" Default(1)
+// @Param       per_page   query    int    false "Versions per page, at most 100" Default(20)
+// @Param       license_id query    string false "License ID"
+// @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Success     200        {object} omnitruck.VersionDetailsList
+// @Failure     400        {object} ErrorResponse
+// @Failure     403        {object} ErrorResponse
+// @Router      /{channel}/{product}/versions [get]
+func (h *DownloadsHandler) VersionDetailsHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
 	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	query, err := versionDetailsQuery(c)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.ProductVersions(params)
+	data, request := downloadService.VersionDetails(c.UserContext(), params, query)
+	if request.Ok {
+		return h.SendResponse(c, &data)
+	} else {
+		return h.SendError(c, request)
+	}
+}
+
+// versionDetailsQuery reads and validates the paging and filter query parameters
+func versionDetailsQuery(c *fiber.Ctx) (services.VersionDetailsQuery, error) {
+	query := services.VersionDetailsQuery{
+		Page:       1,
+		PerPage:    services.DefaultVersionDetailsPerPage,
+		Order:      c.Query("order", "asc"),
+		OpenSource: c.Query("opensource"),
+		Prerelease: c.Query("prerelease"),
+	}
+
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return query, errors.New("page must be a positive integer")
+		}
+		query.Page = page
+	}
+	if v := c.Query("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil || perPage < 1 || perPage > services.MaxVersionDetailsPerPage {
+			return query, fmt.Errorf("per_page must be between 1 and %d", services.MaxVersionDetailsPerPage)
+		}
+		query.PerPage = perPage
+	}
+	if query.Order != "asc" && query.Order != "desc" {
+		return query, errors.New("order must be asc or desc")
+	}
+	for _, v := range [][2]string{{"opensource", query.OpenSource}, {"prerelease", query.Prerelease}} {
+		if v[1] != "" && v[1] != "true" && v[1] != "false" {
+			return query, fmt.Errorf("%s must be true or false", v[0])
+		}
+	}
+
+	return query, nil
+}
+
+// @Summary Get the release notes of a product version
+// @description Get the release notes of a single version. The version may be `latest`, a partial version such as `18.2` or a constraint.
+// @description Notes are read from the product catalog, or from the configured release notes source when the catalog has none.
+// @Accept      json
+// @Produce     json
+// @Param       channel    path     string true  "Channel" Enums(current, stable)
+// @Param       product    path     string true  "Product"
+// @Param       version    path     string true  "Version" Example(latest)
+// @Param       license_id query    string false "License ID"
+// @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Success     200        {object} omnitruck.ReleaseNote
+// @Failure     400        {object} ErrorResponse
+// @Failure     403        {object} ErrorResponse
+// @Failure     404        {object} ErrorResponse
+// @Router      /{channel}/{product}/versions/{version}/notes [get]
+func (h *DownloadsHandler) ReleaseNotesHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	params.Version = c.Params("version")
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	data, request := downloadService.ReleaseNotes(c.UserContext(), params)
+	if request.Ok {
+		return h.SendResponse(c, &data)
+	} else {
+		return h.SendError(c, request)
+	}
+}
+
+// @Summary Get the release notes between two product versions
+// @description Get the release notes of every version newer than `from`, up to and including `to`, newest first.
+// @description The notes are also returned concatenated, each version under its own heading. Versions without notes are skipped.
+// @Accept      json
+// @Produce     json
+// @Param       channel    path     string true  "Channel" Enums(current, stable)
+// @Param       product    path     string true  "Product"
+// @Param       from       query    string true  "Version currently installed" Example(18.0.0)
+// @Param       to         query    string false "Version to upgrade to, partial versions resolve to the newest match" Default(latest)
+// @Param       license_id query    string false "License ID"
+// @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Success     200        {object} omnitruck.ReleaseNotesList
+// @Failure     400        {object} ErrorResponse
+// @Failure     403        {object} ErrorResponse
+// @Router      /{channel}/{product}/notes [get]
+func (h *DownloadsHandler) ReleaseNotesRangeHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	params.Version = c.Query("to", constants.LATEST)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	from := c.Query("from")
+	if from == "" {
+		return h.SendErrorResponse(c, http.StatusBadRequest, "from is required")
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	data, request := downloadService.ReleaseNotesRange(c.UserContext(), params, from)
 	if request.Ok {
 		return h.SendResponse(c, &data)
 	} else {
@@ -236,6 +470,7 @@ func (h *DownloadsHandler) ProductVersionsHandler(c *fiber.Ctx) error {
 // @Param       v          query    string false "Version"
 // @Param       license_id query    string false "License ID"
 // @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Param       fields     query    string false "Comma separated metadata fields to return for each package. All fields are returned by default"
 // @Success     200        {object} omnitruck.PackageList
 // @Failure     400        {object} ErrorResponse
 // @Failure     403        {object} ErrorResponse
@@ -248,15 +483,95 @@ func (h *DownloadsHandler) ProductPackagesHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	fields, err := omnitruck.ParseMetadataFields(c.Query("fields"))
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	data, request := downloadService.ProductPackages(c.UserContext(), params)
+	if request.Ok && fields != nil {
+		return h.SendResponse(c, data.SelectFields(fields))
+	} else if request.Ok {
+		return h.SendResponse(c, &data)
+	} else {
+		return h.SendError(c, request)
+	}
+}
+
+// @Summary Get packages for a product version as a typed list
+// @description Get every package of a product version as a list with explicit platform, platform_version, arch and package_manager fields, sorted by those fields.
+// @description Fields a backend does not record, such as the platform version of catalog products, are returned empty.
+// @description By default packages for the latest version are returned. If the v query string parameter is included the packages for the specified version are returned.
+// @Accept      json
+// @Produce     json
+// @Param       channel    path     string true  "Channel" Enums(current, stable)
+// @Param       product    path     string true  "Product" Example(chef)
+// @Param       v          query    string false "Version"
+// @Param       license_id query    string false "License ID"
+// @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Success     200        {object} omnitruck.Packages
+// @Failure     400        {object} ErrorResponse
+// @Failure     403        {object} ErrorResponse
+// @Router      /v2/{channel}/{product}/packages [get]
+func (h *DownloadsHandler) ProductPackagesV2Handler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	data, request := downloadService.ProductPackagesV2(c.UserContext(), params)
+	if request.Ok {
+		return h.SendResponse(c, &data)
+	} else {
+		return h.SendError(c, request)
+	}
+}
+
+// @Summary Get the support matrix for a product version
+// @description Get every platform, platform version, architecture and package manager combination of a product version as a flat table, with the file name and checksums of each package.
+// @description By default the latest version is used. If the v query string parameter is included the combinations for the specified version are returned.
+// @Accept      json
+// @Produce     json
+// @Param       channel    path     string true  "Channel" Enums(current, stable)
+// @Param       product    path     string true  "Product" Example(chef)
+// @Param       v          query    string false "Version"
+// @Param       license_id query    string false "License ID"
+// @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Success     200        {object} omnitruck.SupportMatrix
+// @Failure     400        {object} ErrorResponse
+// @Failure     403        {object} ErrorResponse
+// @Router      /{channel}/{product}/support-matrix [get]
+func (h *DownloadsHandler) SupportMatrixHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
 	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.ProductPackages(params)
+	data, request := downloadService.SupportMatrix(c.UserContext(), params)
 	if request.Ok {
 		return h.SendResponse(c, &data)
 	} else {
@@ -275,9 +590,10 @@ func (h *DownloadsHandler) ProductPackagesHandler(c *fiber.Ctx) error {
 // @Param       pv         query    string true  "Platform Version, possible values depend on the platform. For example, Ubuntu: 16.04, or 18.04 or for macOS: 10.14 or 10.15." Example(20.04)
 // @Param       m          query    string true  "Machine architecture, valid values are returned by the `/architectures` endpoint."                                            Example(x86_64)
 // @Param       pm         query    string false "Package Manager, valid values depend on the platform (e.g., Linux: deb, rpm, tar; Windows: msi; Darwin: dmg, tar)." Example(tar)
-// @Param       v          query    string false "Version of the product to be installed. A version always takes the form `x.y.z`"                                              Default(latest)
+// @Param       v          query    string false "Version of the product to be installed. Either a version `x.y.z`, a prefix such as `x.y` or a constraint such as `~> x.y`" Default(latest)
 // @Param       license_id query    string false "License ID"
 // @Param       eol        query    bool   false "EOL Products" Default(false)
+// @Param       fields     query    string false "Comma separated metadata fields to return, for example `sha1,sha256,url,version`. All fields are returned by default"
 // @Success     200        {object} omnitruck.PackageMetadata
 // @Failure     400        {object} ErrorResponse
 // @Failure     403        {object} ErrorResponse
@@ -290,22 +606,87 @@ func (h *DownloadsHandler) ProductMetadataHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	fields, err := omnitruck.ParseMetadataFields(c.Query("fields"))
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.ProductMetadata(params)
-	if request.Ok {
+	data, request := downloadService.ProductMetadata(c.UserContext(), params)
+	if request.Ok && fields != nil {
+		return h.SendResponse(c, data.SelectFields(fields))
+	} else if request.Ok {
 		return h.SendResponse(c, &data)
 	} else {
 		return h.SendError(c, request)
 	}
 }
 
+// @Summary Get metadata for several packages
+// @description Returns the metadata for up to 100 product, platform and architecture combinations in one call.
+// @description Each item is validated and resolved like a call to `/{channel}/{product}/metadata` and carries either its metadata or its error.
+// @Accept      json
+// @Produce     json
+// @Param       request    body   MetadataBatchRequest true  "Items to resolve"
+// @Param       license_id query  string               false "License ID"
+// @Success     200 {object} MetadataBatchResponse
+// @Failure     400 {object} ErrorResponse
+// @Failure     403 {object} ErrorResponse
+// @Router      /metadata/batch [post]
+func (h *DownloadsHandler) ProductMetadataBatchHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	var body MetadataBatchRequest
+	if err := c.BodyParser(&body); err != nil {
+		return h.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if len(body.Items) == 0 {
+		return h.SendErrorResponse(c, http.StatusBadRequest, "items cannot be empty")
+	}
+	if len(body.Items) > maxMetadataBatchItems {
+		return h.SendErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("items cannot contain more than %d entries", maxMetadataBatchItems))
+	}
+	locals := setLocals(c)
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+
+	results := make([]MetadataBatchItemResult, len(body.Items))
+	var pending []*omnitruck.RequestParams
+	var pendingIndex []int
+	for i, item := range body.Items {
+		results[i] = MetadataBatchItemResult{Index: i, Request: item}
+		params := item.requestParams(c.Query("license_id"))
+		if request := h.ValidateRequest(params, c); !request.Ok {
+			results[i].Error = newErrorResponse(request.Code, request.Message)
+			continue
+		}
+		pending = append(pending, params)
+		pendingIndex = append(pendingIndex, i)
+	}
+
+	for j, result := range downloadService.ProductMetadataBatch(c.UserContext(), pending) {
+		i := pendingIndex[j]
+		if result.Request.Ok {
+			metadata := result.Metadata
+			results[i].Metadata = &metadata
+		} else {
+			results[i].Error = newErrorResponse(result.Request.Code, result.Request.Message)
+		}
+	}
+
+	return h.SendResponse(c, &MetadataBatchResponse{Results: results})
+}
+
 // @Summary Download a product package
 // @description Get details for a particular package.
 // @description The `ACCEPT` HTTP header with a value of `application/json` must be provided in the request for a JSON response to be returned
@@ -317,7 +698,7 @@ func (h *DownloadsHandler) ProductMetadataHandler(c *fiber.Ctx) error {
 // @Param       pv         query  string true  "Platform Version, possible values depend on the platform. For example, Ubuntu: 16.04, or 18.04 or for macOS: 10.14 or 10.15." Example(20.04)
 // @Param       m          query  string true  "Machine architecture, valid values are returned by the `/architectures` endpoint."                                            Example(x86_64)
 // @Param       pm         query  string false "Package Manager, valid values depend on the platform (e.g., Linux: deb, rpm, tar; Windows: msi; Darwin: dmg, tar)." Example(tar)
-// @Param       v          query  string false "Version of the product to be installed. A version always takes the form `x.y.z`"                                              Default(latest)
+// @Param       v          query  string false "Version of the product to be installed. Either a version `x.y.z`, a prefix such as `x.y` or a constraint such as `~> x.y`" Default(latest)
 // @Param       license_id query  string false "License ID"
 // @Param       eol        query  bool   false "EOL Products" Default(false)
 // @Success     302
@@ -332,21 +713,105 @@ func (h *DownloadsHandler) ProductDownloadHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	url, downloadResp, header, msg, code, err := downloadService.ProductDownload(params, c)
+	url, downloadResp, header, msg, code, err := downloadService.ProductDownload(c.UserContext(), params, c)
 	if err != nil {
-		return h.SendErrorResponse(c, code, msg)
+		return h.sendDownloadError(c, code, msg, err)
 	}
 	return h.sendDownloadResponse(c, url, downloadResp, header)
 }
 
+// @Summary Get the SBOM of a product package
+// @description Returns the CycloneDX or SPDX SBOM published for a package, either streamed or as a redirect.
+// @description The format is negotiated with the `Accept` header: `application/vnd.cyclonedx+json` or `application/spdx+json`.
+// @description Any other accepted type returns whichever format is available.
+// @Produce     application/vnd.cyclonedx+json,application/spdx+json
+// @Param       channel    path   string true  "Channel"                                                                                                                      Enums(current, stable)
+// @Param       product    path   string true  "Product"                                                                                                                      Example(chef)
+// @Param       p          query  string true  "Platform, valid values are returned from the `/platforms` endpoint."                                                          Example(ubuntu)
+// @Param       pv         query  string true  "Platform Version, possible values depend on the platform. For example, Ubuntu: 16.04, or 18.04 or for macOS: 10.14 or 10.15." Example(20.04)
+// @Param       m          query  string true  "Machine architecture, valid values are returned by the `/architectures` endpoint."                                            Example(x86_64)
+// @Param       pm         query  string false "Package Manager, valid values depend on the platform (e.g., Linux: deb, rpm, tar; Windows: msi; Darwin: dmg, tar)." Example(tar)
+// @Param       v          query  string false "Version of the product. Either a version `x.y.z`, a prefix such as `x.y` or a constraint such as `~> x.y`" Default(latest)
+// @Param       license_id query  string false "License ID"
+// @Param       eol        query  bool   false "EOL Products" Default(false)
+// @Success     200
+// @Success     302
+// @Failure     400 {object} ErrorResponse
+// @Failure     403 {object} ErrorResponse
+// @Failure     404 {object} ErrorResponse
+// @Failure     406 {object} ErrorResponse
+// @Router      /{channel}/{product}/sbom [get]
+func (h *DownloadsHandler) ProductSbomHandler(c *fiber.Ctx) error {
+	reqInjectorI := c.Locals("reqinjector")
+	reqInjector, ok := reqInjectorI.(*do.Injector)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Not able to process the request.")
+	}
+	locals := setLocals(c)
+	params := helpers.GetRequestParams(c)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
+	}
+	format, ok := sbomFormat(c)
+	if !ok {
+		return h.SendErrorResponse(c, http.StatusNotAcceptable, "SBOMs are available as "+sbomMediaTypes())
+	}
+	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
+	if err != nil {
+		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
+	}
+	url, downloadResp, header, msg, code, err := downloadService.ProductSbom(c.UserContext(), params, format)
+	if err != nil {
+		return h.sendDownloadError(c, code, msg, err)
+	}
+	if err := h.sendDownloadResponse(c, url, downloadResp, header); err != nil {
+		return err
+	}
+	if downloadResp != nil {
+		// sendDownloadResponse serves packages, SBOMs keep their document type
+		c.Set(fiber.HeaderContentType, header.Get(fiber.HeaderContentType))
+	}
+	return nil
+}
+
+// sbomFormat negotiates the SBOM format from the Accept header. Wildcards and
+// other generic types accept whichever format the catalog has.
+func sbomFormat(c *fiber.Ctx) (helpers.SbomFormat, bool) {
+	accept := c.Get(fiber.HeaderAccept)
+	if accept == "" {
+		return helpers.SbomFormat{}, true
+	}
+	offers := []string{}
+	for _, format := range helpers.SbomFormats {
+		offers = append(offers, format.MediaType)
+	}
+	offers = append(offers, fiber.MIMEApplicationJSON)
+
+	accepted := c.Accepts(offers...)
+	if accepted == "" {
+		return helpers.SbomFormat{}, false
+	}
+	if format, ok := helpers.SbomFormatForMediaType(accepted); ok && strings.Contains(accept, accepted) {
+		return format, true
+	}
+	return helpers.SbomFormat{}, true
+}
+
+func sbomMediaTypes() string {
+	types := []string{}
+	for _, format := range helpers.SbomFormats {
+		types = append(types, format.MediaType)
+	}
+	return strings.Join(types, ", ")
+}
+
 // @Summary Download a product package using path params
 // @description Path-param variant of download endpoint. Architecture is always passed in path; package manager is path-based only for infra products.
 // @description Tail formats:
@@ -359,7 +824,7 @@ func (h *DownloadsHandler) ProductDownloadHandler(c *fiber.Ctx) error {
 // @Param       product    path   string true  "Product" Example(chef)
 // @Param       version    path   string true  "Version" Example(latest)
 // @Param       platform   path   string true  "Platform" Example(linux)
-// @Param       tail       path   string true  "Path tail containing platformVersion/arch/pm/fileName by product strategy"
+// @Param       tail       path   string true  "Path tail containing platformVersion/arch/pm/fileName by product strategy. A fileName ending in .asc or .sig serves the detached signature of the package"
 // @Param       license_id query  string false "License ID"
 // @Param       eol        query  bool   false "EOL Products" Default(false)
 // @Success     302
@@ -380,14 +845,19 @@ func (h *DownloadsHandler) ProductFilesDownloadHandler(c *fiber.Ctx) error {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
 
-	url, downloadResp, header, msg, code, err := downloadService.ProductFilesDownload(c)
+	url, downloadResp, header, msg, code, err := downloadService.ProductFilesDownload(c.UserContext(), c)
 	if err != nil {
-		return h.SendErrorResponse(c, code, msg)
+		return h.sendDownloadError(c, code, msg, err)
 	}
 
 	return h.sendDownloadResponse(c, url, downloadResp, header)
 }
 
+// sendDownloadError answers a failed download with the code attached to err
+func (h *DownloadsHandler) sendDownloadError(c *fiber.Ctx, code int, msg string, err error) error {
+	return h.SendError(c, (&clients.Request{}).Failure(code, msg).WithErrorCode(apierror.CodeOf(err)))
+}
+
 func (h *DownloadsHandler) sendDownloadResponse(c *fiber.Ctx, url string, downloadResp io.ReadCloser, header http.Header) error {
 	if downloadResp != nil {
 		// If the response is not nil, it means we are returning a file download
@@ -458,15 +928,14 @@ func (h *DownloadsHandler) RelatedProductsHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	data, request := downloadService.RelatedProducts(params)
+	data, request := downloadService.RelatedProducts(c.UserContext(), params)
 	if request.Ok {
 		return h.SendResponse(c, &data)
 	} else {
@@ -484,7 +953,7 @@ func (h *DownloadsHandler) RelatedProductsHandler(c *fiber.Ctx) error {
 // @Param       pv         query    string true  "Platform Version, possible values depend on the platform. For example, Ubuntu: 16.04, or 18.04 or for macOS: 10.14 or 10.15." Example(20.04)
 // @Param       m          query    string true  "Machine architecture, valid values are returned by the `/architectures` endpoint."                                            Example(x86_64)
 // @Param       pm         query    string false "Package Manager, valid values depend on the platform (e.g., Linux: deb, rpm, tar; Windows: msi; Darwin: dmg, tar)." Example(tar)
-// @Param       v          query    string false "Version of the product to be installed. A version always takes the form `x.y.z`"                                              Default(latest)
+// @Param       v          query    string false "Version of the product to be installed. Either a version `x.y.z`, a prefix such as `x.y` or a constraint such as `~> x.y`" Default(latest)
 // @Param       license_id query    string false "License ID"
 // @Success     200        {object} map[string]interface{}
 // @Failure     400        {object} ErrorResponse
@@ -498,15 +967,14 @@ func (h *DownloadsHandler) FileNameHandler(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	downloadService, err := services.NewDownloadService(reqInjector, h.Log, locals)
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	fileName, request := downloadService.GetFileName(params)
+	fileName, request := downloadService.GetFileName(c.UserContext(), params)
 	if request.Ok {
 		return h.SendResponse(c, map[string]interface{}{
 			"fileName": fileName,
@@ -533,9 +1001,8 @@ func (h *DownloadsHandler) DownloadLinuxScript(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	c.Set("Content-Type", "application/x-sh")
 	c.Set("Content-Disposition", "attachment;filename=install.sh")
@@ -568,9 +1035,8 @@ func (h *DownloadsHandler) DownloadWindowsScript(c *fiber.Ctx) error {
 	}
 	locals := setLocals(c)
 	params := helpers.GetRequestParams(c)
-	msg, code, ok := h.ValidateRequest(params, c)
-	if !ok {
-		return h.SendErrorResponse(c, code, msg)
+	if request := h.ValidateRequest(params, c); !request.Ok {
+		return h.SendError(c, request)
 	}
 	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
 	c.Set("Content-Disposition", "attachment;filename=install.ps1")
@@ -605,7 +1071,7 @@ func (h *DownloadsHandler) PackageManagersHandler(c *fiber.Ctx) error {
 	if err != nil {
 		return h.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create download service")
 	}
-	packageManagers, request := downloadService.GetPackageManagers()
+	packageManagers, request := downloadService.GetPackageManagers(c.UserContext())
 	if !request.Ok {
 		return h.SendError(c, request)
 	} else {
@@ -613,7 +1079,10 @@ func (h *DownloadsHandler) PackageManagersHandler(c *fiber.Ctx) error {
 	}
 }
 
-func (h *DownloadsHandler) ValidateRequest(params *omnitruck.RequestParams, c *fiber.Ctx) (string, int, bool) {
+// ValidateRequest checks params against the validator of the request and
+// returns the failure to answer when they are not valid
+func (h *DownloadsHandler) ValidateRequest(params *omnitruck.RequestParams, c *fiber.Ctx) *clients.Request {
+	request := &clients.Request{Ok: true}
 	context := omnitruck.Context{
 		License: h.validLicense(c),
 	}
@@ -621,19 +1090,19 @@ func (h *DownloadsHandler) ValidateRequest(params *omnitruck.RequestParams, c *f
 	reqInjectorI := c.Locals("reqinjector")
 	reqInjector, ok := reqInjectorI.(*do.Injector)
 	if !ok {
-		return "Failed to retrieve request injector", fiber.StatusInternalServerError, false
+		return request.Failure(fiber.StatusInternalServerError, "Failed to retrieve request injector")
 	}
 
 	validator := do.MustInvokeNamed[omnitruck.IRequestValidator](reqInjector, "validator")
 
 	errors := validator.Params(params, context)
 	if errors != nil {
-		msgs, code := validator.ErrorMessages(errors)
+		msgs, code, errorCode := validator.ErrorMessages(errors)
 
-		return msgs, code, false
+		return request.Failure(code, msgs).WithErrorCode(errorCode)
 	}
 
-	return "", 0, true
+	return request
 }
 
 func (h *DownloadsHandler) validLicense(c *fiber.Ctx) bool {
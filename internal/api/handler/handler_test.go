@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"time"
 
 	"testing"
@@ -20,6 +22,7 @@ import (
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
 	_ "github.com/chef/omnitruck-service/docs"
+	"github.com/chef/omnitruck-service/internal/apierror"
 
 	"github.com/chef/omnitruck-service/utils/template"
 	"github.com/gofiber/fiber/v2"
@@ -58,18 +61,19 @@ func testInjector(
 				if params.Channel != "stable" && params.Channel != "current" {
 					return []*omnitruck.ValidationError{
 						{
-							Msg:  "Channel can only be stable or current",
-							Code: fiber.StatusBadRequest,
+							Msg:       "Channel can only be stable or current",
+							Code:      fiber.StatusBadRequest,
+							ErrorCode: apierror.CodeChannelInvalid,
 						},
 					}
 				}
 				return nil
 			},
-			ErrorMessagesFunc: func(errors []*omnitruck.ValidationError) (string, int) {
+			ErrorMessagesFunc: func(errors []*omnitruck.ValidationError) (string, int, string) {
 				if len(errors) == 0 {
-					return "", 0
+					return "", 0, ""
 				}
-				return errors[0].Msg, errors[0].Code
+				return errors[0].Msg, errors[0].Code, errors[0].ErrorCode
 			},
 		})
 
@@ -83,10 +87,10 @@ func TestProductsHandler(t *testing.T) {
 
 	// Mock DB Service
 	mockDb := new(dboperations.MockIDbOperations)
-	mockDb.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+	mockDb.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 		return []string{"0.1.1"}, nil
 	}
-	mockDb.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+	mockDb.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 		return "0.1.1", nil
 	}
 
@@ -291,10 +295,10 @@ func TestLatestVersionsHandler(t *testing.T) {
 
 			// mock db
 			mockDbService := &dboperations.MockIDbOperations{
-				GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+				GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 					return test.versions, test.versions_err
 				},
-				GetVersionLatestfunc: func(partitionValue string) (string, error) {
+				GetVersionLatestfunc: func(ctx context.Context, partitionValue string) (string, error) {
 					return test.version, test.version_err
 				},
 				SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -390,7 +394,7 @@ func TestProductVersionsHandler(t *testing.T) {
 				return c.Next()
 			})
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return test.versions, test.versions_err
 			}
 			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {
@@ -573,7 +577,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-ice/metadata?p=linux&m=amd64&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -606,7 +610,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-ice/metadata?p=linux&m=amd64&pm=rpm&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -626,7 +630,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-ice/metadata?p=ubuntu&m=amd64&pm=rpm&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -646,7 +650,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-ice/metadata?p=ubuntu&m=amd64&pm=tar&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=tar&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-ice/download?eol=false&m=amd64&p=linux&pm=tar&v=latest", "version":"latest", "package_manager":"tar"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -679,7 +683,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/migrate-ice/metadata?p=linux&m=amd64&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/migrate-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/migrate-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -699,7 +703,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/migrate-ice/metadata?p=linux&m=amd64&pm=rpm&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/migrate-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/migrate-ice/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -732,7 +736,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-workstation-enterprise/metadata?p=linux&m=amd64&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-workstation-enterprise/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-workstation-enterprise/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -752,7 +756,7 @@ func TestProductMetadataHandler(t *testing.T) {
 			serverMode:       constants.Trial,
 			requestPath:      "/stable/chef-workstation-enterprise/metadata?p=linux&m=amd64&pm=rpm&eol=false&v=latest",
 			expectedStatus:   fiber.StatusOK,
-			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-workstation-enterprise/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest"}`,
+			expectedResponse: `{"sha1":"", "sha256":"abcd", "url":"http://example.com/stable/chef-workstation-enterprise/download?eol=false&m=amd64&p=linux&pm=rpm&v=latest", "version":"latest", "package_manager":"rpm"}`,
 			metadata: models.MetaData{
 				Architecture:   "amd64",
 				Platform:       "linux",
@@ -789,13 +793,13 @@ func TestProductMetadataHandler(t *testing.T) {
 				return c.Next()
 			})
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				return &test.metadata, test.err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return test.version, test.version_err
 			}
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return test.versions, test.versions_err
 			}
 			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {
@@ -1020,7 +1024,9 @@ func TestProductPackagesHandler(t *testing.T) {
 									"sha1": "dcf75b37bb80128af4657501bfd41eac52820191",
 									"sha256": "2c501d02b16d67e9d5a28578b95f8d3155bed940ee4946229213f41a2e8b798e",
 									"url": "http://example.com/stable/chef-ice/download?eol=false&license_id=tmns-e88dafdb-06e1-4676-908f-87503da14c4d-3413&m=x86_64&p=linux&pm=deb&v=19.7.17",
-									"version": "19.7.17"
+									"version": "19.7.17",
+									"filename": "chef-ice_19.7.17_amd64.deb",
+									"package_manager": "deb"
 								}
 							}
 						}
@@ -1058,7 +1064,9 @@ func TestProductPackagesHandler(t *testing.T) {
 							"sha1": "dcf75b37bb80128af4657501bfd41eac52820191",
 							"sha256": "2c501d02b16d67e9d5a28578b95f8d3155bed940ee4946229213f41a2e8b798e",
 							"url": "http://example.com/stable/migrate-ice/download?eol=false&license_id=tmns-e88dafdb-06e1-4676-908f-87503da14c4d-3413&m=x86_64&p=linux&pm=deb&v=19.0.1",
-							"version": "19.0.1"
+							"version": "19.0.1",
+							"filename": "migration-tool_19.0.1_amd64.deb",
+							"package_manager": "deb"
 						}
 					}
 				}
@@ -1093,13 +1101,13 @@ func TestProductPackagesHandler(t *testing.T) {
 				return c.Next()
 			})
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetPackagesfunc = func(partitionValue, sortValue string) (interface{}, error) {
+			mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
 				return test.details, test.err
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return test.version, test.version_err
 			}
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return test.versions, test.versions_err
 			}
 			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {
@@ -1301,8 +1309,8 @@ func TestFileNameHandler(t *testing.T) {
 			name:             "chef-ice parameter missing",
 			requestPath:      "/current/chef-ice/fileName?p=windows&pv=pv&m=x86_64&v=latest&license_id=viv2c0a2-111f-2caf-1fa2-1211fe1212d1",
 			serverMode:       constants.Commercial,
-			expectedStatus:   http.StatusInternalServerError,
-			expectedResponse: `{"code":500, "message":"Product information not found. Please check the input parameters.", "status_text":"Internal Server Error"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"code":400, "message":"Product information not found. Please check the input parameters.", "status_text":"Bad Request"}`,
 			metadata:         models.MetaData{},
 			version_err:      nil,
 			versions:         []string{"19.0.1"},
@@ -1387,13 +1395,13 @@ func TestFileNameHandler(t *testing.T) {
 				})
 				mockDbService := new(dboperations.MockIDbOperations)
 
-				mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+				mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 					return &test.metadata, test.metadata_err
 				}
-				mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+				mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 					return test.version, test.version_err
 				}
-				mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+				mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 					return test.versions, test.versions_err
 				}
 				mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {
@@ -1571,7 +1579,7 @@ func TestPackageManagersHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetPackageManagersfunc = func() ([]string, error) {
+			mockDbService.GetPackageManagersfunc = func(ctx context.Context) ([]string, error) {
 				return tt.mockData, tt.mockErr
 			}
 
@@ -1643,7 +1651,7 @@ func TestProductDownloadHandler(t *testing.T) {
 			app := fiber.New()
 			// Set up DownloadService with necessary mocks (see other tests for pattern)
 			mockDbService := new(dboperations.MockIDbOperations)
-			mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				return &models.MetaData{
 					FileName:        "test-file.rpm",
 					Platform:        platform,
@@ -1653,10 +1661,10 @@ func TestProductDownloadHandler(t *testing.T) {
 					SHA256:          "abcd1234",
 				}, nil
 			}
-			mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 				return "latest", nil
 			}
-			mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			}
 			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
@@ -1685,7 +1693,7 @@ func TestProductMetadataHandler_DirectUrl(t *testing.T) {
 	})
 
 	mockDbService := new(dboperations.MockIDbOperations)
-	mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+	mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 		return &models.MetaData{
 			Architecture: architecture,
 			Platform:     platform,
@@ -1693,10 +1701,10 @@ func TestProductMetadataHandler_DirectUrl(t *testing.T) {
 			SHA256:       "abcd",
 		}, nil
 	}
-	mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+	mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 		return "latest", nil
 	}
-	mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 		return []string{"latest"}, nil
 	}
 	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
@@ -1714,7 +1722,78 @@ func TestProductMetadataHandler_DirectUrl(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	bodyBytes, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
-	assert.JSONEq(t, `{"sha1":"","sha256":"abcd","url":"http://example.com/files/stable/automate/latest/linux/amd64/automate_4.7.52-1_amd64.deb?license_id=","version":"latest"}`, string(bodyBytes))
+	assert.JSONEq(t, `{"sha1":"","sha256":"abcd","url":"http://example.com/files/stable/automate/latest/linux/amd64/automate_4.7.52-1_amd64.deb?license_id=","version":"latest","filename":"automate_4.7.52-1_amd64.deb"}`, string(bodyBytes))
+}
+
+func TestProductMetadataHandler_Fields(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestPath      string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "legacy fields",
+			requestPath:      "/stable/automate/metadata?p=linux&m=amd64&v=latest&eol=false&fields=sha1,sha256,url,version",
+			expectedStatus:   fiber.StatusOK,
+			expectedResponse: `{"sha1":"","sha256":"abcd","url":"http://example.com/stable/automate/download?eol=false&m=amd64&p=linux&v=latest","version":"latest"}`,
+		},
+		{
+			name:             "extended fields",
+			requestPath:      "/stable/automate/metadata?p=linux&m=amd64&v=latest&eol=false&fields=sha256,size,install_message,sbom_url",
+			expectedStatus:   fiber.StatusOK,
+			expectedResponse: `{"sha256":"abcd","size":1024,"install_message":"Thank you for installing","sbom_url":""}`,
+		},
+		{
+			name:             "unknown field",
+			requestPath:      "/stable/automate/metadata?p=linux&m=amd64&v=latest&eol=false&fields=sha1,md5",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"message":"unknown field md5, valid fields are sha1, sha256, url, version, filename, size, package_manager, install_message, signature_url, sbom_url","status_text":"Bad Request"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("base_url", "http://example.com")
+				return c.Next()
+			})
+
+			mockDbService := new(dboperations.MockIDbOperations)
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+				return &models.MetaData{
+					Architecture:   architecture,
+					Platform:       platform,
+					SHA256:         "abcd",
+					Size:           1024,
+					InstallMessage: "Thank you for installing",
+				}, nil
+			}
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+				return "latest", nil
+			}
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+				return []string{"latest"}, nil
+			}
+			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+			log := logrus.NewEntry(logrus.New())
+			handler := NewDownloadsHandler(log)
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/:channel/:product/metadata", func(c *fiber.Ctx) error {
+				return handler.ProductMetadataHandler(c)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, test.requestPath, nil)
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResponse, string(bodyBytes))
+		})
+	}
 }
 
 func TestProductPackagesHandler_DirectUrl(t *testing.T) {
@@ -1725,7 +1804,7 @@ func TestProductPackagesHandler_DirectUrl(t *testing.T) {
 	})
 
 	mockDbService := new(dboperations.MockIDbOperations)
-	mockDbService.GetPackagesfunc = func(partitionValue, sortValue string) (interface{}, error) {
+	mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
 		return &models.ProductDetails{
 			Product: "automate",
 			Version: "latest",
@@ -1736,7 +1815,7 @@ func TestProductPackagesHandler_DirectUrl(t *testing.T) {
 			}},
 		}, nil
 	}
-	mockDbService.GetMetaDatafunc = func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+	mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 		return &models.MetaData{
 			Architecture: architecture,
 			Platform:     platform,
@@ -1744,10 +1823,10 @@ func TestProductPackagesHandler_DirectUrl(t *testing.T) {
 			SHA256:       "abcd",
 		}, nil
 	}
-	mockDbService.GetVersionLatestfunc = func(partitionValue string) (string, error) {
+	mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
 		return "latest", nil
 	}
-	mockDbService.GetVersionAllfunc = func(partitionValue string) ([]string, error) {
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
 		return []string{"latest"}, nil
 	}
 	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
@@ -1775,15 +1854,15 @@ func TestProductFilesHandler(t *testing.T) {
 	t.Run("files route redirects for automate", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(testInjector(&dboperations.MockIDbOperations{
-			GetMetaDatafunc: func(partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
 				assert.Equal(t, "", packageManager)
 				assert.Equal(t, "amd64", architecture)
 				return &models.MetaData{FileName: "chef-automate_linux_amd64.zip"}, nil
 			},
-			GetVersionLatestfunc: func(partitionValue string) (string, error) {
+			GetVersionLatestfunc: func(ctx context.Context, partitionValue string) (string, error) {
 				return "latest", nil
 			},
-			GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			},
 			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -1799,7 +1878,7 @@ func TestProductFilesHandler(t *testing.T) {
 	t.Run("files route validation fails when arch cannot be inferred", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(testInjector(&dboperations.MockIDbOperations{
-			GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			},
 			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -1818,7 +1897,7 @@ func TestProductFilesHandler(t *testing.T) {
 	t.Run("files route validation fails when arch path and filename mismatch", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(testInjector(&dboperations.MockIDbOperations{
-			GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			},
 			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -1837,7 +1916,7 @@ func TestProductFilesHandler(t *testing.T) {
 	t.Run("files route validation fails when default strategy platform_version missing", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(testInjector(&dboperations.MockIDbOperations{
-			GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			},
 			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -1856,7 +1935,7 @@ func TestProductFilesHandler(t *testing.T) {
 	t.Run("files route validation fails when infra package manager missing", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(testInjector(&dboperations.MockIDbOperations{
-			GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 				return []string{"latest"}, nil
 			},
 			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -2051,8 +2130,8 @@ func TestRelatedProductsHandler_BrokenInjector(t *testing.T) {
 		ParamsFunc: func(params *omnitruck.RequestParams, ctx omnitruck.Context) []*omnitruck.ValidationError {
 			return nil
 		},
-		ErrorMessagesFunc: func(errors []*omnitruck.ValidationError) (string, int) {
-			return "", 0
+		ErrorMessagesFunc: func(errors []*omnitruck.ValidationError) (string, int, string) {
+			return "", 0, ""
 		},
 	})
 	// do not register other dependencies
@@ -2075,3 +2154,468 @@ func TestRelatedProductsHandler_BrokenInjector(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode, string(body))
 	assert.Contains(t, string(body), "Failed to create download service")
 }
+
+func TestProductMetadataBatchHandler(t *testing.T) {
+	mockDbService := new(dboperations.MockIDbOperations)
+	mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+		if architecture != "amd64" {
+			return nil, errors.New("ResourceNotFoundException: Requested resource not found")
+		}
+		return &models.MetaData{
+			Architecture: architecture,
+			Platform:     platform,
+			SHA256:       "abcd",
+		}, nil
+	}
+	mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+		return "latest", nil
+	}
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+		return []string{"latest"}, nil
+	}
+	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:           "mixed results keep input order",
+			body:           `{"items":[{"channel":"stable","product":"automate","p":"linux","m":"amd64","v":"latest"},{"channel":"beta","product":"automate","p":"linux","m":"amd64"},{"channel":"stable","product":"automate","p":"linux","m":"arm64","v":"latest"}]}`,
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"results":[
+				{"index":0,"request":{"channel":"stable","product":"automate","v":"latest","p":"linux","m":"amd64"},"metadata":{"sha1":"","sha256":"abcd","url":"http://example.com/stable/automate/download?eol=false&m=amd64&p=linux&v=latest","version":"latest"}},
+				{"index":1,"request":{"channel":"beta","product":"automate","p":"linux","m":"amd64"},"error":{"code":400,"status_text":"Bad Request","message":"Channel can only be stable or current"}},
+				{"index":2,"request":{"channel":"stable","product":"automate","v":"latest","p":"linux","m":"arm64"},"error":{"code":500,"status_text":"Internal Server Error","message":"Error while fetching the information for the product from DB."}}
+			]}`,
+		},
+		{
+			name:             "empty items",
+			body:             `{"items":[]}`,
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"items cannot be empty"}`,
+		},
+		{
+			name:             "invalid body",
+			body:             `{"items":`,
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"Invalid request body"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("base_url", "http://example.com")
+				return c.Next()
+			})
+			handler := NewDownloadsHandler(logrus.NewEntry(logrus.New()))
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Post("/metadata/batch", handler.ProductMetadataBatchHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/metadata/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedResponse, string(bodyBytes))
+		})
+	}
+}
+
+func TestVersionDetailsHandler(t *testing.T) {
+	mockDbService := new(dboperations.MockIDbOperations)
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+		return []string{"4.10.1", "4.13.0"}, nil
+	}
+	mockDbService.GetReleaseDatesfunc = func(ctx context.Context, partitionValue string) (map[string]string, error) {
+		return map[string]string{"4.13.0": "2024-05-02"}, nil
+	}
+	mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+		return &models.ProductDetails{
+			Product:  partitionValue,
+			Version:  sortValue,
+			MetaData: []models.MetaData{{Platform: "linux", Architecture: "x86_64"}},
+		}, nil
+	}
+	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:           "success",
+			query:          "order=desc&per_page=1",
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"versions":[
+				{"version":"4.13.0","release_date":"2024-05-02","eol":false,"open_source":true,"prerelease":false,"platforms":["linux"]}
+			],"page":1,"per_page":1,"total":2}`,
+		},
+		{
+			name:             "invalid page",
+			query:            "page=0",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"page must be a positive integer"}`,
+		},
+		{
+			name:             "per_page too large",
+			query:            "per_page=101",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"per_page must be between 1 and 100"}`,
+		},
+		{
+			name:             "invalid order",
+			query:            "order=newest",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"order must be asc or desc"}`,
+		},
+		{
+			name:             "invalid filter",
+			query:            "prerelease=maybe",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"prerelease must be true or false"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			handler := NewDownloadsHandler(logrus.NewEntry(logrus.New()))
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/:channel/:product/versions", handler.VersionDetailsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/stable/automate/versions?"+tt.query, nil)
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedResponse, string(bodyBytes))
+		})
+	}
+}
+
+func TestReleaseNotesHandlers(t *testing.T) {
+	mockDbService := new(dboperations.MockIDbOperations)
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+		return []string{"4.10.1", "4.13.0"}, nil
+	}
+	mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+		return &models.ProductDetails{Product: partitionValue, Version: sortValue, ReleaseNotes: "Notes for " + sortValue}, nil
+	}
+	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "single version",
+			path:             "/stable/automate/versions/latest/notes",
+			expectedStatus:   fiber.StatusOK,
+			expectedResponse: `{"version":"4.13.0","notes":"Notes for 4.13.0"}`,
+		},
+		{
+			name:             "invalid channel",
+			path:             "/beta/automate/versions/latest/notes",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"Channel can only be stable or current"}`,
+		},
+		{
+			name:             "range",
+			path:             "/stable/automate/notes?from=4.10.1",
+			expectedStatus:   fiber.StatusOK,
+			expectedResponse: `{"product":"automate","from":"4.10.1","to":"4.13.0","versions":[{"version":"4.13.0","notes":"Notes for 4.13.0"}],"notes":"## 4.13.0\n\nNotes for 4.13.0\n"}`,
+		},
+		{
+			name:             "range without from",
+			path:             "/stable/automate/notes?to=4.13.0",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"from is required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			handler := NewDownloadsHandler(logrus.NewEntry(logrus.New()))
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/:channel/:product/versions/:version/notes", handler.ReleaseNotesHandler)
+			app.Get("/:channel/:product/notes", handler.ReleaseNotesRangeHandler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedResponse, string(bodyBytes))
+		})
+	}
+}
+
+func TestProductSbomHandler(t *testing.T) {
+	tests := []struct {
+		name             string
+		accept           string
+		sbomUrl          string
+		expectedStatus   int
+		expectedLocation string
+		expectedResponse string
+	}{
+		{
+			name:             "redirects to catalog sbom",
+			sbomUrl:          "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+			expectedStatus:   fiber.StatusFound,
+			expectedLocation: "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+		},
+		{
+			name:             "requested format matches",
+			accept:           "application/vnd.cyclonedx+json",
+			sbomUrl:          "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+			expectedStatus:   fiber.StatusFound,
+			expectedLocation: "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+		},
+		{
+			name:             "generic json accepts any format",
+			accept:           "application/json",
+			sbomUrl:          "https://packages.example.com/automate_4.7.52-1_amd64.deb.spdx.json",
+			expectedStatus:   fiber.StatusFound,
+			expectedLocation: "https://packages.example.com/automate_4.7.52-1_amd64.deb.spdx.json",
+		},
+		{
+			name:             "requested format not published",
+			accept:           "application/spdx+json",
+			sbomUrl:          "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+			expectedStatus:   fiber.StatusNotAcceptable,
+			expectedResponse: `{"code":406,"message":"SBOM for automate latest is only available as application/vnd.cyclonedx+json","status_text":"Not Acceptable"}`,
+		},
+		{
+			name:             "unsupported media type",
+			accept:           "text/html",
+			sbomUrl:          "https://packages.example.com/automate_4.7.52-1_amd64.deb.cdx.json",
+			expectedStatus:   fiber.StatusNotAcceptable,
+			expectedResponse: `{"code":406,"message":"SBOMs are available as application/vnd.cyclonedx+json, application/spdx+json","status_text":"Not Acceptable"}`,
+		},
+		{
+			name:             "no sbom in catalog",
+			expectedStatus:   fiber.StatusNotFound,
+			expectedResponse: `{"code":404,"message":"No SBOM available for automate latest","status_text":"Not Found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("base_url", "http://example.com")
+				return c.Next()
+			})
+
+			mockDbService := new(dboperations.MockIDbOperations)
+			mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+				return &models.MetaData{
+					Architecture: architecture,
+					Platform:     platform,
+					SHA256:       "abcd",
+					SbomUrl:      test.sbomUrl,
+				}, nil
+			}
+			mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+				return "latest", nil
+			}
+			mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+				return []string{"latest"}, nil
+			}
+			mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+			log := logrus.NewEntry(logrus.New())
+			handler := NewDownloadsHandler(log)
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/:channel/:product/sbom", func(c *fiber.Ctx) error {
+				return handler.ProductSbomHandler(c)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/stable/automate/sbom?p=linux&m=amd64&v=latest&eol=false", nil)
+			if test.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, test.accept)
+			}
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			if test.expectedLocation != "" {
+				assert.Equal(t, test.expectedLocation, resp.Header.Get(fiber.HeaderLocation))
+			}
+			if test.expectedResponse != "" {
+				bodyBytes, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResponse, string(bodyBytes))
+			}
+		})
+	}
+}
+
+func TestSupportMatrixHandler(t *testing.T) {
+	mockDbService := new(dboperations.MockIDbOperations)
+	mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
+		return &models.ProductDetails{
+			Product: partitionValue,
+			Version: sortValue,
+			MetaData: []models.MetaData{
+				{Platform: "linux", Architecture: "x86_64", FileName: "automate_4.13.0_linux_amd64.zip", SHA256: "x86"},
+				{Platform: "linux", Architecture: "aarch64", FileName: "automate_4.13.0_linux_arm64.zip", SHA256: "arm"},
+			},
+		}, nil
+	}
+	mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+		return "4.13.0", nil
+	}
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+		return []string{"4.10.1", "4.13.0"}, nil
+	}
+	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:           "latest version",
+			query:          "",
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"product":"automate","version":"4.13.0","entries":[
+				{"platform":"linux","architecture":"aarch64","filename":"automate_4.13.0_linux_arm64.zip","sha256":"arm"},
+				{"platform":"linux","architecture":"x86_64","filename":"automate_4.13.0_linux_amd64.zip","sha256":"x86"}
+			]}`,
+		},
+		{
+			name:           "requested version",
+			query:          "v=4.10.1",
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"product":"automate","version":"4.10.1","entries":[
+				{"platform":"linux","architecture":"aarch64","filename":"automate_4.13.0_linux_arm64.zip","sha256":"arm"},
+				{"platform":"linux","architecture":"x86_64","filename":"automate_4.13.0_linux_amd64.zip","sha256":"x86"}
+			]}`,
+		},
+		{
+			name:             "unknown version",
+			query:            "v=1.0.0",
+			expectedStatus:   fiber.StatusBadRequest,
+			expectedResponse: `{"code":400,"status_text":"Bad Request","message":"the requested version is not supported on the selected persona or channel"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			handler := NewDownloadsHandler(logrus.NewEntry(logrus.New()))
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/:channel/:product/support-matrix", handler.SupportMatrixHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/stable/automate/support-matrix?"+tt.query, nil)
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedResponse, string(bodyBytes))
+		})
+	}
+}
+
+func TestProductPackagesV2Handler(t *testing.T) {
+	mockDbService := new(dboperations.MockIDbOperations)
+	mockDbService.GetPackagesfunc = func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
+		return &models.ProductDetails{
+			Product: partitionValue,
+			Version: sortValue,
+			MetaData: []models.MetaData{
+				{Platform: "linux", Architecture: "x86_64", FileName: "automate_linux_amd64.zip", SHA256: "x86", PackageManager: "tar"},
+				{Platform: "linux", Architecture: "aarch64", FileName: "automate_linux_arm64.zip", SHA256: "arm"},
+			},
+		}, nil
+	}
+	mockDbService.GetMetaDatafunc = func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+		return &models.MetaData{Platform: platform, Architecture: architecture, FileName: "automate_linux_" + architecture + ".zip"}, nil
+	}
+	mockDbService.GetVersionLatestfunc = func(ctx context.Context, partitionValue string) (string, error) {
+		return "4.13.0", nil
+	}
+	mockDbService.GetVersionAllfunc = func(ctx context.Context, partitionValue string) ([]string, error) {
+		return []string{"4.10.1", "4.13.0"}, nil
+	}
+	mockDbService.SetDbInfofunc = func(tableName string, dbModel reflect.Type) {}
+
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:           "typed packages",
+			path:           "/v2/stable/automate/packages?v=4.10.1",
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"product":"automate","version":"4.10.1","packages":[
+				{"platform":"linux","platform_version":"","arch":"aarch64","package_manager":"","version":"4.10.1","url":"http://example.com/stable/automate/download?eol=false&m=aarch64&p=linux&v=4.10.1","filename":"automate_linux_arm64.zip","sha256":"arm"},
+				{"platform":"linux","platform_version":"","arch":"x86_64","package_manager":"tar","version":"4.10.1","url":"http://example.com/stable/automate/download?eol=false&m=x86_64&p=linux&v=4.10.1","filename":"automate_linux_amd64.zip","sha256":"x86"}
+			]}`,
+		},
+		{
+			name:           "direct urls",
+			path:           "/v2/stable/automate/packages?direct=true",
+			expectedStatus: fiber.StatusOK,
+			expectedResponse: `{"product":"automate","version":"4.13.0","packages":[
+				{"platform":"linux","platform_version":"","arch":"aarch64","package_manager":"","version":"4.13.0","url":"http://example.com/files/stable/automate/4.13.0/linux/aarch64/automate_linux_aarch64.zip?license_id=","filename":"automate_linux_arm64.zip","sha256":"arm"},
+				{"platform":"linux","platform_version":"","arch":"x86_64","package_manager":"tar","version":"4.13.0","url":"http://example.com/files/stable/automate/4.13.0/linux/x86_64/automate_linux_x86_64.zip?license_id=","filename":"automate_linux_amd64.zip","sha256":"x86"}
+			]}`,
+		},
+		{
+			name:           "unknown version",
+			path:           "/v2/stable/automate/packages?v=1.0.0",
+			expectedStatus: fiber.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"code":"version.unsupported_for_mode",
+				"detail":"the requested version is not supported on the selected persona or channel","instance":"/v2/stable/automate/packages"}`,
+		},
+		{
+			name:           "invalid channel",
+			path:           "/v2/nightly/automate/packages",
+			expectedStatus: fiber.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"code":"channel.invalid",
+				"detail":"Channel can only be stable or current","instance":"/v2/nightly/automate/packages"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("base_url", "http://example.com")
+				return c.Next()
+			})
+			handler := NewDownloadsHandler(logrus.NewEntry(logrus.New()))
+			app.Use(testInjector(mockDbService, constants.Commercial, &template.MockTemplateRenderer{}))
+			app.Get("/v2/:channel/:product/packages", handler.ProductPackagesV2Handler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			resp, err := app.Test(req, 100*1000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			bodyBytes, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedResponse, string(bodyBytes))
+		})
+	}
+}
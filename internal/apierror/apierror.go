@@ -0,0 +1,210 @@
+// Package apierror classifies the errors of the catalog, the package storage
+// and the upstream clients, so handlers can answer them with an accurate
+// status and a message that does not leak upstream details.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/chef/omnitruck-service/utils"
+)
+
+// Kinds of error. Check them with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrThrottled    = errors.New("throttled")
+	ErrUnavailable  = errors.New("upstream unavailable")
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrAccessDenied is a backing service refusing the service's own
+	// credentials, not anything the user can change
+	ErrAccessDenied = errors.New("upstream access denied")
+)
+
+// Machine-readable error codes, answered by the /v2 API. Clients should
+// branch on these instead of the human readable message, which may change.
+const (
+	CodeRequestInvalid            = "request.invalid"
+	CodeParameterMissing          = "parameter.missing"
+	CodeChannelInvalid            = "channel.invalid"
+	CodeNotAcceptable             = "request.not_acceptable"
+	CodeThrottled                 = "request.throttled"
+	CodeForbidden                 = "access.forbidden"
+	CodeNotFound                  = "resource.not_found"
+	CodeRouteNotFound             = "route.not_found"
+	CodeProductNotFound           = "product.not_found"
+	CodeVersionNotFound           = "version.not_found"
+	CodeVersionEol                = "version.eol"
+	CodeVersionUnsupportedForMode = "version.unsupported_for_mode"
+	CodeLicenseMissing            = "license.missing"
+	CodeLicenseInvalid            = "license.invalid"
+	CodeLicenseNotAllowedForMode  = "license.not_allowed_for_mode"
+	CodeLicenseUnavailable        = "license.service_unavailable"
+	CodeCatalogUnavailable        = "catalog.unavailable"
+	CodeUpstreamUnavailable       = "upstream.unavailable"
+	CodeInternal                  = "internal.error"
+)
+
+var kinds = []struct {
+	kind   error
+	status int
+	msg    string
+	code   string
+}{
+	{ErrNotFound, http.StatusNotFound, utils.OmnitruckDataNotFoundError, CodeNotFound},
+	{ErrForbidden, http.StatusForbidden, utils.ForbiddenError, CodeForbidden},
+	{ErrThrottled, http.StatusTooManyRequests, utils.ThrottledError, CodeThrottled},
+	{ErrUnavailable, http.StatusServiceUnavailable, utils.UpstreamUnavailableError, CodeUpstreamUnavailable},
+	{ErrInvalidInput, http.StatusBadRequest, utils.InvalidInputError, CodeRequestInvalid},
+	{ErrAccessDenied, http.StatusBadGateway, utils.UpstreamAccessDeniedError, CodeUpstreamUnavailable},
+}
+
+// Error is an error marked with its kind
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Wrap marks err with kind, keeping err in the chain. A nil err stays nil.
+func Wrap(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// coded is an error carrying the code it was created with
+type coded struct {
+	code string
+	err  error
+}
+
+func (e *coded) Error() string {
+	return e.err.Error()
+}
+
+func (e *coded) Unwrap() error {
+	return e.err
+}
+
+// WithCode attaches a machine-readable code to err, keeping err in the chain.
+// A nil err stays nil.
+func WithCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &coded{code: code, err: err}
+}
+
+// CodeOf returns the code attached to err, falling back to the code of its
+// kind. It is empty for errors created without one.
+func CodeOf(err error) string {
+	var c *coded
+	if errors.As(err, &c) {
+		return c.code
+	}
+	for _, k := range kinds {
+		if errors.Is(err, k.kind) {
+			return k.code
+		}
+	}
+	return ""
+}
+
+// KindOf returns the kind of err, nil when it is not classified
+func KindOf(err error) error {
+	for _, k := range kinds {
+		if errors.Is(err, k.kind) {
+			return k.kind
+		}
+	}
+	return nil
+}
+
+// Status returns the HTTP status answered for the kind of err and a message
+// safe to show to users. ok is false when err is not classified.
+func Status(err error) (code int, msg string, ok bool) {
+	for _, k := range kinds {
+		if errors.Is(err, k.kind) {
+			return k.status, k.msg, true
+		}
+	}
+	return http.StatusInternalServerError, "", false
+}
+
+// FromStatus classifies an error answered by an upstream HTTP service
+func FromStatus(status int, err error) error {
+	switch {
+	case status == http.StatusNotFound:
+		return Wrap(ErrNotFound, err)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return Wrap(ErrForbidden, err)
+	case status == http.StatusTooManyRequests:
+		return Wrap(ErrThrottled, err)
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return Wrap(ErrInvalidInput, err)
+	case status >= 500:
+		return Wrap(ErrUnavailable, err)
+	}
+	return err
+}
+
+// FromAWS classifies an error of an AWS SDK call by its error code, falling
+// back to the HTTP status. Access errors are reported as ErrAccessDenied
+// rather than forbidden: they come from the service's own credentials, not
+// from anything the user can change. A missing table or bucket is a
+// deployment problem, not a missing product, so it is reported as
+// unavailable.
+func FromAWS(err error) error {
+	if err == nil || KindOf(err) != nil {
+		return err
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound":
+			return Wrap(ErrNotFound, err)
+		case "NoSuchBucket", "ResourceNotFoundException":
+			return Wrap(ErrUnavailable, err)
+		case "ThrottlingException", "Throttling", "ProvisionedThroughputExceededException", "RequestLimitExceeded", "SlowDown", "TooManyRequestsException":
+			return Wrap(ErrThrottled, err)
+		case "ValidationException", "InvalidParameterValue", "InvalidRequest":
+			return Wrap(ErrInvalidInput, err)
+		case "AccessDenied", "AccessDeniedException", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch",
+			"UnrecognizedClientException", "ExpiredToken", "ExpiredTokenException":
+			return Wrap(ErrAccessDenied, err)
+		}
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		if status := respErr.HTTPStatusCode(); status == http.StatusForbidden {
+			return Wrap(ErrAccessDenied, err)
+		} else if classified := FromStatus(status, err); classified != err {
+			return classified
+		}
+	}
+	return FromTransport(err)
+}
+
+// FromTransport marks network failures and deadlines as unavailable
+func FromTransport(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return Wrap(ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/chef/omnitruck-service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("no versions")
+	err := fmt.Errorf("fetching versions: %w", Wrap(ErrNotFound, cause))
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, ErrNotFound, KindOf(err))
+	assert.Equal(t, "fetching versions: no versions", err.Error())
+	assert.Nil(t, Wrap(ErrNotFound, nil))
+	assert.Nil(t, KindOf(cause))
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "attached code", err: WithCode(CodeChannelInvalid, errors.New("bad channel")), want: CodeChannelInvalid},
+		{name: "wrapped attached code", err: fmt.Errorf("validating: %w", WithCode(CodeVersionEol, errors.New("eol"))), want: CodeVersionEol},
+		{name: "attached code wins over the kind", err: WithCode(CodeCatalogUnavailable, Wrap(ErrUnavailable, errors.New("down"))), want: CodeCatalogUnavailable},
+		{name: "kind", err: Wrap(ErrThrottled, errors.New("slow down")), want: CodeThrottled},
+		{name: "plain", err: errors.New("boom"), want: ""},
+		{name: "nil", err: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeOf(tt.err))
+		})
+	}
+	assert.Nil(t, WithCode(CodeInternal, nil))
+	assert.Equal(t, "bad channel", WithCode(CodeChannelInvalid, errors.New("bad channel")).Error())
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		kind error
+		code int
+		msg  string
+	}{
+		{ErrNotFound, http.StatusNotFound, utils.OmnitruckDataNotFoundError},
+		{ErrForbidden, http.StatusForbidden, utils.ForbiddenError},
+		{ErrThrottled, http.StatusTooManyRequests, utils.ThrottledError},
+		{ErrUnavailable, http.StatusServiceUnavailable, utils.UpstreamUnavailableError},
+		{ErrInvalidInput, http.StatusBadRequest, utils.InvalidInputError},
+		{ErrAccessDenied, http.StatusBadGateway, utils.UpstreamAccessDeniedError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.Error(), func(t *testing.T) {
+			code, msg, ok := Status(Wrap(tt.kind, errors.New("upstream detail")))
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.msg, msg)
+		})
+	}
+
+	code, msg, ok := Status(errors.New("boom"))
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Empty(t, msg)
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrForbidden},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusTooManyRequests, ErrThrottled},
+		{http.StatusBadRequest, ErrInvalidInput},
+		{http.StatusBadGateway, ErrUnavailable},
+		{http.StatusConflict, nil},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(FromStatus(tt.status, errors.New("failed"))))
+		})
+	}
+}
+
+func TestFromAWS(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "missing table", err: &types.ResourceNotFoundException{Message: aws.String("not found")}, want: ErrUnavailable},
+		{name: "missing bucket", err: &smithy.GenericAPIError{Code: "NoSuchBucket"}, want: ErrUnavailable},
+		{name: "missing object", err: &smithy.GenericAPIError{Code: "NoSuchKey"}, want: ErrNotFound},
+		{name: "throughput", err: &types.ProvisionedThroughputExceededException{Message: aws.String("slow down")}, want: ErrThrottled},
+		{name: "validation", err: &smithy.GenericAPIError{Code: "ValidationException"}, want: ErrInvalidInput},
+		{name: "access denied", err: &smithy.GenericAPIError{Code: "AccessDeniedException"}, want: ErrAccessDenied},
+		{name: "deadline", err: fmt.Errorf("scan: %w", context.DeadlineExceeded), want: ErrUnavailable},
+		{name: "unknown code", err: &smithy.GenericAPIError{Code: "ConditionalCheckFailedException"}, want: nil},
+		{name: "already classified", err: Wrap(ErrNotFound, errors.New("no versions")), want: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(FromAWS(tt.err)))
+		})
+	}
+	assert.Nil(t, FromAWS(nil))
+}
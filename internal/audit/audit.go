@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chef/omnitruck-service/config"
+)
+
+const (
+	SinkTypeFile    = "file"
+	SinkTypeStdout  = "stdout"
+	SinkTypeWebhook = "webhook"
+
+	EndpointDownload = "download"
+	EndpointFiles    = "files"
+	EndpointSbom     = "sbom"
+)
+
+// Event is a structured record of a single download served through one of the
+// product strategies. It is emitted once the strategy has produced either a
+// redirect URL, a streamed body or an error.
+type Event struct {
+	Timestamp       time.Time `json:"timestamp"`
+	RequestId       string    `json:"request_id"`
+	LicenseId       string    `json:"license_id"`
+	Mode            string    `json:"mode"`
+	Endpoint        string    `json:"endpoint"`
+	Channel         string    `json:"channel"`
+	Product         string    `json:"product"`
+	Version         string    `json:"version"`
+	Platform        string    `json:"platform"`
+	PlatformVersion string    `json:"platform_version"`
+	Architecture    string    `json:"architecture"`
+	PackageManager  string    `json:"package_manager"`
+	FileName        string    `json:"filename"`
+	Strategy        string    `json:"strategy"`
+	Status          int       `json:"status"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Sink receives download audit events. Close flushes pending events and
+// releases the sink; it is called once when the server shuts down.
+type Sink interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Sinks fans an event out to every configured sink. A failing sink does not
+// prevent the remaining sinks from receiving the event.
+type Sinks []Sink
+
+func (s Sinks) Write(event Event) error {
+	var errs []error
+	for _, sink := range s {
+		if err := sink.Write(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s Sinks) Close() error {
+	var errs []error
+	for _, sink := range s {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NopSink discards every event. It is used when auditing is not configured.
+type NopSink struct{}
+
+func (NopSink) Write(Event) error {
+	return nil
+}
+
+func (NopSink) Close() error {
+	return nil
+}
+
+// NewFromConfig builds the sink chain described by the audit section of the
+// service config. An empty config results in a NopSink.
+func NewFromConfig(cfg config.AuditConfig) (Sink, error) {
+	if len(cfg.Sinks) == 0 {
+		return NopSink{}, nil
+	}
+
+	sinks := Sinks{}
+	for _, sc := range cfg.Sinks {
+		switch strings.ToLower(sc.Type) {
+		case SinkTypeStdout:
+			sinks = append(sinks, NewStdoutSink())
+		case SinkTypeFile:
+			sink, err := NewFileSink(sc.Path)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case SinkTypeWebhook:
+			if sc.URL == "" {
+				return nil, fmt.Errorf("audit webhook sink requires a url")
+			}
+			sinks = append(sinks, NewAsyncSink(NewWebhookSink(sc.URL, sc.Headers), sc.BufferSize))
+		default:
+			return nil, fmt.Errorf("unknown audit sink type %q", sc.Type)
+		}
+	}
+
+	return sinks, nil
+}
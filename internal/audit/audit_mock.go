@@ -0,0 +1,31 @@
+package audit
+
+import "sync"
+
+type MockSink struct {
+	mu        sync.Mutex
+	WriteFunc func(event Event) error
+	CloseFunc func() error
+	Events    []Event
+	Closed    bool
+}
+
+func (m *MockSink) Write(event Event) error {
+	m.mu.Lock()
+	m.Events = append(m.Events, event)
+	m.mu.Unlock()
+	if m.WriteFunc != nil {
+		return m.WriteFunc(event)
+	}
+	return nil
+}
+
+func (m *MockSink) Close() error {
+	m.mu.Lock()
+	m.Closed = true
+	m.mu.Unlock()
+	if m.CloseFunc != nil {
+		return m.CloseFunc()
+	}
+	return nil
+}
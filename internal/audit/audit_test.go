@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() Event {
+	return Event{
+		Timestamp:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		LicenseId:    "lic-1",
+		Mode:         "commercial",
+		Endpoint:     EndpointDownload,
+		Channel:      "stable",
+		Product:      "chef-ice",
+		Version:      "19.1.0",
+		Platform:     "linux",
+		Architecture: "x86_64",
+		FileName:     "chef-ice-19.1.0.rpm",
+		Strategy:     "s3",
+		Status:       200,
+	}
+}
+
+func TestWriterSink_WritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	require.NoError(t, sink.Write(testEvent()))
+	require.NoError(t, sink.Write(testEvent()))
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var got Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		assert.Equal(t, testEvent(), got)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestFileSink(t *testing.T) {
+	t.Run("appends to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(testEvent()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"product":"chef-ice"`)
+	})
+
+	t.Run("requires a path", func(t *testing.T) {
+		_, err := NewFileSink("")
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		expectErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "rejected", status: http.StatusInternalServerError, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "secret", r.Header.Get("X-Token"))
+				body, _ := io.ReadAll(r.Body)
+				var got Event
+				assert.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, "lic-1", got.LicenseId)
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			err := NewWebhookSink(ts.URL, map[string]string{"X-Token": "secret"}).Write(testEvent())
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAsyncSink_DeliversQueuedEvents(t *testing.T) {
+	mock := &MockSink{}
+	sink := NewAsyncSink(mock, 4)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sink.Write(testEvent()))
+	}
+	require.NoError(t, sink.Close())
+	assert.Len(t, mock.Events, 3)
+	assert.True(t, mock.Closed)
+
+	assert.ErrorIs(t, sink.Write(testEvent()), ErrClosed)
+	assert.NoError(t, sink.Close(), "closing twice is a no-op")
+}
+
+func TestSinks_FanOut(t *testing.T) {
+	first := &MockSink{WriteFunc: func(Event) error { return errors.New("boom") }}
+	second := &MockSink{}
+
+	err := Sinks{first, second}.Write(testEvent())
+
+	assert.EqualError(t, err, "boom")
+	assert.Len(t, first.Events, 1)
+	assert.Len(t, second.Events, 1)
+}
+
+func TestSinks_Close(t *testing.T) {
+	first := &MockSink{CloseFunc: func() error { return errors.New("boom") }}
+	second := &MockSink{}
+
+	err := Sinks{first, second}.Close()
+
+	assert.EqualError(t, err, "boom")
+	assert.True(t, first.Closed)
+	assert.True(t, second.Closed)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.AuditConfig
+		expectErr bool
+		expectNop bool
+		expectLen int
+	}{
+		{name: "empty config", cfg: config.AuditConfig{}, expectNop: true},
+		{
+			name: "all sink types",
+			cfg: config.AuditConfig{Sinks: []config.AuditSinkConfig{
+				{Type: "stdout"},
+				{Type: "file", Path: filepath.Join(t.TempDir(), "audit.log")},
+				{Type: "webhook", URL: "http://localhost/audit"},
+			}},
+			expectLen: 3,
+		},
+		{
+			name:      "webhook without url",
+			cfg:       config.AuditConfig{Sinks: []config.AuditSinkConfig{{Type: "webhook"}}},
+			expectErr: true,
+		},
+		{
+			name:      "unknown type",
+			cfg:       config.AuditConfig{Sinks: []config.AuditSinkConfig{{Type: "kafka"}}},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewFromConfig(tt.cfg)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNop {
+				assert.IsType(t, NopSink{}, sink)
+				return
+			}
+			assert.Len(t, sink, tt.expectLen)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultAsyncBufferSize = 1024
+
+// WriterSink writes each event as a single JSON line to the wrapped writer.
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// NewFileSink appends JSON lines to the file at path, creating it if needed.
+func NewFileSink(path string) (*WriterSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit file sink requires a path")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit file %s: %w", path, err)
+	}
+	return &WriterSink{w: f, closer: f}, nil
+}
+
+func (s *WriterSink) Write(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// Close closes the file of a file sink. Other writers are left open.
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WebhookSink POSTs each event as JSON to a remote endpoint.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string
+	Client  HTTPClient
+}
+
+func NewWebhookSink(url string, headers map[string]string) *WebhookSink {
+	return &WebhookSink{
+		URL:     url,
+		Headers: headers,
+		Client: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+func (s *WebhookSink) Write(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// AsyncSink queues events and hands them to the wrapped sink from a single
+// background goroutine so slow sinks do not hold up the download response.
+// Events are dropped, and logged, when the queue is full.
+type AsyncSink struct {
+	sink   Sink
+	queue  chan Event
+	log    *log.Entry
+	closed chan struct{}
+
+	mu       sync.RWMutex
+	stopping bool
+}
+
+var (
+	ErrQueueFull = errors.New("audit queue is full, event dropped")
+	ErrClosed    = errors.New("audit sink is closed, event dropped")
+)
+
+func NewAsyncSink(sink Sink, bufferSize int) *AsyncSink {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	s := &AsyncSink{
+		sink:   sink,
+		queue:  make(chan Event, bufferSize),
+		log:    log.WithField("pkg", "audit"),
+		closed: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.closed)
+	for event := range s.queue {
+		if err := s.sink.Write(event); err != nil {
+			s.log.WithError(err).Error("Unable to deliver audit event")
+		}
+	}
+}
+
+func (s *AsyncSink) Write(event Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopping {
+		return ErrClosed
+	}
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting events, waits for queued events to be delivered and
+// closes the wrapped sink.
+func (s *AsyncSink) Close() error {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.closed
+	return s.sink.Close()
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries bounds a cache created without a maximum entry count
+const DefaultMaxEntries = 10000
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// Cache is a small in-memory TTL cache safe for concurrent use. Expired
+// entries are swept on Set at most once per TTL, and a full cache evicts the
+// entry closest to expiring.
+type Cache struct {
+	name       string
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.RWMutex
+	items      map[string]entry
+	nextSweep  time.Time
+	now        func() time.Time
+}
+
+// Stats describes the current contents of a cache for introspection.
+type Stats struct {
+	Name       string       `json:"name"`
+	TTL        string       `json:"ttl"`
+	Entries    int          `json:"entries"`
+	MaxEntries int          `json:"maxEntries"`
+	Keys       []EntryStats `json:"keys"`
+}
+
+type EntryStats struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires"`
+}
+
+// New returns a cache keeping entries for ttl and holding at most maxEntries
+// of them, DefaultMaxEntries when maxEntries is not positive
+func New(name string, ttl time.Duration, maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Cache{
+		name:       name,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		items:      map[string]entry{},
+		now:        time.Now,
+	}
+}
+
+func (c *Cache) Name() string {
+	return c.name
+}
+
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+	if !ok || c.now().After(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !now.Before(c.nextSweep) {
+		c.sweep(now)
+		c.nextSweep = now.Add(c.ttl)
+	}
+	if _, ok := c.items[key]; !ok && len(c.items) >= c.maxEntries {
+		c.sweep(now)
+		if len(c.items) >= c.maxEntries {
+			c.evict()
+		}
+	}
+	c.items[key] = entry{value: value, expires: now.Add(c.ttl)}
+}
+
+// sweep removes the expired entries. The caller holds the write lock.
+func (c *Cache) sweep(now time.Time) {
+	for k, e := range c.items {
+		if now.After(e.expires) {
+			delete(c.items, k)
+		}
+	}
+}
+
+// evict removes the entry closest to expiring, which is the oldest one as
+// every entry lives for the same TTL. The caller holds the write lock.
+func (c *Cache) evict() {
+	var oldest string
+	var expires time.Time
+	found := false
+	for k, e := range c.items {
+		if !found || e.expires.Before(expires) {
+			oldest, expires, found = k, e.expires, true
+		}
+	}
+	delete(c.items, oldest)
+}
+
+// Purge removes every entry from the cache and returns how many were removed.
+func (c *Cache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.items)
+	c.items = map[string]entry{}
+	return n
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	keys := []EntryStats{}
+	for k, e := range c.items {
+		if now.After(e.expires) {
+			continue
+		}
+		keys = append(keys, EntryStats{Key: k, Expires: e.expires})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+
+	return Stats{
+		Name:       c.name,
+		TTL:        c.ttl.String(),
+		Entries:    len(keys),
+		MaxEntries: c.maxEntries,
+		Keys:       keys,
+	}
+}
+
+// Registry keeps track of the caches owned by a server so they can be
+// inspected and purged as a group.
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]*Cache
+}
+
+func NewRegistry() *Registry {
+	return &Registry{caches: map[string]*Cache{}}
+}
+
+func (r *Registry) Register(c *Cache) *Cache {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.caches[c.Name()] = c
+	return c
+}
+
+func (r *Registry) Get(name string) (*Cache, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.caches[name]
+	return c, ok
+}
+
+// Purge empties the named cache, or every cache when name is empty.
+// It returns the number of entries removed per cache.
+func (r *Registry) Purge(name string) map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	purged := map[string]int{}
+	for n, c := range r.caches {
+		if name == "" || name == n {
+			purged[n] = c.Purge()
+		}
+	}
+	return purged
+}
+
+func (r *Registry) Stats() []Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]Stats, 0, len(r.caches))
+	for _, c := range r.caches {
+		stats = append(stats, c.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
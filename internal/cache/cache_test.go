@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetSetExpire(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New("catalog", time.Minute, 0)
+	c.now = func() time.Time { return now }
+
+	_, ok := c.Get("products")
+	assert.False(t, ok)
+
+	c.Set("products", []byte(`["chef"]`))
+	v, ok := c.Get("products")
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`["chef"]`), v)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get("products")
+	assert.False(t, ok, "expired entries should not be returned")
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestCache_PurgeAndStats(t *testing.T) {
+	c := New("catalog", time.Minute, 0)
+	c.Set("b", 1)
+	c.Set("a", 2)
+
+	stats := c.Stats()
+	assert.Equal(t, "catalog", stats.Name)
+	assert.Equal(t, "1m0s", stats.TTL)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, "a", stats.Keys[0].Key)
+
+	assert.Equal(t, 2, c.Purge())
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestCache_SetSweepsExpired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New("catalog", time.Minute, 0)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	now = now.Add(2 * time.Minute)
+	c.Set("c", 3)
+
+	assert.Len(t, c.items, 1)
+	_, ok := c.Get("c")
+	assert.True(t, ok)
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New("catalog", time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(time.Second)
+	c.Set("b", 2)
+	now = now.Add(time.Second)
+	c.Set("a", 3)
+	assert.Len(t, c.items, 2, "replacing a key does not evict")
+
+	now = now.Add(time.Second)
+	c.Set("c", 4)
+	assert.Len(t, c.items, 2)
+	_, ok := c.Get("b")
+	assert.False(t, ok, "the entry closest to expiring is evicted")
+	v, _ := c.Get("a")
+	assert.Equal(t, 3, v)
+	assert.Equal(t, 2, c.Stats().MaxEntries)
+	assert.Equal(t, DefaultMaxEntries, New("notes", time.Minute, 0).Stats().MaxEntries)
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	catalog := r.Register(New("catalog", time.Minute, 0))
+	notes := r.Register(New("notes", time.Minute, 0))
+	catalog.Set("products", 1)
+	notes.Set("chef/18.0.0", 1)
+	notes.Set("chef/18.1.0", 1)
+
+	got, ok := r.Get("catalog")
+	assert.True(t, ok)
+	assert.Same(t, catalog, got)
+
+	assert.Len(t, r.Stats(), 2)
+	assert.Equal(t, map[string]int{"catalog": 1}, r.Purge("catalog"))
+	assert.Equal(t, map[string]int{"catalog": 0, "notes": 2}, r.Purge(""))
+}
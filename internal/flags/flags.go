@@ -0,0 +1,289 @@
+package flags
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/constants"
+	log "github.com/sirupsen/logrus"
+)
+
+// Infra19 gates the Chef Infra 19 product family, which is served from S3
+// instead of Omnitruck when enabled.
+const Infra19 = "infra19"
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flag is a named runtime toggle. A flag can be overridden per server mode and
+// gates the products listed in Products: while it is off those products are
+// hidden from product listings and served by the default strategy. While it is
+// on they are served by Strategy, when set, and added to product listings.
+type Flag struct {
+	Name     string            `json:"name"`
+	Enabled  bool              `json:"enabled"`
+	Modes    map[string]bool   `json:"modes,omitempty"`
+	Products []string          `json:"products,omitempty"`
+	Rewrites map[string]string `json:"rewrites,omitempty"`
+	Block    bool              `json:"block,omitempty"`
+	Strategy string            `json:"strategy,omitempty"`
+}
+
+// EnabledFor reports whether the flag is on for the given mode
+func (f Flag) EnabledFor(mode constants.ApiType) bool {
+	if enabled, ok := f.Modes[mode.String()]; ok {
+		return enabled
+	}
+	return f.Enabled
+}
+
+func (f Flag) gates(product string) bool {
+	for _, p := range f.Products {
+		if p == product {
+			return true
+		}
+	}
+	return false
+}
+
+type FeatureFlags interface {
+	Enabled(name string, mode constants.ApiType) bool
+	ProductEnabled(product string, mode constants.ApiType) bool
+	BlockedBy(product string, mode constants.ApiType) (string, bool)
+	FilterProducts(products []string, mode constants.ApiType) []string
+	RewriteProducts(products []string, mode constants.ApiType) []string
+	ProductStrategy(product string, mode constants.ApiType) string
+	RoutedProducts() []string
+}
+
+type Registry struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+func New(flags ...Flag) *Registry {
+	r := &Registry{flags: map[string]Flag{}}
+	for _, f := range flags {
+		r.flags[f.Name] = f
+	}
+	return r
+}
+
+// infra19Strategy is the name of the strategy serving the infra19 products
+// from S3
+const infra19Strategy = "s3"
+
+// routableStrategies names the strategies a flag can route products to. They
+// mirror the strategy names of the strategy package, which imports this one.
+var routableStrategies = map[string]bool{
+	"dynamo":        true,
+	"replicated":    true,
+	infra19Strategy: true,
+}
+
+// infra19Defaults returns the infra19 flag as it behaved when it was the
+// SupportInfra19 config switch.
+func infra19Defaults(enabled bool) Flag {
+	return Flag{
+		Name:     Infra19,
+		Enabled:  enabled,
+		Strategy: infra19Strategy,
+		Products: []string{
+			constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT,
+			constants.MIGRATE_ICE,
+			constants.CHEF_INSPEC_ENTERPRISE_PRODUCT,
+			constants.CHEF_WORKSTATION_ENTERPRISE,
+		},
+		Rewrites: map[string]string{
+			constants.CHEF_INFRA_CLIENT_NEW_VALUE: constants.CHEF_INFRA_CLIENT_OLD_VALUE,
+		},
+	}
+}
+
+// FromConfig builds the registry from the featureFlags section of the service
+// config. The infra19 flag is always present; when it is not configured
+// explicitly it follows SupportInfra19. A strategy the products cannot be
+// routed to is logged and ignored, leaving them with the default strategy.
+func FromConfig(cfg config.ServiceConfig) *Registry {
+	r := New(infra19Defaults(cfg.SupportInfra19))
+
+	for name, fc := range cfg.FeatureFlags {
+		f := Flag{
+			Name:     name,
+			Enabled:  fc.Enabled,
+			Modes:    fc.Modes,
+			Products: fc.Products,
+			Rewrites: fc.Rewrites,
+			Block:    fc.Block,
+			Strategy: fc.Strategy,
+		}
+		if f.Strategy != "" && !routableStrategies[f.Strategy] {
+			log.WithField("pkg", "flags").Warnf("Ignoring unknown strategy %q of feature flag %s", f.Strategy, name)
+			f.Strategy = ""
+		}
+		if name == Infra19 {
+			defaults := infra19Defaults(fc.Enabled)
+			if len(f.Products) == 0 {
+				f.Products = defaults.Products
+			}
+			if len(f.Rewrites) == 0 {
+				f.Rewrites = defaults.Rewrites
+			}
+			if f.Strategy == "" {
+				f.Strategy = defaults.Strategy
+			}
+		}
+		r.flags[name] = f
+	}
+
+	return r
+}
+
+func (r *Registry) Get(name string) (Flag, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.flags[name]
+	return f, ok
+}
+
+func (r *Registry) List() []Flag {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Flag, 0, len(r.flags))
+	for _, f := range r.flags {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Set turns the named flag on or off for a single mode
+func (r *Registry) Set(name string, mode constants.ApiType, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.flags[name]
+	if !ok {
+		return ErrUnknownFlag
+	}
+
+	modes := make(map[string]bool, len(f.Modes)+1)
+	for k, v := range f.Modes {
+		modes[k] = v
+	}
+	modes[mode.String()] = enabled
+	f.Modes = modes
+	r.flags[name] = f
+
+	return nil
+}
+
+func (r *Registry) Enabled(name string, mode constants.ApiType) bool {
+	f, ok := r.Get(name)
+	return ok && f.EnabledFor(mode)
+}
+
+// ProductEnabled reports whether every flag gating product is on for mode.
+// Products without a gating flag are always enabled.
+func (r *Registry) ProductEnabled(product string, mode constants.ApiType) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.flags {
+		if f.gates(product) && !f.EnabledFor(mode) {
+			return false
+		}
+	}
+	return true
+}
+
+// BlockedBy returns the name of a disabled blocking flag gating product, if any
+func (r *Registry) BlockedBy(product string, mode constants.ApiType) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.flags {
+		if f.Block && f.gates(product) && !f.EnabledFor(mode) {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+// FilterProducts drops products gated by a disabled flag
+func (r *Registry) FilterProducts(products []string, mode constants.ApiType) []string {
+	filtered := products[:0:0]
+	for _, p := range products {
+		if r.ProductEnabled(p, mode) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// RewriteProducts applies the display rewrites of every disabled flag
+func (r *Registry) RewriteProducts(products []string, mode constants.ApiType) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rewrites := map[string]string{}
+	for _, f := range r.flags {
+		if f.EnabledFor(mode) {
+			continue
+		}
+		for from, to := range f.Rewrites {
+			rewrites[from] = to
+		}
+	}
+	if len(rewrites) == 0 {
+		return products
+	}
+
+	rewritten := products[:0:0]
+	for _, p := range products {
+		if to, ok := rewrites[p]; ok {
+			p = to
+		}
+		rewritten = append(rewritten, p)
+	}
+	return rewritten
+}
+
+// ProductStrategy returns the strategy a flag routes product to, or "" when no
+// flag does or product is disabled for mode
+func (r *Registry) ProductStrategy(product string, mode constants.ApiType) string {
+	if !r.ProductEnabled(product, mode) {
+		return ""
+	}
+	for _, f := range r.List() {
+		if f.Strategy != "" && f.gates(product) {
+			return f.Strategy
+		}
+	}
+	return ""
+}
+
+// RoutedProducts returns the products flags route to a strategy. They are not
+// known to Omnitruck, so product listings add them.
+func (r *Registry) RoutedProducts() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{}
+	products := []string{}
+	for _, f := range r.flags {
+		if f.Strategy == "" {
+			continue
+		}
+		for _, p := range f.Products {
+			if !seen[p] {
+				seen[p] = true
+				products = append(products, p)
+			}
+		}
+	}
+	sort.Strings(products)
+	return products
+}
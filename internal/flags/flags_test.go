@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/chef/omnitruck-service/config"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromConfig_Infra19(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.ServiceConfig
+		expected map[constants.ApiType]bool
+	}{
+		{
+			name:     "follows SupportInfra19 when not configured",
+			cfg:      config.ServiceConfig{SupportInfra19: true},
+			expected: map[constants.ApiType]bool{constants.Trial: true, constants.Commercial: true},
+		},
+		{
+			name: "explicit flag with mode override wins",
+			cfg: config.ServiceConfig{
+				SupportInfra19: true,
+				FeatureFlags: map[string]config.FeatureFlagConfig{
+					Infra19: {Enabled: false, Modes: map[string]bool{"commercial": true}},
+				},
+			},
+			expected: map[constants.ApiType]bool{constants.Trial: false, constants.Commercial: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := FromConfig(tt.cfg)
+			for mode, enabled := range tt.expected {
+				assert.Equal(t, enabled, r.Enabled(Infra19, mode), mode.String())
+			}
+			f, ok := r.Get(Infra19)
+			require.True(t, ok)
+			assert.Contains(t, f.Products, constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT)
+			assert.Equal(t, constants.CHEF_INFRA_CLIENT_OLD_VALUE, f.Rewrites[constants.CHEF_INFRA_CLIENT_NEW_VALUE])
+		})
+	}
+}
+
+func TestRegistry_ProductsFilterAndRewrite(t *testing.T) {
+	r := FromConfig(config.ServiceConfig{
+		FeatureFlags: map[string]config.FeatureFlagConfig{
+			"next": {Enabled: true, Products: []string{"chef-next"}, Block: true},
+		},
+	})
+	products := []string{"chef", "chef-ice", "chef-next", constants.CHEF_INFRA_CLIENT_NEW_VALUE}
+
+	assert.Equal(t, []string{"chef", "chef-next", constants.CHEF_INFRA_CLIENT_NEW_VALUE}, r.FilterProducts(products, constants.Commercial))
+	assert.Equal(t, []string{"chef", "chef-ice", "chef-next", constants.CHEF_INFRA_CLIENT_OLD_VALUE}, r.RewriteProducts(products, constants.Commercial))
+	assert.False(t, r.ProductEnabled("chef-ice", constants.Commercial))
+	assert.True(t, r.ProductEnabled("chef", constants.Commercial))
+
+	_, blocked := r.BlockedBy("chef-ice", constants.Commercial)
+	assert.False(t, blocked, "infra19 does not block requests")
+
+	require.NoError(t, r.Set("next", constants.Trial, false))
+	name, blocked := r.BlockedBy("chef-next", constants.Trial)
+	assert.True(t, blocked)
+	assert.Equal(t, "next", name)
+	_, blocked = r.BlockedBy("chef-next", constants.Commercial)
+	assert.False(t, blocked)
+
+	assert.ErrorIs(t, r.Set("unknown", constants.Trial, true), ErrUnknownFlag)
+}
+
+func TestRegistry_ProductStrategy(t *testing.T) {
+	r := FromConfig(config.ServiceConfig{
+		SupportInfra19: true,
+		FeatureFlags: map[string]config.FeatureFlagConfig{
+			"next":  {Enabled: true, Modes: map[string]bool{"trial": false}, Products: []string{"chef-next"}, Strategy: "s3"},
+			"other": {Enabled: true, Products: []string{"chef"}},
+		},
+	})
+
+	assert.Equal(t, "s3", r.ProductStrategy(constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT, constants.Commercial))
+	assert.Equal(t, "s3", r.ProductStrategy("chef-next", constants.Commercial))
+	assert.Equal(t, "", r.ProductStrategy("chef-next", constants.Trial), "disabled flags do not route")
+	assert.Equal(t, "", r.ProductStrategy("chef", constants.Commercial), "flags without a strategy do not route")
+
+	assert.Equal(t, []string{
+		constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT,
+		"chef-next",
+		constants.CHEF_WORKSTATION_ENTERPRISE,
+		constants.CHEF_INSPEC_ENTERPRISE_PRODUCT,
+		constants.MIGRATE_ICE,
+	}, r.RoutedProducts())
+}
+
+func TestFromConfig_Strategy(t *testing.T) {
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{strategy: "dynamo", expected: "dynamo"},
+		{strategy: "replicated", expected: "replicated"},
+		{strategy: "s3", expected: "s3"},
+		{strategy: "S3", expected: ""},
+		{strategy: "mirror", expected: ""},
+		{strategy: "omnitruck", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			r := FromConfig(config.ServiceConfig{
+				FeatureFlags: map[string]config.FeatureFlagConfig{
+					"next": {Enabled: true, Products: []string{"chef-next"}, Strategy: tt.strategy},
+				},
+			})
+
+			f, ok := r.Get("next")
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, f.Strategy)
+			assert.Equal(t, tt.expected, r.ProductStrategy("chef-next", constants.Commercial))
+			if tt.expected == "" {
+				assert.NotContains(t, r.RoutedProducts(), "chef-next")
+			} else {
+				assert.Contains(t, r.RoutedProducts(), "chef-next")
+			}
+		})
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/utils"
 	"github.com/gofiber/fiber/v2"
+	version "github.com/hashicorp/go-version"
 )
 
 const substring = ".metadata.json"
@@ -109,6 +113,38 @@ func GetPackageUrl(params *omnitruck.RequestParams, baseUrl string, fileName str
 	return GetDownloadUrl(params, baseUrl)
 }
 
+// SignatureExtensions are the detached signature companions served next to a
+// package: .asc for GPG and .sig for cosign.
+var SignatureExtensions = []string{".asc", ".sig"}
+
+// SplitSignatureFileName splits a signature companion name into the package file
+// name and the signature extension. Other names are returned unchanged with an
+// empty extension.
+func SplitSignatureFileName(fileName string) (string, string) {
+	for _, ext := range SignatureExtensions {
+		if base := strings.TrimSuffix(fileName, ext); base != fileName && base != "" {
+			return base, ext
+		}
+	}
+	return fileName, ""
+}
+
+// SignatureExtension returns the signature extension of the file a URL points
+// at, or an empty string if it is not a recognised signature.
+func SignatureExtension(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	_, ext := SplitSignatureFileName(path.Base(u.Path))
+	return ext
+}
+
+// GetSignatureUrl constructs the /files URL of the signature companion of a package.
+func GetSignatureUrl(params *omnitruck.RequestParams, baseUrl string, fileName string, ext string) string {
+	return GetFilesUrl(params, baseUrl, fileName+ext, params.PackageManager)
+}
+
 func GetRequestParams(c omnitruck.FiberContext) *omnitruck.RequestParams {
 	return &omnitruck.RequestParams{
 		Channel:         c.Params("channel"),
@@ -187,6 +223,44 @@ func VerifyRequestType(params *omnitruck.RequestParams) bool {
 	return false
 }
 
+// versionConstraintChars mark a requested version as a constraint expression
+// such as "~> 18.2", ">= 17, < 18" or "!= 18.3.0" rather than a version prefix
+const versionConstraintChars = "<>=!~,"
+
+// IsVersionConstraint reports whether v is a version constraint expression
+func IsVersionConstraint(v string) bool {
+	return strings.ContainsAny(v, versionConstraintChars)
+}
+
+// MatchingVersions returns the versions matching requested, keeping their order.
+// requested is either a constraint expression or an exact version or dotted
+// prefix, where "16" matches "16.1.0" but not "160.0.0".
+func MatchingVersions(versions []omnitruck.ProductVersion, requested string) ([]omnitruck.ProductVersion, error) {
+	matches := []omnitruck.ProductVersion{}
+
+	if IsVersionConstraint(requested) {
+		constraints, err := version.NewConstraint(requested)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q", requested)
+		}
+		for _, v := range versions {
+			// Versions that do not parse, such as "latest", never satisfy a constraint
+			if parsed, err := version.NewVersion(string(v)); err == nil && constraints.Check(parsed) {
+				matches = append(matches, v)
+			}
+		}
+		return matches, nil
+	}
+
+	for _, v := range versions {
+		vStr := string(v)
+		if strings.HasPrefix(vStr, requested) && (len(vStr) == len(requested) || vStr[len(requested)] == '.') {
+			matches = append(matches, v)
+		}
+	}
+	return matches, nil
+}
+
 func ValidateOrSetVersion(params *omnitruck.RequestParams, filtered []omnitruck.ProductVersion) error {
 	if params.Version == "" || params.Version == "latest" {
 		// Use the latest version from filtered list if not provided
@@ -194,22 +268,17 @@ func ValidateOrSetVersion(params *omnitruck.RequestParams, filtered []omnitruck.
 		return nil
 	}
 
-	// Iterate backwards to find the latest matching version efficiently
-	requestedVersion := params.Version
-	for i := len(filtered) - 1; i >= 0; i-- {
-		vStr := string(filtered[i])
-		// Check if version starts with the requested prefix (e.g., "16" matches "16.1.0", "16.2.5")
-		if strings.HasPrefix(vStr, requestedVersion) {
-			// Ensure it's a proper version boundary (e.g., "16" shouldn't match "160.0.0")
-			if len(vStr) == len(requestedVersion) || vStr[len(requestedVersion)] == '.' {
-				// Found the latest match, set and return immediately
-				params.Version = vStr
-				return nil
-			}
-		}
+	// The filtered list is sorted, so the last match is the latest one
+	matches, err := MatchingVersions(filtered, params.Version)
+	if err != nil {
+		return err
+	}
+	if len(matches) > 0 {
+		params.Version = string(matches[len(matches)-1])
+		return nil
 	}
 
-	return fmt.Errorf("the requested version is not supported on the selected persona or channel")
+	return apierror.WithCode(apierror.CodeVersionUnsupportedForMode, errors.New(utils.VersionUnsupportedError))
 }
 
 func GetFileNameFromURL(url string) string {
@@ -225,5 +294,10 @@ func GetErrorCodeAndMsg(err error) (code int, msg string) {
 		msg = fiberErr.Message
 		return code, msg
 	}
-	return fiber.StatusInternalServerError, ""
+	// Classified errors are answered with the status and message of their kind
+	if code, msg, ok := apierror.Status(err); ok {
+		return code, msg
+	}
+	// Anything else is answered without leaking its text
+	return fiber.StatusInternalServerError, utils.InternalError
 }
\ No newline at end of file
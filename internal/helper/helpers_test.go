@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/constants"
 	_ "github.com/chef/omnitruck-service/docs"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -679,6 +682,49 @@ func TestValidateOrSetVersion(t *testing.T) {
 			filtered:    []omnitruck.ProductVersion{"16.2.0", "16.2.3", "16.2.5"},
 			expectError: true,
 		},
+		{
+			name: "pessimistic constraint picks latest in range",
+			params: &omnitruck.RequestParams{
+				Version: "~> 18.2",
+			},
+			filtered:    []omnitruck.ProductVersion{"17.10.0", "18.1.0", "18.2.1", "18.5.0", "19.0.0"},
+			expectError: false,
+			expectVer:   "18.5.0",
+		},
+		{
+			name: "range constraint",
+			params: &omnitruck.RequestParams{
+				Version: ">= 17, < 18",
+			},
+			filtered:    []omnitruck.ProductVersion{"16.0.0", "17.0.0", "17.10.0", "18.0.0"},
+			expectError: false,
+			expectVer:   "17.10.0",
+		},
+		{
+			name: "exclusion constraint skips latest",
+			params: &omnitruck.RequestParams{
+				Version: "!= 18.3.0",
+			},
+			filtered:    []omnitruck.ProductVersion{"18.1.0", "18.2.0", "18.3.0"},
+			expectError: false,
+			expectVer:   "18.2.0",
+		},
+		{
+			name: "constraint without matches",
+			params: &omnitruck.RequestParams{
+				Version: "> 20",
+			},
+			filtered:    []omnitruck.ProductVersion{"18.1.0", "19.0.0"},
+			expectError: true,
+		},
+		{
+			name: "invalid constraint",
+			params: &omnitruck.RequestParams{
+				Version: ">= banana",
+			},
+			filtered:    []omnitruck.ProductVersion{"18.1.0"},
+			expectError: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -693,6 +739,32 @@ func TestValidateOrSetVersion(t *testing.T) {
 	}
 }
 
+func TestMatchingVersions(t *testing.T) {
+	versions := []omnitruck.ProductVersion{"latest", "16.1.0", "16.2.0", "160.0.0", "17.0.0"}
+	tests := []struct {
+		name        string
+		requested   string
+		expected    []omnitruck.ProductVersion
+		expectError bool
+	}{
+		{name: "prefix", requested: "16", expected: []omnitruck.ProductVersion{"16.1.0", "16.2.0"}},
+		{name: "constraint ignores unparseable versions", requested: ">= 16.2", expected: []omnitruck.ProductVersion{"16.2.0", "160.0.0", "17.0.0"}},
+		{name: "no matches", requested: "18", expected: []omnitruck.ProductVersion{}},
+		{name: "invalid constraint", requested: "~> x", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := MatchingVersions(versions, tt.requested)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
 func TestGetFileNameFromURL(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -740,7 +812,25 @@ func TestGetErrorCodeAndMsg(t *testing.T) {
 			name:         "generic error fallback",
 			err:          errors.New("some error"),
 			expectedCode: fiber.StatusInternalServerError,
-			expectedMsg:  "",
+			expectedMsg:  utils.InternalError,
+		},
+		{
+			name:         "not found",
+			err:          apierror.Wrap(apierror.ErrNotFound, errors.New("no versions")),
+			expectedCode: fiber.StatusNotFound,
+			expectedMsg:  utils.OmnitruckDataNotFoundError,
+		},
+		{
+			name:         "throttled",
+			err:          fmt.Errorf("scanning: %w", apierror.Wrap(apierror.ErrThrottled, errors.New("ThrottlingException"))),
+			expectedCode: fiber.StatusTooManyRequests,
+			expectedMsg:  utils.ThrottledError,
+		},
+		{
+			name:         "upstream unavailable",
+			err:          apierror.Wrap(apierror.ErrUnavailable, errors.New("connection refused")),
+			expectedCode: fiber.StatusServiceUnavailable,
+			expectedMsg:  utils.UpstreamUnavailableError,
 		},
 	}
 	for _, tt := range tests {
@@ -751,3 +841,49 @@ func TestGetErrorCodeAndMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitSignatureFileName(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileName     string
+		expectedBase string
+		expectedExt  string
+	}{
+		{name: "gpg signature", fileName: "chef_19.1.2_amd64.deb.asc", expectedBase: "chef_19.1.2_amd64.deb", expectedExt: ".asc"},
+		{name: "cosign signature", fileName: "chef-19.1.2.msi.sig", expectedBase: "chef-19.1.2.msi", expectedExt: ".sig"},
+		{name: "package", fileName: "chef_19.1.2_amd64.deb", expectedBase: "chef_19.1.2_amd64.deb", expectedExt: ""},
+		{name: "bare extension", fileName: ".asc", expectedBase: ".asc", expectedExt: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, ext := SplitSignatureFileName(tt.fileName)
+			assert.Equal(t, tt.expectedBase, base)
+			assert.Equal(t, tt.expectedExt, ext)
+		})
+	}
+}
+
+func TestSignatureExtension(t *testing.T) {
+	assert.Equal(t, ".asc", SignatureExtension("https://packages.example.com/chef_19.1.2_amd64.deb.asc"))
+	assert.Equal(t, ".sig", SignatureExtension("https://packages.example.com/chef-19.1.2.msi.sig?X-Amz-Signature=abc"))
+	assert.Equal(t, "", SignatureExtension("https://packages.example.com/chef_19.1.2_amd64.deb"))
+	assert.Equal(t, "", SignatureExtension(""))
+}
+
+func TestGetSignatureUrl(t *testing.T) {
+	params := &omnitruck.RequestParams{
+		Channel:        "stable",
+		Product:        "chef-ice",
+		Version:        "19.1.2",
+		Platform:       "linux",
+		Architecture:   "x86_64",
+		PackageManager: "deb",
+		LicenseId:      "abc",
+	}
+	assert.Equal(t, "http://example.com/files/stable/chef-ice/19.1.2/linux/x86_64/deb/chef-ice_19.1.2_amd64.deb.asc?license_id=abc",
+		GetSignatureUrl(params, "http://example.com", "chef-ice_19.1.2_amd64.deb", ".asc"))
+
+	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
+	assert.Equal(t, "http://example.com/files/stable/chef-ice/19.1.2/linux/x86_64/chef-ice_19.1.2_amd64.deb.sig?license_id=abc",
+		GetSignatureUrl(params, "http://example.com", "chef-ice_19.1.2_amd64.deb", ".sig"))
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// SbomFormat describes an SBOM document format the catalog can link to. The
+// object is stored next to the package as the package file name plus Suffix.
+type SbomFormat struct {
+	Name      string
+	MediaType string
+	Suffix    string
+}
+
+var (
+	CycloneDX = SbomFormat{Name: "cyclonedx", MediaType: "application/vnd.cyclonedx+json", Suffix: ".cdx.json"}
+	SPDX      = SbomFormat{Name: "spdx", MediaType: "application/spdx+json", Suffix: ".spdx.json"}
+)
+
+// SbomFormats lists the supported formats in order of preference
+var SbomFormats = []SbomFormat{CycloneDX, SPDX}
+
+// SbomFormatFromUrl returns the format of the SBOM a URL points at, based on
+// the file suffix. The second result is false for unrecognised files.
+func SbomFormatFromUrl(rawUrl string) (SbomFormat, bool) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return SbomFormat{}, false
+	}
+	name := path.Base(u.Path)
+	for _, format := range SbomFormats {
+		if strings.HasSuffix(name, format.Suffix) {
+			return format, true
+		}
+	}
+	return SbomFormat{}, false
+}
+
+// SbomFormatForMediaType returns the format served with the given media type
+func SbomFormatForMediaType(mediaType string) (SbomFormat, bool) {
+	for _, format := range SbomFormats {
+		if format.MediaType == mediaType {
+			return format, true
+		}
+	}
+	return SbomFormat{}, false
+}
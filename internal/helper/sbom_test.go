@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSbomFormatFromUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected SbomFormat
+		ok       bool
+	}{
+		{name: "cyclonedx", url: "https://packages.example.com/chef_19.1.2_amd64.deb.cdx.json", expected: CycloneDX, ok: true},
+		{name: "spdx with query", url: "https://packages.example.com/chef_19.1.2_amd64.deb.spdx.json?X-Amz-Signature=abc", expected: SPDX, ok: true},
+		{name: "plain json", url: "https://packages.example.com/chef_19.1.2_amd64.deb.json", ok: false},
+		{name: "empty", url: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := SbomFormatFromUrl(tt.url)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestSbomFormatForMediaType(t *testing.T) {
+	format, ok := SbomFormatForMediaType("application/spdx+json")
+	assert.True(t, ok)
+	assert.Equal(t, SPDX, format)
+
+	_, ok = SbomFormatForMediaType("application/json")
+	assert.False(t, ok)
+}
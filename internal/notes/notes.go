@@ -0,0 +1,133 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chef/omnitruck-service/internal/cache"
+)
+
+// ErrNotFound is returned by a Source that has no notes for the version
+var ErrNotFound = errors.New("release notes not found")
+
+// Largest notes document fetched from a URL source
+const maxNotesSize = 1 << 20
+
+// Key identifies the release notes of a single product version
+type Key struct {
+	Channel string
+	Product string
+	Version string
+}
+
+func (k Key) String() string {
+	return k.Channel + "/" + k.Product + "/" + k.Version
+}
+
+// Source looks up the release notes of a product version.
+type Source interface {
+	Notes(ctx context.Context, key Key) (string, error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc func(ctx context.Context, key Key) (string, error)
+
+func (f SourceFunc) Notes(ctx context.Context, key Key) (string, error) {
+	return f(ctx, key)
+}
+
+// Sources asks each source in turn and returns the first notes found. Any
+// error other than ErrNotFound stops the lookup.
+type Sources []Source
+
+func (s Sources) Notes(ctx context.Context, key Key) (string, error) {
+	for _, source := range s {
+		notes, err := source.Notes(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return notes, err
+	}
+	return "", ErrNotFound
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// URLSource fetches notes from a URL built from a text/template, for example
+// https://docs.chef.io/release_notes/{{.Product}}/{{.Version}}.md
+type URLSource struct {
+	Template *template.Template
+	Client   HTTPClient
+}
+
+func NewURLSource(urlTemplate string) (*URLSource, error) {
+	tmpl, err := template.New("notes").Option("missingkey=error").Parse(urlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release notes url template: %w", err)
+	}
+
+	return &URLSource{
+		Template: tmpl,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+func (s *URLSource) Notes(ctx context.Context, key Key) (string, error) {
+	var url strings.Builder
+	if err := s.Template.Execute(&url, key); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("release notes source returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxNotesSize))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// CachedSource keeps the notes found by the wrapped source in a cache.
+// Missing notes are not cached so newly published notes show up right away.
+type CachedSource struct {
+	Source Source
+	Cache  *cache.Cache
+}
+
+func (s CachedSource) Notes(ctx context.Context, key Key) (string, error) {
+	if notes, ok := s.Cache.Get(key.String()); ok {
+		return notes.(string), nil
+	}
+
+	notes, err := s.Source.Notes(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	s.Cache.Set(key.String(), notes)
+	return notes, nil
+}
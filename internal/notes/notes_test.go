@@ -0,0 +1,101 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	missing := SourceFunc(func(ctx context.Context, key Key) (string, error) { return "", ErrNotFound })
+	found := SourceFunc(func(ctx context.Context, key Key) (string, error) { return "notes for " + key.Version, nil })
+	failing := SourceFunc(func(ctx context.Context, key Key) (string, error) { return "", errors.New("boom") })
+
+	tests := []struct {
+		name    string
+		sources Sources
+		want    string
+		wantErr error
+	}{
+		{name: "first found wins", sources: Sources{missing, found, failing}, want: "notes for 18.0.0"},
+		{name: "errors stop the lookup", sources: Sources{failing, found}, wantErr: errors.New("boom")},
+		{name: "nothing found", sources: Sources{missing}, wantErr: ErrNotFound},
+		{name: "no sources", sources: Sources{}, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sources.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "18.0.0"})
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestURLSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stable/chef/18.0.0.md":
+			w.Write([]byte("# Chef 18.0.0"))
+		case "/stable/chef/18.1.0.md":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	source, err := NewURLSource(ts.URL + "/{{.Channel}}/{{.Product}}/{{.Version}}.md")
+	require.NoError(t, err)
+
+	notes, err := source.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "18.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "# Chef 18.0.0", notes)
+
+	_, err = source.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "17.0.0"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = source.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "18.1.0"})
+	assert.EqualError(t, err, "release notes source returned status 500")
+
+	_, err = NewURLSource("{{.Product")
+	assert.Error(t, err)
+}
+
+func TestCachedSource(t *testing.T) {
+	calls := 0
+	source := CachedSource{
+		Source: SourceFunc(func(ctx context.Context, key Key) (string, error) {
+			calls++
+			if key.Version == "17.0.0" {
+				return "", ErrNotFound
+			}
+			return "notes", nil
+		}),
+		Cache: cache.New("notes", time.Minute, 0),
+	}
+
+	for i := 0; i < 2; i++ {
+		notes, err := source.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "18.0.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, "notes", notes)
+	}
+	assert.Equal(t, 1, calls)
+
+	for i := 0; i < 2; i++ {
+		_, err := source.Notes(context.Background(), Key{Channel: "stable", Product: "chef", Version: "17.0.0"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 1, source.Cache.Stats().Entries)
+}
@@ -0,0 +1,119 @@
+// Package problem renders errors of the /v2 API as problem details
+// (RFC 9457) carrying a machine-readable error code.
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Prefix is the path prefix of the routes that answer errors with problem details
+const Prefix = "/v2"
+
+const ContentType = "application/problem+json"
+
+// Problem is the error body of every /v2 route
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestId string `json:"request_id,omitempty"`
+} //@name Problem
+
+// Enabled reports whether the request is served by the /v2 API
+func Enabled(c *fiber.Ctx) bool {
+	path := c.Path()
+	return path == Prefix || strings.HasPrefix(path, Prefix+"/")
+}
+
+// New builds a problem with the given status, code and detail
+func New(status int, code string, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// FromRequest builds a problem for a failed client request, using the code
+// the failure was created with
+func FromRequest(request *clients.Request) Problem {
+	return New(request.Code, CodeFor(request.Code, request.ErrorCode), request.Message)
+}
+
+// CodeFor returns code when it is set, falling back to a generic code for
+// the status
+func CodeFor(status int, code string) string {
+	if code != "" {
+		return code
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return apierror.CodeForbidden
+	case status == http.StatusNotFound:
+		return apierror.CodeNotFound
+	case status == http.StatusNotAcceptable:
+		return apierror.CodeNotAcceptable
+	case status == http.StatusTooManyRequests:
+		return apierror.CodeThrottled
+	case status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout:
+		return apierror.CodeUpstreamUnavailable
+	case status >= 500:
+		return apierror.CodeInternal
+	default:
+		return apierror.CodeRequestInvalid
+	}
+}
+
+// Send writes the problem as the response, filling in the request path and id
+func Send(c *fiber.Ctx, p Problem) error {
+	if p.Instance == "" {
+		p.Instance = c.Path()
+	}
+	if id, ok := c.Locals("requestid").(string); ok {
+		p.RequestId = id
+	}
+
+	c.Status(p.Status)
+	return c.JSON(p, ContentType)
+}
+
+// NotFound answers requests that match no /v2 route
+func NotFound(c *fiber.Ctx) error {
+	return Send(c, New(fiber.StatusNotFound, apierror.CodeRouteNotFound, "Cannot "+c.Method()+" "+c.Path()))
+}
+
+// ErrorHandler renders errors returned by /v2 handlers as problem details
+// and leaves every other route to the fiber default. Errors classified by
+// apierror get the status of their kind on every route.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	code := apierror.CodeOf(err)
+	var e *fiber.Error
+	if !errors.As(err, &e) {
+		// Answer classified errors with the status and message of their kind
+		// rather than the upstream error text
+		if code, msg, ok := apierror.Status(err); ok {
+			err = fiber.NewError(code, msg)
+		}
+	}
+	if !Enabled(c) {
+		return fiber.DefaultErrorHandler(c, err)
+	}
+
+	status := fiber.StatusInternalServerError
+	if errors.As(err, &e) {
+		status = e.Code
+	}
+	return Send(c, New(status, CodeFor(status, code), err.Error()))
+}
@@ -0,0 +1,163 @@
+package problem
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		want   string
+	}{
+		{name: "attached code", status: 400, code: apierror.CodeVersionUnsupportedForMode, want: apierror.CodeVersionUnsupportedForMode},
+		{name: "attached code wins over status", status: 500, code: apierror.CodeCatalogUnavailable, want: apierror.CodeCatalogUnavailable},
+		{name: "bad request", status: 400, want: apierror.CodeRequestInvalid},
+		{name: "forbidden", status: 403, want: apierror.CodeForbidden},
+		{name: "not found", status: 404, want: apierror.CodeNotFound},
+		{name: "not acceptable", status: 406, want: apierror.CodeNotAcceptable},
+		{name: "throttled", status: 429, want: apierror.CodeThrottled},
+		{name: "bad gateway", status: 502, want: apierror.CodeUpstreamUnavailable},
+		{name: "internal", status: 500, want: apierror.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeFor(tt.status, tt.code))
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	request := (&clients.Request{}).Failure(400, utils.ChannelParamsError).WithErrorCode(apierror.CodeChannelInvalid)
+
+	assert.Equal(t, New(400, apierror.CodeChannelInvalid, utils.ChannelParamsError), FromRequest(request))
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/v2", want: true},
+		{path: "/v2/stable/chef/packages", want: true},
+		{path: "/stable/chef/packages", want: false},
+		{path: "/v2beta/products", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			app := fiber.New()
+			var got bool
+			app.Use(func(c *fiber.Ctx) error {
+				got = Enabled(c)
+				return nil
+			})
+			_, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	fail := func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusServiceUnavailable, utils.OmnitruckApiError)
+	}
+	app.Get("/v2/products", requestid.New(requestid.Config{Generator: func() string { return "req-1" }}), fail)
+	app.Get("/v2/broken", func(c *fiber.Ctx) error { return errors.New("boom") })
+	throttled := func(c *fiber.Ctx) error {
+		return apierror.Wrap(apierror.ErrThrottled, errors.New("ProvisionedThroughputExceededException: rate of requests exceeds the allowed throughput"))
+	}
+	app.Get("/v2/throttled", throttled)
+	app.Get("/v2/channel", func(c *fiber.Ctx) error {
+		return apierror.WithCode(apierror.CodeChannelInvalid, fiber.NewError(fiber.StatusBadRequest, utils.ChannelParamsError))
+	})
+	app.Get("/throttled", throttled)
+	app.Get("/products", fail)
+	app.Group(Prefix).Use(NotFound)
+
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "fiber error",
+			path:        "/v2/products",
+			status:      fiber.StatusServiceUnavailable,
+			contentType: ContentType,
+			body:        `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"Error while fetching omnitruck data","instance":"/v2/products","code":"upstream.unavailable","request_id":"req-1"}`,
+		},
+		{
+			name:        "plain error",
+			path:        "/v2/broken",
+			status:      fiber.StatusInternalServerError,
+			contentType: ContentType,
+			body:        `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"boom","instance":"/v2/broken","code":"internal.error"}`,
+		},
+		{
+			name:        "classified error",
+			path:        "/v2/throttled",
+			status:      fiber.StatusTooManyRequests,
+			contentType: ContentType,
+			body:        `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"Too many requests, please retry later","instance":"/v2/throttled","code":"request.throttled"}`,
+		},
+		{
+			name:        "coded error",
+			path:        "/v2/channel",
+			status:      fiber.StatusBadRequest,
+			contentType: ContentType,
+			body:        `{"type":"about:blank","title":"Bad Request","status":400,"detail":"` + utils.ChannelParamsError + `","instance":"/v2/channel","code":"channel.invalid"}`,
+		},
+		{
+			name:        "v1 classified error",
+			path:        "/throttled",
+			status:      fiber.StatusTooManyRequests,
+			contentType: fiber.MIMETextPlainCharsetUTF8,
+			body:        utils.ThrottledError,
+		},
+		{
+			name:        "unknown route",
+			path:        "/v2/unknown",
+			status:      fiber.StatusNotFound,
+			contentType: ContentType,
+			body:        `{"type":"about:blank","title":"Not Found","status":404,"detail":"Cannot GET /v2/unknown","instance":"/v2/unknown","code":"route.not_found"}`,
+		},
+		{
+			name:        "v1 keeps the fiber default",
+			path:        "/products",
+			status:      fiber.StatusServiceUnavailable,
+			contentType: fiber.MIMETextPlainCharsetUTF8,
+			body:        utils.OmnitruckApiError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if tt.contentType == ContentType {
+				assert.JSONEq(t, tt.body, string(body))
+			} else {
+				assert.Equal(t, tt.body, string(body))
+			}
+		})
+	}
+}
@@ -1,21 +1,38 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/audit"
+	"github.com/chef/omnitruck-service/internal/cache"
+	"github.com/chef/omnitruck-service/internal/flags"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/internal/notes"
 	"github.com/chef/omnitruck-service/internal/strategy"
 	"github.com/chef/omnitruck-service/logger"
+	"github.com/chef/omnitruck-service/mirror"
+	"github.com/chef/omnitruck-service/utils"
 	"github.com/chef/omnitruck-service/utils/template"
 	"github.com/gofiber/fiber/v2"
+	version "github.com/hashicorp/go-version"
 	"github.com/samber/do"
 	log "github.com/sirupsen/logrus"
 )
@@ -30,6 +47,16 @@ type DownloadService struct {
 	mode              constants.ApiType
 	locals            map[string]interface{}
 	config            config.ServiceConfig
+	auditor           audit.Sink
+	catalogCache      *cache.Cache
+	flags             flags.FeatureFlags
+	notesSource       notes.Source
+	notesCache        *cache.Cache
+	mirror            *mirror.Catalog
+	objectStore       s3aws.ObjectStore
+	omnitruckBreaker  *omnitruck.Breaker
+	staleCache        *cache.Cache
+	versions          *versionsMemo
 }
 
 func NewDownloadService(injector *do.Injector, log *log.Entry, locals map[string]interface{}) (*DownloadService, error) {
@@ -61,6 +88,40 @@ func NewDownloadService(injector *do.Injector, log *log.Entry, locals map[string
 		service.config = cfg
 		service.licenseServiceUrl = cfg.LicenseServiceUrl
 	}
+	// Auditing is optional, fall back to discarding events when no sink is registered
+	if service.auditor, err = do.InvokeNamed[audit.Sink](injector, "auditor"); err != nil {
+		service.auditor = audit.NopSink{}
+	}
+	// The catalog cache is optional as well, without it every request goes upstream
+	if catalogCache, err := do.InvokeNamed[*cache.Cache](injector, "catalogCache"); err == nil {
+		service.catalogCache = catalogCache
+	}
+	// Without a flag registry the flags are derived from the static config
+	if service.flags, err = do.InvokeNamed[flags.FeatureFlags](injector, "flags"); err != nil {
+		service.flags = flags.FromConfig(service.config)
+	}
+	// Release notes come from the catalog alone unless a notes source is registered
+	if notesSource, err := do.InvokeNamed[notes.Source](injector, "notesSource"); err == nil {
+		service.notesSource = notesSource
+	}
+	if notesCache, err := do.InvokeNamed[*cache.Cache](injector, "notesCache"); err == nil {
+		service.notesCache = notesCache
+	}
+	// Without a shared object store the infra strategy builds one per download
+	if objectStore, err := do.InvokeNamed[s3aws.ObjectStore](injector, "objectStore"); err == nil {
+		service.objectStore = objectStore
+	}
+	// The breaker and the stale answers are shared across requests when registered
+	if breaker, err := do.InvokeNamed[*omnitruck.Breaker](injector, "omnitruckBreaker"); err == nil {
+		service.omnitruckBreaker = breaker
+	}
+	if staleCache, err := do.InvokeNamed[*cache.Cache](injector, "staleCache"); err == nil {
+		service.staleCache = staleCache
+	}
+	// A mirror catalog as database serves every product from the mirror directory
+	if catalog, ok := service.databaseService.(*mirror.Catalog); ok {
+		service.mirror = catalog
+	}
 
 	return service, nil
 }
@@ -71,6 +132,16 @@ func (svc *DownloadService) setMode(mode constants.ApiType) {
 
 func (svc *DownloadService) Omnitruck() *omnitruck.Omnitruck {
 	client := omnitruck.New(svc.logCtx(), svc.config.OmnitruckUrl)
+	client.Configure(svc.config.OmnitruckClient)
+	if svc.catalogCache != nil {
+		client.SetCatalogCache(svc.catalogCache)
+	}
+	if svc.omnitruckBreaker != nil {
+		client.SetBreaker(svc.omnitruckBreaker)
+	}
+	if svc.staleCache != nil {
+		client.SetStaleCache(svc.staleCache)
+	}
 
 	return &client
 }
@@ -95,61 +166,54 @@ func (svc *DownloadService) logCtx() *log.Entry {
 	return svc.log.WithField("license_id", svc.locals["license_id"])
 }
 
-func (svc *DownloadService) Products(params *omnitruck.RequestParams) (data omnitruck.ItemList, request *clients.Request) {
-	request = svc.Omnitruck().Products(params, &data)
-
-	data = svc.DynamoServices(svc.databaseService).Products(data, params.Eol)
-	// This a temporary fix to hide CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT when infra19Enabled is false
-	if !svc.config.SupportInfra19 {
-		// Remove CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT and CHEF_INSPEC_ENTERPRISE_PRODUCT from the data array
-		var filtered omnitruck.ItemList
-		for _, item := range data {
-			if item != constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT && item != constants.MIGRATE_ICE && item != constants.CHEF_INSPEC_ENTERPRISE_PRODUCT && item != constants.CHEF_WORKSTATION_ENTERPRISE {
-				filtered = append(filtered, item)
-			}
-		}
-		data = filtered
+func (svc *DownloadService) Products(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.ItemList, request *clients.Request) {
+	if svc.mirror != nil {
+		// The mirror holds the products chosen at sync time, already filtered
+		// for the mode of the server they were copied from
+		data = svc.flags.FilterProducts(svc.mirror.Products(), svc.mode)
+		return data, &clients.Request{Ok: true, Code: fiber.StatusOK}
 	}
+	request = svc.Omnitruck().Products(ctx, params, &data)
+
+	data = svc.DynamoServices(svc.databaseService).Products(data, params.Eol, svc.flags.RoutedProducts()...)
+	// Hide products gated by a disabled feature flag
+	data = svc.flags.FilterProducts(data, svc.mode)
 	getServerStrategy := strategy.SelectModeStrategy(svc.mode)
 	eol := params.Eol == "true"
 	data = getServerStrategy.FilterProducts(data, eol)
-
-	if !svc.config.SupportInfra19 {
-		var filtered omnitruck.ItemList
-		for _, item := range data {
-			if item == constants.CHEF_INFRA_CLIENT_NEW_VALUE {
-				filtered = append(filtered, constants.CHEF_INFRA_CLIENT_OLD_VALUE)
-			} else {
-				filtered = append(filtered, item)
-			}
-		}
-		data = filtered
-	}
+	data = svc.flags.RewriteProducts(data, svc.mode)
 
 	return data, request
 
 }
 
-func (svc *DownloadService) Platforms() (data omnitruck.PlatformList, request *clients.Request) {
-	request = svc.Omnitruck().Platforms().ParseData(&data)
-
-	data = svc.DynamoServices(svc.databaseService).Platforms(data)
+// Platforms returns the platform registry merged with the platforms known to
+// the upstream Omnitruck API
+func (svc *DownloadService) Platforms(ctx context.Context) (data omnitruck.PlatformCatalog, request *clients.Request) {
+	if svc.mirror != nil {
+		return omnitruck.BuildPlatformCatalog(nil), &clients.Request{Ok: true, Code: fiber.StatusOK}
+	}
+	var upstream omnitruck.PlatformList
+	request = svc.Omnitruck().Platforms(ctx).ParseData(&upstream)
 
-	return data, request
+	return omnitruck.BuildPlatformCatalog(upstream), request
 }
 
-func (svc *DownloadService) Architectures() (data omnitruck.ItemList, request *clients.Request) {
-	request = svc.Omnitruck().Architectures().ParseData(&data)
+func (svc *DownloadService) Architectures(ctx context.Context) (data omnitruck.ItemList, request *clients.Request) {
+	if svc.mirror != nil {
+		return svc.mirror.Architectures(), &clients.Request{Ok: true, Code: fiber.StatusOK}
+	}
+	request = svc.Omnitruck().Architectures(ctx).ParseData(&data)
 
 	return data, request
 }
 
-func (svc *DownloadService) LatestVersion(params *omnitruck.RequestParams) (data omnitruck.ProductVersion, request *clients.Request) {
+func (svc *DownloadService) LatestVersion(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.ProductVersion, request *clients.Request) {
 	// Two-Level Strategy: select both product and mode strategies
 	// Get all versions using product strategy
 	// Filter versions using mode strategy
 	// Return the latest version (assume last in filtered list is latest)
-	filtered, err := svc.getFilteredVersions(params)
+	filtered, err := svc.getFilteredVersions(ctx, params)
 	if err != nil {
 		return "", err
 	}
@@ -162,15 +226,28 @@ func (svc *DownloadService) LatestVersion(params *omnitruck.RequestParams) (data
 	}
 }
 
-func (svc *DownloadService) ProductVersions(params *omnitruck.RequestParams) (data []omnitruck.ProductVersion, request *clients.Request) {
+func (svc *DownloadService) ProductVersions(ctx context.Context, params *omnitruck.RequestParams) (data []omnitruck.ProductVersion, request *clients.Request) {
 	// Two-Level Strategy: select both product and mode strategies
 	// Get all versions using product strategy
 	// Filter versions using mode strategy
-	filtered, err := svc.getFilteredVersions(params)
+	filtered, err := svc.getFilteredVersions(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
+	// An optional version prefix or constraint narrows the list
+	if params.Version != "" && params.Version != constants.LATEST {
+		matches, merr := helpers.MatchingVersions(filtered, params.Version)
+		if merr != nil {
+			return nil, &clients.Request{
+				Ok:      false,
+				Code:    fiber.StatusBadRequest,
+				Message: merr.Error(),
+			}
+		}
+		filtered = matches
+	}
+
 	return filtered, &clients.Request{
 		Ok:      true,
 		Code:    fiber.StatusOK,
@@ -178,45 +255,406 @@ func (svc *DownloadService) ProductVersions(params *omnitruck.RequestParams) (da
 	}
 }
 
-func (svc *DownloadService) ProductPackages(params *omnitruck.RequestParams) (data omnitruck.PackageList, request *clients.Request) {
+// VersionDetailsQuery holds the paging and filter options for VersionDetails.
+// OpenSource and Prerelease filter on the flag when set to "true" or "false".
+type VersionDetailsQuery struct {
+	Page       int
+	PerPage    int
+	Order      string
+	OpenSource string
+	Prerelease string
+}
+
+const (
+	DefaultVersionDetailsPerPage = 20
+	MaxVersionDetailsPerPage     = 100
+)
+
+// VersionDetails returns one page of the product versions visible in the
+// current mode, each annotated with its release date, EOL, open-source and
+// pre-release status and the platforms it ships for. The v parameter accepts
+// the same prefixes and constraints as ProductVersions.
+func (svc *DownloadService) VersionDetails(ctx context.Context, params *omnitruck.RequestParams, query VersionDetailsQuery) (data omnitruck.VersionDetailsList, request *clients.Request) {
+	versions, request := svc.ProductVersions(ctx, params)
+	if !request.Ok {
+		return data, request
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = DefaultVersionDetailsPerPage
+	}
+	if query.PerPage > MaxVersionDetailsPerPage {
+		query.PerPage = MaxVersionDetailsPerPage
+	}
+
+	details := []omnitruck.VersionDetail{}
+	for _, v := range versions {
+		detail := omnitruck.VersionDetail{
+			Version:    v,
+			Eol:        omnitruck.EolProductVersion(params.Product, v),
+			OpenSource: omnitruck.OsProductVersion(params.Product, v),
+			Prerelease: isPrerelease(v),
+			Platforms:  []string{},
+		}
+		if !matchesFlag(query.OpenSource, detail.OpenSource) || !matchesFlag(query.Prerelease, detail.Prerelease) {
+			continue
+		}
+		details = append(details, detail)
+	}
+	if query.Order == "desc" {
+		for i, j := 0, len(details)-1; i < j; i, j = i+1, j-1 {
+			details[i], details[j] = details[j], details[i]
+		}
+	}
+
+	data = omnitruck.VersionDetailsList{
+		Versions: []omnitruck.VersionDetail{},
+		Page:     query.Page,
+		PerPage:  query.PerPage,
+		Total:    len(details),
+	}
+	start := (query.Page - 1) * query.PerPage
+	if start >= len(details) {
+		return data, &clients.Request{
+			Ok:      true,
+			Code:    fiber.StatusOK,
+			Message: "Version details retrieved successfully",
+		}
+	}
+	end := start + query.PerPage
+	if end > len(details) {
+		end = len(details)
+	}
+	data.Versions = details[start:end]
+
+	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
+	dates := map[string]string{}
+	if provider, ok := productStrategy.(strategy.ReleaseDateProvider); ok {
+		var err error
+		if dates, err = provider.GetReleaseDates(ctx, params); err != nil {
+			code, msg := helpers.GetErrorCodeAndMsg(err)
+			return omnitruck.VersionDetailsList{}, &clients.Request{
+				Ok:        false,
+				Code:      code,
+				Message:   msg,
+				ErrorCode: apierror.CodeOf(err),
+			}
+		}
+	}
+
+	// Platforms are only looked up for the versions on the requested page
+	for i := range data.Versions {
+		detail := &data.Versions[i]
+		detail.ReleaseDate = dates[string(detail.Version)]
+
+		versionParams := *params
+		versionParams.Version = string(detail.Version)
+		packages, err := productStrategy.GetPackages(ctx, &versionParams)
+		if err != nil {
+			code, msg := helpers.GetErrorCodeAndMsg(err)
+			return omnitruck.VersionDetailsList{}, &clients.Request{
+				Ok:        false,
+				Code:      code,
+				Message:   msg,
+				ErrorCode: apierror.CodeOf(err),
+			}
+		}
+		for platform := range packages {
+			detail.Platforms = append(detail.Platforms, platform)
+		}
+		sort.Strings(detail.Platforms)
+	}
+
+	return data, &clients.Request{
+		Ok:      true,
+		Code:    fiber.StatusOK,
+		Message: "Version details retrieved successfully",
+	}
+}
+
+func isPrerelease(v omnitruck.ProductVersion) bool {
+	parsed, err := version.NewVersion(string(v))
+	return err == nil && parsed.Prerelease() != ""
+}
+
+// matchesFlag reports whether value passes a "true"/"false" filter. An empty
+// filter matches everything.
+func matchesFlag(filter string, value bool) bool {
+	switch filter {
+	case "true":
+		return value
+	case "false":
+		return !value
+	}
+	return true
+}
+
+// Most versions a release notes range may span
+const MaxReleaseNotesRange = 100
+
+// ReleaseNotes returns the release notes of the requested version. The version
+// may be latest, a partial version or a constraint, like on the download endpoint.
+func (svc *DownloadService) ReleaseNotes(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.ReleaseNote, request *clients.Request) {
 	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
-	filtered, err := svc.getFilteredVersions(params)
+	filtered, req := svc.getFilteredVersions(ctx, params)
+	if req != nil {
+		return data, req
+	}
+
+	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
+		return data, &clients.Request{
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   err.Error(),
+			ErrorCode: apierror.CodeOf(err),
+		}
+	}
+
+	text, err := svc.releaseNotesSource(productStrategy, params).Notes(ctx, notes.Key{Channel: params.Channel, Product: params.Product, Version: params.Version})
 	if err != nil {
-		return nil, err
+		return data, svc.releaseNotesError(params.Product, params.Version, err)
 	}
 
-	// If a version is provided, validate it is in the filtered list
+	return omnitruck.ReleaseNote{Version: omnitruck.ProductVersion(params.Version), Notes: text}, &clients.Request{
+		Ok:      true,
+		Code:    fiber.StatusOK,
+		Message: "Release notes retrieved successfully",
+	}
+}
+
+// ReleaseNotesRange collects the notes of every version after from up to and
+// including params.Version, which defaults to latest. Versions without notes
+// are skipped. The notes are concatenated newest first, each under a heading.
+func (svc *DownloadService) ReleaseNotesRange(ctx context.Context, params *omnitruck.RequestParams, from string) (data omnitruck.ReleaseNotesList, request *clients.Request) {
+	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
+	filtered, req := svc.getFilteredVersions(ctx, params)
+	if req != nil {
+		return data, req
+	}
+
+	fromVersion, err := version.NewVersion(from)
+	if err != nil {
+		return data, &clients.Request{
+			Ok:      false,
+			Code:    fiber.StatusBadRequest,
+			Message: fmt.Sprintf("invalid from version %q", from),
+		}
+	}
 	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
-		return nil, &clients.Request{
+		return data, &clients.Request{
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   err.Error(),
+			ErrorCode: apierror.CodeOf(err),
+		}
+	}
+	toVersion, err := version.NewVersion(params.Version)
+	if err != nil || toVersion.LessThan(fromVersion) {
+		return data, &clients.Request{
 			Ok:      false,
 			Code:    fiber.StatusBadRequest,
-			Message: err.Error(),
+			Message: "from must not be newer than to",
 		}
 	}
 
-	data, perr := productStrategy.GetPackages(params)
-	if perr != nil {
-		code, msg := helpers.GetErrorCodeAndMsg(perr)
-		return nil, &clients.Request{
+	between := []omnitruck.ProductVersion{}
+	for _, v := range filtered {
+		parsed, err := version.NewVersion(string(v))
+		if err == nil && parsed.GreaterThan(fromVersion) && !parsed.GreaterThan(toVersion) {
+			between = append(between, v)
+		}
+	}
+	if len(between) > MaxReleaseNotesRange {
+		return data, &clients.Request{
 			Ok:      false,
-			Code:    code,
-			Message: msg,
+			Code:    fiber.StatusBadRequest,
+			Message: fmt.Sprintf("the range spans %d versions, at most %d are allowed", len(between), MaxReleaseNotesRange),
 		}
 	}
-	productStrategy.UpdatePackages(&data, params, svc.locals["base_url"].(string))
+
+	data = omnitruck.ReleaseNotesList{
+		Product:  params.Product,
+		From:     omnitruck.ProductVersion(from),
+		To:       omnitruck.ProductVersion(params.Version),
+		Versions: []omnitruck.ReleaseNote{},
+	}
+	source := svc.releaseNotesSource(productStrategy, params)
+	var combined strings.Builder
+	for i := len(between) - 1; i >= 0; i-- {
+		v := between[i]
+		text, err := source.Notes(ctx, notes.Key{Channel: params.Channel, Product: params.Product, Version: string(v)})
+		if errors.Is(err, notes.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return omnitruck.ReleaseNotesList{}, svc.releaseNotesError(params.Product, string(v), err)
+		}
+		data.Versions = append(data.Versions, omnitruck.ReleaseNote{Version: v, Notes: text})
+		fmt.Fprintf(&combined, "## %s\n\n%s\n\n", v, strings.TrimSpace(text))
+	}
+	data.Notes = strings.TrimSuffix(combined.String(), "\n")
 
 	return data, &clients.Request{
+		Ok:      true,
+		Code:    fiber.StatusOK,
+		Message: "Release notes retrieved successfully",
+	}
+}
+
+// releaseNotesSource returns the notes stored in the catalog of the product
+// strategy, falling back to the configured notes source, behind the notes cache.
+func (svc *DownloadService) releaseNotesSource(productStrategy strategy.ProductStrategy, params *omnitruck.RequestParams) notes.Source {
+	sources := notes.Sources{}
+	if provider, ok := productStrategy.(strategy.ReleaseNotesProvider); ok {
+		sources = append(sources, notes.SourceFunc(func(ctx context.Context, key notes.Key) (string, error) {
+			versionParams := *params
+			versionParams.Version = key.Version
+			text, err := provider.GetReleaseNotes(ctx, &versionParams)
+			if err == nil && text == "" {
+				return "", notes.ErrNotFound
+			}
+			return text, err
+		}))
+	}
+	if svc.notesSource != nil {
+		sources = append(sources, svc.notesSource)
+	}
+
+	if svc.notesCache == nil {
+		return sources
+	}
+	return notes.CachedSource{Source: sources, Cache: svc.notesCache}
+}
+
+func (svc *DownloadService) releaseNotesError(product, version string, err error) *clients.Request {
+	if errors.Is(err, notes.ErrNotFound) {
+		return &clients.Request{
+			Ok:      false,
+			Code:    fiber.StatusNotFound,
+			Message: fmt.Sprintf("No release notes found for %s %s", product, version),
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return &clients.Request{
+			Ok:      false,
+			Code:    fiberErr.Code,
+			Message: fiberErr.Message,
+		}
+	}
+
+	svc.logCtx().WithError(err).Errorf("Error while fetching release notes for %s %s", product, version)
+	return &clients.Request{
+		Ok:      false,
+		Code:    fiber.StatusBadGateway,
+		Message: "Error while fetching release notes",
+	}
+}
+
+func (svc *DownloadService) ProductPackages(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.PackageList, request *clients.Request) {
+	data, _, request = svc.productPackages(ctx, params)
+	return data, request
+}
+
+// ProductPackagesV2 returns the packages of a product version as a sorted list
+// with typed platform, platform version, architecture and package manager fields.
+func (svc *DownloadService) ProductPackagesV2(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.Packages, request *clients.Request) {
+	packages, productStrategy, request := svc.productPackages(ctx, params)
+	if !request.Ok {
+		return omnitruck.Packages{}, request
+	}
+
+	data = omnitruck.Packages{
+		Product:  params.Product,
+		Version:  omnitruck.ProductVersion(params.Version),
+		Packages: packages.Packages(productStrategy.PackageKeys),
+	}
+	return data, request
+}
+
+// productPackages returns the package list with download urls along with the
+// strategy that produced it, so callers can interpret its keys.
+func (svc *DownloadService) productPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, strategy.ProductStrategy, *clients.Request) {
+	data, productStrategy, request := svc.packageList(ctx, params)
+	if !request.Ok {
+		return nil, productStrategy, request
+	}
+	// UpdatePackages overwrites the version in params, keep the requested one
+	version := params.Version
+	productStrategy.UpdatePackages(ctx, &data, params, svc.locals["base_url"].(string))
+	params.Version = version
+
+	return data, productStrategy, &clients.Request{
 		Ok:      true,
 		Code:    fiber.StatusOK,
 		Message: "Packages retrieved successfully",
 	}
 }
 
-func (svc *DownloadService) ProductMetadata(params *omnitruck.RequestParams) (data omnitruck.PackageMetadata, request *clients.Request) {
+// packageList looks up the packages of the requested, or latest, version
+func (svc *DownloadService) packageList(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, strategy.ProductStrategy, *clients.Request) {
+	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
+	filtered, req := svc.getFilteredVersions(ctx, params)
+	if req != nil {
+		return nil, productStrategy, req
+	}
+
+	// If a version is provided, validate it is in the filtered list
+	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
+		return nil, productStrategy, &clients.Request{
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   err.Error(),
+			ErrorCode: apierror.CodeOf(err),
+		}
+	}
+
+	data, err := productStrategy.GetPackages(ctx, params)
+	if err != nil {
+		code, msg := helpers.GetErrorCodeAndMsg(err)
+		return nil, productStrategy, &clients.Request{
+			Ok:        false,
+			Code:      code,
+			Message:   msg,
+			ErrorCode: apierror.CodeOf(err),
+		}
+	}
+	data.UpdatePackages(func(platform, platformVersion, arch string, meta omnitruck.PackageMetadata) omnitruck.PackageMetadata {
+		return withFileName(meta)
+	})
+
+	return data, productStrategy, &clients.Request{Ok: true, Code: fiber.StatusOK}
+}
+
+// SupportMatrix lists every platform, platform version, architecture and
+// package manager combination of a product version as a flat table.
+func (svc *DownloadService) SupportMatrix(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.SupportMatrix, request *clients.Request) {
+	packages, productStrategy, request := svc.packageList(ctx, params)
+	if !request.Ok {
+		return omnitruck.SupportMatrix{}, request
+	}
+
+	data = omnitruck.SupportMatrix{
+		Product: params.Product,
+		Version: omnitruck.ProductVersion(params.Version),
+		Entries: packages.Flatten(productStrategy.PackageKeys),
+	}
+	return data, &clients.Request{
+		Ok:      true,
+		Code:    fiber.StatusOK,
+		Message: "Support matrix retrieved successfully",
+	}
+}
+
+func (svc *DownloadService) ProductMetadata(ctx context.Context, params *omnitruck.RequestParams) (data omnitruck.PackageMetadata, request *clients.Request) {
 	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
 
 	// Get all versions using product strategy
-	filtered, req := svc.getFilteredVersions(params)
+	filtered, req := svc.getFilteredVersions(ctx, params)
 	if req != nil {
 		return omnitruck.PackageMetadata{}, req
 	}
@@ -224,17 +662,19 @@ func (svc *DownloadService) ProductMetadata(params *omnitruck.RequestParams) (da
 	// If a version is provided, validate it is in the filtered list
 	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
 		return omnitruck.PackageMetadata{}, &clients.Request{
-			Ok:      false,
-			Code:    fiber.StatusBadRequest,
-			Message: err.Error(),
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   err.Error(),
+			ErrorCode: apierror.CodeOf(err),
 		}
 	}
 
-	data, request = productStrategy.GetMetadata(params)
+	data, request = productStrategy.GetMetadata(ctx, params)
 	if request.Ok {
+		data = withFileName(data)
 		fileName := params.FileName
 		if fileName == "" && params.Direct == "true" {
-			if resolvedFileName, err := productStrategy.GetFileName(params); err == nil {
+			if resolvedFileName, err := productStrategy.GetFileName(ctx, params); err == nil {
 				fileName = resolvedFileName
 			}
 		}
@@ -246,6 +686,7 @@ func (svc *DownloadService) ProductMetadata(params *omnitruck.RequestParams) (da
 
 		// Remap the package url to our endpoint URL (download by default, files when direct=true).
 		data.Url = helpers.GetPackageUrl(params, svc.locals["base_url"].(string), fileName)
+		data.SignatureUrl = svc.signatureUrl(ctx, productStrategy, params, data, fileName)
 	}
 
 	if request.Ok {
@@ -259,27 +700,112 @@ func (svc *DownloadService) ProductMetadata(params *omnitruck.RequestParams) (da
 	}
 }
 
-func (svc *DownloadService) RelatedProducts(params *omnitruck.RequestParams) (data map[string]interface{}, request *clients.Request) {
+// MetadataBatchResult holds the outcome of one item of a metadata batch
+type MetadataBatchResult struct {
+	Params   *omnitruck.RequestParams
+	Metadata omnitruck.PackageMetadata
+	Request  *clients.Request
+}
+
+// Default number of metadata lookups a batch runs in parallel
+const DefaultMetadataBatchConcurrency = 8
+
+// ProductMetadataBatch resolves metadata for every item with the same semantics
+// as ProductMetadata. Items are resolved concurrently, bounded by the
+// metadataBatchConcurrency config, and results are returned in input order.
+func (svc *DownloadService) ProductMetadataBatch(ctx context.Context, items []*omnitruck.RequestParams) []MetadataBatchResult {
+	concurrency := svc.config.MetadataBatchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMetadataBatchConcurrency
+	}
+
+	results := make([]MetadataBatchResult, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	// Items of one product share their versions
+	batch := *svc
+	batch.versions = &versionsMemo{}
+
+	for i, params := range items {
+		if !acquire(ctx, sem) {
+			results[i] = MetadataBatchResult{Params: params, Request: &clients.Request{
+				Ok:      false,
+				Code:    fiber.StatusServiceUnavailable,
+				Message: "Request ended before the metadata was fetched",
+			}}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, params *omnitruck.RequestParams) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				// The recover middleware does not see panics in these goroutines
+				if r := recover(); r != nil {
+					svc.logCtx().Errorf("Panic while fetching metadata for %s: %v", params.Product, r)
+					results[i] = MetadataBatchResult{Params: params, Request: &clients.Request{
+						Ok:      false,
+						Code:    fiber.StatusInternalServerError,
+						Message: "Error while fetching metadata",
+					}}
+				}
+			}()
+
+			data, request := batch.isolated().ProductMetadata(ctx, params)
+			results[i] = MetadataBatchResult{Params: params, Metadata: data, Request: request}
+		}(i, params)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// acquire takes a slot of sem, giving up once ctx is done
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isolated returns a copy of the service with its own database handle, so
+// strategies selected concurrently do not race on SetDbInfo.
+func (svc *DownloadService) isolated() *DownloadService {
+	clone := *svc
+	if cloner, ok := svc.databaseService.(dboperations.Cloner); ok {
+		clone.databaseService = cloner.Clone()
+	}
+	return &clone
+}
+
+func (svc *DownloadService) RelatedProducts(ctx context.Context, params *omnitruck.RequestParams) (data map[string]interface{}, request *clients.Request) {
 	svc.logCtx().Info("Validating related products API for " + params.BOM)
 
-	relatedProducts, err := svc.DynamoServices(svc.databaseService).GetRelatedProducts(params)
+	relatedProducts, err := svc.DynamoServices(svc.databaseService).GetRelatedProducts(ctx, params)
 
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
 		svc.logCtx().Error("Error while fetching related products for "+params.BOM, err.Error())
 		return nil, &clients.Request{
-			Ok:      false,
-			Code:    code,
-			Message: msg,
+			Ok:        false,
+			Code:      code,
+			Message:   msg,
+			ErrorCode: apierror.CodeOf(err),
 		}
 	}
 
-	if !svc.config.SupportInfra19 && relatedProducts != nil && relatedProducts.Products != nil {
-		// This a temporary fix to hide CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT and CHEF_INSPEC_ENTERPRISE_PRODUCT when supportInfra19 is false
-		delete(relatedProducts.Products, constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT)
-		delete(relatedProducts.Products, constants.MIGRATE_ICE)
-		delete(relatedProducts.Products, constants.CHEF_INSPEC_ENTERPRISE_PRODUCT)
-		delete(relatedProducts.Products, constants.CHEF_WORKSTATION_ENTERPRISE)
+	if relatedProducts != nil && relatedProducts.Products != nil {
+		// Hide products gated by a disabled feature flag
+		for product := range relatedProducts.Products {
+			if !svc.flags.ProductEnabled(product, svc.mode) {
+				delete(relatedProducts.Products, product)
+			}
+		}
 	}
 
 	response := map[string]interface{}{
@@ -293,18 +819,18 @@ func (svc *DownloadService) RelatedProducts(params *omnitruck.RequestParams) (da
 	}
 }
 
-func (svc *DownloadService) GetFileName(params *omnitruck.RequestParams) (string, *clients.Request) {
+func (svc *DownloadService) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, *clients.Request) {
 	// Two-Level Strategy: select both product and mode strategies
 	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
 
 	// Get all versions using product strategy
-	versions, req := productStrategy.GetAllVersions(params)
+	versions, req := productStrategy.GetAllVersions(ctx, params)
 	if !req.Ok || len(versions) == 0 {
 		return "", req
 	}
 
 	// Get all versions using product strategy
-	filtered, req := svc.getFilteredVersions(params)
+	filtered, req := svc.getFilteredVersions(ctx, params)
 	if req != nil {
 		return "", req
 	}
@@ -312,19 +838,22 @@ func (svc *DownloadService) GetFileName(params *omnitruck.RequestParams) (string
 	// If a version is provided, validate it is in the filtered list
 	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
 		return "", &clients.Request{
-			Ok:      false,
-			Code:    fiber.StatusBadRequest,
-			Message: err.Error(),
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   err.Error(),
+			ErrorCode: apierror.CodeOf(err),
 		}
 	}
 
-	fileName, err := productStrategy.GetFileName(params)
+	fileName, err := productStrategy.GetFileName(ctx, params)
 	if err != nil {
 		svc.logCtx().Error("Error while fetching fileName for "+params.Product, err.Error())
+		code, msg := helpers.GetErrorCodeAndMsg(err)
 		return "", &clients.Request{
-			Ok:      false,
-			Code:    fiber.StatusInternalServerError,
-			Message: err.Error(),
+			Ok:        false,
+			Code:      code,
+			Message:   msg,
+			ErrorCode: apierror.CodeOf(err),
 		}
 	}
 
@@ -336,7 +865,7 @@ func (svc *DownloadService) GetFileName(params *omnitruck.RequestParams) (string
 	}
 }
 
-func (svc *DownloadService) ProductFilesDownload(c omnitruck.FiberContext) (string, io.ReadCloser, http.Header, string, int, error) {
+func (svc *DownloadService) ProductFilesDownload(ctx context.Context, c omnitruck.FiberContext) (string, io.ReadCloser, http.Header, string, int, error) {
 	svc.logCtx().Infof("Received product files download request for %s", c.Params("product"))
 
 	// Parse params using strategy-specific parser
@@ -350,13 +879,17 @@ func (svc *DownloadService) ProductFilesDownload(c omnitruck.FiberContext) (stri
 
 	// Validate common required fields
 	if err := helpers.ValidateCommonRequiredFilesParams(params); err != nil {
-		return "", nil, nil, err.Error(), fiber.StatusBadRequest, err
+		return "", nil, nil, err.Error(), fiber.StatusBadRequest, apierror.WithCode(apierror.CodeParameterMissing, err)
 	}
 
+	// A signature companion (.asc/.sig) is validated against the package it signs
+	var signatureExt string
+	params.FileName, signatureExt = helpers.SplitSignatureFileName(params.FileName)
+
 	// Resolve partial version (e.g., "19.1" -> "19.1.172")
-	filtered, req := svc.getFilteredVersions(params)
+	filtered, req := svc.getFilteredVersions(ctx, params)
 	if req != nil && !req.Ok {
-		return "", nil, nil, req.Message, req.Code, fiber.NewError(req.Code, req.Message)
+		return "", nil, nil, req.Message, req.Code, req.Err()
 	}
 	if len(filtered) > 0 {
 		if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
@@ -366,13 +899,26 @@ func (svc *DownloadService) ProductFilesDownload(c omnitruck.FiberContext) (stri
 
 	// Validate strategy-specific fields
 	if validator, ok := productStrategy.(strategy.FilesParamsValidator); ok {
-		if err := validator.ValidateFilesParams(params); err != nil {
+		if err := validator.ValidateFilesParams(ctx, params); err != nil {
 			return "", nil, nil, err.Error(), fiber.StatusBadRequest, err
 		}
 	}
 
+	if signatureExt != "" {
+		signer, ok := productStrategy.(strategy.SignatureDownloader)
+		if !ok {
+			msg := "Signatures are not available for " + params.Product
+			return "", nil, nil, msg, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, msg)
+		}
+		url, body, header, msg, code, err := signer.DownloadSignature(ctx, params, signatureExt)
+		svc.auditDownload(audit.EndpointFiles, params, productStrategy, url, header, code, err)
+		return url, body, header, msg, code, err
+	}
+
 	// Download using the product strategy
-	return productStrategy.Download(params)
+	url, body, header, msg, code, err := productStrategy.Download(ctx, params)
+	svc.auditDownload(audit.EndpointFiles, params, productStrategy, url, header, code, err)
+	return url, body, header, msg, code, err
 }
 
 func (svc *DownloadService) GetLinuxScript(params *omnitruck.RequestParams) (string, *clients.Request) {
@@ -411,22 +957,22 @@ func (svc *DownloadService) GetWindowsScript(params *omnitruck.RequestParams) (s
 	}
 }
 
-func (svc *DownloadService) ProductDownload(params *omnitruck.RequestParams, c *fiber.Ctx) (string, io.ReadCloser, http.Header, string, int, error) {
+func (svc *DownloadService) ProductDownload(ctx context.Context, params *omnitruck.RequestParams, c *fiber.Ctx) (string, io.ReadCloser, http.Header, string, int, error) {
 	svc.logCtx().Infof("Received product download request for %s", params.Product)
 	// Two-Level Strategy: select both product and mode strategies
 	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
 
 	// Get all versions using product strategy
-	versions, req := productStrategy.GetAllVersions(params)
+	versions, req := productStrategy.GetAllVersions(ctx, params)
 	if !req.Ok || len(versions) == 0 {
-		return "", nil, nil, req.Message, req.Code, fiber.NewError(req.Code, req.Message)
+		return "", nil, nil, req.Message, req.Code, req.Err()
 		//return svc.SendError(c, req)
 	}
 
 	// Get all versions using product strategy
-	filtered, req := svc.getFilteredVersions(params)
+	filtered, req := svc.getFilteredVersions(ctx, params)
 	if req != nil {
-		return "", nil, nil, req.Message, req.Code, fiber.NewError(req.Code, req.Message)
+		return "", nil, nil, req.Message, req.Code, req.Err()
 	}
 
 	// Validate or set version
@@ -436,18 +982,191 @@ func (svc *DownloadService) ProductDownload(params *omnitruck.RequestParams, c *
 	}
 
 	// Download using the product strategy
-	return productStrategy.Download(params)
+	url, body, header, msg, code, err := productStrategy.Download(ctx, params)
+	svc.auditDownload(audit.EndpointDownload, params, productStrategy, url, header, code, err)
+	return url, body, header, msg, code, err
+}
+
+// ProductSbom resolves the SBOM of a package. Strategies that store SBOMs next to
+// their packages stream it, others redirect to the link recorded in the catalog.
+// An empty format accepts whichever format the catalog has.
+func (svc *DownloadService) ProductSbom(ctx context.Context, params *omnitruck.RequestParams, format helpers.SbomFormat) (string, io.ReadCloser, http.Header, string, int, error) {
+	svc.logCtx().Infof("Received SBOM request for %s", params.Product)
+	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
+
+	filtered, req := svc.getFilteredVersions(ctx, params)
+	if req != nil {
+		return "", nil, nil, req.Message, req.Code, req.Err()
+	}
+	if err := helpers.ValidateOrSetVersion(params, filtered); err != nil {
+		return "", nil, nil, err.Error(), fiber.StatusBadRequest, err
+	}
+
+	meta, req := productStrategy.GetMetadata(ctx, params)
+	if !req.Ok {
+		return "", nil, nil, req.Message, req.Code, req.Err()
+	}
+	available, linked := helpers.SbomFormatFromUrl(meta.SbomUrl)
+	notFound := fmt.Sprintf("No SBOM available for %s %s", params.Product, params.Version)
+	notAcceptable := func() (string, io.ReadCloser, http.Header, string, int, error) {
+		msg := fmt.Sprintf("SBOM for %s %s is only available as %s", params.Product, params.Version, available.MediaType)
+		return "", nil, nil, msg, fiber.StatusNotAcceptable, fiber.NewError(fiber.StatusNotAcceptable, msg)
+	}
+	otherFormat := linked && format.Name != "" && format.Name != available.Name
+
+	if downloader, ok := productStrategy.(strategy.SbomDownloader); ok {
+		// SBOMs are stored next to the package, so the store is asked even
+		// when the catalog links none or links another format
+		formats := helpers.SbomFormats
+		if format.Name != "" {
+			formats = []helpers.SbomFormat{format}
+		}
+		var (
+			url    string
+			body   io.ReadCloser
+			header http.Header
+			msg    string
+			code   int
+			err    error
+		)
+		for _, f := range formats {
+			url, body, header, msg, code, err = downloader.DownloadSbom(ctx, params, f)
+			if !errors.Is(err, s3aws.ErrObjectNotFound) {
+				break
+			}
+		}
+		if errors.Is(err, s3aws.ErrObjectNotFound) {
+			if otherFormat {
+				return notAcceptable()
+			}
+			msg = notFound
+		}
+		svc.auditDownload(audit.EndpointSbom, params, productStrategy, url, header, code, err)
+		return url, body, header, msg, code, err
+	}
+	if !linked {
+		return "", nil, nil, notFound, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, notFound)
+	}
+	if otherFormat {
+		return notAcceptable()
+	}
+	svc.auditDownload(audit.EndpointSbom, params, productStrategy, meta.SbomUrl, nil, 0, nil)
+	return meta.SbomUrl, nil, nil, "", 0, nil
 }
 
-func (svc *DownloadService) GetPackageManagers() (data omnitruck.ItemList, request *clients.Request) {
+// auditDownload records the outcome of a strategy download in the audit sinks.
+// Failures to write the event are logged and never fail the download itself.
+func (svc *DownloadService) auditDownload(endpoint string, params *omnitruck.RequestParams, productStrategy strategy.ProductStrategy, downloadUrl string, header http.Header, code int, err error) {
+	event := audit.Event{
+		Timestamp:       time.Now().UTC(),
+		RequestId:       localString(svc.locals, "requestid"),
+		LicenseId:       params.LicenseId,
+		Mode:            svc.mode.String(),
+		Endpoint:        endpoint,
+		Channel:         params.Channel,
+		Product:         params.Product,
+		Version:         params.Version,
+		Platform:        params.Platform,
+		PlatformVersion: params.PlatformVersion,
+		Architecture:    params.Architecture,
+		PackageManager:  params.PackageManager,
+		FileName:        downloadFileName(params, downloadUrl, header),
+		Strategy:        strategy.StrategyName(productStrategy),
+		Status:          code,
+	}
+	if event.LicenseId == "" {
+		event.LicenseId = localString(svc.locals, "license_id")
+	}
+	if event.PackageManager == constants.DUMMY_PACKAGE_MANAGER {
+		event.PackageManager = ""
+	}
+
+	switch {
+	case err != nil:
+		event.Error = err.Error()
+		if event.Status == 0 {
+			event.Status = fiber.StatusInternalServerError
+		}
+	case downloadUrl != "":
+		event.Status = fiber.StatusFound
+	case event.Status == 0:
+		event.Status = fiber.StatusOK
+	}
+
+	if werr := svc.auditor.Write(event); werr != nil {
+		svc.logCtx().WithError(werr).Error("Unable to write download audit event")
+	}
+}
+
+// downloadFileName works out which file was handed to the user, preferring the
+// name from the request path, then the redirect URL, then the Content-Disposition header.
+func downloadFileName(params *omnitruck.RequestParams, downloadUrl string, header http.Header) string {
+	if params.FileName != "" {
+		return params.FileName
+	}
+	if downloadUrl != "" {
+		if u, err := url.Parse(downloadUrl); err == nil {
+			return helpers.GetFileNameFromURL(u.Path)
+		}
+	}
+	if header != nil {
+		if _, p, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+			return p["filename"]
+		}
+	}
+	return ""
+}
+
+// signatureUrl points a catalog signature at the /files companion of the package,
+// so clients fetch it from the same place as the package. Signatures the server
+// cannot serve are returned as recorded in the catalog.
+func (svc *DownloadService) signatureUrl(ctx context.Context, productStrategy strategy.ProductStrategy, params *omnitruck.RequestParams, meta omnitruck.PackageMetadata, fileName string) string {
+	ext := helpers.SignatureExtension(meta.SignatureUrl)
+	if ext == "" {
+		return meta.SignatureUrl
+	}
+	if _, ok := productStrategy.(strategy.SignatureDownloader); !ok {
+		return meta.SignatureUrl
+	}
+	if fileName == "" {
+		resolved, err := productStrategy.GetFileName(ctx, params)
+		if err != nil || resolved == "" {
+			return meta.SignatureUrl
+		}
+		fileName = resolved
+	}
+	return helpers.GetSignatureUrl(params, svc.locals["base_url"].(string), fileName, ext)
+}
+
+// withFileName fills in the package file name from the upstream package URL
+// when the backend did not provide one. It must run before the URL is remapped.
+func withFileName(meta omnitruck.PackageMetadata) omnitruck.PackageMetadata {
+	if meta.FileName != "" || meta.Url == "" {
+		return meta
+	}
+	if u, err := url.Parse(meta.Url); err == nil {
+		meta.FileName = helpers.GetFileNameFromURL(u.Path)
+	}
+	return meta
+}
+
+func localString(locals map[string]interface{}, key string) string {
+	if v, ok := locals[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func (svc *DownloadService) GetPackageManagers(ctx context.Context) (data omnitruck.ItemList, request *clients.Request) {
 	svc.logCtx().Info("Fetching package managers")
-	packageManagers, err := svc.DynamoServices(svc.databaseService).GetPackageManagers()
+	packageManagers, err := svc.DynamoServices(svc.databaseService).GetPackageManagers(ctx)
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
 		return nil, &clients.Request{
-			Ok:      false,
-			Code:    code,
-			Message: msg,
+			Ok:        false,
+			Code:      code,
+			Message:   msg,
+			ErrorCode: apierror.CodeOf(err),
 		}
 	}
 	return packageManagers, &clients.Request{
@@ -472,14 +1191,26 @@ func (svc *DownloadService) ProductStrategyDeps() *strategy.ProductStrategyDeps
 		LicenseServiceUrl: svc.licenseServiceUrl,
 		Mode:              svc.mode,
 		Config:            svc.config,
+		Flags:             svc.flags,
 		Locals:            svc.locals,
+		Mirror:            svc.mirror,
+		ObjectStore:       svc.objectStore,
+	}
+}
+
+func (svc *DownloadService) getFilteredVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+	if svc.versions != nil {
+		return svc.versions.get(params, func() ([]omnitruck.ProductVersion, *clients.Request) {
+			return svc.filterVersions(ctx, params)
+		})
 	}
+	return svc.filterVersions(ctx, params)
 }
 
-func (svc *DownloadService) getFilteredVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+func (svc *DownloadService) filterVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
 	productStrategy := strategy.SelectProductStrategy(params.Product, params.Channel, svc.ProductStrategyDeps())
 	modeStrategy := strategy.SelectModeStrategy(svc.mode)
-	versions, req := productStrategy.GetAllVersions(params)
+	versions, req := productStrategy.GetAllVersions(ctx, params)
 	if !req.Ok || len(versions) == 0 {
 		return nil, req
 	}
@@ -488,10 +1219,44 @@ func (svc *DownloadService) getFilteredVersions(params *omnitruck.RequestParams)
 	filtered := modeStrategy.FilterVersions(versions, params.Product, params.Eol)
 	if len(filtered) == 0 {
 		return nil, &clients.Request{
-			Ok:      false,
-			Code:    fiber.StatusBadRequest,
-			Message: "No versions found for this product/mode",
+			Ok:        false,
+			Code:      fiber.StatusBadRequest,
+			Message:   utils.NoVersionsError,
+			ErrorCode: apierror.CodeVersionNotFound,
 		}
 	}
 	return filtered, nil
 }
+
+// versionsMemo resolves the filtered versions of a channel, product and eol
+// once, for the items of a metadata batch
+type versionsMemo struct {
+	mu      sync.Mutex
+	entries map[string]*versionsEntry
+}
+
+type versionsEntry struct {
+	once     sync.Once
+	versions []omnitruck.ProductVersion
+	request  *clients.Request
+}
+
+func (m *versionsMemo) get(params *omnitruck.RequestParams, resolve func() ([]omnitruck.ProductVersion, *clients.Request)) ([]omnitruck.ProductVersion, *clients.Request) {
+	key := params.Channel + "/" + params.Product + "/" + params.Eol
+
+	m.mu.Lock()
+	if m.entries == nil {
+		m.entries = map[string]*versionsEntry{}
+	}
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &versionsEntry{}
+		m.entries[key] = entry
+	}
+	m.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.versions, entry.request = resolve()
+	})
+	return entry.versions, entry.request
+}
@@ -1,22 +1,35 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 
 	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
 	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/audit"
+	"github.com/chef/omnitruck-service/internal/cache"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/internal/notes"
+	"github.com/chef/omnitruck-service/mirror"
 	"github.com/chef/omnitruck-service/models"
 	"github.com/chef/omnitruck-service/utils/template"
 	"github.com/gofiber/fiber/v2"
@@ -28,7 +41,7 @@ import (
 func buildInjector(templateRenderer template.TemplateRenderer, omnitruckURL string) *do.Injector {
 	injector := do.New()
 	do.ProvideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
-		GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 			return []string{"1.0.0", "2.0.0"}, nil
 		},
 	})
@@ -313,7 +326,7 @@ func TestProductDownload_FailsOnEmptyVersions(t *testing.T) {
 	log := logrus.NewEntry(logrus.New())
 	svc, _ := NewDownloadService(injector, log, map[string]interface{}{"base_url": "http://x"})
 	params := &omnitruck.RequestParams{Product: "chef", Channel: "stable"}
-	_, _, _, msg, code, _ := svc.ProductDownload(params, &fiber.Ctx{})
+	_, _, _, msg, code, _ := svc.ProductDownload(context.Background(), params, &fiber.Ctx{})
 
 	assert.NotEqual(t, fiber.StatusOK, code)
 	assert.NotEmpty(t, msg)
@@ -349,13 +362,13 @@ func TestDownloadService_Products(t *testing.T) {
 			injector := do.New()
 
 			do.ProvideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
-				GetPackagesfunc: func(partitionValue, sortValue string) (interface{}, error) {
+				GetPackagesfunc: func(ctx context.Context, partitionValue, sortValue string) (interface{}, error) {
 					return nil, nil
 				},
-				GetVersionAllfunc: func(partitionValue string) ([]string, error) {
+				GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
 					return []string{"1.0.0"}, nil
 				},
-				GetRelatedProductsfunc: func(partitionValue string) (*models.RelatedProducts, error) {
+				GetRelatedProductsfunc: func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 					return &models.RelatedProducts{
 						Bom: "example-bom",
 						Products: map[string]string{
@@ -363,7 +376,7 @@ func TestDownloadService_Products(t *testing.T) {
 						},
 					}, nil
 				},
-				GetPackageManagersfunc: func() ([]string, error) {
+				GetPackageManagersfunc: func(ctx context.Context) ([]string, error) {
 					return []string{"apt"}, nil
 				},
 				SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
@@ -395,7 +408,7 @@ func TestDownloadService_Products(t *testing.T) {
 				Eol: tt.eolParam,
 			}
 
-			data, req := svc.Products(params)
+			data, req := svc.Products(context.Background(), params)
 
 			assert.NotNil(t, req)
 			assert.True(t, req.Ok)
@@ -419,7 +432,7 @@ func TestDownloadService_Platforms(t *testing.T) {
 
 	injector := do.New()
 	do.ProvideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
-		GetRelatedProductsfunc: func(partitionValue string) (*models.RelatedProducts, error) {
+		GetRelatedProductsfunc: func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 			return &models.RelatedProducts{Products: map[string]string{"test": "test"}}, nil
 		},
 	})
@@ -436,10 +449,13 @@ func TestDownloadService_Platforms(t *testing.T) {
 	svc, err := NewDownloadService(injector, logEntry, locals)
 	require.NoError(t, err)
 
-	data, req := svc.Platforms()
+	data, req := svc.Platforms(context.Background())
 
 	assert.True(t, req.Ok, "expected request to be OK")
 	assert.NotEmpty(t, data, "expected platform list from Omnitruck to be non-empty")
+	assert.Equal(t, "deb", data["debian"].PackageManager)
+	assert.Equal(t, "linux", data["ubuntu"].DbPlatform)
+	assert.Contains(t, data, "linux-kernel2")
 }
 
 func TestDownloadService_Architectures(t *testing.T) {
@@ -468,7 +484,7 @@ func TestDownloadService_Architectures(t *testing.T) {
 	svc, err := NewDownloadService(injector, logEntry, locals)
 	require.NoError(t, err)
 
-	data, req := svc.Architectures()
+	data, req := svc.Architectures(context.Background())
 
 	assert.True(t, req.Ok, "expected request to be OK")
 	assert.NotEmpty(t, data, "expected architectures list from Omnitruck to be non-empty")
@@ -532,7 +548,7 @@ func TestDownloadService_LatestVersion(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			data, req := svc.LatestVersion(tt.params)
+			data, req := svc.LatestVersion(context.Background(), tt.params)
 			if tt.expectSuccess {
 				assert.True(t, req.Ok, "expected LatestVersion to succeed")
 				assert.NotEmpty(t, data, "expected a latest version")
@@ -579,6 +595,7 @@ func TestDownloadService_ProductVersions(t *testing.T) {
 		name          string
 		params        *omnitruck.RequestParams
 		expectSuccess bool
+		expected      []omnitruck.ProductVersion
 	}{
 		{
 			name: "success",
@@ -596,15 +613,47 @@ func TestDownloadService_ProductVersions(t *testing.T) {
 			},
 			expectSuccess: false,
 		},
+		{
+			name: "constraint filters versions",
+			params: &omnitruck.RequestParams{
+				Product: "inspec",
+				Channel: "stable",
+				Version: "~> 5.0",
+			},
+			expectSuccess: true,
+			expected:      []omnitruck.ProductVersion{"5.0.0"},
+		},
+		{
+			name: "prefix filters versions",
+			params: &omnitruck.RequestParams{
+				Product: "inspec",
+				Channel: "stable",
+				Version: "4",
+			},
+			expectSuccess: true,
+			expected:      []omnitruck.ProductVersion{"4.0.0"},
+		},
+		{
+			name: "invalid constraint",
+			params: &omnitruck.RequestParams{
+				Product: "inspec",
+				Channel: "stable",
+				Version: ">= banana",
+			},
+			expectSuccess: false,
+		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			data, req := svc.ProductVersions(tt.params)
+			data, req := svc.ProductVersions(context.Background(), tt.params)
 			if tt.expectSuccess {
 				assert.True(t, req.Ok, "expected ProductVersions to succeed")
 				assert.NotEmpty(t, data, "expected non-empty versions")
+				if tt.expected != nil {
+					assert.Equal(t, tt.expected, data)
+				}
 			} else {
 				assert.False(t, req.Ok, "expected ProductVersions to fail")
 				assert.Empty(t, data, "expected no versions")
@@ -613,6 +662,286 @@ func TestDownloadService_ProductVersions(t *testing.T) {
 	}
 }
 
+func TestDownloadService_VersionDetails(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/stable/inspec/versions/all":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`["4.0.0", "5.0.0", "5.1.0", "6.0.0-rc.1"]`))
+		case r.URL.Path == "/stable/inspec/packages" && r.URL.Query().Get("v") == "5.0.0":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"windows":{"2019":{"x86_64":{"version":"5.0.0"}}},"el":{"8":{"x86_64":{"version":"5.0.0"}}}}`))
+		case r.URL.Path == "/stable/inspec/packages":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"el":{"8":{"x86_64":{"version":"4.0.0"}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	injector := buildInjector(&template.MockTemplateRenderer{}, ts.URL)
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		params    *omnitruck.RequestParams
+		query     VersionDetailsQuery
+		expected  []omnitruck.ProductVersion
+		total     int
+		platforms []string
+	}{
+		{
+			name:      "first page",
+			params:    &omnitruck.RequestParams{Product: "inspec", Channel: "stable"},
+			query:     VersionDetailsQuery{Page: 1, PerPage: 2},
+			expected:  []omnitruck.ProductVersion{"4.0.0", "5.0.0"},
+			total:     3,
+			platforms: []string{"el", "windows"},
+		},
+		{
+			name:      "second page in descending order",
+			params:    &omnitruck.RequestParams{Product: "inspec", Channel: "stable"},
+			query:     VersionDetailsQuery{Page: 2, PerPage: 2, Order: "desc"},
+			expected:  []omnitruck.ProductVersion{"4.0.0"},
+			total:     3,
+			platforms: []string{"el"},
+		},
+		{
+			name:     "page past the end",
+			params:   &omnitruck.RequestParams{Product: "inspec", Channel: "stable"},
+			query:    VersionDetailsQuery{Page: 5, PerPage: 2},
+			expected: []omnitruck.ProductVersion{},
+			total:    3,
+		},
+		{
+			name:      "version constraint and release filter",
+			params:    &omnitruck.RequestParams{Product: "inspec", Channel: "stable", Version: ">= 5.0.0"},
+			query:     VersionDetailsQuery{Prerelease: "false", PerPage: 1},
+			expected:  []omnitruck.ProductVersion{"5.0.0"},
+			total:     2,
+			platforms: []string{"el", "windows"},
+		},
+		{
+			name:     "open source filter",
+			params:   &omnitruck.RequestParams{Product: "inspec", Channel: "stable"},
+			query:    VersionDetailsQuery{OpenSource: "true"},
+			expected: []omnitruck.ProductVersion{"4.0.0"},
+			total:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			data, req := svc.VersionDetails(context.Background(), tt.params, tt.query)
+			require.True(t, req.Ok, req.Message)
+			assert.Equal(t, tt.total, data.Total)
+
+			versions := []omnitruck.ProductVersion{}
+			for _, d := range data.Versions {
+				versions = append(versions, d.Version)
+			}
+			assert.Equal(t, tt.expected, versions)
+			if tt.platforms != nil {
+				assert.Equal(t, tt.platforms, data.Versions[len(data.Versions)-1].Platforms)
+			}
+		})
+	}
+
+	// Pre-releases never satisfy the supported version constraint so they only show up with eol=true
+	data, req := svc.VersionDetails(context.Background(), &omnitruck.RequestParams{Product: "inspec", Channel: "stable", Eol: "true"}, VersionDetailsQuery{Prerelease: "true"})
+	require.True(t, req.Ok, req.Message)
+	require.Len(t, data.Versions, 1)
+	assert.Equal(t, omnitruck.ProductVersion("6.0.0-rc.1"), data.Versions[0].Version)
+	assert.True(t, data.Versions[0].Eol)
+	assert.False(t, data.Versions[0].OpenSource)
+	assert.Equal(t, DefaultVersionDetailsPerPage, data.PerPage)
+}
+
+func TestDownloadService_VersionDetails_ReleaseDates(t *testing.T) {
+	injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+			return []string{"4.10.1", "4.13.0"}, nil
+		},
+		GetReleaseDatesfunc: func(ctx context.Context, partitionValue string) (map[string]string, error) {
+			return map[string]string{"4.13.0": "2024-05-02"}, nil
+		},
+		GetPackagesfunc: func(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+			return &models.ProductDetails{
+				Product:  partitionValue,
+				Version:  sortValue,
+				MetaData: []models.MetaData{{Platform: "linux", Architecture: "x86_64"}},
+			}, nil
+		},
+		SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+	})
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+	require.NoError(t, err)
+
+	data, req := svc.VersionDetails(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable"}, VersionDetailsQuery{})
+	require.True(t, req.Ok, req.Message)
+	require.Len(t, data.Versions, 2)
+	assert.Equal(t, "", data.Versions[0].ReleaseDate)
+	assert.Equal(t, "2024-05-02", data.Versions[1].ReleaseDate)
+	assert.Equal(t, []string{"linux"}, data.Versions[1].Platforms)
+}
+
+func TestDownloadService_RequestContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	cancel()
+
+	var got []context.Context
+	injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+			got = append(got, ctx)
+			return nil, ctx.Err()
+		},
+		SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+	})
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+	require.NoError(t, err)
+
+	_, req := svc.ProductVersions(ctx, &omnitruck.RequestParams{Product: "automate", Channel: "stable"})
+	assert.False(t, req.Ok)
+	require.NotEmpty(t, got, "the catalog was not queried")
+	for _, c := range got {
+		assert.Equal(t, "request", c.Value(ctxKey{}))
+		assert.ErrorIs(t, c.Err(), context.Canceled)
+	}
+}
+
+func releaseNotesService(t *testing.T, notesSource notes.Source) (*DownloadService, *cache.Cache) {
+	injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+			return []string{"4.10.1", "4.13.0", "4.14.2", "4.15.0"}, nil
+		},
+		GetPackagesfunc: func(ctx context.Context, partitionValue string, sortValue string) (interface{}, error) {
+			details := &models.ProductDetails{Product: partitionValue, Version: sortValue}
+			if sortValue == "4.13.0" {
+				details.ReleaseNotes = "Catalog notes for 4.13.0"
+			}
+			return details, nil
+		},
+		SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+	})
+	notesCache := cache.New("notes", time.Minute, 0)
+	do.ProvideNamedValue[*cache.Cache](injector, "notesCache", notesCache)
+	if notesSource != nil {
+		do.ProvideNamedValue[notes.Source](injector, "notesSource", notesSource)
+	}
+
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+	require.NoError(t, err)
+	return svc, notesCache
+}
+
+func TestDownloadService_ReleaseNotes(t *testing.T) {
+	urlNotes := notes.SourceFunc(func(ctx context.Context, key notes.Key) (string, error) {
+		switch key.Version {
+		case "4.15.0":
+			return "URL notes for 4.15.0", nil
+		case "4.10.1":
+			return "", errors.New("connection refused")
+		}
+		return "", notes.ErrNotFound
+	})
+
+	tests := []struct {
+		name         string
+		source       notes.Source
+		version      string
+		expectedCode int
+		expected     omnitruck.ReleaseNote
+	}{
+		{
+			name:         "catalog notes",
+			source:       urlNotes,
+			version:      "4.13",
+			expectedCode: fiber.StatusOK,
+			expected:     omnitruck.ReleaseNote{Version: "4.13.0", Notes: "Catalog notes for 4.13.0"},
+		},
+		{
+			name:         "latest falls back to the notes source",
+			source:       urlNotes,
+			version:      "latest",
+			expectedCode: fiber.StatusOK,
+			expected:     omnitruck.ReleaseNote{Version: "4.15.0", Notes: "URL notes for 4.15.0"},
+		},
+		{
+			name:         "no notes anywhere",
+			source:       urlNotes,
+			version:      "4.14.2",
+			expectedCode: fiber.StatusNotFound,
+		},
+		{
+			name:         "catalog only",
+			version:      "4.15.0",
+			expectedCode: fiber.StatusNotFound,
+		},
+		{
+			name:         "source failure",
+			source:       urlNotes,
+			version:      "4.10.1",
+			expectedCode: fiber.StatusBadGateway,
+		},
+		{
+			name:         "unknown version",
+			source:       urlNotes,
+			version:      "5.0.0",
+			expectedCode: fiber.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := releaseNotesService(t, tt.source)
+			data, req := svc.ReleaseNotes(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable", Version: tt.version})
+			assert.Equal(t, tt.expectedCode, req.Code, req.Message)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestDownloadService_ReleaseNotesRange(t *testing.T) {
+	urlNotes := notes.SourceFunc(func(ctx context.Context, key notes.Key) (string, error) {
+		if key.Version == "4.15.0" {
+			return "URL notes for 4.15.0\n", nil
+		}
+		return "", notes.ErrNotFound
+	})
+	svc, notesCache := releaseNotesService(t, urlNotes)
+
+	data, req := svc.ReleaseNotesRange(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable"}, "4.10.1")
+	require.True(t, req.Ok, req.Message)
+	assert.Equal(t, omnitruck.ProductVersion("4.10.1"), data.From)
+	assert.Equal(t, omnitruck.ProductVersion("4.15.0"), data.To)
+	assert.Equal(t, []omnitruck.ReleaseNote{
+		{Version: "4.15.0", Notes: "URL notes for 4.15.0\n"},
+		{Version: "4.13.0", Notes: "Catalog notes for 4.13.0"},
+	}, data.Versions)
+	assert.Equal(t, "## 4.15.0\n\nURL notes for 4.15.0\n\n## 4.13.0\n\nCatalog notes for 4.13.0\n", data.Notes)
+	assert.Equal(t, 2, notesCache.Stats().Entries)
+
+	data, req = svc.ReleaseNotesRange(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable", Version: "4.14"}, "4.13.0")
+	require.True(t, req.Ok, req.Message)
+	assert.Empty(t, data.Versions)
+	assert.Equal(t, "", data.Notes)
+
+	_, req = svc.ReleaseNotesRange(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable", Version: "4.10.1"}, "4.13.0")
+	assert.Equal(t, fiber.StatusBadRequest, req.Code)
+	assert.Equal(t, "from must not be newer than to", req.Message)
+
+	_, req = svc.ReleaseNotesRange(context.Background(), &omnitruck.RequestParams{Product: "automate", Channel: "stable"}, "banana")
+	assert.Equal(t, fiber.StatusBadRequest, req.Code)
+}
+
 func TestDownloadService_ProductPackages(t *testing.T) {
 	t.Parallel()
 
@@ -716,7 +1045,7 @@ func TestDownloadService_ProductPackages(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			data, req := svc.ProductPackages(tt.params)
+			data, req := svc.ProductPackages(context.Background(), tt.params)
 
 			if tt.expectSuccess {
 				assert.True(t, req.Ok, "expected ok true")
@@ -734,6 +1063,146 @@ func TestDownloadService_ProductPackages(t *testing.T) {
 	}
 }
 
+func TestDownloadService_ProductPackagesV2(t *testing.T) {
+	t.Parallel()
+
+	logEntry := logrus.NewEntry(logrus.New())
+	locals := map[string]interface{}{"base_url": "http://example.com"}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/versions/all"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`["16.0.0", "17.0.0"]`))
+		case strings.Contains(r.URL.Path, "/packages"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"ubuntu":{"20.04":{"x86_64":{"sha256":"a256","url":"https://packages.chef.io/files/stable/chef/16.0.0/ubuntu/20.04/chef_16.0.0-1_amd64.deb","version":"16.0.0"}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer ts.Close()
+
+	injector := buildInjector(&template.MockTemplateRenderer{}, ts.URL)
+	svc, err := NewDownloadService(injector, logEntry, locals)
+	require.NoError(t, err)
+
+	data, req := svc.ProductPackagesV2(context.Background(), &omnitruck.RequestParams{Product: "chef", Channel: "stable", Version: "16.0.0"})
+	require.True(t, req.Ok)
+	assert.Equal(t, omnitruck.Packages{
+		Product: "chef",
+		Version: "16.0.0",
+		Packages: []omnitruck.Package{{
+			Platform:        "ubuntu",
+			PlatformVersion: "20.04",
+			Architecture:    "x86_64",
+			Version:         "16.0.0",
+			Url:             "http://example.com/stable/chef/download?m=x86_64&p=ubuntu&pv=20.04&v=16.0.0",
+			FileName:        "chef_16.0.0-1_amd64.deb",
+			Sha256:          "a256",
+		}},
+	}, data)
+
+	data, req = svc.ProductPackagesV2(context.Background(), &omnitruck.RequestParams{Product: "chef", Channel: "stable", Version: "invalid-version"})
+	assert.False(t, req.Ok)
+	assert.Equal(t, fiber.StatusBadRequest, req.Code)
+	assert.Empty(t, data.Packages)
+}
+
+func TestDownloadService_SupportMatrix(t *testing.T) {
+	t.Parallel()
+
+	logEntry := logrus.NewEntry(logrus.New())
+	locals := map[string]interface{}{"base_url": "http://example.com"}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "invalid-product"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		case strings.Contains(r.URL.Path, "/versions/all"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`["16.0.0", "17.0.0"]`))
+		case strings.Contains(r.URL.Path, "/packages"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"ubuntu":{"20.04":{"x86_64":{"sha1":"a1","sha256":"a256","url":"https://packages.chef.io/files/stable/chef/16.0.0/ubuntu/20.04/chef_16.0.0-1_amd64.deb","version":"16.0.0"}}},` +
+				`"el":{"8":{"x86_64":{"sha256":"b256","url":"https://packages.chef.io/files/stable/chef/16.0.0/el/8/chef-16.0.0-1.el8.x86_64.rpm","version":"16.0.0"}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer ts.Close()
+
+	mockTemplate := &template.MockTemplateRenderer{
+		GetScriptfunc: func(baseUrl string, params *omnitruck.RequestParams, filePath string) (string, error) {
+			return "mock script", nil
+		},
+	}
+
+	injector := buildInjector(mockTemplate, ts.URL)
+
+	svc, err := NewDownloadService(injector, logEntry, locals)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		params     *omnitruck.RequestParams
+		expectCode int
+		expected   omnitruck.SupportMatrix
+	}{
+		{
+			name:       "success flattens packages",
+			params:     &omnitruck.RequestParams{Product: "chef", Channel: "stable", Version: "16.0.0"},
+			expectCode: fiber.StatusOK,
+			expected: omnitruck.SupportMatrix{
+				Product: "chef",
+				Version: "16.0.0",
+				Entries: []omnitruck.SupportMatrixEntry{
+					{Platform: "el", PlatformVersion: "8", Architecture: "x86_64", FileName: "chef-16.0.0-1.el8.x86_64.rpm", Sha256: "b256"},
+					{Platform: "ubuntu", PlatformVersion: "20.04", Architecture: "x86_64", FileName: "chef_16.0.0-1_amd64.deb", Sha1: "a1", Sha256: "a256"},
+				},
+			},
+		},
+		{
+			name:       "defaults to the latest version",
+			params:     &omnitruck.RequestParams{Product: "chef", Channel: "stable"},
+			expectCode: fiber.StatusOK,
+		},
+		{
+			name:       "error on invalid product",
+			params:     &omnitruck.RequestParams{Product: "invalid-product", Channel: "stable"},
+			expectCode: fiber.StatusBadRequest,
+		},
+		{
+			name:       "error on unknown version",
+			params:     &omnitruck.RequestParams{Product: "chef", Channel: "stable", Version: "invalid-version"},
+			expectCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			data, req := svc.SupportMatrix(context.Background(), tt.params)
+			assert.Equal(t, tt.expectCode, req.Code)
+			if req.Ok && tt.expected.Product != "" {
+				assert.Equal(t, tt.expected, data)
+			} else if req.Ok {
+				assert.Equal(t, omnitruck.ProductVersion("17.0.0"), data.Version)
+				assert.Len(t, data.Entries, 2)
+			} else {
+				assert.Empty(t, data.Entries)
+			}
+		})
+	}
+}
+
 func TestDownloadService_ProductMetadata(t *testing.T) {
 	t.Parallel()
 
@@ -839,7 +1308,7 @@ func TestDownloadService_ProductMetadata(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			data, req := svc.ProductMetadata(tt.params)
+			data, req := svc.ProductMetadata(context.Background(), tt.params)
 			if tt.expectSuccess {
 				assert.True(t, req.Ok, "expected ProductMetadata to succeed")
 				assert.Equal(t, tt.expectCode, req.Code)
@@ -864,7 +1333,7 @@ func TestDownloadService_RelatedProducts(t *testing.T) {
 
 	injector := do.New()
 	do.ProvideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
-		GetRelatedProductsfunc: func(partitionValue string) (*models.RelatedProducts, error) {
+		GetRelatedProductsfunc: func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 			if partitionValue == "valid-bom" {
 				return &models.RelatedProducts{
 					Bom:      "valid-bom",
@@ -910,7 +1379,7 @@ func TestDownloadService_RelatedProducts(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			data, req := svc.RelatedProducts(tt.params)
+			data, req := svc.RelatedProducts(context.Background(), tt.params)
 
 			if tt.expectSuccess {
 				assert.True(t, req.Ok, "expected ok true")
@@ -959,7 +1428,7 @@ func TestDownloadService_RelatedProducts_SupportInfra19(t *testing.T) {
 
 			injector := do.New()
 			do.ProvideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
-				GetRelatedProductsfunc: func(partitionValue string) (*models.RelatedProducts, error) {
+				GetRelatedProductsfunc: func(ctx context.Context, partitionValue string) (*models.RelatedProducts, error) {
 					return &models.RelatedProducts{
 						Bom: "test-bom",
 						Products: map[string]string{
@@ -987,7 +1456,7 @@ func TestDownloadService_RelatedProducts_SupportInfra19(t *testing.T) {
 				BOM: "test-bom",
 			}
 
-			data, req := svc.RelatedProducts(params)
+			data, req := svc.RelatedProducts(context.Background(), params)
 
 			assert.True(t, req.Ok, "expected ok true")
 			assert.Equal(t, fiber.StatusOK, req.Code)
@@ -1041,13 +1510,50 @@ func TestDownloadService_GetFileName_InvalidProduct(t *testing.T) {
 		Channel: "stable",
 	}
 
-	fileName, req := svc.GetFileName(params)
+	fileName, req := svc.GetFileName(context.Background(), params)
 
 	assert.False(t, req.Ok, "expected ok false for invalid product")
 	assert.Equal(t, fiber.StatusBadRequest, req.Code, "expected 400 bad request for invalid product")
 	assert.Empty(t, fileName, "expected empty filename for invalid product")
 }
 
+func TestDownloadService_GetFileName_StrategyError(t *testing.T) {
+	tests := []struct {
+		name              string
+		metaErr           error
+		expectedCode      int
+		expectedErrorCode string
+	}{
+		{name: "catalog unavailable", metaErr: apierror.Wrap(apierror.ErrUnavailable, errors.New("table missing")), expectedCode: fiber.StatusServiceUnavailable, expectedErrorCode: apierror.CodeCatalogUnavailable},
+		{name: "no catalog entry", expectedCode: fiber.StatusBadRequest, expectedErrorCode: apierror.CodeProductNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+			do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+				GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+					return []string{"19.1.2"}, nil
+				},
+				GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+					return nil, tt.metaErr
+				},
+				SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+			})
+			svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+			require.NoError(t, err)
+
+			params := &omnitruck.RequestParams{Channel: "stable", Product: "chef-ice", Version: "19.1.2", Platform: "linux", Architecture: "x86_64", PackageManager: "deb", Eol: "false"}
+			fileName, req := svc.GetFileName(context.Background(), params)
+
+			assert.Empty(t, fileName)
+			assert.False(t, req.Ok)
+			assert.Equal(t, tt.expectedCode, req.Code)
+			assert.Equal(t, tt.expectedErrorCode, req.ErrorCode)
+			assert.NotContains(t, req.Message, "table missing")
+		})
+	}
+}
+
 func TestDownloadService_GetScripts(t *testing.T) {
 	t.Parallel()
 
@@ -1173,7 +1679,7 @@ func TestDownloadService_GetPackageManagers(t *testing.T) {
 	injector := do.New()
 
 	mockDb := &dboperations.MockIDbOperations{
-		GetPackageManagersfunc: func() ([]string, error) {
+		GetPackageManagersfunc: func(ctx context.Context) ([]string, error) {
 			return []string{"yum", "apt"}, nil
 		},
 	}
@@ -1189,7 +1695,7 @@ func TestDownloadService_GetPackageManagers(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("success returns package managers", func(t *testing.T) {
-		data, req := svc.GetPackageManagers()
+		data, req := svc.GetPackageManagers(context.Background())
 		assert.True(t, req.Ok)
 		assert.Equal(t, fiber.StatusOK, req.Code)
 		assert.NotNil(t, data)
@@ -1197,11 +1703,11 @@ func TestDownloadService_GetPackageManagers(t *testing.T) {
 	})
 
 	t.Run("error returns failure response", func(t *testing.T) {
-		mockDb.GetPackageManagersfunc = func() ([]string, error) {
+		mockDb.GetPackageManagersfunc = func(ctx context.Context) ([]string, error) {
 			return nil, fmt.Errorf("dynamo down")
 		}
 
-		data, req := svc.GetPackageManagers()
+		data, req := svc.GetPackageManagers(context.Background())
 		assert.False(t, req.Ok)
 		assert.Equal(t, fiber.StatusInternalServerError, req.Code)
 		assert.Nil(t, data)
@@ -1209,3 +1715,384 @@ func TestDownloadService_GetPackageManagers(t *testing.T) {
 	})
 
 }
+
+func TestProductDownload_EmitsAuditEvent(t *testing.T) {
+	injector := buildInjector(&template.MockTemplateRenderer{}, "https://omnitruck.chef.io")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+			return []string{"4.10.1"}, nil
+		},
+		GetVersionLatestfunc: func(ctx context.Context, partitionValue string) (string, error) {
+			return "4.10.1", nil
+		},
+		GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			return &models.MetaData{FileName: "chef-automate_linux_amd64.zip", Platform: platform, Architecture: architecture}, nil
+		},
+		SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+	})
+	sink := &audit.MockSink{}
+	do.ProvideNamedValue[audit.Sink](injector, "auditor", sink)
+
+	log := logrus.NewEntry(logrus.New())
+	locals := map[string]interface{}{"base_url": "http://x", "requestid": "req-1", "license_id": "lic-1"}
+	svc, err := NewDownloadService(injector, log, locals)
+	require.NoError(t, err)
+
+	params := &omnitruck.RequestParams{Product: "automate", Channel: "current", Platform: "linux", Architecture: "amd64", LicenseId: "lic-1"}
+	url, _, _, _, _, err := svc.ProductDownload(context.Background(), params, &fiber.Ctx{})
+	require.NoError(t, err)
+	require.NotEmpty(t, url)
+
+	require.Len(t, sink.Events, 1)
+	event := sink.Events[0]
+	assert.Equal(t, audit.EndpointDownload, event.Endpoint)
+	assert.Equal(t, "req-1", event.RequestId)
+	assert.Equal(t, "lic-1", event.LicenseId)
+	assert.Equal(t, "commercial", event.Mode)
+	assert.Equal(t, "automate", event.Product)
+	assert.Equal(t, "4.10.1", event.Version)
+	assert.Equal(t, "chef-automate_linux_amd64.zip", event.FileName)
+	assert.Equal(t, "dynamo", event.Strategy)
+	assert.Equal(t, fiber.StatusFound, event.Status)
+	assert.Empty(t, event.Error)
+}
+
+type cloningDbOperations struct {
+	*dboperations.MockIDbOperations
+	clones *int32
+}
+
+func (c cloningDbOperations) Clone() dboperations.IDbOperations {
+	atomic.AddInt32(c.clones, 1)
+	return c
+}
+
+func TestDownloadService_ProductMetadataBatch(t *testing.T) {
+	var clones, versionLookups int32
+	injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", cloningDbOperations{
+		MockIDbOperations: &dboperations.MockIDbOperations{
+			GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+				atomic.AddInt32(&versionLookups, 1)
+				return []string{"1.0.0", "2.0.0"}, nil
+			},
+			GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+				if platform != "linux" {
+					return nil, errors.New("ResourceNotFoundException: Requested resource not found")
+				}
+				return &models.MetaData{Platform: platform, Architecture: architecture, SHA256: "sha-" + sortValue}, nil
+			},
+			SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+		},
+		clones: &clones,
+	})
+	do.OverrideNamedValue[config.ServiceConfig](injector, "config", config.ServiceConfig{MetadataBatchConcurrency: 2})
+
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{"base_url": "http://example.com"})
+	require.NoError(t, err)
+
+	var items []*omnitruck.RequestParams
+	for i := 0; i < 6; i++ {
+		platform := "linux"
+		if i == 3 {
+			platform = "windows"
+		}
+		items = append(items, &omnitruck.RequestParams{
+			Channel:      "stable",
+			Product:      "automate",
+			Version:      "2.0.0",
+			Platform:     platform,
+			Architecture: "amd64",
+			Eol:          "false",
+		})
+	}
+
+	results := svc.ProductMetadataBatch(context.Background(), items)
+
+	require.Len(t, results, len(items))
+	for i, result := range results {
+		assert.Same(t, items[i], result.Params)
+		if i == 3 {
+			assert.False(t, result.Request.Ok)
+			continue
+		}
+		assert.True(t, result.Request.Ok, result.Request.Message)
+		assert.Equal(t, "sha-2.0.0", result.Metadata.Sha256)
+	}
+	assert.Equal(t, int32(len(items)), atomic.LoadInt32(&clones), "each item should use its own database handle")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&versionLookups), "items of one product should share their versions")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = svc.ProductMetadataBatch(ctx, items)
+	for _, result := range results {
+		assert.False(t, result.Request.Ok)
+		assert.Equal(t, fiber.StatusServiceUnavailable, result.Request.Code)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&versionLookups), "a cancelled batch should not query the catalog")
+}
+
+type filesContext struct {
+	params map[string]string
+}
+
+func (c filesContext) Params(key string, defaultValue ...string) string {
+	return c.params[key]
+}
+
+func (c filesContext) Query(key string, defaultValue ...string) string {
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (c filesContext) BaseURL() string {
+	return "http://example.com"
+}
+
+func TestDownloadService_Signatures(t *testing.T) {
+	injector := buildInjector(&template.MockTemplateRenderer{}, "https://omnitruck.chef.io")
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+		GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+			return []string{"4.10.1"}, nil
+		},
+		GetVersionLatestfunc: func(ctx context.Context, partitionValue string) (string, error) {
+			return "4.10.1", nil
+		},
+		GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+			return &models.MetaData{
+				FileName:     "chef-automate_linux_amd64.zip",
+				Platform:     platform,
+				Architecture: architecture,
+				SHA256:       "abcd",
+				SignatureUrl: "https://packages.example.com/chef-automate_linux_amd64.zip.asc",
+			}, nil
+		},
+		SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+	})
+
+	log := logrus.NewEntry(logrus.New())
+	svc, err := NewDownloadService(injector, log, map[string]interface{}{"base_url": "http://example.com"})
+	require.NoError(t, err)
+
+	t.Run("metadata points at the files companion", func(t *testing.T) {
+		params := &omnitruck.RequestParams{Product: "automate", Channel: "current", Version: "4.10.1", Platform: "linux", Architecture: "amd64"}
+		data, req := svc.ProductMetadata(context.Background(), params)
+		require.True(t, req.Ok, req.Message)
+		assert.Equal(t, "http://example.com/files/current/automate/4.10.1/linux/amd64/chef-automate_linux_amd64.zip.asc?license_id=", data.SignatureUrl)
+	})
+
+	tests := []struct {
+		name         string
+		fileName     string
+		expectedURL  string
+		expectedCode int
+	}{
+		{
+			name:        "gpg signature redirects to the catalog",
+			fileName:    "chef-automate_linux_amd64.zip.asc",
+			expectedURL: "https://packages.example.com/chef-automate_linux_amd64.zip.asc",
+		},
+		{
+			name:         "missing cosign signature",
+			fileName:     "chef-automate_linux_amd64.zip.sig",
+			expectedCode: fiber.StatusNotFound,
+		},
+		{
+			name:         "signature of another package",
+			fileName:     "chef-automate_linux_arm64.zip.asc",
+			expectedCode: fiber.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := filesContext{params: map[string]string{
+				"channel":  "current",
+				"product":  "automate",
+				"version":  "4.10.1",
+				"platform": "linux",
+				"*":        "amd64/" + tt.fileName,
+			}}
+			url, _, _, _, code, err := svc.ProductFilesDownload(context.Background(), c)
+			assert.Equal(t, tt.expectedURL, url)
+			assert.Equal(t, tt.expectedCode, code)
+			if tt.expectedCode != 0 {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDownloadService_Mirror(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	pkg := omnitruck.Package{Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64", PackageManager: "deb", Version: "18.2.7", FileName: "chef-18.2.7.deb", Sha256: "abc"}
+	entryPath, err := mirror.EntryPath("stable", "chef", pkg)
+	require.NoError(t, err)
+	index := &mirror.Index{Packages: []mirror.Entry{
+		{Channel: "stable", Product: "chef", Path: entryPath, Package: pkg},
+	}}
+	catalog := mirror.NewCatalog(index, fstest.MapFS{
+		entryPath: {Data: []byte("package")},
+	})
+
+	injector := buildInjector(&template.MockTemplateRenderer{}, upstream.URL)
+	do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", catalog)
+	svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{"base_url": "http://mirror.local"})
+	require.NoError(t, err)
+
+	products, request := svc.Products(context.Background(), &omnitruck.RequestParams{})
+	assert.True(t, request.Ok)
+	assert.Equal(t, omnitruck.ItemList{"chef"}, products)
+
+	architectures, request := svc.Architectures(context.Background())
+	assert.True(t, request.Ok)
+	assert.Equal(t, omnitruck.ItemList{"x86_64"}, architectures)
+
+	_, request = svc.Platforms(context.Background())
+	assert.True(t, request.Ok)
+
+	packages, request := svc.ProductPackagesV2(context.Background(), &omnitruck.RequestParams{Channel: "stable", Product: "chef", Version: "18.2.7"})
+	require.True(t, request.Ok, request.Message)
+	require.Len(t, packages.Packages, 1)
+	assert.Equal(t, "22.04", packages.Packages[0].PlatformVersion)
+	assert.Equal(t, "deb", packages.Packages[0].PackageManager)
+
+	params := &omnitruck.RequestParams{Channel: "stable", Product: "chef", Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64", Eol: "false"}
+	url, body, header, _, _, err := svc.ProductDownload(context.Background(), params, &fiber.Ctx{})
+	require.NoError(t, err)
+	assert.Empty(t, url)
+	defer body.Close()
+	assert.Equal(t, "attachment; filename=chef-18.2.7.deb", header.Get("Content-Disposition"))
+	assert.Equal(t, "18.2.7", params.Version)
+}
+
+func TestDownloadService_ProductSbom_OtherFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       string
+		expectedCode int
+		expectBody   bool
+	}{
+		{name: "stored next to the package", stored: ".spdx.json", expectBody: true},
+		{name: "not stored", stored: ".cdx.json", expectedCode: fiber.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+			do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+				GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+					return []string{"19.1.2"}, nil
+				},
+				GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+					return &models.MetaData{
+						Platform:     platform,
+						Architecture: architecture,
+						FileName:     "chef-ice_19.1.2_amd64.deb",
+						SbomUrl:      "https://packages.example.com/chef-ice_19.1.2_amd64.deb.cdx.json",
+					}, nil
+				},
+				SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+			})
+			do.ProvideNamedValue[s3aws.ObjectStore](injector, "objectStore", &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					keys = append(keys, key)
+					if !strings.HasSuffix(key, tt.stored) {
+						return nil, s3aws.ErrObjectNotFound
+					}
+					return &s3aws.Object{Body: io.NopCloser(strings.NewReader("{}")), ContentLength: -1}, nil
+				},
+			})
+			svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+			require.NoError(t, err)
+
+			params := &omnitruck.RequestParams{Channel: "stable", Product: "chef-ice", Version: "19.1.2", Platform: "linux", Architecture: "x86_64", PackageManager: "deb", Eol: "false"}
+			_, body, header, _, code, err := svc.ProductSbom(context.Background(), params, helpers.SPDX)
+
+			require.Len(t, keys, 1)
+			assert.True(t, strings.HasSuffix(keys[0], ".deb.spdx.json"), keys[0])
+			assert.Equal(t, tt.expectedCode, code)
+			if tt.expectBody {
+				require.NoError(t, err)
+				require.NotNil(t, body)
+				body.Close()
+				assert.Equal(t, helpers.SPDX.MediaType, header.Get("Content-Type"))
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, body)
+			}
+		})
+	}
+}
+
+func TestDownloadService_ProductSbom_NotLinked(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       helpers.SbomFormat
+		stored       string
+		expectedKeys []string
+		expectedType string
+		expectedCode int
+	}{
+		{name: "first stored format", stored: ".spdx.json", expectedKeys: []string{".deb.cdx.json", ".deb.spdx.json"}, expectedType: helpers.SPDX.MediaType},
+		{name: "requested format", format: helpers.CycloneDX, stored: ".cdx.json", expectedKeys: []string{".deb.cdx.json"}, expectedType: helpers.CycloneDX.MediaType},
+		{name: "requested format not stored", format: helpers.CycloneDX, stored: ".spdx.json", expectedKeys: []string{".deb.cdx.json"}, expectedCode: fiber.StatusNotFound},
+		{name: "not stored", stored: ".none", expectedKeys: []string{".deb.cdx.json", ".deb.spdx.json"}, expectedCode: fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			injector := buildInjector(&template.MockTemplateRenderer{}, "http://omnitruck.invalid")
+			do.OverrideNamedValue[dboperations.IDbOperations](injector, "dbService", &dboperations.MockIDbOperations{
+				GetVersionAllfunc: func(ctx context.Context, partitionValue string) ([]string, error) {
+					return []string{"19.1.2"}, nil
+				},
+				GetMetaDatafunc: func(ctx context.Context, partitionValue, sortValue, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+					return &models.MetaData{
+						Platform:     platform,
+						Architecture: architecture,
+						FileName:     "chef-ice_19.1.2_amd64.deb",
+					}, nil
+				},
+				SetDbInfofunc: func(tableName string, dbModel reflect.Type) {},
+			})
+			do.ProvideNamedValue[s3aws.ObjectStore](injector, "objectStore", &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					keys = append(keys, key)
+					if !strings.HasSuffix(key, tt.stored) {
+						return nil, s3aws.ErrObjectNotFound
+					}
+					return &s3aws.Object{Body: io.NopCloser(strings.NewReader("{}")), ContentLength: -1}, nil
+				},
+			})
+			svc, err := NewDownloadService(injector, logrus.NewEntry(logrus.New()), map[string]interface{}{})
+			require.NoError(t, err)
+
+			params := &omnitruck.RequestParams{Channel: "stable", Product: "chef-ice", Version: "19.1.2", Platform: "linux", Architecture: "x86_64", PackageManager: "deb", Eol: "false"}
+			_, body, header, _, code, err := svc.ProductSbom(context.Background(), params, tt.format)
+
+			require.Len(t, keys, len(tt.expectedKeys))
+			for i, suffix := range tt.expectedKeys {
+				assert.True(t, strings.HasSuffix(keys[i], suffix), keys[i])
+			}
+			assert.Equal(t, tt.expectedCode, code)
+			if tt.expectedType != "" {
+				require.NoError(t, err)
+				require.NotNil(t, body)
+				body.Close()
+				assert.Equal(t, tt.expectedType, header.Get("Content-Type"))
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, body)
+			}
+		})
+	}
+}
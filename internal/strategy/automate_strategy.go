@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
 	log "github.com/sirupsen/logrus"
 )
@@ -19,25 +21,25 @@ type ProductDynamoStrategy struct {
 	Log              *log.Entry
 }
 
-func (s *ProductDynamoStrategy) GetLatestVersion(params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
+func (s *ProductDynamoStrategy) GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
 	request := clients.Request{}
-	data, err := s.DynamoService.VersionLatest(params)
+	data, err := s.DynamoService.VersionLatest(ctx, params)
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
 		s.Log.WithError(err).Error("Error while fetching latest version for Automate/Habitat")
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return data, &request
 	}
 	request.Success()
 	return data, &request
 }
 
-func (s *ProductDynamoStrategy) GetAllVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+func (s *ProductDynamoStrategy) GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
 	request := clients.Request{}
-	data, err := s.DynamoService.VersionAll(params)
+	data, err := s.DynamoService.VersionAll(ctx, params)
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return nil, &request
 	}
 
@@ -48,25 +50,33 @@ func (s *ProductDynamoStrategy) GetAllVersions(params *omnitruck.RequestParams)
 	return data, &request
 }
 
-func (s *ProductDynamoStrategy) GetPackages(params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
-	return s.DynamoService.ProductPackages(params)
+func (s *ProductDynamoStrategy) GetReleaseDates(ctx context.Context, params *omnitruck.RequestParams) (map[string]string, error) {
+	return s.DynamoService.ReleaseDates(ctx, params)
 }
 
-func (s *ProductDynamoStrategy) GetMetadata(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
+func (s *ProductDynamoStrategy) GetReleaseNotes(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
+	return s.DynamoService.ReleaseNotes(ctx, params)
+}
+
+func (s *ProductDynamoStrategy) GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+	return s.DynamoService.ProductPackages(ctx, params)
+}
+
+func (s *ProductDynamoStrategy) GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
 	request := &clients.Request{}
 	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
-	data, err := s.DynamoService.ProductMetadata(params)
+	data, err := s.DynamoService.ProductMetadata(ctx, params)
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 	} else {
 		request.Success()
 	}
 	return data, request
 }
 
-func (s *ProductDynamoStrategy) Download(params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
-	url, err = s.DynamoService.ProductDownload(params)
+func (s *ProductDynamoStrategy) Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	url, err = s.DynamoService.ProductDownload(ctx, params)
 
 	// Append licenseId query parameter if present
 	// Note: This URL does not have any existing query parameters
@@ -77,16 +87,27 @@ func (s *ProductDynamoStrategy) Download(params *omnitruck.RequestParams) (url s
 	return url, nil, nil, "", 0, err
 }
 
-func (s *ProductDynamoStrategy) GetFileName(params *omnitruck.RequestParams) (string, error) {
+// DownloadSignature redirects to the signature the catalog records for the package.
+func (s *ProductDynamoStrategy) DownloadSignature(ctx context.Context, params *omnitruck.RequestParams, ext string) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
+	url, err = catalogSignatureUrl(ctx, s.DynamoService, params, ext)
+	if err != nil {
+		code, msg := helpers.GetErrorCodeAndMsg(err)
+		return "", nil, nil, msg, code, err
+	}
+	return url, nil, nil, "", 0, nil
+}
+
+func (s *ProductDynamoStrategy) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
-	fileName, err := s.DynamoService.GetFilename(params)
+	fileName, err := s.DynamoService.GetFilename(ctx, params)
 	return fileName, err
 }
 
 // ValidateFilesParams for Automate/Habitat has no strategy-specific required fields.
-func (s *ProductDynamoStrategy) ValidateFilesParams(params *omnitruck.RequestParams) error {
+func (s *ProductDynamoStrategy) ValidateFilesParams(ctx context.Context, params *omnitruck.RequestParams) error {
 	// Validate filename matches what database expects
-	correctFileName, err := s.GetFileName(params)
+	correctFileName, err := s.GetFileName(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -97,7 +118,7 @@ func (s *ProductDynamoStrategy) ValidateFilesParams(params *omnitruck.RequestPar
 	return nil
 }
 
-func (s *ProductDynamoStrategy) UpdatePackages(data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
+func (s *ProductDynamoStrategy) UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
 	data.UpdatePackages(func(platform string, pv string, arch string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
 		params.Version = m.Version
 		params.Platform = platform
@@ -105,7 +126,7 @@ func (s *ProductDynamoStrategy) UpdatePackages(data *omnitruck.PackageList, para
 
 		fileName := ""
 		if params.Direct == "true" {
-			if resolvedFileName, err := s.GetFileName(params); err == nil {
+			if resolvedFileName, err := s.GetFileName(ctx, params); err == nil {
 				fileName = resolvedFileName
 			}
 		}
@@ -116,6 +137,12 @@ func (s *ProductDynamoStrategy) UpdatePackages(data *omnitruck.PackageList, para
 	})
 }
 
+// PackageKeys reads catalog package lists keyed platform/"pv"/arch. The
+// catalog does not record platform versions, so the placeholder is dropped.
+func (s *ProductDynamoStrategy) PackageKeys(platform string, _ string, arch string) omnitruck.PackageKeys {
+	return omnitruck.PackageKeys{Platform: platform, Architecture: arch}
+}
+
 // ParseTail parses the /files URL tail in Automate/Habitat format: {arch}/{fileName}
 // Expected format: arch/filename (exactly 2 segments)
 func (s *ProductDynamoStrategy) ParseTail(segments []string) helpers.FilesPathParams {
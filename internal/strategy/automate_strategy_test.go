@@ -1,6 +1,7 @@
 package strategy_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -22,7 +23,7 @@ func TestProductDynamoStrategy_GetLatestVersion(t *testing.T) {
 			name: "successfully gets latest version",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					VersionLatestFunc: func(p *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
+					VersionLatestFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
 						return "2.0.0", nil
 					},
 				}
@@ -35,7 +36,7 @@ func TestProductDynamoStrategy_GetLatestVersion(t *testing.T) {
 			name: "error fetching latest version",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					VersionLatestFunc: func(p *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
+					VersionLatestFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
 						return "", errors.New("db error")
 					},
 				}
@@ -55,7 +56,7 @@ func TestProductDynamoStrategy_GetLatestVersion(t *testing.T) {
 			}
 			params := &omnitruck.RequestParams{Product: "automate"}
 
-			version, req := s.GetLatestVersion(params)
+			version, req := s.GetLatestVersion(context.Background(), params)
 
 			assert.Equal(t, tt.expectOK, req.Ok)
 			assert.Equal(t, tt.expectVer, string(version))
@@ -79,7 +80,7 @@ func TestProductDynamoStrategy_GetAllVersions(t *testing.T) {
 			name: "successfully fetches all versions",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					VersionAllFunc: func(p *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
+					VersionAllFunc: func(ctx context.Context, p *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
 						return []omnitruck.ProductVersion{"1.0.0", "2.0.0"}, nil
 					},
 				}
@@ -91,7 +92,7 @@ func TestProductDynamoStrategy_GetAllVersions(t *testing.T) {
 			name: "error fetching all versions",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					VersionAllFunc: func(p *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
+					VersionAllFunc: func(ctx context.Context, p *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
 						return nil, errors.New("db error")
 					},
 				}
@@ -110,7 +111,7 @@ func TestProductDynamoStrategy_GetAllVersions(t *testing.T) {
 			}
 
 			params := &omnitruck.RequestParams{Product: "automate"}
-			versions, req := s.GetAllVersions(params)
+			versions, req := s.GetAllVersions(context.Background(), params)
 
 			assert.Equal(t, tt.expectOK, req.Ok)
 			if tt.expectOK {
@@ -124,7 +125,7 @@ func TestProductDynamoStrategy_GetAllVersions(t *testing.T) {
 
 func TestProductDynamoStrategy_GetPackages(t *testing.T) {
 	mock := &omnitruck.MockDynamoServices{
-		ProductPackagesFunc: func(p *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+		ProductPackagesFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.PackageList, error) {
 			return omnitruck.PackageList{}, nil
 		},
 	}
@@ -133,7 +134,7 @@ func TestProductDynamoStrategy_GetPackages(t *testing.T) {
 		Log:           log.NewEntry(log.New()),
 	}
 	params := &omnitruck.RequestParams{Product: "automate"}
-	data, err := s.GetPackages(params)
+	data, err := s.GetPackages(context.Background(), params)
 	assert.NoError(t, err)
 	assert.NotNil(t, data)
 }
@@ -149,7 +150,7 @@ func TestProductDynamoStrategy_GetMetadata(t *testing.T) {
 			name: "returns metadata successfully",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					ProductMetadataFunc: func(p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+					ProductMetadataFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
 						return omnitruck.PackageMetadata{Version: "2.0.0"}, nil
 					},
 				}
@@ -161,7 +162,7 @@ func TestProductDynamoStrategy_GetMetadata(t *testing.T) {
 			name: "error retrieving metadata",
 			mockService: func() *omnitruck.MockDynamoServices {
 				return &omnitruck.MockDynamoServices{
-					ProductMetadataFunc: func(p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+					ProductMetadataFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
 						return omnitruck.PackageMetadata{}, errors.New("db error")
 					},
 				}
@@ -180,7 +181,7 @@ func TestProductDynamoStrategy_GetMetadata(t *testing.T) {
 			}
 
 			params := &omnitruck.RequestParams{Product: "automate"}
-			meta, req := s.GetMetadata(params)
+			meta, req := s.GetMetadata(context.Background(), params)
 
 			assert.Equal(t, tt.expectOK, req.Ok)
 			assert.Equal(t, tt.expectVer, meta.Version)
@@ -221,7 +222,7 @@ func TestProductDynamoStrategy_Download(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockDynamoServices{
-				ProductDownloadFunc: func(p *omnitruck.RequestParams) (string, error) {
+				ProductDownloadFunc: func(ctx context.Context, p *omnitruck.RequestParams) (string, error) {
 					return tt.mockURL, nil
 				},
 			}
@@ -229,7 +230,7 @@ func TestProductDynamoStrategy_Download(t *testing.T) {
 				DynamoService: mock,
 				Log:           log.NewEntry(log.New()),
 			}
-			url, rc, hdr, msg, code, err := s.Download(tt.params)
+			url, rc, hdr, msg, code, err := s.Download(context.Background(), tt.params)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedURL, url)
 			assert.Nil(t, rc)
@@ -242,7 +243,7 @@ func TestProductDynamoStrategy_Download(t *testing.T) {
 
 func TestProductDynamoStrategy_GetFileName(t *testing.T) {
 	mock := &omnitruck.MockDynamoServices{
-		GetFilenameFunc: func(p *omnitruck.RequestParams) (string, error) {
+		GetFilenameFunc: func(ctx context.Context, p *omnitruck.RequestParams) (string, error) {
 			return "test.deb", nil
 		},
 	}
@@ -251,7 +252,7 @@ func TestProductDynamoStrategy_GetFileName(t *testing.T) {
 		Log:           log.NewEntry(log.New()),
 	}
 	params := &omnitruck.RequestParams{Product: "automate"}
-	name, err := s.GetFileName(params)
+	name, err := s.GetFileName(context.Background(), params)
 	assert.NoError(t, err)
 	assert.Equal(t, "test.deb", name)
 }
@@ -270,7 +271,67 @@ func TestProductDynamoStrategy_UpdatePackages(t *testing.T) {
 		Channel:      "stable",
 		Architecture: "x86_64",
 	}
-	s.UpdatePackages(&list, params, "http://download")
+	s.UpdatePackages(context.Background(), &list, params, "http://download")
 	pkg := list["linux"]["20.04"]["x86_64"]
 	assert.Contains(t, pkg.Url, "http://download")
 }
+
+func TestProductDynamoStrategy_DownloadSignature(t *testing.T) {
+	tests := []struct {
+		name         string
+		signatureUrl string
+		metadataErr  error
+		ext          string
+		expectedURL  string
+		expectedCode int
+	}{
+		{
+			name:         "redirects to catalog signature",
+			signatureUrl: "https://packages.example.com/automate_4.7.52-1_amd64.deb.asc",
+			ext:          ".asc",
+			expectedURL:  "https://packages.example.com/automate_4.7.52-1_amd64.deb.asc",
+		},
+		{
+			name:         "no signature in catalog",
+			ext:          ".asc",
+			expectedCode: 404,
+		},
+		{
+			name:         "signature of another kind",
+			signatureUrl: "https://packages.example.com/automate_4.7.52-1_amd64.deb.asc",
+			ext:          ".sig",
+			expectedCode: 404,
+		},
+		{
+			name:         "metadata error",
+			metadataErr:  errors.New("db error"),
+			ext:          ".asc",
+			expectedCode: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &omnitruck.MockDynamoServices{
+				ProductMetadataFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+					assert.Equal(t, "pm", p.PackageManager)
+					return omnitruck.PackageMetadata{SignatureUrl: tt.signatureUrl}, tt.metadataErr
+				},
+			}
+			s := &strategy.ProductDynamoStrategy{
+				DynamoService: mock,
+				Log:           log.NewEntry(log.New()),
+			}
+			params := &omnitruck.RequestParams{Product: "automate", FileName: "automate_4.7.52-1_amd64.deb"}
+			url, rc, _, _, code, err := s.DownloadSignature(context.Background(), params, tt.ext)
+			assert.Nil(t, rc)
+			assert.Equal(t, tt.expectedURL, url)
+			assert.Equal(t, tt.expectedCode, code)
+			if tt.expectedCode != 0 {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,8 +11,8 @@ import (
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
-	"github.com/gofiber/fiber/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,15 +22,15 @@ type DefaultProductStrategy struct {
 	Log              *log.Entry
 }
 
-func (s *DefaultProductStrategy) GetLatestVersion(params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
+func (s *DefaultProductStrategy) GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
 	var data omnitruck.ProductVersion
-	request := s.OmnitruckService.LatestVersion(params).ParseData(&data)
+	request := s.OmnitruckService.LatestVersion(ctx, params).ParseData(&data)
 	return data, request
 }
 
-func (s *DefaultProductStrategy) GetAllVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+func (s *DefaultProductStrategy) GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
 	var data []omnitruck.ProductVersion
-	request := s.OmnitruckService.ProductVersions(params).ParseData(&data)
+	request := s.OmnitruckService.ProductVersions(ctx, params).ParseData(&data)
 
 	// Sort versions if the request was successful
 	if request.Ok {
@@ -39,27 +40,27 @@ func (s *DefaultProductStrategy) GetAllVersions(params *omnitruck.RequestParams)
 	return data, request
 }
 
-func (s *DefaultProductStrategy) GetPackages(params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+func (s *DefaultProductStrategy) GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
 	var data omnitruck.PackageList
-	request := s.OmnitruckService.ProductPackages(params).ParseData(&data)
+	request := s.OmnitruckService.ProductPackages(ctx, params).ParseData(&data)
 	if !request.Ok {
-		return data, fiber.NewError(request.Code, request.Message)
+		return data, request.Err()
 	}
 	return data, nil
 }
 
-func (s *DefaultProductStrategy) GetMetadata(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
+func (s *DefaultProductStrategy) GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
 	var data omnitruck.PackageMetadata
 	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
-	request := s.OmnitruckService.ProductMetadata(params).ParseData(&data)
+	request := s.OmnitruckService.ProductMetadata(ctx, params).ParseData(&data)
 	return data, request
 }
 
-func (s *DefaultProductStrategy) Download(params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+func (s *DefaultProductStrategy) Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
 	var data omnitruck.PackageMetadata
-	request := s.OmnitruckService.ProductDownload(params).ParseData(&data)
+	request := s.OmnitruckService.ProductDownload(ctx, params).ParseData(&data)
 	if !request.Ok {
-		return "", nil, nil, request.Message, request.Code, fiber.NewError(request.Code, request.Message)
+		return "", nil, nil, request.Message, request.Code, request.Err()
 	}
 
 	// Append licenseId query parameter if present
@@ -71,23 +72,23 @@ func (s *DefaultProductStrategy) Download(params *omnitruck.RequestParams) (url
 	return data.Url, nil, nil, request.Message, request.Code, nil
 }
 
-func (s *DefaultProductStrategy) GetFileName(params *omnitruck.RequestParams) (string, error) {
+func (s *DefaultProductStrategy) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 	var data omnitruck.PackageMetadata
-	request := s.OmnitruckService.ProductMetadata(params).ParseData(&data)
+	request := s.OmnitruckService.ProductMetadata(ctx, params).ParseData(&data)
 	if !request.Ok {
-		return "", fiber.NewError(request.Code, request.Message)
+		return "", request.Err()
 	}
 	return helpers.GetFileNameFromURL(data.Url), nil
 }
 
 // ValidateFilesParams enforces that PlatformVersion is provided for default products.
-func (s *DefaultProductStrategy) ValidateFilesParams(params *omnitruck.RequestParams) error {
+func (s *DefaultProductStrategy) ValidateFilesParams(ctx context.Context, params *omnitruck.RequestParams) error {
 	if strings.TrimSpace(params.PlatformVersion) == "" {
-		return errors.New("Platform Version (pv) params cannot be empty")
+		return apierror.WithCode(apierror.CodeParameterMissing, errors.New("Platform Version (pv) params cannot be empty"))
 	}
 
 	// Validate filename matches what database expects
-	correctFileName, err := s.GetFileName(params)
+	correctFileName, err := s.GetFileName(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -114,7 +115,12 @@ func (s *DefaultProductStrategy) ParseTail(segments []string) helpers.FilesPathP
 	return p
 }
 
-func (s *DefaultProductStrategy) UpdatePackages(data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
+// PackageKeys reads omnitruck package lists keyed platform/platformVersion/arch
+func (s *DefaultProductStrategy) PackageKeys(platform string, pv string, arch string) omnitruck.PackageKeys {
+	return omnitruck.PackageKeys{Platform: platform, PlatformVersion: pv, Architecture: arch}
+}
+
+func (s *DefaultProductStrategy) UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
 	data.UpdatePackages(func(platform string, pv string, arch string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
 		params.Version = m.Version
 		params.Platform = platform
@@ -123,7 +129,7 @@ func (s *DefaultProductStrategy) UpdatePackages(data *omnitruck.PackageList, par
 
 		fileName := ""
 		if params.Direct == "true" {
-			if resolvedFileName, err := s.GetFileName(params); err == nil {
+			if resolvedFileName, err := s.GetFileName(ctx, params); err == nil {
 				fileName = resolvedFileName
 			}
 		}
@@ -1,6 +1,7 @@
 package strategy_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/chef/omnitruck-service/clients"
@@ -33,12 +34,12 @@ func TestDefaultProductStrategy_GetPackages(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				ProductPackagesFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				ProductPackagesFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.ok, Code: tt.code, Message: tt.message, Body: []byte(`{}`)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
-			pkgs, err := s.GetPackages(&omnitruck.RequestParams{})
+			pkgs, err := s.GetPackages(context.Background(), &omnitruck.RequestParams{})
 			if tt.hasError {
 				assert.Error(t, err)
 				assert.Nil(t, pkgs)
@@ -93,13 +94,13 @@ func TestDefaultProductStrategy_Download(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				ProductDownloadFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				ProductDownloadFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.ok, Code: tt.code, Message: tt.message, Body: []byte(tt.body)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
 			params := &omnitruck.RequestParams{LicenseId: tt.licenseId}
-			url, _, _, msg, code, err := s.Download(params)
+			url, _, _, msg, code, err := s.Download(context.Background(), params)
 			assert.Equal(t, tt.expected, url)
 			assert.Equal(t, tt.message, msg)
 			assert.Equal(t, tt.code, code)
@@ -140,12 +141,12 @@ func TestDefaultProductStrategy_GetFileName(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				ProductMetadataFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				ProductMetadataFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.ok, Code: tt.code, Message: tt.message, Body: []byte(tt.body)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
-			out, err := s.GetFileName(&omnitruck.RequestParams{})
+			out, err := s.GetFileName(context.Background(), &omnitruck.RequestParams{})
 			assert.Equal(t, tt.expected, out)
 			if tt.hasError {
 				assert.Error(t, err)
@@ -181,12 +182,12 @@ func TestDefaultProductStrategy_GetMetadata(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				ProductMetadataFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				ProductMetadataFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.ok, Body: []byte(tt.body)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
-			meta, req := s.GetMetadata(&omnitruck.RequestParams{})
+			meta, req := s.GetMetadata(context.Background(), &omnitruck.RequestParams{})
 
 			assert.Equal(t, tt.ok, req.Ok)
 			assert.Equal(t, tt.expected, meta.Version)
@@ -219,12 +220,12 @@ func TestDefaultProductStrategy_GetLatestVersion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				LatestVersionFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				LatestVersionFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.mockOk, Body: []byte(tt.mockResponse)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
-			out, req := s.GetLatestVersion(&omnitruck.RequestParams{})
+			out, req := s.GetLatestVersion(context.Background(), &omnitruck.RequestParams{})
 			assert.Equal(t, tt.expectedOk, req.Ok)
 			assert.Equal(t, tt.expected, string(out))
 		})
@@ -254,12 +255,12 @@ func TestDefaultProductStrategy_GetAllVersions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &omnitruck.MockOmnitruck{
-				ProductVersionsFunc: func(_ *omnitruck.RequestParams) *clients.Request {
+				ProductVersionsFunc: func(ctx context.Context, _ *omnitruck.RequestParams) *clients.Request {
 					return &clients.Request{Ok: tt.ok, Body: []byte(tt.body)}
 				},
 			}
 			s := &strategy.DefaultProductStrategy{OmnitruckService: mock}
-			out, req := s.GetAllVersions(&omnitruck.RequestParams{})
+			out, req := s.GetAllVersions(context.Background(), &omnitruck.RequestParams{})
 			assert.Equal(t, tt.ok, req.Ok)
 			assert.Len(t, out, tt.expected)
 		})
@@ -279,6 +280,6 @@ func TestDefaultProductStrategy_UpdatePackages(t *testing.T) {
 		},
 	}
 
-	s.UpdatePackages(&list, params, "http://baseurl")
+	s.UpdatePackages(context.Background(), &list, params, "http://baseurl")
 	assert.Contains(t, list["linux"]["20.04"]["x86_64"].Url, "http://baseurl")
 }
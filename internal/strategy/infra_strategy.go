@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,7 +13,7 @@ import (
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/config"
-	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
 	"github.com/chef/omnitruck-service/utils"
 	log "github.com/sirupsen/logrus"
@@ -21,6 +22,7 @@ import (
 type InfraProductStrategy struct {
 	DynamoService    omnitruck.IDynamoServices
 	AWSConfig        config.AWSConfig
+	Store            s3aws.ObjectStore
 	Log              *log.Entry
 }
 
@@ -30,7 +32,7 @@ func (s *InfraProductStrategy) normalizePackageManager(params *omnitruck.Request
 
 	if params.PackageManager == "" {
 		if params.Platform == "" {
-			return fmt.Errorf(utils.PlatformParamsError)
+			return apierror.WithCode(apierror.CodeParameterMissing, errors.New(utils.PlatformParamsError))
 		}
 		derived := omnitruck.DerivePackageManager(params.Platform)
 		if derived == "" {
@@ -47,26 +49,26 @@ func (s *InfraProductStrategy) normalizePackageManager(params *omnitruck.Request
 	return nil
 }
 
-func (s *InfraProductStrategy) GetLatestVersion(params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
+func (s *InfraProductStrategy) GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
 	request := clients.Request{}
-	data, err := s.DynamoService.VersionLatest(params)
+	data, err := s.DynamoService.VersionLatest(ctx, params)
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
 		s.Log.WithError(err).Error("Error while fetching latest versions for "+params.Product+": ", err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return data, &request
 	}
 	request.Success()
 	return data, &request
 }
 
-func (s *InfraProductStrategy) GetAllVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+func (s *InfraProductStrategy) GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
 	request := clients.Request{}
-	data, err := s.DynamoService.VersionAll(params)
+	data, err := s.DynamoService.VersionAll(ctx, params)
 	if err != nil {
 		s.Log.WithError(err).Error("Error while fetching all versions for "+params.Product+": ", err)
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return nil, &request
 	}
 
@@ -77,11 +79,11 @@ func (s *InfraProductStrategy) GetAllVersions(params *omnitruck.RequestParams) (
 	return data, &request
 }
 
-func (s *InfraProductStrategy) Download(params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+func (s *InfraProductStrategy) Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
 	if err = s.normalizePackageManager(params); err != nil {
 		return "", nil, nil, err.Error(), http.StatusBadRequest, err
 	}
-	fileName, err := s.DynamoService.GetFilename(params)
+	fileName, err := s.DynamoService.GetFilename(ctx, params)
 	if err != nil {
 		s.Log.WithError(err).Error("Error while fetching download filename for "+params.Product+": ", err)
 		code, msg := helpers.GetErrorCodeAndMsg(err)
@@ -93,52 +95,101 @@ func (s *InfraProductStrategy) Download(params *omnitruck.RequestParams) (url st
 	}
 
 	s.Log.Infof("Downloading file %s from S3 bucket %s in region %s", fileName, s.AWSConfig.S3Config.Bucket, s.AWSConfig.Region)
-	return s.downloadFromS3(params, fileName)
+	return s.downloadFromS3(ctx, params, fileName)
 }
 
-func (s *InfraProductStrategy) downloadFromS3(params *omnitruck.RequestParams, fileName string) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
-	// Validate S3 configuration
-	err = s3aws.ValidateS3Config(s.AWSConfig)
+// DownloadSignature streams the signature stored next to the package in S3,
+// provided the catalog records one of the requested kind.
+func (s *InfraProductStrategy) DownloadSignature(ctx context.Context, params *omnitruck.RequestParams, ext string) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	if err = s.normalizePackageManager(params); err != nil {
+		return "", nil, nil, err.Error(), http.StatusBadRequest, err
+	}
+	if _, err = catalogSignatureUrl(ctx, s.DynamoService, params, ext); err != nil {
+		code, msg := helpers.GetErrorCodeAndMsg(err)
+		return "", nil, nil, msg, code, err
+	}
+	fileName, err := s.DynamoService.GetFilename(ctx, params)
 	if err != nil {
-		s.Log.Errorf("Invalid S3 config found : %s ", err.Error())
-		return "", nil, nil, err.Error(), http.StatusInternalServerError, nil
+		s.Log.WithError(err).Error("Error while fetching signature filename for "+params.Product+": ", err)
+		code, msg := helpers.GetErrorCodeAndMsg(err)
+		return "", nil, nil, msg, code, err
 	}
-	bucket := s.AWSConfig.S3Config.Bucket
-	roleArn := s.AWSConfig.S3Config.RoleArn
-	region := s.AWSConfig.Region
-	path := ""
-	if params.Channel == constants.CURRENT_CHANNEL {
-		path = s.AWSConfig.S3Config.CurrentPath
-	} else if params.Channel == constants.STABLE_CHANNEL {
-		path = s.AWSConfig.S3Config.StablePath
+	if fileName == "" {
+		s.Log.Error("Download filename is empty for " + params.Product)
+		return "", nil, nil, "Download filename is empty", http.StatusInternalServerError, nil
 	}
 
-	//key should be formulated as path/product/version/platform/architecture/
-	key := path + "/" + params.Product + "/" + params.Version + "/" + params.Platform + "/" + params.Architecture + "/" + fileName
-	s.Log.Debugf("S3 key for download: %s", key)
+	s.Log.Infof("Downloading signature %s%s from S3 bucket %s in region %s", fileName, ext, s.AWSConfig.S3Config.Bucket, s.AWSConfig.Region)
+	return s.downloadFromS3(ctx, params, fileName+ext)
+}
 
-	sess, err := s3aws.NewS3Session(region)
+// DownloadSbom streams the SBOM stored next to the package in S3.
+func (s *InfraProductStrategy) DownloadSbom(ctx context.Context, params *omnitruck.RequestParams, format helpers.SbomFormat) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	fileName, err := s.GetFileName(ctx, params)
 	if err != nil {
-		s.Log.WithError(err).Error("Failed to create AWS session")
-		return "", nil, nil, "Failed to create AWS session", http.StatusInternalServerError, err
+		s.Log.WithError(err).Error("Error while fetching SBOM filename for "+params.Product+": ", err)
+		code, msg := helpers.GetErrorCodeAndMsg(err)
+		return "", nil, nil, msg, code, err
+	}
+	if fileName == "" {
+		s.Log.Error("Download filename is empty for " + params.Product)
+		return "", nil, nil, "Download filename is empty", http.StatusInternalServerError, nil
 	}
-	creds := s3aws.NewS3Credentials(sess, roleArn)
-	result, err := s3aws.GetS3Object(context.Background(), sess, creds, bucket, key)
+
+	s.Log.Infof("Downloading SBOM %s%s from S3 bucket %s in region %s", fileName, format.Suffix, s.AWSConfig.S3Config.Bucket, s.AWSConfig.Region)
+	url, resp, header, msg, code, err = s.downloadFromS3(ctx, params, fileName+format.Suffix)
+	if resp != nil {
+		header.Set("Content-Type", format.MediaType)
+	}
+	return url, resp, header, msg, code, err
+}
+
+// objectStore returns the store the package files are read from, selecting
+// it from the S3 configuration unless one was given.
+func (s *InfraProductStrategy) objectStore() (s3aws.ObjectStore, error) {
+	if s.Store != nil {
+		return s.Store, nil
+	}
+	return s3aws.NewObjectStore(s.AWSConfig)
+}
+
+func (s *InfraProductStrategy) downloadFromS3(ctx context.Context, params *omnitruck.RequestParams, fileName string) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	store, err := s.objectStore()
 	if err != nil {
+		s.Log.Errorf("Invalid S3 config found : %s ", err.Error())
+		return "", nil, nil, err.Error(), http.StatusInternalServerError, nil
+	}
+	key := s3aws.InfraObjectKey(s.AWSConfig.S3Config, params.Channel, params.Product, params.Version, params.Platform, params.Architecture, fileName)
+	s.Log.Debugf("S3 key for download: %s", key)
+
+	// The body is streamed to the client after the handler returns, once the
+	// request context has ended, so it must outlive that context
+	result, err := store.GetObject(context.WithoutCancel(ctx), key)
+	switch {
+	case errors.Is(err, s3aws.ErrObjectNotFound):
+		s.Log.WithError(err).Warn("Package file not found in S3")
+		return "", nil, nil, "Package file not found", http.StatusNotFound, err
+	case errors.Is(err, s3aws.ErrAccessDenied):
+		s.Log.WithError(err).Error("Access to S3 denied")
+		return "", nil, nil, "Access to the package storage was denied", http.StatusBadGateway, err
+	case apierror.KindOf(err) != nil:
+		s.Log.WithError(err).Error("Failed to get object from S3")
+		code, msg, _ := apierror.Status(err)
+		return "", nil, nil, msg, code, err
+	case err != nil:
 		s.Log.WithError(err).Error("Failed to get object from S3")
 		return "", nil, nil, "Failed to get object from S3", http.StatusInternalServerError, err
 	}
 
 	headers := http.Header{}
-	if result.ContentType != nil {
-		headers.Set("Content-Type", *result.ContentType)
+	if result.ContentType != "" {
+		headers.Set("Content-Type", result.ContentType)
 	}
-	if result.ContentLength != nil {
-		headers.Set("Content-Length", strconv.FormatInt(*result.ContentLength, 10))
-
+	if result.ContentLength >= 0 {
+		headers.Set("Content-Length", strconv.FormatInt(result.ContentLength, 10))
 	}
-	if result.ContentDisposition != nil {
-		headers.Set("Content-Disposition", *result.ContentDisposition)
+	if result.ContentDisposition != "" {
+		headers.Set("Content-Disposition", result.ContentDisposition)
 	} else {
 		headers.Set("Content-Disposition", "attachment; filename="+fileName)
 	}
@@ -146,46 +197,54 @@ func (s *InfraProductStrategy) downloadFromS3(params *omnitruck.RequestParams, f
 	return "", result.Body, headers, "", 0, nil
 }
 
-func (s *InfraProductStrategy) GetPackages(params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
-	return s.DynamoService.ProductPackages(params)
+func (s *InfraProductStrategy) GetReleaseDates(ctx context.Context, params *omnitruck.RequestParams) (map[string]string, error) {
+	return s.DynamoService.ReleaseDates(ctx, params)
+}
+
+func (s *InfraProductStrategy) GetReleaseNotes(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
+	return s.DynamoService.ReleaseNotes(ctx, params)
+}
+
+func (s *InfraProductStrategy) GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+	return s.DynamoService.ProductPackages(ctx, params)
 }
 
-func (s *InfraProductStrategy) GetMetadata(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
+func (s *InfraProductStrategy) GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
 	request := &clients.Request{}
 	if err := s.normalizePackageManager(params); err != nil {
-		request.Failure(http.StatusBadRequest, err.Error())
+		request.Failure(http.StatusBadRequest, err.Error()).WithErrorCode(apierror.CodeOf(err))
 		return omnitruck.PackageMetadata{}, request
 	}
-	data, err := s.DynamoService.ProductMetadata(params)
+	data, err := s.DynamoService.ProductMetadata(ctx, params)
 	if err != nil {
 		s.Log.WithError(err).Error("Error while fetching metadata for "+params.Product+": ", err)
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 	} else {
 		request.Success()
 	}
 	return data, request
 }
 
-func (s *InfraProductStrategy) GetFileName(params *omnitruck.RequestParams) (string, error) {
+func (s *InfraProductStrategy) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 	if err := s.normalizePackageManager(params); err != nil {
 		return "", err
 	}
-	fileName, err := s.DynamoService.GetFilename(params)
+	fileName, err := s.DynamoService.GetFilename(ctx, params)
 	return fileName, err
 }
 
 // ValidateFilesParams ensures that PackageManager is set for infra products,
 // deriving from platform if not provided in URL.
-func (s *InfraProductStrategy) ValidateFilesParams(params *omnitruck.RequestParams) error {
+func (s *InfraProductStrategy) ValidateFilesParams(ctx context.Context, params *omnitruck.RequestParams) error {
 	// Normalize/derive package manager and platform
 	if err := s.normalizePackageManager(params); err != nil {
-		return fmt.Errorf(utils.PackageManagerParamsError)
+		return apierror.WithCode(apierror.CodeParameterMissing, errors.New(utils.PackageManagerParamsError))
 	}
 
 	s.Log.Infof("params after normalization: %+v", params)
 	// Validate filename matches what database expects (infra products only)
-	correctFileName, err := s.GetFileName(params)
+	correctFileName, err := s.GetFileName(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -221,23 +280,29 @@ func (s *InfraProductStrategy) ParseTail(segments []string) helpers.FilesPathPar
 	return p
 }
 
-func (s *InfraProductStrategy) UpdatePackages(data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
-	data.UpdatePackages(func(platform string, arch string, packageManager string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
+// PackageKeys reads package lists keyed platform/arch/packageManager
+func (s *InfraProductStrategy) PackageKeys(platform string, arch string, packageManager string) omnitruck.PackageKeys {
+	return omnitruck.PackageKeys{Platform: platform, Architecture: arch, PackageManager: packageManager}
+}
+
+func (s *InfraProductStrategy) UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
+	data.UpdatePackages(func(platform string, second string, third string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
+		keys := s.PackageKeys(platform, second, third)
 		params.Version = m.Version
-		params.Platform = platform
-		params.PackageManager = packageManager
-		params.Architecture = arch
+		params.Platform = keys.Platform
+		params.PackageManager = keys.PackageManager
+		params.Architecture = keys.Architecture
 
 		fileName := ""
 		if params.Direct == "true" {
-			if resolvedFileName, err := s.GetFileName(params); err == nil {
+			if resolvedFileName, err := s.GetFileName(ctx, params); err == nil {
 				fileName = resolvedFileName
 			}
 		}
 
 		if strings.EqualFold(params.Direct, "true") && fileName != "" {
 			// Infra products include the package manager segment in the /files URL path.
-			m.Url = helpers.GetFilesUrl(params, baseUrl, fileName, packageManager)
+			m.Url = helpers.GetFilesUrl(params, baseUrl, fileName, keys.PackageManager)
 		} else {
 			m.Url = helpers.GetDownloadUrl(params, baseUrl)
 		}
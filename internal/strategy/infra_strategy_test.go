@@ -4,52 +4,32 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// Patch s3aws functions for testing
-func patchS3AWS() func() {
-	origValidate := s3aws.ValidateS3Config
-	origNewSession := s3aws.NewS3Session
-	origNewCreds := s3aws.NewS3Credentials
-	origGetObj := s3aws.GetS3Object
-
-	s3aws.ValidateS3Config = func(cfg config.AWSConfig) error {
-		return s3aws.MockValidateS3ConfigFunc(cfg)
-	}
-	s3aws.NewS3Session = func(region string) (aws.Config, error) {
-		return s3aws.MockNewS3SessionFunc(region)
-	}
-	s3aws.NewS3Credentials = func(cfg aws.Config, roleArn string) aws.CredentialsProvider {
-		return s3aws.MockNewS3CredentialsFunc(cfg, roleArn)
-	}
-	s3aws.GetS3Object = func(ctx context.Context, cfg aws.Config, creds aws.CredentialsProvider, bucket, key string) (*s3.GetObjectOutput, error) {
-		return s3aws.MockGetS3ObjectFunc(ctx, cfg, creds, bucket, key)
-	}
-	return func() {
-		s3aws.ValidateS3Config = origValidate
-		s3aws.NewS3Session = origNewSession
-		s3aws.NewS3Credentials = origNewCreds
-		s3aws.GetS3Object = origGetObj
-	}
-}
-
 func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 	tests := []struct {
 		name                  string
-		setupMocks            func()
+		store                 s3aws.ObjectStore
+		s3Config              config.S3Config
 		params                *omnitruck.RequestParams
+		expectedKey           string
 		expectedMsg           string
 		expectedCode          int
 		expectError           bool
@@ -59,24 +39,19 @@ func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 		expectedContentDispo  string
 	}{
 		{
-			name: "Validation error from config",
-			setupMocks: func() {
-				s3aws.MockValidateS3ConfigFunc = func(cfg config.AWSConfig) error {
-					return errors.New("bad config")
-				}
-			},
+			name:         "Validation error from config",
+			s3Config:     config.S3Config{CurrentPath: "current"},
 			params:       &omnitruck.RequestParams{},
-			expectedMsg:  "bad config",
+			expectedMsg:  "AWS configuration is incomplete for S3 download",
 			expectedCode: http.StatusInternalServerError,
 			expectError:  false,
 		},
 		{
-			name: "New S3 session fails",
-			setupMocks: func() {
-				s3aws.MockValidateS3ConfigFunc = func(cfg config.AWSConfig) error { return nil }
-				s3aws.MockNewS3SessionFunc = func(region string) (aws.Config, error) {
-					return aws.Config{}, errors.New("session error")
-				}
+			name: "Get S3 object fails",
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return nil, errors.New("s3 error")
+				},
 			},
 			params: &omnitruck.RequestParams{
 				Channel:      constants.CURRENT_CHANNEL,
@@ -85,21 +60,17 @@ func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 				Platform:     "ubuntu",
 				Architecture: "x86_64",
 			},
-			expectedMsg:  "Failed to create AWS session",
+			expectedKey:  "current/chef/1.2.3/ubuntu/x86_64/file.txt",
+			expectedMsg:  "Failed to get object from S3",
 			expectedCode: http.StatusInternalServerError,
 			expectError:  true,
 		},
 		{
-			name: "Get S3 object fails",
-			setupMocks: func() {
-				s3aws.MockValidateS3ConfigFunc = func(cfg config.AWSConfig) error { return nil }
-				s3aws.MockNewS3SessionFunc = func(region string) (aws.Config, error) { return aws.Config{Region: region}, nil }
-				s3aws.MockNewS3CredentialsFunc = func(cfg aws.Config, roleArn string) aws.CredentialsProvider {
-					return aws.AnonymousCredentials{} // or use a custom provider if needed
-				}
-				s3aws.MockGetS3ObjectFunc = func(ctx context.Context, cfg aws.Config, creds aws.CredentialsProvider, bucket, key string) (*s3.GetObjectOutput, error) {
-					return nil, errors.New("s3 error")
-				}
+			name: "Object not found",
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return nil, fmt.Errorf("%w: NoSuchKey", s3aws.ErrObjectNotFound)
+				},
 			},
 			params: &omnitruck.RequestParams{
 				Channel:      constants.CURRENT_CHANNEL,
@@ -108,38 +79,69 @@ func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 				Platform:     "ubuntu",
 				Architecture: "x86_64",
 			},
-			expectedMsg:  "Failed to get object from S3",
-			expectedCode: http.StatusInternalServerError,
+			expectedKey:  "current/chef/1.2.3/ubuntu/x86_64/file.txt",
+			expectedMsg:  "Package file not found",
+			expectedCode: http.StatusNotFound,
+			expectError:  true,
+		},
+		{
+			name: "Access denied",
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return nil, fmt.Errorf("%w: AccessDenied", s3aws.ErrAccessDenied)
+				},
+			},
+			params: &omnitruck.RequestParams{
+				Channel:      constants.CURRENT_CHANNEL,
+				Product:      "chef",
+				Version:      "1.2.3",
+				Platform:     "ubuntu",
+				Architecture: "x86_64",
+			},
+			expectedKey:  "current/chef/1.2.3/ubuntu/x86_64/file.txt",
+			expectedMsg:  "Access to the package storage was denied",
+			expectedCode: http.StatusBadGateway,
+			expectError:  true,
+		},
+		{
+			name: "Storage throttled",
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return nil, apierror.Wrap(apierror.ErrThrottled, errors.New("SlowDown"))
+				},
+			},
+			params: &omnitruck.RequestParams{
+				Channel:      constants.CURRENT_CHANNEL,
+				Product:      "chef",
+				Version:      "1.2.3",
+				Platform:     "ubuntu",
+				Architecture: "x86_64",
+			},
+			expectedKey:  "current/chef/1.2.3/ubuntu/x86_64/file.txt",
+			expectedMsg:  utils.ThrottledError,
+			expectedCode: http.StatusTooManyRequests,
 			expectError:  true,
 		},
 		{
 			name: "Successful S3 download",
-			setupMocks: func() {
-				s3aws.MockValidateS3ConfigFunc = func(cfg config.AWSConfig) error { return nil }
-				s3aws.MockNewS3SessionFunc = func(region string) (aws.Config, error) { return aws.Config{Region: region}, nil }
-				s3aws.MockNewS3CredentialsFunc = func(cfg aws.Config, roleArn string) aws.CredentialsProvider {
-					return aws.AnonymousCredentials{}
-				}
-				contentType := "application/octet-stream"
-				contentLength := int64(123)
-				contentDisposition := "attachment; filename=file.txt"
-				body := io.NopCloser(bytes.NewBufferString("testdata"))
-				s3aws.MockGetS3ObjectFunc = func(ctx context.Context, cfg aws.Config, creds aws.CredentialsProvider, bucket, key string) (*s3.GetObjectOutput, error) {
-					return &s3.GetObjectOutput{
-						Body:               body,
-						ContentType:        &contentType,
-						ContentLength:      &contentLength,
-						ContentDisposition: &contentDisposition,
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return &s3aws.Object{
+						Body:               io.NopCloser(bytes.NewBufferString("testdata")),
+						ContentType:        "application/octet-stream",
+						ContentLength:      123,
+						ContentDisposition: "attachment; filename=file.txt",
 					}, nil
-				}
+				},
 			},
 			params: &omnitruck.RequestParams{
-				Channel:      constants.CURRENT_CHANNEL,
+				Channel:      constants.STABLE_CHANNEL,
 				Product:      "chef",
 				Version:      "1.2.3",
 				Platform:     "ubuntu",
 				Architecture: "x86_64",
 			},
+			expectedKey:           "stable/chef/1.2.3/ubuntu/x86_64/file.txt",
 			expectedMsg:           "",
 			expectedCode:          0,
 			expectError:           false,
@@ -148,31 +150,62 @@ func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 			expectedContentLength: "123",
 			expectedContentDispo:  "attachment; filename=file.txt",
 		},
+		{
+			name: "Object without headers",
+			store: &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					return &s3aws.Object{Body: io.NopCloser(bytes.NewBufferString("testdata")), ContentLength: -1}, nil
+				},
+			},
+			params: &omnitruck.RequestParams{
+				Channel:      constants.STABLE_CHANNEL,
+				Product:      "chef",
+				Version:      "1.2.3",
+				Platform:     "ubuntu",
+				Architecture: "x86_64",
+			},
+			expectedKey:          "stable/chef/1.2.3/ubuntu/x86_64/file.txt",
+			expectResponseNotNil: true,
+			expectedContentDispo: "attachment; filename=file.txt",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			defer patchS3AWS()()
-			tt.setupMocks()
+			var requestedKey string
+			var store s3aws.ObjectStore
+			if mock, ok := tt.store.(*s3aws.MockObjectStore); ok {
+				getObject := mock.GetObjectFunc
+				store = &s3aws.MockObjectStore{GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					requestedKey = key
+					return getObject(ctx, key)
+				}}
+			}
+			s3Config := tt.s3Config
+			if s3Config.Bucket == "" && store != nil {
+				s3Config = config.S3Config{
+					Bucket:      "bucket",
+					RoleArn:     "arn",
+					CurrentPath: "current",
+					StablePath:  "stable",
+				}
+			}
 
 			strategy := &InfraProductStrategy{
 				AWSConfig: config.AWSConfig{
-					S3Config: config.S3Config{
-						Bucket:      "bucket",
-						RoleArn:     "arn",
-						CurrentPath: "current",
-						StablePath:  "stable",
-					},
-					Region: "us-west-2",
+					S3Config: s3Config,
+					Region:   "us-west-2",
 				},
-				Log: logrus.NewEntry(logrus.New()),
+				Store: store,
+				Log:   logrus.NewEntry(logrus.New()),
 			}
 
-			url, resp, header, msg, code, err := strategy.downloadFromS3(tt.params, "file.txt")
+			url, resp, header, msg, code, err := strategy.downloadFromS3(context.Background(), tt.params, "file.txt")
 
 			assert.Equal(t, "", url)
 			assert.Equal(t, tt.expectedMsg, msg)
 			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedKey, requestedKey)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -193,10 +226,46 @@ func TestInfraProductStrategy_DownloadFromS3(t *testing.T) {
 	}
 }
 
+func TestInfraProductStrategy_DownloadFromLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "stable", "chef-ice", "19.1.2", "linux", "x86_64"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stable", "chef-ice", "19.1.2", "linux", "x86_64", "chef-ice.rpm"), []byte("package"), 0o644))
+
+	strategy := &InfraProductStrategy{
+		AWSConfig: config.AWSConfig{
+			S3Config: config.S3Config{Type: s3aws.ObjectStoreLocal, Dir: dir, StablePath: "stable"},
+		},
+		Log: logrus.NewEntry(logrus.New()),
+	}
+	params := &omnitruck.RequestParams{
+		Channel:      constants.STABLE_CHANNEL,
+		Product:      "chef-ice",
+		Version:      "19.1.2",
+		Platform:     "linux",
+		Architecture: "x86_64",
+	}
+
+	_, resp, header, _, code, err := strategy.downloadFromS3(context.Background(), params, "chef-ice.rpm")
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	defer resp.Close()
+	content, err := io.ReadAll(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "package", string(content))
+	assert.Equal(t, "7", header.Get("Content-Length"))
+	assert.Equal(t, "attachment; filename=chef-ice.rpm", header.Get("Content-Disposition"))
+
+	_, resp, _, msg, code, err := strategy.downloadFromS3(context.Background(), params, "chef-ice.deb")
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, "Package file not found", msg)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
 func TestInfraProductStrategy_GetLatestVersion(t *testing.T) {
 	tests := []struct {
 		name           string
-		mockFunc       func(params *omnitruck.RequestParams) (omnitruck.ProductVersion, error)
+		mockFunc       func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, error)
 		expectedOK     bool
 		expectedCode   int
 		expectedOutput string
@@ -204,7 +273,7 @@ func TestInfraProductStrategy_GetLatestVersion(t *testing.T) {
 	}{
 		{
 			name: "success",
-			mockFunc: func(params *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
+			mockFunc: func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
 				return "1.2.3", nil
 			},
 			expectedOK:     true,
@@ -213,7 +282,7 @@ func TestInfraProductStrategy_GetLatestVersion(t *testing.T) {
 		},
 		{
 			name: "error from dynamo",
-			mockFunc: func(params *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
+			mockFunc: func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, error) {
 				return "", errors.New("db error")
 			},
 			expectedOK:     false,
@@ -234,7 +303,7 @@ func TestInfraProductStrategy_GetLatestVersion(t *testing.T) {
 			}
 			params := &omnitruck.RequestParams{Product: "chef"}
 
-			data, req := strategy.GetLatestVersion(params)
+			data, req := strategy.GetLatestVersion(context.Background(), params)
 
 			assert.Equal(t, tt.expectedOK, req.Ok)
 			assert.Equal(t, tt.expectedCode, req.Code)
@@ -246,14 +315,14 @@ func TestInfraProductStrategy_GetLatestVersion(t *testing.T) {
 func TestInfraProductStrategy_GetAllVersions(t *testing.T) {
 	tests := []struct {
 		name         string
-		mockFunc     func(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error)
+		mockFunc     func(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error)
 		expectedOK   bool
 		expectedCode int
 		expectedData []omnitruck.ProductVersion
 	}{
 		{
 			name: "success",
-			mockFunc: func(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
+			mockFunc: func(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
 				return []omnitruck.ProductVersion{"1.2.3"}, nil
 			},
 			expectedOK:   true,
@@ -262,7 +331,7 @@ func TestInfraProductStrategy_GetAllVersions(t *testing.T) {
 		},
 		{
 			name: "error from dynamo",
-			mockFunc: func(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
+			mockFunc: func(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, error) {
 				return nil, errors.New("db error")
 			},
 			expectedOK:   false,
@@ -282,7 +351,7 @@ func TestInfraProductStrategy_GetAllVersions(t *testing.T) {
 			}
 			params := &omnitruck.RequestParams{Product: "chef"}
 
-			data, req := strategy.GetAllVersions(params)
+			data, req := strategy.GetAllVersions(context.Background(), params)
 
 			assert.Equal(t, tt.expectedOK, req.Ok)
 			assert.Equal(t, tt.expectedCode, req.Code)
@@ -294,7 +363,7 @@ func TestInfraProductStrategy_GetAllVersions(t *testing.T) {
 func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 	tests := []struct {
 		name           string
-		mockFilenameFn func(*omnitruck.RequestParams) (string, error)
+		mockFilenameFn func(context.Context, *omnitruck.RequestParams) (string, error)
 		expectedFile   string
 		expectedMsg    string
 		expectedCode   int
@@ -303,17 +372,17 @@ func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 	}{
 		{
 			name: "download: filename error",
-			mockFilenameFn: func(params *omnitruck.RequestParams) (string, error) {
+			mockFilenameFn: func(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 				return "", errors.New("db error")
 			},
-			expectedMsg:  "",
+			expectedMsg:  utils.InternalError,
 			expectedCode: http.StatusInternalServerError,
 			expectErr:    true,
 			isDownload:   true,
 		},
 		{
 			name: "download: empty filename",
-			mockFilenameFn: func(params *omnitruck.RequestParams) (string, error) {
+			mockFilenameFn: func(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 				return "", nil
 			},
 			expectedMsg:  "Download filename is empty",
@@ -323,7 +392,7 @@ func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 		},
 		{
 			name: "get filename: success",
-			mockFilenameFn: func(params *omnitruck.RequestParams) (string, error) {
+			mockFilenameFn: func(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 				return "file.deb", nil
 			},
 			expectedFile: "file.deb",
@@ -344,7 +413,7 @@ func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 			params := &omnitruck.RequestParams{Product: "chef", Platform: "ubuntu"}
 
 			if tt.isDownload {
-				url, rc, hdr, msg, code, err := strategy.Download(params)
+				url, rc, hdr, msg, code, err := strategy.Download(context.Background(), params)
 				assert.Empty(t, url)
 				assert.Nil(t, rc)
 				assert.Nil(t, hdr)
@@ -356,7 +425,7 @@ func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 					assert.NoError(t, err)
 				}
 			} else {
-				file, err := strategy.GetFileName(params)
+				file, err := strategy.GetFileName(context.Background(), params)
 				assert.Equal(t, tt.expectedFile, file)
 				if tt.expectErr {
 					assert.Error(t, err)
@@ -370,7 +439,7 @@ func TestInfraProductStrategy_DownloadAndGetFileName(t *testing.T) {
 
 func TestInfraProductStrategy_GetPackages(t *testing.T) {
 	mockDynamo := &omnitruck.MockDynamoServices{
-		ProductPackagesFunc: func(params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+		ProductPackagesFunc: func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
 			return omnitruck.PackageList{}, nil
 		},
 	}
@@ -378,7 +447,7 @@ func TestInfraProductStrategy_GetPackages(t *testing.T) {
 		DynamoService: mockDynamo,
 	}
 	params := &omnitruck.RequestParams{Product: "chef"}
-	pkgs, err := strategy.GetPackages(params)
+	pkgs, err := strategy.GetPackages(context.Background(), params)
 	assert.NoError(t, err)
 	assert.NotNil(t, pkgs)
 }
@@ -386,7 +455,7 @@ func TestInfraProductStrategy_GetPackages(t *testing.T) {
 func TestInfraProductStrategy_GetMetadata(t *testing.T) {
 	tests := []struct {
 		name              string
-		mockMetadataFunc  func(*omnitruck.RequestParams) (omnitruck.PackageMetadata, error)
+		mockMetadataFunc  func(context.Context, *omnitruck.RequestParams) (omnitruck.PackageMetadata, error)
 		expectedVersion   string
 		expectedOk        bool
 		expectedCode      int
@@ -394,7 +463,7 @@ func TestInfraProductStrategy_GetMetadata(t *testing.T) {
 	}{
 		{
 			name: "success - returns metadata",
-			mockMetadataFunc: func(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+			mockMetadataFunc: func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
 				return omnitruck.PackageMetadata{Version: "1.2.3"}, nil
 			},
 			expectedVersion: "1.2.3",
@@ -402,7 +471,7 @@ func TestInfraProductStrategy_GetMetadata(t *testing.T) {
 		},
 		{
 			name: "error - db failure",
-			mockMetadataFunc: func(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+			mockMetadataFunc: func(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
 				return omnitruck.PackageMetadata{}, errors.New("db error")
 			},
 			expectedVersion:   "",
@@ -423,7 +492,7 @@ func TestInfraProductStrategy_GetMetadata(t *testing.T) {
 			}
 			params := &omnitruck.RequestParams{Product: "chef", Platform: "ubuntu"}
 
-			meta, req := strategy.GetMetadata(params)
+			meta, req := strategy.GetMetadata(context.Background(), params)
 
 			assert.Equal(t, tt.expectedOk, req.Ok)
 
@@ -448,7 +517,7 @@ func TestInfraProductStrategy_UpdatePackages(t *testing.T) {
 	params := &omnitruck.RequestParams{
 		Product: "chef", Channel: "stable", Architecture: "x86_64",
 	}
-	strategy.UpdatePackages(&list, params, "http://myurl")
+	strategy.UpdatePackages(context.Background(), &list, params, "http://myurl")
 	pkg := list["ubuntu"]["20.04"]["x86_64"]
 	assert.Contains(t, pkg.Url, "http://myurl")
 }
@@ -575,3 +644,116 @@ func TestInfraProductStrategy_normalizePackageManager(t *testing.T) {
 		})
 	}
 }
+
+func TestInfraProductStrategy_DownloadSignature(t *testing.T) {
+	tests := []struct {
+		name         string
+		signatureUrl string
+		ext          string
+		expectedKey  string
+		expectedCode int
+		expectBody   bool
+	}{
+		{
+			name:         "streams signature stored next to the package",
+			signatureUrl: "https://packages.example.com/chef-ice_19.1.2_amd64.deb.asc",
+			ext:          ".asc",
+			expectedKey:  "stable/chef-ice/19.1.2/linux/x86_64/chef-ice_19.1.2_amd64.deb.asc",
+			expectBody:   true,
+		},
+		{
+			name:         "no signature in catalog",
+			ext:          ".sig",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestedKey string
+			store := &s3aws.MockObjectStore{
+				GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+					requestedKey = key
+					return &s3aws.Object{Body: io.NopCloser(bytes.NewBufferString("signature")), ContentLength: -1}, nil
+				},
+			}
+
+			strategy := &InfraProductStrategy{
+				DynamoService: &omnitruck.MockDynamoServices{
+					ProductMetadataFunc: func(ctx context.Context, p *omnitruck.RequestParams) (omnitruck.PackageMetadata, error) {
+						return omnitruck.PackageMetadata{SignatureUrl: tt.signatureUrl}, nil
+					},
+					GetFilenameFunc: func(ctx context.Context, p *omnitruck.RequestParams) (string, error) {
+						return "chef-ice_19.1.2_amd64.deb", nil
+					},
+				},
+				AWSConfig: config.AWSConfig{
+					S3Config: config.S3Config{Bucket: "bucket", StablePath: "stable"},
+					Region:   "us-west-2",
+				},
+				Store: store,
+				Log:   logrus.NewEntry(logrus.New()),
+			}
+			params := &omnitruck.RequestParams{
+				Channel:      constants.STABLE_CHANNEL,
+				Product:      "chef-ice",
+				Version:      "19.1.2",
+				Platform:     "linux",
+				Architecture: "x86_64",
+				FileName:     "chef-ice_19.1.2_amd64.deb",
+			}
+
+			_, resp, header, _, code, err := strategy.DownloadSignature(context.Background(), params, tt.ext)
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedKey, requestedKey)
+			if tt.expectBody {
+				assert.NoError(t, err)
+				assert.NotNil(t, resp)
+				assert.Equal(t, "attachment; filename=chef-ice_19.1.2_amd64.deb.asc", header.Get("Content-Disposition"))
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+			}
+		})
+	}
+}
+
+func TestInfraProductStrategy_DownloadSbom(t *testing.T) {
+	var requestedKey string
+	store := &s3aws.MockObjectStore{
+		GetObjectFunc: func(ctx context.Context, key string) (*s3aws.Object, error) {
+			requestedKey = key
+			return &s3aws.Object{Body: io.NopCloser(bytes.NewBufferString("{}")), ContentLength: -1}, nil
+		},
+	}
+
+	strategy := &InfraProductStrategy{
+		DynamoService: &omnitruck.MockDynamoServices{
+			GetFilenameFunc: func(ctx context.Context, p *omnitruck.RequestParams) (string, error) {
+				return "chef-ice_19.1.2_amd64.deb", nil
+			},
+		},
+		AWSConfig: config.AWSConfig{
+			S3Config: config.S3Config{Bucket: "bucket", CurrentPath: "current"},
+			Region:   "us-west-2",
+		},
+		Store: store,
+		Log:   logrus.NewEntry(logrus.New()),
+	}
+	params := &omnitruck.RequestParams{
+		Channel:        constants.CURRENT_CHANNEL,
+		Product:        "chef-ice",
+		Version:        "19.1.2",
+		Platform:       "linux",
+		Architecture:   "x86_64",
+		PackageManager: "deb",
+	}
+
+	_, resp, header, _, code, err := strategy.DownloadSbom(context.Background(), params, helpers.SPDX)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.NotNil(t, resp)
+	assert.Equal(t, "current/chef-ice/19.1.2/linux/x86_64/chef-ice_19.1.2_amd64.deb.spdx.json", requestedKey)
+	assert.Equal(t, "application/spdx+json", header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=chef-ice_19.1.2_amd64.deb.spdx.json", header.Get("Content-Disposition"))
+}
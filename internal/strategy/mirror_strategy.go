@@ -0,0 +1,213 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/mirror"
+	"github.com/chef/omnitruck-service/utils"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// MirrorProductStrategy implements ProductStrategy for every product when the
+// server runs from a mirror directory. Versions, packages and files all come
+// from the mirror catalog, nothing is fetched upstream.
+type MirrorProductStrategy struct {
+	Catalog *mirror.Catalog
+	Log     *log.Entry
+}
+
+func (s *MirrorProductStrategy) GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
+	request := clients.Request{}
+	data, err := s.Catalog.GetVersionLatest(ctx, params.Product)
+	if err != nil {
+		request.Failure(http.StatusNotFound, utils.BadRequestError).WithErrorCode(apierror.CodeProductNotFound)
+		return "", &request
+	}
+	request.Success()
+	return omnitruck.ProductVersion(data), &request
+}
+
+func (s *MirrorProductStrategy) GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+	request := clients.Request{}
+	versions, _ := s.Catalog.GetVersionAll(ctx, params.Product)
+	if len(versions) == 0 {
+		request.Failure(http.StatusNotFound, utils.BadRequestError).WithErrorCode(apierror.CodeProductNotFound)
+		return nil, &request
+	}
+
+	data := make([]omnitruck.ProductVersion, len(versions))
+	for i, v := range versions {
+		data[i] = omnitruck.ProductVersion(v)
+	}
+	request.Success()
+	return omnitruck.SortProductVersions(data), &request
+}
+
+// GetPackages lists the mirrored packages keyed platform/platformVersion/arch.
+// Packages without a platform version are keyed by their package manager
+// instead, so packages of several package managers can share a platform.
+func (s *MirrorProductStrategy) GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+	entries := s.Catalog.Entries(params.Product, params.Version)
+	if len(entries) == 0 {
+		return nil, apierror.WithCode(apierror.CodeProductNotFound, fiber.NewError(http.StatusNotFound, utils.OmnitruckDataNotFoundError))
+	}
+
+	data := omnitruck.PackageList{}
+	for _, e := range entries {
+		second := e.PlatformVersion
+		if second == "" {
+			second = e.PackageManager
+		}
+		if data[e.Platform] == nil {
+			data[e.Platform] = omnitruck.PlatformVersionList{}
+		}
+		if data[e.Platform][second] == nil {
+			data[e.Platform][second] = omnitruck.ArchList{}
+		}
+		data[e.Platform][second][e.Architecture] = packageMetadata(e)
+	}
+	return data, nil
+}
+
+func (s *MirrorProductStrategy) GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
+	request := &clients.Request{}
+	e, err := s.lookup(params)
+	if err != nil {
+		request.Failure(http.StatusNotFound, utils.OmnitruckDataNotFoundError).WithErrorCode(apierror.CodeProductNotFound)
+		return omnitruck.PackageMetadata{}, request
+	}
+	request.Success()
+	return packageMetadata(e), request
+}
+
+// Download streams the package file from the mirror directory
+func (s *MirrorProductStrategy) Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+	e, err := s.lookup(params)
+	if err != nil {
+		return "", nil, nil, utils.OmnitruckDataNotFoundError, http.StatusNotFound, err
+	}
+
+	f, info, err := s.Catalog.Open(e)
+	if err != nil {
+		s.Log.WithError(err).Errorf("Unable to open mirrored package %s", e.Path)
+		return "", nil, nil, "Package file is missing from the mirror", http.StatusInternalServerError, err
+	}
+
+	headers := http.Header{}
+	contentType := mime.TypeByExtension(path.Ext(e.FileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	headers.Set("Content-Disposition", "attachment; filename="+e.FileName)
+
+	return "", f, headers, "", 0, nil
+}
+
+func (s *MirrorProductStrategy) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
+	e, err := s.lookup(params)
+	if err != nil {
+		return "", err
+	}
+	return e.FileName, nil
+}
+
+// ValidateFilesParams checks that the file name is the one mirrored for the
+// requested package
+func (s *MirrorProductStrategy) ValidateFilesParams(ctx context.Context, params *omnitruck.RequestParams) error {
+	correctFileName, err := s.GetFileName(ctx, params)
+	if err != nil {
+		return err
+	}
+	if params.FileName != correctFileName {
+		return fmt.Errorf("invalid filename for the specified product parameters")
+	}
+
+	return nil
+}
+
+// ParseTail parses the /files URL tail in mirror format, which follows the
+// mirror directory layout: [{platformVersion}/]{arch}/[{pm}/]{fileName}
+func (s *MirrorProductStrategy) ParseTail(segments []string) helpers.FilesPathParams {
+	p := helpers.FilesPathParams{}
+
+	if len(segments) == 0 {
+		return p
+	}
+	p.FileName = segments[len(segments)-1]
+	rest := segments[:len(segments)-1]
+	if len(rest) >= 2 && s.Catalog.IsPackageManager(rest[len(rest)-1]) {
+		p.PackageManager = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+	}
+	switch len(rest) {
+	case 0:
+	case 1:
+		p.Architecture = rest[0]
+	default:
+		p.PlatformVersion = rest[0]
+		p.Architecture = rest[1]
+	}
+
+	return p
+}
+
+// PackageKeys reads mirror package lists, where the second key is either a
+// platform version or, for packages without one, a package manager
+func (s *MirrorProductStrategy) PackageKeys(platform string, second string, arch string) omnitruck.PackageKeys {
+	if s.Catalog.IsPackageManager(second) {
+		return omnitruck.PackageKeys{Platform: platform, Architecture: arch, PackageManager: second}
+	}
+	return omnitruck.PackageKeys{Platform: platform, PlatformVersion: second, Architecture: arch}
+}
+
+func (s *MirrorProductStrategy) UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
+	data.UpdatePackages(func(platform string, second string, arch string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
+		keys := s.PackageKeys(platform, second, arch)
+		params.Version = m.Version
+		params.Platform = keys.Platform
+		params.PlatformVersion = keys.PlatformVersion
+		params.Architecture = keys.Architecture
+		params.PackageManager = m.PackageManager
+
+		if strings.EqualFold(params.Direct, "true") && m.FileName != "" {
+			m.Url = helpers.GetFilesUrl(params, baseUrl, m.FileName, m.PackageManager)
+		} else {
+			m.Url = helpers.GetDownloadUrl(params, baseUrl)
+		}
+		return m
+	})
+}
+
+func (s *MirrorProductStrategy) lookup(params *omnitruck.RequestParams) (mirror.Entry, error) {
+	e, err := s.Catalog.Lookup(params.Product, params.Version, params.Platform, params.PlatformVersion, params.Architecture, params.PackageManager)
+	if err != nil {
+		return e, apierror.WithCode(apierror.CodeProductNotFound, fiber.NewError(http.StatusNotFound, utils.OmnitruckDataNotFoundError))
+	}
+	return e, nil
+}
+
+func packageMetadata(e mirror.Entry) omnitruck.PackageMetadata {
+	return omnitruck.PackageMetadata{
+		Sha1:           e.Sha1,
+		Sha256:         e.Sha256,
+		Version:        e.Version,
+		FileName:       e.FileName,
+		Size:           e.Size,
+		PackageManager: e.PackageManager,
+		InstallMessage: e.InstallMessage,
+	}
+}
@@ -0,0 +1,150 @@
+package strategy
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/constants"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/mirror"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMirrorStrategy() *MirrorProductStrategy {
+	entry := func(product, version, platform, pv, arch, pm, file string) mirror.Entry {
+		pkg := omnitruck.Package{
+			Platform: platform, PlatformVersion: pv, Architecture: arch, PackageManager: pm,
+			Version: version, FileName: file, Sha256: "sum-" + file, Size: 7,
+		}
+		entryPath, err := mirror.EntryPath("stable", product, pkg)
+		if err != nil {
+			panic(err)
+		}
+		return mirror.Entry{Channel: "stable", Product: product, Path: entryPath, Package: pkg}
+	}
+	index := &mirror.Index{Packages: []mirror.Entry{
+		entry("chef", "18.2.7", "ubuntu", "22.04", "x86_64", "deb", "chef-18.2.7.deb"),
+		entry("chef", "18.10.1", "ubuntu", "22.04", "x86_64", "deb", "chef-18.10.1.deb"),
+		entry("chef-ice", "19.1.27", "linux", "", "x86_64", "rpm", "chef-ice.rpm"),
+		entry("chef-ice", "19.1.27", "linux", "", "x86_64", "deb", "chef-ice.deb"),
+	}}
+	files := fstest.MapFS{
+		"stable/chef/18.2.7/ubuntu/22.04/x86_64/chef-18.2.7.deb": {Data: []byte("package")},
+	}
+	return &MirrorProductStrategy{
+		Catalog: mirror.NewCatalog(index, files),
+		Log:     logrus.NewEntry(logrus.New()),
+	}
+}
+
+func TestMirrorProductStrategy_Versions(t *testing.T) {
+	s := newMirrorStrategy()
+
+	versions, req := s.GetAllVersions(context.Background(), &omnitruck.RequestParams{Product: "chef"})
+	assert.True(t, req.Ok)
+	assert.Equal(t, []omnitruck.ProductVersion{"18.2.7", "18.10.1"}, versions)
+
+	latest, req := s.GetLatestVersion(context.Background(), &omnitruck.RequestParams{Product: "chef"})
+	assert.True(t, req.Ok)
+	assert.Equal(t, omnitruck.ProductVersion("18.10.1"), latest)
+
+	_, req = s.GetAllVersions(context.Background(), &omnitruck.RequestParams{Product: "inspec"})
+	assert.False(t, req.Ok)
+	assert.Equal(t, 404, req.Code)
+}
+
+func TestMirrorProductStrategy_GetPackages(t *testing.T) {
+	s := newMirrorStrategy()
+	params := &omnitruck.RequestParams{Channel: "stable", Product: "chef-ice", Version: "19.1.27", Direct: "true", LicenseId: "lic"}
+
+	data, err := s.GetPackages(context.Background(), params)
+	require.NoError(t, err)
+	s.UpdatePackages(context.Background(), &data, params, "http://mirror.local")
+
+	packages := data.Packages(s.PackageKeys)
+	require.Len(t, packages, 2)
+	assert.Equal(t, "linux", packages[0].Platform)
+	assert.Equal(t, "", packages[0].PlatformVersion)
+	assert.Equal(t, "deb", packages[0].PackageManager)
+	assert.Equal(t, "chef-ice.deb", packages[0].FileName)
+	assert.Equal(t, "http://mirror.local/files/stable/chef-ice/19.1.27/linux/x86_64/deb/chef-ice.deb?license_id=lic", packages[0].Url)
+	assert.Equal(t, "rpm", packages[1].PackageManager)
+
+	_, err = s.GetPackages(context.Background(), &omnitruck.RequestParams{Product: "chef-ice", Version: "1.0.0"})
+	code, _ := helpers.GetErrorCodeAndMsg(err)
+	assert.Equal(t, 404, code)
+}
+
+func TestMirrorProductStrategy_Download(t *testing.T) {
+	s := newMirrorStrategy()
+
+	url, body, header, _, _, err := s.Download(context.Background(), &omnitruck.RequestParams{Product: "chef", Version: "18.2.7", Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64"})
+	require.NoError(t, err)
+	assert.Empty(t, url)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "package", string(content))
+	assert.Equal(t, "7", header.Get("Content-Length"))
+	assert.Equal(t, "attachment; filename=chef-18.2.7.deb", header.Get("Content-Disposition"))
+
+	// Listed in the index but missing from the directory
+	_, body, _, _, code, err := s.Download(context.Background(), &omnitruck.RequestParams{Product: "chef", Version: "18.10.1", Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64"})
+	assert.Error(t, err)
+	assert.Nil(t, body)
+	assert.Equal(t, 500, code)
+
+	_, _, _, _, code, err = s.Download(context.Background(), &omnitruck.RequestParams{Product: "chef", Version: "18.2.7", Platform: "windows", Architecture: "x86_64"})
+	assert.Error(t, err)
+	assert.Equal(t, 404, code)
+}
+
+func TestMirrorProductStrategy_Metadata(t *testing.T) {
+	s := newMirrorStrategy()
+	params := &omnitruck.RequestParams{Product: "chef-ice", Version: "19.1.27", Platform: "linux", Architecture: "x86_64", PackageManager: "rpm"}
+
+	meta, req := s.GetMetadata(context.Background(), params)
+	assert.True(t, req.Ok)
+	assert.Equal(t, "sum-chef-ice.rpm", meta.Sha256)
+	assert.Equal(t, "rpm", meta.PackageManager)
+
+	params.FileName = "chef-ice.rpm"
+	assert.NoError(t, s.ValidateFilesParams(context.Background(), params))
+	params.FileName = "chef-ice.deb"
+	assert.Error(t, s.ValidateFilesParams(context.Background(), params))
+}
+
+func TestMirrorProductStrategy_ParseTail(t *testing.T) {
+	s := newMirrorStrategy()
+	tests := []struct {
+		name     string
+		segments []string
+		want     helpers.FilesPathParams
+	}{
+		{name: "platform version", segments: []string{"22.04", "x86_64", "chef.deb"}, want: helpers.FilesPathParams{PlatformVersion: "22.04", Architecture: "x86_64", FileName: "chef.deb"}},
+		{name: "platform version and package manager", segments: []string{"22.04", "x86_64", "deb", "chef.deb"}, want: helpers.FilesPathParams{PlatformVersion: "22.04", Architecture: "x86_64", PackageManager: "deb", FileName: "chef.deb"}},
+		{name: "package manager", segments: []string{"x86_64", "rpm", "chef-ice.rpm"}, want: helpers.FilesPathParams{Architecture: "x86_64", PackageManager: "rpm", FileName: "chef-ice.rpm"}},
+		{name: "architecture", segments: []string{"x86_64", "chef-ice.rpm"}, want: helpers.FilesPathParams{Architecture: "x86_64", FileName: "chef-ice.rpm"}},
+		{name: "file name", segments: []string{"chef-ice.rpm"}, want: helpers.FilesPathParams{FileName: "chef-ice.rpm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.ParseTail(tt.segments))
+		})
+	}
+}
+
+func TestSelectProductStrategy_Mirror(t *testing.T) {
+	catalog := newMirrorStrategy().Catalog
+	for _, product := range []string{constants.AUTOMATE_PRODUCT, constants.MIGRATE_ICE, "chef"} {
+		s := SelectProductStrategy(product, "stable", &ProductStrategyDeps{Mirror: catalog, Log: logrus.NewEntry(logrus.New())})
+		assert.IsType(t, &MirrorProductStrategy{}, s)
+		assert.Equal(t, StrategyMirror, StrategyName(s))
+	}
+}
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"github.com/chef/omnitruck-service/clients/omnitruck"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
 	helpers "github.com/chef/omnitruck-service/internal/helper"
 	log "github.com/sirupsen/logrus"
 )
@@ -29,24 +31,24 @@ var JsonUnmarshal = func(data []byte, v any) error {
 	return json.Unmarshal(data, &v)
 }
 
-func (s *PlatformServiceStrategy) GetLatestVersion(params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
+func (s *PlatformServiceStrategy) GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request) {
 	request := clients.Request{}
 	data, err := s.PlatformService.PlatformVersionLatest(params, int(s.Mode))
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return data, &request
 	}
 	request.Success()
 	return data, &request
 }
 
-func (s *PlatformServiceStrategy) GetAllVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
+func (s *PlatformServiceStrategy) GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request) {
 	data, err := s.PlatformService.PlatformVersionsAll(params, int(s.Mode))
 	request := &clients.Request{}
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 		return nil, request
 	}
 
@@ -57,33 +59,33 @@ func (s *PlatformServiceStrategy) GetAllVersions(params *omnitruck.RequestParams
 	return data, request
 }
 
-func (s *PlatformServiceStrategy) GetPackages(params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
+func (s *PlatformServiceStrategy) GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error) {
 	return s.PlatformService.PlatformPackages(params, int(s.Mode))
 }
 
-func (s *PlatformServiceStrategy) GetMetadata(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
+func (s *PlatformServiceStrategy) GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request) {
 	request := &clients.Request{}
 	params.PackageManager = constants.DUMMY_PACKAGE_MANAGER
 	data, err := s.PlatformService.PlatformMetadata(params, int(s.Mode))
 	if err != nil {
 		code, msg := helpers.GetErrorCodeAndMsg(err)
-		request.Failure(code, msg)
+		request.Failure(code, msg).WithErrorCode(apierror.CodeOf(err))
 	} else {
 		request.Success()
 	}
 	return data, request
 }
 
-func (s *PlatformServiceStrategy) Download(params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
+func (s *PlatformServiceStrategy) Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, header http.Header, msg string, code int, err error) {
 	if s.Mode == constants.Commercial {
-		return s.DownloadChefPlatform(params)
+		return s.DownloadChefPlatform(ctx, params)
 	}
 	return "", nil, nil, "Platform Service does not support download in Open Source mode", http.StatusBadRequest, fmt.Errorf("%d Error: Platform Service does not support download in Open Source mode", http.StatusBadRequest)
 }
 
-func (s *PlatformServiceStrategy) DownloadChefPlatform(params *omnitruck.RequestParams) (url string, respBody io.ReadCloser, header http.Header, msg string, code int, err error) {
+func (s *PlatformServiceStrategy) DownloadChefPlatform(ctx context.Context, params *omnitruck.RequestParams) (url string, respBody io.ReadCloser, header http.Header, msg string, code int, err error) {
 	resp := clients.Response{}
-	request := s.LicenseClient.GetReplicatedCustomerEmail(params.LicenseId, s.LicenseServiceUrl, &resp)
+	request := s.LicenseClient.GetReplicatedCustomerEmail(ctx, params.LicenseId, s.LicenseServiceUrl, &resp)
 
 	if !request.Ok {
 		s.Log.Errorf("Received error response from getReplicatedCustomer")
@@ -106,7 +108,7 @@ func (s *PlatformServiceStrategy) DownloadChefPlatform(params *omnitruck.Request
 
 	//2. Run a search customer on replicated with email
 	requestId := s.Locals["requestid"].(string)
-	customers, err := s.Replicated.SearchCustomersByEmail(replicatedEmailResp.ReplicatedEmail, requestId)
+	customers, err := s.Replicated.SearchCustomersByEmail(ctx, replicatedEmailResp.ReplicatedEmail, requestId)
 
 	if err != nil {
 		s.Log.Errorf("Error while fetching replicated customers with Email : %s", err.Error())
@@ -129,7 +131,9 @@ func (s *PlatformServiceStrategy) DownloadChefPlatform(params *omnitruck.Request
 	s.Log.Info("Successfully formulated download url")
 
 	s.Log.Info("Performing download from replicated")
-	downloadResp, err := s.Replicated.DownloadFromReplicated(url, requestId, customer.InstallationId)
+	// The body is streamed to the client after the handler returns, once the
+	// request context has ended, so it must outlive that context
+	downloadResp, err := s.Replicated.DownloadFromReplicated(context.WithoutCancel(ctx), url, requestId, customer.InstallationId)
 	if err != nil {
 		s.Log.Errorf("Error while downloading from replicated : %s", err.Error())
 		return "", nil, nil, constants.REPLICATED_DOWNLOAD_ERROR, http.StatusInternalServerError, fmt.Errorf("%d Error while downloading from replicated: %s", http.StatusInternalServerError, constants.REPLICATED_DOWNLOAD_ERROR)
@@ -140,7 +144,7 @@ func (s *PlatformServiceStrategy) DownloadChefPlatform(params *omnitruck.Request
 	return "", downloadResp.Body, headers, "", downloadResp.StatusCode, nil
 }
 
-func (s *PlatformServiceStrategy) GetFileName(params *omnitruck.RequestParams) (string, error) {
+func (s *PlatformServiceStrategy) GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error) {
 	return s.PlatformService.PlatformFilename(params, int(s.Mode))
 }
 
@@ -149,7 +153,13 @@ func (s *PlatformServiceStrategy) ParseTail(_ []string) helpers.FilesPathParams
 	return helpers.FilesPathParams{}
 }
 
-func (s *PlatformServiceStrategy) UpdatePackages(data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
+// PackageKeys reads package lists keyed platform/"pv"/arch, dropping the
+// platform version placeholder.
+func (s *PlatformServiceStrategy) PackageKeys(platform string, _ string, arch string) omnitruck.PackageKeys {
+	return omnitruck.PackageKeys{Platform: platform, Architecture: arch}
+}
+
+func (s *PlatformServiceStrategy) UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string) {
 	data.UpdatePackages(func(platform string, pv string, arch string, m omnitruck.PackageMetadata) omnitruck.PackageMetadata {
 		params.Version = m.Version
 		params.Platform = platform
@@ -2,6 +2,7 @@ package strategy_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -61,7 +62,7 @@ func TestPlatformServiceStrategy_GetLatestVersion(t *testing.T) {
 				Mode:            constants.Commercial,
 			}
 
-			version, req := s.GetLatestVersion(&omnitruck.RequestParams{})
+			version, req := s.GetLatestVersion(context.Background(), &omnitruck.RequestParams{})
 
 			if tt.ok {
 				assert.Equal(t, tt.expected, string(version))
@@ -113,7 +114,7 @@ func TestPlatformServiceStrategy_GetAllVersions(t *testing.T) {
 				Mode:            constants.Commercial,
 			}
 
-			versions, req := s.GetAllVersions(&omnitruck.RequestParams{})
+			versions, req := s.GetAllVersions(context.Background(), &omnitruck.RequestParams{})
 
 			assert.Equal(t, tt.ok, req.Ok)
 
@@ -136,7 +137,7 @@ func TestPlatformServiceStrategy_GetPackages(t *testing.T) {
 	s := &strategy.PlatformServiceStrategy{
 		PlatformService: mockPlatform,
 	}
-	_, err := s.GetPackages(&omnitruck.RequestParams{})
+	_, err := s.GetPackages(context.Background(), &omnitruck.RequestParams{})
 	assert.NoError(t, err)
 }
 
@@ -178,7 +179,7 @@ func TestPlatformServiceStrategy_GetMetadata(t *testing.T) {
 				Mode:            constants.Commercial,
 			}
 
-			meta, req := s.GetMetadata(&omnitruck.RequestParams{})
+			meta, req := s.GetMetadata(context.Background(), &omnitruck.RequestParams{})
 
 			if tt.ok {
 				assert.Equal(t, tt.expected, meta.Version)
@@ -215,7 +216,7 @@ func TestPlatformServiceStrategy_Download(t *testing.T) {
 			name: "commercial mode should return file",
 			mode: constants.Commercial,
 			mockLicenseClient: &clients.MockLicense{
-				GetReplicatedCustomerEmailFunc: func(licenseID, url string, resp *clients.Response) *clients.Request {
+				GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseID, url string, resp *clients.Response) *clients.Request {
 					return &clients.Request{
 						Ok:      true,
 						Code:    200,
@@ -225,13 +226,13 @@ func TestPlatformServiceStrategy_Download(t *testing.T) {
 				},
 			},
 			mockReplicated: &replicated.MockReplicated{
-				SearchCustomersByEmailFunc: func(email, requestId string) ([]models.Customer, error) {
+				SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) ([]models.Customer, error) {
 					return []models.Customer{{InstallationId: "install123"}}, nil
 				},
 				GetDowloadUrlFunc: func(customer models.Customer, requestId string) (string, error) {
 					return "http://example.com/download", nil
 				},
-				DownloadFromReplicatedFunc: func(url, requestId, auth string) (*http.Response, error) {
+				DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, auth string) (*http.Response, error) {
 					return &http.Response{
 						StatusCode: 200,
 						Body:       io.NopCloser(bytes.NewReader([]byte("ok"))),
@@ -263,7 +264,7 @@ func TestPlatformServiceStrategy_Download(t *testing.T) {
 				s.Locals = tt.locals
 			}
 
-			url, respBody, header, msg, code, err := s.Download(&omnitruck.RequestParams{LicenseId: "lic123"})
+			url, respBody, header, msg, code, err := s.Download(context.Background(), &omnitruck.RequestParams{LicenseId: "lic123"})
 
 			assert.Equal(t, tt.expectedCode, code)
 			assert.Equal(t, tt.expectedMsg, msg)
@@ -290,7 +291,7 @@ func TestPlatformServiceStrategy_GetFileName(t *testing.T) {
 		PlatformService: mockPlatform,
 		Mode:            constants.Commercial,
 	}
-	filename, err := s.GetFileName(&omnitruck.RequestParams{})
+	filename, err := s.GetFileName(context.Background(), &omnitruck.RequestParams{})
 	assert.NoError(t, err)
 	assert.Equal(t, "platformservice.tar.gz", filename)
 }
@@ -317,7 +318,7 @@ func TestPlatformServiceStrategy_UpdatePackages(t *testing.T) {
 
 	s := &strategy.PlatformServiceStrategy{}
 
-	s.UpdatePackages(&packageList, params, baseUrl)
+	s.UpdatePackages(context.Background(), &packageList, params, baseUrl)
 
 	updated := packageList["linux"]["pv"]["amd64"]
 	assert.Contains(t, updated.Url, baseUrl)
@@ -413,7 +414,7 @@ func TestPlatformServiceStrategy_DownloadChefPlatform_Errors(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockLicense := &clients.MockLicense{
-				GetReplicatedCustomerEmailFunc: func(licenseID, url string, resp *clients.Response) *clients.Request {
+				GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseID, url string, resp *clients.Response) *clients.Request {
 					return &clients.Request{
 						Ok:      tt.requestOk,
 						Code:    tt.requestCode,
@@ -423,13 +424,13 @@ func TestPlatformServiceStrategy_DownloadChefPlatform_Errors(t *testing.T) {
 				},
 			}
 			mockReplicated := &replicated.MockReplicated{
-				SearchCustomersByEmailFunc: func(email, reqID string) ([]models.Customer, error) {
+				SearchCustomersByEmailFunc: func(ctx context.Context, email, reqID string) ([]models.Customer, error) {
 					return tt.customers, tt.searchCustomersError
 				},
 				GetDowloadUrlFunc: func(customer models.Customer, reqID string) (string, error) {
 					return "http://example.com", tt.getDownloadUrlError
 				},
-				DownloadFromReplicatedFunc: func(url, reqID, auth string) (*http.Response, error) {
+				DownloadFromReplicatedFunc: func(ctx context.Context, url, reqID, auth string) (*http.Response, error) {
 					if tt.downloadReplicatedErr != nil {
 						return nil, tt.downloadReplicatedErr
 					}
@@ -449,7 +450,7 @@ func TestPlatformServiceStrategy_DownloadChefPlatform_Errors(t *testing.T) {
 				Locals:            map[string]interface{}{"requestid": "req123"},
 			}
 
-			_, _, _, msg, code, err := s.DownloadChefPlatform(&omnitruck.RequestParams{LicenseId: "lic123"})
+			_, _, _, msg, code, err := s.DownloadChefPlatform(context.Background(), &omnitruck.RequestParams{LicenseId: "lic123"})
 			assert.Error(t, err)
 			assert.Equal(t, tt.expectedMsg, msg)
 			assert.Equal(t, tt.expectedCode, code)
@@ -484,12 +485,12 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "https://replicated.app/embedded/app/beta/channel/chef-360", nil
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						mockResponse := http.Response{Status: "200", Body: ioutil.NopCloser(bytes.NewBufferString(("This is body"))), Header: make(http.Header)}
 						mockResponse.Header.Set("Content-Type", "application/json")
 						return &mockResponse, nil
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return []models.Customer{
 							{
 								ID:             "2eDnZGGGSjwJNOoWkRr91WZh74A",
@@ -513,7 +514,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -538,7 +539,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 			name: "License Client Err",
 			fields: fields{
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   false,
@@ -561,15 +562,15 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "https://replicated.app/embedded/app/beta/channel/chef-360", nil
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						return &http.Response{Status: "200", Body: ioutil.NopCloser(bytes.NewBufferString(("This is body")))}, nil
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return nil, errors.New("Error fetching customer")
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -597,15 +598,15 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "https://replicated.app/embedded/app/beta/channel/chef-360", nil
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						return &http.Response{Status: "200", Body: ioutil.NopCloser(bytes.NewBufferString(("This is body")))}, nil
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return []models.Customer{}, nil
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -633,10 +634,10 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "", errors.New("error getting download url")
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						return &http.Response{Status: "200", Body: ioutil.NopCloser(bytes.NewBufferString(("This is body")))}, nil
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return []models.Customer{
 							{
 								ID:             "2eDnZGGGSjwJNOoWkRr91WZh74A",
@@ -660,7 +661,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -688,10 +689,10 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "https://replicated.app/embedded/app/beta/channel/chef-360", nil
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						return nil, errors.New("error downloading")
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return []models.Customer{
 							{
 								ID:             "2eDnZGGGSjwJNOoWkRr91WZh74A",
@@ -715,7 +716,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -743,12 +744,12 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					GetDowloadUrlFunc: func(customer models.Customer, requestId string) (url string, err error) {
 						return "https://replicated.app/embedded/app/beta/channel/chef-360", nil
 					},
-					DownloadFromReplicatedFunc: func(url, requestId, authorization string) (res *http.Response, err error) {
+					DownloadFromReplicatedFunc: func(ctx context.Context, url, requestId, authorization string) (res *http.Response, err error) {
 						mockResponse := http.Response{Status: "200", Body: ioutil.NopCloser(bytes.NewBufferString(("This is body"))), Header: make(http.Header)}
 						mockResponse.Header.Set("Content-Type", "application/json")
 						return &mockResponse, nil
 					},
-					SearchCustomersByEmailFunc: func(email, requestId string) (customers []models.Customer, err error) {
+					SearchCustomersByEmailFunc: func(ctx context.Context, email, requestId string) (customers []models.Customer, err error) {
 						return []models.Customer{
 							{
 								ID:             "2eDnZGGGSjwJNOoWkRr91WZh74A",
@@ -772,7 +773,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 					},
 				},
 				LicenseClient: &clients.MockLicense{
-					GetReplicatedCustomerEmailFunc: func(licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
+					GetReplicatedCustomerEmailFunc: func(ctx context.Context, licenseId, licenseServiceUrl string, data *clients.Response) *clients.Request {
 						return &clients.Request{
 							Code: 200,
 							Ok:   true,
@@ -814,7 +815,7 @@ func TestApiService_downloadChefPlatform(t *testing.T) {
 				Locals:        map[string]interface{}{"requestid": "req01"},
 			}
 
-			_, _, _, msg, code, err := s.DownloadChefPlatform(tt.args.params)
+			_, _, _, msg, code, err := s.DownloadChefPlatform(context.Background(), tt.args.params)
 			if tt.wantErr {
 				assert.NotNil(t, err, "expected error but got nil")
 			} else {
@@ -1,33 +1,63 @@
 package strategy
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"reflect"
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/clients/omnitruck"
+	s3aws "github.com/chef/omnitruck-service/clients/omnitruck/aws"
 	"github.com/chef/omnitruck-service/clients/omnitruck/replicated"
 	"github.com/chef/omnitruck-service/config"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/flags"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/chef/omnitruck-service/mirror"
 	"github.com/chef/omnitruck-service/models"
 	log "github.com/sirupsen/logrus"
 )
 
 type ProductStrategy interface {
-	GetLatestVersion(params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request)
-	GetAllVersions(params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request)
-	GetPackages(params *omnitruck.RequestParams) (omnitruck.PackageList, error)
-	GetMetadata(params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request)
-	Download(params *omnitruck.RequestParams) (url string, resp io.ReadCloser, headers http.Header, msg string, code int, err error)
-	GetFileName(params *omnitruck.RequestParams) (string, error)
-	UpdatePackages(data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string)
+	GetLatestVersion(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.ProductVersion, *clients.Request)
+	GetAllVersions(ctx context.Context, params *omnitruck.RequestParams) ([]omnitruck.ProductVersion, *clients.Request)
+	GetPackages(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageList, error)
+	GetMetadata(ctx context.Context, params *omnitruck.RequestParams) (omnitruck.PackageMetadata, *clients.Request)
+	Download(ctx context.Context, params *omnitruck.RequestParams) (url string, resp io.ReadCloser, headers http.Header, msg string, code int, err error)
+	GetFileName(ctx context.Context, params *omnitruck.RequestParams) (string, error)
+	UpdatePackages(ctx context.Context, data *omnitruck.PackageList, params *omnitruck.RequestParams, baseUrl string)
+	PackageKeys(platform string, second string, third string) omnitruck.PackageKeys
 }
 
 // FilesParamsValidator is implemented by each ProductStrategy to validate
 // the strategy-specific required parameters for the /files endpoint.
 type FilesParamsValidator interface {
-	ValidateFilesParams(params *omnitruck.RequestParams) error
+	ValidateFilesParams(ctx context.Context, params *omnitruck.RequestParams) error
+}
+
+// ReleaseDateProvider is implemented by strategies backed by a catalog that
+// records release dates. It returns the release date keyed by version.
+type ReleaseDateProvider interface {
+	GetReleaseDates(ctx context.Context, params *omnitruck.RequestParams) (map[string]string, error)
+}
+
+// ReleaseNotesProvider is implemented by strategies backed by a catalog that
+// can store release notes. An empty result means the catalog has none.
+type ReleaseNotesProvider interface {
+	GetReleaseNotes(ctx context.Context, params *omnitruck.RequestParams) (string, error)
+}
+
+// SignatureDownloader is implemented by strategies that can serve the detached
+// signature (.asc or .sig) of a package through the /files endpoint.
+type SignatureDownloader interface {
+	DownloadSignature(ctx context.Context, params *omnitruck.RequestParams, ext string) (url string, resp io.ReadCloser, headers http.Header, msg string, code int, err error)
+}
+
+// SbomDownloader is implemented by strategies that store SBOMs next to their
+// packages instead of linking to them from the catalog.
+type SbomDownloader interface {
+	DownloadSbom(ctx context.Context, params *omnitruck.RequestParams, format helpers.SbomFormat) (url string, resp io.ReadCloser, headers http.Header, msg string, code int, err error)
 }
 
 type ProductStrategyDeps struct {
@@ -40,16 +70,68 @@ type ProductStrategyDeps struct {
 	LicenseServiceUrl string
 	Mode              constants.ApiType
 	Config            config.ServiceConfig
+	Flags             flags.FeatureFlags
 	Locals            map[string]interface{}
+	Mirror            *mirror.Catalog
+	ObjectStore       s3aws.ObjectStore
 }
 
-// SelectProductStrategy returns the appropriate ProductStrategy based on the product.
-func SelectProductStrategy(product string, channel string, deps *ProductStrategyDeps) ProductStrategy {
+const (
+	StrategyOmnitruck  = "omnitruck"
+	StrategyDynamo     = "dynamo"
+	StrategyS3         = "s3"
+	StrategyReplicated = "replicated"
+	StrategyMirror     = "mirror"
+)
+
+// StrategyName returns a short, stable name for the strategy that serves a request.
+// It is used to tag audit events and log lines.
+func StrategyName(s ProductStrategy) string {
+	switch s.(type) {
+	case *DefaultProductStrategy:
+		return StrategyOmnitruck
+	case *ProductDynamoStrategy:
+		return StrategyDynamo
+	case *InfraProductStrategy:
+		return StrategyS3
+	case *PlatformServiceStrategy:
+		return StrategyReplicated
+	case *MirrorProductStrategy:
+		return StrategyMirror
+	}
+	return "unknown"
+}
+
+// ProductStrategyName returns the name of the strategy SelectProductStrategy
+// picks for product with the given feature flags, without touching any backend.
+func ProductStrategyName(product string, mode constants.ApiType, ff flags.FeatureFlags) string {
 	switch product {
 	case constants.AUTOMATE_PRODUCT, constants.HABITAT_PRODUCT:
+		return StrategyDynamo
+	case constants.PLATFORM_SERVICE_PRODUCT:
+		return StrategyReplicated
+	}
+	if name := ff.ProductStrategy(product, mode); name != "" {
+		return name
+	}
+	return StrategyOmnitruck
+}
+
+// SelectProductStrategy returns the appropriate ProductStrategy based on the product.
+func SelectProductStrategy(product string, channel string, deps *ProductStrategyDeps) ProductStrategy {
+	if deps.Mirror != nil {
+		return &MirrorProductStrategy{Catalog: deps.Mirror.ForChannel(channel), Log: deps.Log}
+	}
+	ff := deps.Flags
+	if ff == nil {
+		ff = flags.FromConfig(deps.Config)
+	}
+
+	switch ProductStrategyName(product, deps.Mode, ff) {
+	case StrategyDynamo:
 		deps.DynamoService.SetDbInfo(deps.Config.MetadataDetailsTable, reflect.TypeOf(models.ProductDetails{}))
 		return &ProductDynamoStrategy{DynamoService: deps.DynamoService, Log: deps.Log}
-	case constants.PLATFORM_SERVICE_PRODUCT:
+	case StrategyReplicated:
 		return &PlatformServiceStrategy{
 			PlatformService:   deps.PlatformService,
 			Log:               deps.Log,
@@ -59,13 +141,7 @@ func SelectProductStrategy(product string, channel string, deps *ProductStrategy
 			Mode:              deps.Mode,
 			Locals:            deps.Locals,
 		}
-	case constants.CHEF_INFRA_CLIENT_ENTERPRISE_PRODUCT, constants.MIGRATE_ICE, constants.CHEF_INSPEC_ENTERPRISE_PRODUCT, constants.CHEF_WORKSTATION_ENTERPRISE:
-		if !deps.Config.SupportInfra19 {
-			return &DefaultProductStrategy{
-				OmnitruckService: deps.OmnitruckService,
-				Log:              deps.Log,
-			}
-		}
+	case StrategyS3:
 		if channel == constants.CURRENT_CHANNEL {
 			deps.DynamoService.SetDbInfo(deps.Config.PackageDetailsCurrentTable, reflect.TypeOf(models.PackageDetails{}))
 		} else {
@@ -75,6 +151,7 @@ func SelectProductStrategy(product string, channel string, deps *ProductStrategy
 			DynamoService:    deps.DynamoService,
 			Log:              deps.Log,
 			AWSConfig:        deps.Config.AWSConfig,
+			Store:            deps.ObjectStore,
 		}
 	default:
 		return &DefaultProductStrategy{
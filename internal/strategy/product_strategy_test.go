@@ -281,3 +281,42 @@ func TestSelectProductStrategy_SupportInfra19(t *testing.T) {
 		})
 	}
 }
+
+func TestProductStrategy_PackageKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy strategy.ProductStrategy
+		keys     [3]string
+		want     omnitruck.PackageKeys
+	}{
+		{
+			name:     "omnitruck packages are keyed by platform version",
+			strategy: &strategy.DefaultProductStrategy{},
+			keys:     [3]string{"ubuntu", "20.04", "x86_64"},
+			want:     omnitruck.PackageKeys{Platform: "ubuntu", PlatformVersion: "20.04", Architecture: "x86_64"},
+		},
+		{
+			name:     "catalog packages drop the platform version placeholder",
+			strategy: &strategy.ProductDynamoStrategy{},
+			keys:     [3]string{"linux", constants.PLATFORM_VERSION_KEY, "x86_64"},
+			want:     omnitruck.PackageKeys{Platform: "linux", Architecture: "x86_64"},
+		},
+		{
+			name:     "infra packages are keyed by package manager",
+			strategy: &strategy.InfraProductStrategy{},
+			keys:     [3]string{"linux", "x86_64", "deb"},
+			want:     omnitruck.PackageKeys{Platform: "linux", Architecture: "x86_64", PackageManager: "deb"},
+		},
+		{
+			name:     "platform service packages drop the platform version placeholder",
+			strategy: &strategy.PlatformServiceStrategy{},
+			keys:     [3]string{"linux", constants.PLATFORM_VERSION_KEY, "amd64"},
+			want:     omnitruck.PackageKeys{Platform: "linux", Architecture: "amd64"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.strategy.PackageKeys(tt.keys[0], tt.keys[1], tt.keys[2]))
+		})
+	}
+}
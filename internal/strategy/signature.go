@@ -0,0 +1,24 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	helpers "github.com/chef/omnitruck-service/internal/helper"
+	"github.com/gofiber/fiber/v2"
+)
+
+// catalogSignatureUrl returns the signature the catalog records for the package
+// described by params. It fails with 404 when the catalog has no signature of
+// the requested kind.
+func catalogSignatureUrl(ctx context.Context, dynamo omnitruck.IDynamoServices, params *omnitruck.RequestParams, ext string) (string, error) {
+	meta, err := dynamo.ProductMetadata(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	if meta.SignatureUrl == "" || helpers.SignatureExtension(meta.SignatureUrl) != ext {
+		return "", fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("No %s signature available for %s", ext, params.FileName))
+	}
+	return meta.SignatureUrl, nil
+}
@@ -0,0 +1,63 @@
+package cachecontrol
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+type Config struct {
+	// Policy is the Cache-Control header value, e.g. "public, max-age=300".
+	// An empty policy leaves the header unset.
+	Policy string
+	// ETag adds a strong ETag computed from the response body and answers
+	// requests with a matching If-None-Match with 304 Not Modified.
+	ETag bool
+	Next func(c *fiber.Ctx) bool
+}
+
+var ConfigDefault = Config{
+	Policy: "",
+	ETag:   true,
+	Next:   nil,
+}
+
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	cfg := ConfigDefault
+
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return cfg
+}
+
+// New sets the Cache-Control header on successful and not modified responses
+func New(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+
+	next := func(c *fiber.Ctx) error {
+		return c.Next()
+	}
+	if cfg.ETag {
+		next = etag.New(etag.Config{Weak: false})
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		err := next(c)
+		if err != nil || cfg.Policy == "" {
+			return err
+		}
+
+		switch c.Response().StatusCode() {
+		case fiber.StatusOK, fiber.StatusNotModified:
+			c.Set(fiber.HeaderCacheControl, cfg.Policy)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package cachecontrol
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(policy string) *fiber.App {
+	app := fiber.New()
+	app.Get("/products", New(Config{Policy: policy, ETag: true}), func(c *fiber.Ctx) error {
+		return c.JSON([]string{"chef", "inspec"})
+	})
+	app.Get("/missing", New(Config{Policy: policy, ETag: true}), func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusNotFound).JSON("not found")
+	})
+	return app
+}
+
+func TestCacheControl_SetsPolicyAndHonoursETag(t *testing.T) {
+	app := newApp("public, max-age=300")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/products", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+	tag := resp.Header.Get("ETag")
+	require.NotEmpty(t, tag)
+	assert.NotContains(t, tag, "W/", "etag should be strong")
+
+	req := httptest.NewRequest("GET", "/products", nil)
+	req.Header.Set("If-None-Match", tag)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 304, resp.StatusCode)
+	assert.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+}
+
+func TestCacheControl_SkipsErrorsAndEmptyPolicy(t *testing.T) {
+	resp, err := newApp("public, max-age=300").Test(httptest.NewRequest("GET", "/missing", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Cache-Control"))
+
+	resp, err = newApp("").Test(httptest.NewRequest("GET", "/products", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Cache-Control"))
+}
+
+func TestCacheControl_WithoutETag(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", New(Config{Policy: "no-store"}), func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Empty(t, resp.Header.Get("ETag"))
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/chef/omnitruck-service/clients"
 	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/problem"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -73,7 +75,7 @@ func New(config ...Config) fiber.Handler {
 
 			if len(id) == 0 {
 				if cfg.Required {
-					return cfg.Unauthorized(403, "Missing license_id query param", c)
+					return cfg.deny(c, 403, apierror.CodeLicenseMissing, "Missing license_id query param")
 				}
 				// No license id found but not required
 				return c.Next()
@@ -82,23 +84,32 @@ func New(config ...Config) fiber.Handler {
 			if cfg.Mode == constants.Opensource {
 				// Only Free licenses are valid in opensource mode
 				if !cfg.LicenseClient.IsFree(id) {
-					return cfg.Unauthorized(403, "Only Free license can be used in Open Source mode", c)
+					return cfg.deny(c, 403, apierror.CodeLicenseNotAllowedForMode, "Only Free license can be used in Open Source mode")
 				}
 			}
 
 			if cfg.Mode == constants.Trial {
 				// Only Free or Trial licenses are valid in trial mode
 				if !cfg.LicenseClient.IsTrial(id) && !cfg.LicenseClient.IsFree(id) {
-					return cfg.Unauthorized(403, "Only Trial or Free license can be used in Trial mode", c)
+					return cfg.deny(c, 403, apierror.CodeLicenseNotAllowedForMode, "Only Trial or Free license can be used in Trial mode")
 				}
 			}
 
 			resp := clients.Response{}
-			request := cfg.LicenseClient.Validate(id, cfg.URL, &resp)
+			request := cfg.LicenseClient.Validate(c.UserContext(), id, cfg.URL, &resp)
+
+			// The license service is down or throttling us, the license may well be valid
+			if request.Code == fiber.StatusServiceUnavailable || request.Code == fiber.StatusTooManyRequests {
+				errCode := request.ErrorCode
+				if errCode == "" {
+					errCode = apierror.CodeLicenseUnavailable
+				}
+				return cfg.deny(c, request.Code, errCode, request.Message)
+			}
 
 			// Invalid license of some sort returned from license API
 			if request.Code >= 400 {
-				return cfg.Unauthorized(403, resp.Message, c)
+				return cfg.deny(c, 403, apierror.CodeLicenseInvalid, resp.Message)
 			}
 		}
 		c.Locals("valid_license", true)
@@ -106,3 +117,12 @@ func New(config ...Config) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// deny rejects the request through Unauthorized, or with problem details
+// on the /v2 API
+func (cfg Config) deny(c *fiber.Ctx, code int, errCode string, msg string) error {
+	if problem.Enabled(c) {
+		return problem.Send(c, problem.New(code, errCode, msg))
+	}
+	return cfg.Unauthorized(code, msg, c)
+}
@@ -1,11 +1,13 @@
 package license
 
 import (
+	"context"
 	"io"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/chef/omnitruck-service/clients"
+	"github.com/chef/omnitruck-service/constants"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,7 +19,7 @@ func TestNoLicenseIDWithRequiredTrue(t *testing.T) {
 		URL:      "http://example.com",
 		Required: true,
 		LicenseClient: &clients.MockLicense{
-			ValidateFunc: func(id, url string, resp *clients.Response) *clients.Request {
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
 				return &clients.Request{Ok: false, Code: 403, Message: "invalid license"}
 			},
 		},
@@ -47,7 +49,7 @@ func TestInvalidLicenseProvided(t *testing.T) {
 		URL:      "http://example.com",
 		Required: true,
 		LicenseClient: &clients.MockLicense{
-			ValidateFunc: func(id, url string, resp *clients.Response) *clients.Request {
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
 				return &clients.Request{Ok: false, Code: 403, Message: "invalid license"}
 			},
 			IsTrialFunc: func(l string) bool {
@@ -75,7 +77,7 @@ func TestValidLicenseProvided(t *testing.T) {
 		URL:      "http://example.com",
 		Required: true,
 		LicenseClient: &clients.MockLicense{
-			ValidateFunc: func(id, url string, resp *clients.Response) *clients.Request {
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
 				resp.Message = "valid license"
 				return &clients.Request{Ok: true, Code: 200, Message: "valid license"}
 			},
@@ -108,7 +110,7 @@ func TestNextSkipsLicense(t *testing.T) {
 		URL:      "http://example.com",
 		Required: true,
 		LicenseClient: &clients.MockLicense{
-			ValidateFunc: func(id, url string, resp *clients.Response) *clients.Request {
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
 				resp.Message = "valid license"
 				return &clients.Request{Ok: true, Code: 200, Message: "valid license"}
 			},
@@ -144,7 +146,7 @@ func TestNonRequired_NoLicenseID(t *testing.T) {
 		URL:      "http://example.com",
 		Required: false,
 		LicenseClient: &clients.MockLicense{
-			ValidateFunc: func(id, url string, resp *clients.Response) *clients.Request {
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
 				resp.Message = "valid license"
 				return &clients.Request{Ok: true, Code: 200, Message: "valid license"}
 			},
@@ -169,3 +171,96 @@ func TestNonRequired_NoLicenseID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
 }
+
+func TestV2ProblemResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     constants.ApiType
+		query    string
+		expected string
+	}{
+		{
+			name:     "missing license",
+			mode:     constants.Commercial,
+			query:    "",
+			expected: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"Missing license_id query param","instance":"/v2/stable/chef/packages","code":"license.missing"}`,
+		},
+		{
+			name:     "license not allowed for mode",
+			mode:     constants.Opensource,
+			query:    "?license_id=commercial",
+			expected: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"Only Free license can be used in Open Source mode","instance":"/v2/stable/chef/packages","code":"license.not_allowed_for_mode"}`,
+		},
+		{
+			name:     "invalid license",
+			mode:     constants.Commercial,
+			query:    "?license_id=bad-license",
+			expected: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"invalid license","instance":"/v2/stable/chef/packages","code":"license.invalid"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(New(Config{
+				URL:      "http://example.com",
+				Required: true,
+				Mode:     tt.mode,
+				LicenseClient: &clients.MockLicense{
+					ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
+						resp.Message = "invalid license"
+						return &clients.Request{Ok: false, Code: 403}
+					},
+					IsFreeFunc: func(l string) bool {
+						return false
+					},
+				},
+			}))
+			app.Get("/v2/:channel/:product/packages", func(c *fiber.Ctx) error {
+				return c.SendString("OK")
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/v2/stable/chef/packages"+tt.query, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, 403, resp.StatusCode)
+			assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestLicenseServiceUnavailable(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		URL:      "http://example.com",
+		Required: true,
+		Mode:     constants.Commercial,
+		LicenseClient: &clients.MockLicense{
+			ValidateFunc: func(ctx context.Context, id, url string, resp *clients.Response) *clients.Request {
+				return &clients.Request{Ok: false, Code: 503, Message: "Error while validating License"}
+			},
+			IsTrialFunc: func(l string) bool {
+				return false
+			},
+			IsFreeFunc: func(l string) bool {
+				return false
+			},
+		},
+		Unauthorized: func(code int, msg string, c *fiber.Ctx) error {
+			return c.Status(code).SendString(msg)
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/?license_id=some-license", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "Error while validating License", string(body))
+}
@@ -0,0 +1,249 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/dboperations"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/models"
+)
+
+var _ dboperations.IDbOperations = (*Catalog)(nil)
+
+// ErrNotFound is returned when the mirror has no package for a request
+var ErrNotFound = apierror.Wrap(apierror.ErrNotFound, errors.New("package not found in mirror"))
+
+// Catalog serves the packages of a mirror directory. It implements
+// dboperations.IDbOperations over the index, where SetDbInfo selects the
+// channel instead of a table, and opens package files from Files.
+type Catalog struct {
+	index   *Index
+	files   fs.FS
+	channel string
+	// Package managers of every entry, used to tell them apart from
+	// platform versions in package list keys
+	managers map[string]bool
+}
+
+// NewCatalog returns a catalog of the index with package files read from files
+func NewCatalog(index *Index, files fs.FS) *Catalog {
+	c := &Catalog{index: index, files: files, channel: constants.STABLE_CHANNEL, managers: map[string]bool{}}
+	for _, e := range index.Packages {
+		if e.PackageManager != "" {
+			c.managers[e.PackageManager] = true
+		}
+	}
+	return c
+}
+
+// OpenCatalog reads the index of a mirror directory
+func OpenCatalog(dir string) (*Catalog, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	index, err := LoadIndex(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	return NewCatalog(index, os.DirFS(dir)), nil
+}
+
+// Source returns the service the mirror was copied from
+func (c *Catalog) Source() string {
+	return c.index.Source
+}
+
+// ForChannel returns a copy of the catalog reading the given channel
+func (c *Catalog) ForChannel(channel string) *Catalog {
+	clone := *c
+	clone.channel = channel
+	return &clone
+}
+
+func (c *Catalog) Clone() dboperations.IDbOperations {
+	return c.ForChannel(c.channel)
+}
+
+// SetDbInfo selects the channel read by the catalog. The model is ignored.
+func (c *Catalog) SetDbInfo(channel string, _ reflect.Type) {
+	c.channel = channel
+}
+
+// Products returns every product in the mirror, in any channel
+func (c *Catalog) Products() []string {
+	return c.distinct(func(e Entry) string { return e.Product })
+}
+
+// Architectures returns every architecture in the mirror
+func (c *Catalog) Architectures() []string {
+	return c.distinct(func(e Entry) string { return e.Architecture })
+}
+
+// IsPackageManager reports whether a package of the mirror uses the package manager
+func (c *Catalog) IsPackageManager(pm string) bool {
+	return c.managers[pm]
+}
+
+// Entries returns the packages of a product version in the current channel
+func (c *Catalog) Entries(product, version string) []Entry {
+	var entries []Entry
+	for _, e := range c.index.Packages {
+		if e.Channel == c.channel && e.Product == product && e.Version == version {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Lookup finds the package of a product version for a platform, platform
+// version, architecture and package manager. An exact platform version is
+// preferred over a package without one. An empty platform version in the
+// request matches any package, an empty package manager is derived from the
+// platform like the infra strategy does.
+func (c *Catalog) Lookup(product, version, platform, platformVersion, architecture, packageManager string) (Entry, error) {
+	switch packageManager {
+	case constants.DUMMY_PACKAGE_MANAGER:
+		packageManager = ""
+	case "":
+		packageManager = omnitruck.DerivePackageManager(platform)
+	}
+	dbPlatform := omnitruck.NormalizePlatformForDatabase(platform)
+
+	var candidates []Entry
+	for _, e := range c.Entries(product, version) {
+		if !strings.EqualFold(e.Platform, platform) && !strings.EqualFold(e.Platform, dbPlatform) {
+			continue
+		}
+		if !strings.EqualFold(e.Architecture, architecture) {
+			continue
+		}
+		if packageManager != "" && !strings.EqualFold(e.PackageManager, packageManager) {
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+
+	var fallback *Entry
+	for i, e := range candidates {
+		switch {
+		case e.PlatformVersion == platformVersion:
+			return e, nil
+		case fallback == nil && (e.PlatformVersion == "" || platformVersion == ""):
+			fallback = &candidates[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return Entry{}, ErrNotFound
+}
+
+// Open opens the file of a package
+func (c *Catalog) Open(e Entry) (fs.File, fs.FileInfo, error) {
+	f, err := c.files.Open(e.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+func (c *Catalog) GetVersionAll(ctx context.Context, product string) ([]string, error) {
+	seen := map[string]bool{}
+	versions := []string{}
+	for _, e := range c.index.Packages {
+		if e.Channel == c.channel && e.Product == product && !seen[e.Version] {
+			seen[e.Version] = true
+			versions = append(versions, e.Version)
+		}
+	}
+	return versions, nil
+}
+
+func (c *Catalog) GetVersionLatest(ctx context.Context, product string) (string, error) {
+	versions, _ := c.GetVersionAll(ctx, product)
+	if len(versions) == 0 {
+		return "", ErrNotFound
+	}
+	list := make([]omnitruck.ProductVersion, len(versions))
+	for i, v := range versions {
+		list[i] = omnitruck.ProductVersion(v)
+	}
+	list = omnitruck.SortProductVersions(list)
+	return string(list[len(list)-1]), nil
+}
+
+// GetPackages returns the packages of a product version as *models.ProductDetails
+func (c *Catalog) GetPackages(ctx context.Context, product string, version string) (interface{}, error) {
+	entries := c.Entries(product, version)
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+	details := &models.ProductDetails{Product: product, Version: version}
+	for _, e := range entries {
+		details.MetaData = append(details.MetaData, metaData(e))
+	}
+	return details, nil
+}
+
+func (c *Catalog) GetMetaData(ctx context.Context, product, version, platform, platformVersion, architecture, packageManager string) (*models.MetaData, error) {
+	e, err := c.Lookup(product, version, platform, platformVersion, architecture, packageManager)
+	if err != nil {
+		return nil, err
+	}
+	meta := metaData(e)
+	return &meta, nil
+}
+
+// GetRelatedProducts returns no products, the mirror does not copy BOMs
+func (c *Catalog) GetRelatedProducts(ctx context.Context, bom string) (*models.RelatedProducts, error) {
+	return &models.RelatedProducts{}, nil
+}
+
+func (c *Catalog) GetPackageManagers(ctx context.Context) ([]string, error) {
+	managers := c.distinct(func(e Entry) string { return e.PackageManager })
+	if len(managers) == 0 {
+		return nil, errors.New("mirror has no packages")
+	}
+	return managers, nil
+}
+
+func (c *Catalog) distinct(field func(Entry) string) []string {
+	seen := map[string]bool{}
+	values := []string{}
+	for _, e := range c.index.Packages {
+		if v := field(e); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func metaData(e Entry) models.MetaData {
+	return models.MetaData{
+		Architecture:    e.Architecture,
+		FileName:        e.FileName,
+		Platform:        e.Platform,
+		PlatformVersion: e.PlatformVersion,
+		PackageManager:  e.PackageManager,
+		SHA1:            e.Sha1,
+		SHA256:          e.Sha256,
+		InstallMessage:  e.InstallMessage,
+		Size:            e.Size,
+	}
+}
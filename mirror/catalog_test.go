@@ -0,0 +1,176 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/constants"
+	"github.com/chef/omnitruck-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog() *Catalog {
+	entry := func(channel, product, version, platform, pv, arch, pm, file string) Entry {
+		pkg := omnitruck.Package{
+			Platform: platform, PlatformVersion: pv, Architecture: arch, PackageManager: pm,
+			Version: version, FileName: file, Sha256: "sum-" + file,
+		}
+		entryPath, err := EntryPath(channel, product, pkg)
+		if err != nil {
+			panic(err)
+		}
+		return Entry{Channel: channel, Product: product, Path: entryPath, Package: pkg}
+	}
+	index := &Index{Packages: []Entry{
+		entry("stable", "chef", "18.2.7", "ubuntu", "22.04", "x86_64", "deb", "chef-18.2.7.deb"),
+		entry("stable", "chef", "18.2.7", "el", "8", "x86_64", "rpm", "chef-18.2.7.el8.rpm"),
+		entry("stable", "chef", "18.2.7", "el", "9", "x86_64", "rpm", "chef-18.2.7.el9.rpm"),
+		entry("stable", "chef", "18.10.1", "ubuntu", "22.04", "x86_64", "deb", "chef-18.10.1.deb"),
+		entry("current", "chef", "19.0.5", "ubuntu", "22.04", "x86_64", "deb", "chef-19.0.5.deb"),
+		entry("stable", "chef-ice", "19.1.27", "linux", "", "x86_64", "rpm", "chef-ice.rpm"),
+		entry("stable", "chef-ice", "19.1.27", "linux", "", "x86_64", "deb", "chef-ice.deb"),
+		entry("stable", "chef-ice", "19.1.27", "linux", "", "aarch64", "deb", "chef-ice-arm.deb"),
+	}}
+	files := fstest.MapFS{
+		"stable/chef/18.2.7/ubuntu/22.04/x86_64/chef-18.2.7.deb": {Data: []byte("deb 18.2.7")},
+	}
+	return NewCatalog(index, files)
+}
+
+func TestCatalog_Versions(t *testing.T) {
+	catalog := testCatalog()
+
+	versions, err := catalog.GetVersionAll(context.Background(), "chef")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"18.2.7", "18.10.1"}, versions)
+
+	latest, err := catalog.GetVersionLatest(context.Background(), "chef")
+	require.NoError(t, err)
+	assert.Equal(t, "18.10.1", latest)
+
+	current := catalog.ForChannel("current")
+	latest, err = current.GetVersionLatest(context.Background(), "chef")
+	require.NoError(t, err)
+	assert.Equal(t, "19.0.5", latest)
+
+	_, err = catalog.GetVersionLatest(context.Background(), "inspec")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCatalog_SetDbInfo(t *testing.T) {
+	catalog := testCatalog()
+	clone := catalog.Clone()
+	clone.SetDbInfo("current", nil)
+
+	versions, _ := clone.GetVersionAll(context.Background(), "chef")
+	assert.Equal(t, []string{"19.0.5"}, versions)
+	versions, _ = catalog.GetVersionAll(context.Background(), "chef")
+	assert.Equal(t, []string{"18.2.7", "18.10.1"}, versions)
+}
+
+func TestCatalog_Lookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		product  string
+		version  string
+		platform string
+		pv       string
+		arch     string
+		pm       string
+		wantFile string
+		wantErr  bool
+	}{
+		{name: "exact platform version", product: "chef", version: "18.2.7", platform: "el", pv: "9", arch: "x86_64", wantFile: "chef-18.2.7.el9.rpm"},
+		{name: "any platform version", product: "chef", version: "18.2.7", platform: "el", arch: "x86_64", wantFile: "chef-18.2.7.el8.rpm"},
+		{name: "unknown platform version", product: "chef", version: "18.2.7", platform: "el", pv: "7", arch: "x86_64", wantErr: true},
+		{name: "platform case", product: "chef", version: "18.2.7", platform: "Ubuntu", pv: "22.04", arch: "x86_64", wantFile: "chef-18.2.7.deb"},
+		{name: "package manager", product: "chef-ice", version: "19.1.27", platform: "linux", arch: "x86_64", pm: "deb", wantFile: "chef-ice.deb"},
+		{name: "package manager derived from platform", product: "chef-ice", version: "19.1.27", platform: "ubuntu", pv: "22.04", arch: "x86_64", wantFile: "chef-ice.deb"},
+		{name: "package manager derived from alias", product: "chef-ice", version: "19.1.27", platform: "centos", pv: "9", arch: "x86_64", wantFile: "chef-ice.rpm"},
+		{name: "platform normalized for catalog", product: "chef-ice", version: "19.1.27", platform: "ubuntu", pv: "22.04", arch: "aarch64", pm: "deb", wantFile: "chef-ice-arm.deb"},
+		{name: "dummy package manager", product: "chef", version: "18.2.7", platform: "ubuntu", pv: "22.04", arch: "x86_64", pm: constants.DUMMY_PACKAGE_MANAGER, wantFile: "chef-18.2.7.deb"},
+		{name: "wrong package manager", product: "chef", version: "18.2.7", platform: "ubuntu", pv: "22.04", arch: "x86_64", pm: "rpm", wantErr: true},
+		{name: "other channel", product: "chef", version: "19.0.5", platform: "ubuntu", pv: "22.04", arch: "x86_64", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := testCatalog().Lookup(tt.product, tt.version, tt.platform, tt.pv, tt.arch, tt.pm)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, e.FileName)
+		})
+	}
+}
+
+func TestCatalog_GetPackages(t *testing.T) {
+	catalog := testCatalog()
+	res, err := catalog.GetPackages(context.Background(), "chef-ice", "19.1.27")
+	require.NoError(t, err)
+	details, ok := res.(*models.ProductDetails)
+	require.True(t, ok)
+	assert.Len(t, details.MetaData, 3)
+
+	meta, err := catalog.GetMetaData(context.Background(), "chef-ice", "19.1.27", "linux", "", "x86_64", "rpm")
+	require.NoError(t, err)
+	assert.Equal(t, "chef-ice.rpm", meta.FileName)
+	assert.Equal(t, "sum-chef-ice.rpm", meta.SHA256)
+
+	_, err = catalog.GetPackages(context.Background(), "chef-ice", "1.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCatalog_Lists(t *testing.T) {
+	catalog := testCatalog()
+	assert.Equal(t, []string{"chef", "chef-ice"}, catalog.Products())
+	assert.Equal(t, []string{"aarch64", "x86_64"}, catalog.Architectures())
+
+	managers, err := catalog.GetPackageManagers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deb", "rpm"}, managers)
+	assert.True(t, catalog.IsPackageManager("rpm"))
+	assert.False(t, catalog.IsPackageManager("22.04"))
+
+	related, err := catalog.GetRelatedProducts(context.Background(), "bom")
+	require.NoError(t, err)
+	assert.Empty(t, related.Products)
+}
+
+func TestCatalog_Open(t *testing.T) {
+	catalog := testCatalog()
+	e, err := catalog.Lookup("chef", "18.2.7", "ubuntu", "22.04", "x86_64", "")
+	require.NoError(t, err)
+
+	f, info, err := catalog.Open(e)
+	require.NoError(t, err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "deb 18.2.7", string(content))
+	assert.Equal(t, int64(10), info.Size())
+
+	e, err = catalog.Lookup("chef", "18.10.1", "ubuntu", "22.04", "x86_64", "")
+	require.NoError(t, err)
+	_, _, err = catalog.Open(e)
+	assert.Error(t, err)
+}
+
+func TestOpenCatalog(t *testing.T) {
+	dir := t.TempDir()
+	index := &Index{Source: "https://example.com"}
+	require.NoError(t, index.Save(dir))
+
+	catalog, err := OpenCatalog(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", catalog.Source())
+
+	_, err = OpenCatalog(dir + "/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/internal/problem"
+)
+
+// Client reads versions and packages from the /v2 API of an omnitruck
+// service, so the service applies the mode, EOL and license rules
+type Client struct {
+	BaseUrl   string
+	LicenseId string
+	HTTP      *http.Client
+}
+
+// Versions returns the versions of a product matching a prefix or
+// constraint such as ~> 18
+func (c *Client) Versions(ctx context.Context, channel, product, constraint string) ([]omnitruck.ProductVersion, error) {
+	query := url.Values{}
+	if constraint != "" {
+		query.Set("v", constraint)
+	}
+	var versions []omnitruck.ProductVersion
+	err := c.getJSON(ctx, fmt.Sprintf("/v2/%s/%s/versions/all", channel, product), query, &versions)
+	return versions, err
+}
+
+// LatestVersion returns the latest version of a product
+func (c *Client) LatestVersion(ctx context.Context, channel, product string) (omnitruck.ProductVersion, error) {
+	var version omnitruck.ProductVersion
+	err := c.getJSON(ctx, fmt.Sprintf("/v2/%s/%s/versions/latest", channel, product), url.Values{}, &version)
+	return version, err
+}
+
+// Packages returns the packages of a product version
+func (c *Client) Packages(ctx context.Context, channel, product string, version omnitruck.ProductVersion) (omnitruck.Packages, error) {
+	query := url.Values{}
+	query.Set("v", string(version))
+	var packages omnitruck.Packages
+	err := c.getJSON(ctx, fmt.Sprintf("/v2/%s/%s/packages", channel, product), query, &packages)
+	return packages, err
+}
+
+// Open starts the download of a package url. Urls pointing back at the
+// service carry the license.
+func (c *Client) Open(ctx context.Context, rawUrl string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	if base, err := url.Parse(c.BaseUrl); err == nil && base.Host == u.Host && c.LicenseId != "" {
+		query := u.Query()
+		query.Set("license_id", c.LicenseId)
+		u.RawQuery = query.Encode()
+	}
+	resp, err := c.do(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if c.LicenseId != "" {
+		query.Set("license_id", c.LicenseId)
+	}
+	u := strings.TrimSuffix(c.BaseUrl, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	resp, err := c.do(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// do sends a GET request and turns error responses, including problem
+// details, into errors
+func (c *Client) do(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var p problem.Problem
+	if json.Unmarshal(body, &p) == nil && p.Detail != "" {
+		return nil, fmt.Errorf("GET %s: %d %s (%s)", req.URL.Path, resp.StatusCode, p.Detail, p.Code)
+	}
+	return nil, fmt.Errorf("GET %s: %d %s", req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+}
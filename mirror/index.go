@@ -0,0 +1,134 @@
+// Package mirror copies packages from an omnitruck service into a local
+// directory, along with an index describing them, so they can be installed
+// without access to the internet.
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+)
+
+// IndexFile is the name of the index at the root of a mirror directory
+const IndexFile = "index.json"
+
+// Entry is a package stored in the mirror. Path is relative to the mirror
+// directory and slash separated.
+type Entry struct {
+	Channel string `json:"channel"`
+	Product string `json:"product"`
+	Path    string `json:"path"`
+	omnitruck.Package
+}
+
+// Index lists every package of a mirror
+type Index struct {
+	// Source is the service the packages were copied from
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Packages  []Entry   `json:"packages"`
+}
+
+// LoadIndex reads the index of a mirror directory. A directory without an
+// index has an empty one.
+func LoadIndex(dir string) (*Index, error) {
+	content, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// Save writes the index to the mirror directory, replacing the previous one
+// only once it is completely written
+func (idx *Index) Save(dir string) error {
+	idx.sort()
+	content, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, IndexFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, IndexFile))
+}
+
+// Add records a package, replacing an entry for the same channel, product,
+// version, platform, platform version, architecture and package manager
+func (idx *Index) Add(e Entry) {
+	for i, existing := range idx.Packages {
+		if existing.key() == e.key() {
+			idx.Packages[i] = e
+			return
+		}
+	}
+	idx.Packages = append(idx.Packages, e)
+}
+
+func (e Entry) key() [7]string {
+	return [7]string{e.Channel, e.Product, e.Version, e.Platform, e.PlatformVersion, e.Architecture, e.PackageManager}
+}
+
+func (idx *Index) sort() {
+	sort.Slice(idx.Packages, func(i, j int) bool {
+		a, b := idx.Packages[i].key(), idx.Packages[j].key()
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+}
+
+// EntryPath returns where a package is stored in the mirror:
+// channel/product/version/platform/platform_version/architecture/filename,
+// leaving out an empty platform version. Every segment must be a single
+// path element, so a package listed by the server can not be written
+// outside the mirror.
+func EntryPath(channel, product string, pkg omnitruck.Package) (string, error) {
+	if pkg.FileName == "" {
+		return "", errors.New("package has no file name")
+	}
+	segments := []string{channel, product, pkg.Version, pkg.Platform}
+	if pkg.PlatformVersion != "" {
+		segments = append(segments, pkg.PlatformVersion)
+	}
+	segments = append(segments, pkg.Architecture, pkg.FileName)
+	for _, segment := range segments {
+		if !pathElement(segment) {
+			return "", fmt.Errorf("invalid path element %q", segment)
+		}
+	}
+	return path.Join(segments...), nil
+}
+
+// pathElement reports whether s names a single entry of a directory
+func pathElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
@@ -0,0 +1,189 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	log "github.com/sirupsen/logrus"
+)
+
+// Syncer copies the matching packages of a channel into a mirror directory
+type Syncer struct {
+	Client  *Client
+	Dir     string
+	Channel string
+	// Products to copy
+	Products []string
+	// Platforms limits the packages to these platforms, all when empty
+	Platforms []string
+	// Versions is a version prefix or constraint such as ~> 18. Only the
+	// latest version is copied when empty.
+	Versions string
+	Log      *log.Entry
+}
+
+// Result counts the packages of a sync
+type Result struct {
+	Downloaded int
+	// Unchanged packages were already in the mirror with the right checksum
+	Unchanged int
+	Failed    int
+}
+
+// Run copies the packages and updates the index. Packages that fail to
+// download or verify are left out of the index and reported together.
+func (s *Syncer) Run(ctx context.Context) (*Result, error) {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	index, err := LoadIndex(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	result := &Result{}
+	var errs []error
+	for _, product := range s.Products {
+		versions, err := s.versions(ctx, product)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", product, err)
+		}
+		for _, version := range versions {
+			packages, err := s.Client.Packages(ctx, s.Channel, product, version)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", product, version, err)
+			}
+			for _, pkg := range packages.Packages {
+				if !s.platformSelected(pkg.Platform) {
+					continue
+				}
+				downloaded := false
+				entryPath, err := EntryPath(s.Channel, product, pkg)
+				entry := Entry{Channel: s.Channel, Product: product, Path: entryPath, Package: pkg}
+				if err == nil {
+					downloaded, err = s.fetch(ctx, entry)
+				}
+				if err != nil {
+					result.Failed++
+					errs = append(errs, fmt.Errorf("%s %s %s/%s: %w", product, version, pkg.Platform, pkg.Architecture, err))
+					continue
+				}
+				if downloaded {
+					result.Downloaded++
+				} else {
+					result.Unchanged++
+				}
+				index.Add(entry)
+			}
+		}
+	}
+
+	index.Source = s.Client.BaseUrl
+	index.UpdatedAt = time.Now().UTC()
+	if err := index.Save(s.Dir); err != nil {
+		return result, fmt.Errorf("writing index: %w", err)
+	}
+	return result, errors.Join(errs...)
+}
+
+func (s *Syncer) versions(ctx context.Context, product string) ([]omnitruck.ProductVersion, error) {
+	if s.Versions == "" {
+		latest, err := s.Client.LatestVersion(ctx, s.Channel, product)
+		if err != nil {
+			return nil, err
+		}
+		return []omnitruck.ProductVersion{latest}, nil
+	}
+	return s.Client.Versions(ctx, s.Channel, product, s.Versions)
+}
+
+func (s *Syncer) platformSelected(platform string) bool {
+	if len(s.Platforms) == 0 {
+		return true
+	}
+	for _, p := range s.Platforms {
+		if samePlatform(p, platform) {
+			return true
+		}
+	}
+	return false
+}
+
+// samePlatform reports whether two platform names, or aliases of the
+// registry, are the same platform. A platform also matches the database
+// platform it is stored under, such as linux for ubuntu.
+func samePlatform(a, b string) bool {
+	aKey, aPlatform, aOk := omnitruck.LookupPlatform(a)
+	bKey, bPlatform, bOk := omnitruck.LookupPlatform(b)
+	if aKey == bKey {
+		return true
+	}
+	return aOk && bKey == aPlatform.DbPlatform || bOk && aKey == bPlatform.DbPlatform
+}
+
+// fetch downloads a package unless the mirror already has it, and verifies
+// its checksum. It reports whether the package was downloaded.
+func (s *Syncer) fetch(ctx context.Context, e Entry) (bool, error) {
+	if e.FileName == "" {
+		return false, errors.New("package has no file name")
+	}
+	if e.Sha256 == "" {
+		return false, errors.New("package has no sha256 to verify")
+	}
+
+	dest := filepath.Join(s.Dir, filepath.FromSlash(e.Path))
+	if sum, err := fileSha256(dest); err == nil && strings.EqualFold(sum, e.Sha256) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return false, err
+	}
+
+	s.Log.WithField("url", e.Url).WithField("path", e.Path).Info("Downloading package")
+	body, err := s.Client.Open(ctx, e.Url)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, e.Sha256) {
+		return false, fmt.Errorf("checksum mismatch, expected %s got %s", e.Sha256, sum)
+	}
+	return true, os.Rename(tmp.Name(), dest)
+}
+
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
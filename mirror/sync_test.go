@@ -0,0 +1,269 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chef/omnitruck-service/clients/omnitruck"
+	"github.com/chef/omnitruck-service/internal/apierror"
+	"github.com/chef/omnitruck-service/internal/problem"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *log.Entry {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	return log.NewEntry(logger)
+}
+
+func sum(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+// fakeService serves the /v2 routes used by the client and counts downloads
+type fakeService struct {
+	*httptest.Server
+	files     map[string]string
+	downloads map[string]int
+}
+
+func newFakeService(t *testing.T) *fakeService {
+	f := &fakeService{
+		files: map[string]string{
+			"/files/chef-18.2.7.deb":   "deb 18.2.7",
+			"/files/chef-18.2.7.rpm":   "rpm 18.2.7",
+			"/files/chef-18.3.0.deb":   "deb 18.3.0",
+			"/files/chef-18.3.0.rpm":   "tampered",
+			"/files/chef-18.3.0.msi":   "msi 18.3.0",
+			"/files/inspec-5.22.3.deb": "inspec deb",
+		},
+		downloads: map[string]int{},
+	}
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/v2/stable/chef/versions/all", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "~> 18", r.URL.Query().Get("v"))
+		assert.Equal(t, "lic-1", r.URL.Query().Get("license_id"))
+		writeJSON(w, []string{"18.2.7", "18.3.0"})
+	})
+	mux.HandleFunc("/v2/stable/inspec/versions/all", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []string{"5.22.3"})
+	})
+	mux.HandleFunc("/v2/stable/inspec/versions/latest", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, "5.22.3")
+	})
+	mux.HandleFunc("/v2/stable/missing/versions/all", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", problem.ContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, problem.New(http.StatusBadRequest, apierror.CodeProductNotFound, "Product information not found. Please check the input parameters."))
+	})
+	packages := func(product, version string, pkgs ...omnitruck.Package) {
+		mux.HandleFunc("/v2/stable/"+product+"/packages", func(w http.ResponseWriter, r *http.Request) {
+			v := r.URL.Query().Get("v")
+			var out []omnitruck.Package
+			for _, p := range pkgs {
+				if p.Version == v {
+					out = append(out, p)
+				}
+			}
+			writeJSON(w, omnitruck.Packages{Product: product, Version: omnitruck.ProductVersion(v), Packages: out})
+		})
+	}
+	pkg := func(platform, pv, pm, version, file, sha string) omnitruck.Package {
+		return omnitruck.Package{
+			Platform: platform, PlatformVersion: pv, Architecture: "x86_64", PackageManager: pm,
+			Version: version, Url: f.URL + "/files/" + file, FileName: file, Sha256: sha,
+		}
+	}
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lic-1", r.URL.Query().Get("license_id"))
+		content, ok := f.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		f.downloads[r.URL.Path]++
+		io.WriteString(w, content)
+	})
+	f.Server = httptest.NewServer(mux)
+	packages("chef",
+		"",
+		pkg("ubuntu", "22.04", "deb", "18.2.7", "chef-18.2.7.deb", sum("deb 18.2.7")),
+		pkg("el", "8", "rpm", "18.2.7", "chef-18.2.7.rpm", sum("rpm 18.2.7")),
+		pkg("el", "9", "rpm", "18.2.7", "chef-18.2.7.rpm", sum("rpm 18.2.7")),
+		pkg("ubuntu", "22.04", "deb", "18.3.0", "chef-18.3.0.deb", sum("deb 18.3.0")),
+		pkg("el", "8", "rpm", "18.3.0", "chef-18.3.0.rpm", sum("rpm 18.3.0")),
+		pkg("windows", "2022", "msi", "18.3.0", "chef-18.3.0.msi", sum("msi 18.3.0")),
+	)
+	packages("inspec", "", pkg("ubuntu", "22.04", "deb", "5.22.3", "inspec-5.22.3.deb", sum("inspec deb")))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestSyncer_Run(t *testing.T) {
+	service := newFakeService(t)
+	dir := t.TempDir()
+	syncer := &Syncer{
+		Client:    &Client{BaseUrl: service.URL, LicenseId: "lic-1"},
+		Dir:       dir,
+		Channel:   "stable",
+		Products:  []string{"chef"},
+		Platforms: []string{"ubuntu", "EL"},
+		Versions:  "~> 18",
+		Log:       testLogger(),
+	}
+
+	result, err := syncer.Run(context.Background())
+	assert.ErrorContains(t, err, "chef 18.3.0 el/x86_64: checksum mismatch")
+	assert.Equal(t, &Result{Downloaded: 4, Unchanged: 0, Failed: 1}, result)
+
+	content, err := os.ReadFile(filepath.Join(dir, "stable/chef/18.2.7/el/9/x86_64/chef-18.2.7.rpm"))
+	require.NoError(t, err)
+	assert.Equal(t, "rpm 18.2.7", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "stable/chef/18.3.0/el/8/x86_64/chef-18.3.0.rpm"))
+	assert.NoDirExists(t, filepath.Join(dir, "stable/chef/18.3.0/windows"))
+
+	index, err := LoadIndex(dir)
+	require.NoError(t, err)
+	assert.Equal(t, service.URL, index.Source)
+	var paths []string
+	for _, e := range index.Packages {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(t, []string{
+		"stable/chef/18.2.7/el/8/x86_64/chef-18.2.7.rpm",
+		"stable/chef/18.2.7/el/9/x86_64/chef-18.2.7.rpm",
+		"stable/chef/18.2.7/ubuntu/22.04/x86_64/chef-18.2.7.deb",
+		"stable/chef/18.3.0/ubuntu/22.04/x86_64/chef-18.3.0.deb",
+	}, paths)
+
+	// A second sync keeps what is already mirrored and adds new products
+	service.files["/files/chef-18.3.0.rpm"] = "rpm 18.3.0"
+	syncer.Products = []string{"chef", "inspec"}
+	result, err = syncer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Downloaded: 2, Unchanged: 4, Failed: 0}, result)
+	assert.Equal(t, 1, service.downloads["/files/chef-18.2.7.deb"])
+
+	index, err = LoadIndex(dir)
+	require.NoError(t, err)
+	assert.Len(t, index.Packages, 6)
+}
+
+func TestSyncer_RunLatestVersion(t *testing.T) {
+	service := newFakeService(t)
+	dir := t.TempDir()
+	syncer := &Syncer{
+		Client:   &Client{BaseUrl: service.URL, LicenseId: "lic-1"},
+		Dir:      dir,
+		Channel:  "stable",
+		Products: []string{"inspec"},
+		Log:      testLogger(),
+	}
+	result, err := syncer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Downloaded)
+	assert.FileExists(t, filepath.Join(dir, "stable/inspec/5.22.3/ubuntu/22.04/x86_64/inspec-5.22.3.deb"))
+}
+
+func TestSyncer_RunProblem(t *testing.T) {
+	service := newFakeService(t)
+	syncer := &Syncer{
+		Client:   &Client{BaseUrl: service.URL},
+		Dir:      t.TempDir(),
+		Channel:  "stable",
+		Products: []string{"missing"},
+		Versions: ">= 1",
+		Log:      testLogger(),
+	}
+	_, err := syncer.Run(context.Background())
+	assert.EqualError(t, err, "missing: GET /v2/stable/missing/versions/all: 400 Product information not found. Please check the input parameters. (product.not_found)")
+}
+
+func TestEntryPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		product string
+		pkg     omnitruck.Package
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "platform version",
+			product: "chef",
+			pkg:     omnitruck.Package{Version: "18.2.7", Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64", FileName: "chef.deb"},
+			want:    "stable/chef/18.2.7/ubuntu/22.04/x86_64/chef.deb",
+		},
+		{
+			name:    "no platform version",
+			product: "chef-ice",
+			pkg:     omnitruck.Package{Version: "19.1.27", Platform: "linux", Architecture: "x86_64", FileName: "chef-ice.rpm"},
+			want:    "stable/chef-ice/19.1.27/linux/x86_64/chef-ice.rpm",
+		},
+		{
+			name:    "file name escaping the mirror",
+			product: "chef",
+			pkg:     omnitruck.Package{Version: "18.2.7", Platform: "ubuntu", Architecture: "x86_64", FileName: "../../../../../.bashrc"},
+			wantErr: `invalid path element "../../../../../.bashrc"`,
+		},
+		{
+			name:    "parent version",
+			product: "chef",
+			pkg:     omnitruck.Package{Version: "..", Platform: "ubuntu", Architecture: "x86_64", FileName: "chef.deb"},
+			wantErr: `invalid path element ".."`,
+		},
+		{
+			name:    "no file name",
+			product: "chef",
+			pkg:     omnitruck.Package{Version: "18.2.7", Platform: "ubuntu", Architecture: "x86_64"},
+			wantErr: "package has no file name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EntryPath("stable", tt.product, tt.pkg)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSamePlatform(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "ubuntu", b: "ubuntu", want: true},
+		{a: "EL", b: "el", want: true},
+		{a: "centos", b: "el", want: true},
+		{a: "linuxmint", b: "ubuntu", want: true},
+		{a: "ubuntu", b: "linux", want: true},
+		{a: "linux", b: "el", want: true},
+		{a: "ubuntu", b: "el", want: false},
+		{a: "ubuntu", b: "windows", want: false},
+		{a: "plan9", b: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, samePlatform(tt.a, tt.b))
+		})
+	}
+}
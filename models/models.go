@@ -7,24 +7,30 @@ type AWSConfig struct {
 }
 
 type ProductDetails struct {
-	Product  string     `json:"product"`
-	Version  string     `json:"version"`
-	MetaData []MetaData `json:"metadata"`
+	Product      string     `json:"product" dynamodbav:"product"`
+	Version      string     `json:"version" dynamodbav:"version"`
+	ReleaseDate  string     `json:"release_date,omitempty" dynamodbav:"release_date,omitempty"`
+	ReleaseNotes string     `json:"release_notes,omitempty" dynamodbav:"release_notes,omitempty"`
+	MetaData     []MetaData `json:"metadata" dynamodbav:"metadata"`
 }
 
 type MetaData struct {
-	Architecture    string `json:"architecture"`
-	FileName        string `json:"filename"`
-	Platform        string `json:"platform"`
-	PlatformVersion string `json:"platform_version"`
-	PackageManager  string `json:"package_manager"`
-	SHA1            string `json:"sha1"`
-	SHA256          string `json:"sha256"`
+	Architecture    string `json:"architecture" dynamodbav:"architecture"`
+	FileName        string `json:"filename" dynamodbav:"filename"`
+	Platform        string `json:"platform" dynamodbav:"platform"`
+	PlatformVersion string `json:"platform_version" dynamodbav:"platform_version,omitempty"`
+	PackageManager  string `json:"package_manager" dynamodbav:"package_manager,omitempty"`
+	SHA1            string `json:"sha1" dynamodbav:"sha1,omitempty"`
+	SHA256          string `json:"sha256" dynamodbav:"sha256"`
+	InstallMessage  string `json:"install-message" dynamodbav:"install-message,omitempty"`
+	Size            int64  `json:"size,omitempty" dynamodbav:"size,omitempty"`
+	SignatureUrl    string `json:"signature_url,omitempty" dynamodbav:"signature_url,omitempty"`
+	SbomUrl         string `json:"sbom_url,omitempty" dynamodbav:"sbom_url,omitempty"`
 }
 
 type RelatedProducts struct {
-	Bom      string            `json:"bom"`
-	Products map[string]string `json:"products"`
+	Bom      string            `json:"bom" dynamodbav:"bom"`
+	Products map[string]string `json:"products" dynamodbav:"products"`
 }
 
 type ScriptParams struct {
@@ -73,9 +79,11 @@ type EntitlementValues struct {
 }
 
 type PackageDetails struct {
-	Product  string              `json:"product"`
-	Version  string              `json:"version"`
-	Metadata map[string]Platform `json:"metadata"`
+	Product      string              `json:"product" dynamodbav:"product"`
+	Version      string              `json:"version" dynamodbav:"version"`
+	ReleaseDate  string              `json:"release_date,omitempty" dynamodbav:"release_date,omitempty"`
+	ReleaseNotes string              `json:"release_notes,omitempty" dynamodbav:"release_notes,omitempty"`
+	Metadata     map[string]Platform `json:"metadata" dynamodbav:"metadata"`
 }
 
 type Platform map[string]Architecture
@@ -83,8 +91,11 @@ type Platform map[string]Architecture
 type Architecture map[string]PackageType
 
 type PackageType struct {
-	Filename       string `json:"filename"`
-	InstallMessage string `json:"install-message"`
-	SHA1           string `json:"sha1"`
-	SHA256         string `json:"sha256"`
+	Filename       string `json:"filename" dynamodbav:"filename"`
+	InstallMessage string `json:"install-message" dynamodbav:"install-message,omitempty"`
+	SHA1           string `json:"sha1" dynamodbav:"sha1,omitempty"`
+	SHA256         string `json:"sha256" dynamodbav:"sha256"`
+	Size           int64  `json:"size,omitempty" dynamodbav:"size,omitempty"`
+	SignatureUrl   string `json:"signature_url,omitempty" dynamodbav:"signature_url,omitempty"`
+	SbomUrl        string `json:"sbom_url,omitempty" dynamodbav:"sbom_url,omitempty"`
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/chef/omnitruck-service/constants"
+	version "github.com/hashicorp/go-version"
+)
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// ValidateVersion reports whether v is a version the catalog can sort. The
+// service skips versions that do not parse, except for the literal latest.
+func ValidateVersion(v string) error {
+	if v == "" {
+		return errors.New("version is empty")
+	}
+	if v == constants.LATEST {
+		return nil
+	}
+	if _, err := version.NewVersion(v); err != nil {
+		return fmt.Errorf("version %q is not a valid version", v)
+	}
+	return nil
+}
+
+// Validate checks that the record has everything the service needs to list
+// and download its packages
+func (p *ProductDetails) Validate() error {
+	if p.Product == "" {
+		return errors.New("product is empty")
+	}
+	if err := ValidateVersion(p.Version); err != nil {
+		return err
+	}
+	if len(p.MetaData) == 0 {
+		return fmt.Errorf("%s %s has no packages", p.Product, p.Version)
+	}
+
+	var errs []error
+	for _, m := range p.MetaData {
+		if m.Platform == "" || m.Architecture == "" {
+			errs = append(errs, fmt.Errorf("%s %s: package %q has no platform or architecture", p.Product, p.Version, m.FileName))
+			continue
+		}
+		if err := validatePackage(m.FileName, m.SHA256); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s %s/%s: %w", p.Product, p.Version, m.Platform, m.Architecture, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that the record has everything the service needs to list
+// and download its packages
+func (p *PackageDetails) Validate() error {
+	if p.Product == "" {
+		return errors.New("product is empty")
+	}
+	if err := ValidateVersion(p.Version); err != nil {
+		return err
+	}
+	if len(p.Metadata) == 0 {
+		return fmt.Errorf("%s %s has no packages", p.Product, p.Version)
+	}
+
+	var errs []error
+	for platform, archs := range p.Metadata {
+		for arch, managers := range archs {
+			for pm, pkg := range managers {
+				if err := validatePackage(pkg.Filename, pkg.SHA256); err != nil {
+					errs = append(errs, fmt.Errorf("%s %s %s/%s/%s: %w", p.Product, p.Version, platform, arch, pm, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validatePackage(fileName string, sha256 string) error {
+	if fileName == "" {
+		return errors.New("filename is empty")
+	}
+	if !sha256Pattern.MatchString(sha256) {
+		return fmt.Errorf("sha256 of %s is not a hex encoded checksum", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validSum = "0f2a3b8c9d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8"
+
+func TestValidateVersion(t *testing.T) {
+	assert.NoError(t, ValidateVersion("18.2.7"))
+	assert.NoError(t, ValidateVersion("latest"))
+	assert.Error(t, ValidateVersion(""))
+	assert.Error(t, ValidateVersion("not-a-version"))
+}
+
+func TestProductDetails_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		details ProductDetails
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			details: ProductDetails{Product: "habitat", Version: "1.6.826", MetaData: []MetaData{{Platform: "linux", Architecture: "x86_64", FileName: "hab.tar.gz", SHA256: validSum}}},
+		},
+		{name: "no product", details: ProductDetails{Version: "1.6.826"}, wantErr: "product is empty"},
+		{name: "no packages", details: ProductDetails{Product: "habitat", Version: "1.6.826"}, wantErr: "habitat 1.6.826 has no packages"},
+		{
+			name:    "no platform",
+			details: ProductDetails{Product: "habitat", Version: "1.6.826", MetaData: []MetaData{{FileName: "hab.tar.gz", SHA256: validSum}}},
+			wantErr: `package "hab.tar.gz" has no platform or architecture`,
+		},
+		{
+			name:    "bad checksum",
+			details: ProductDetails{Product: "habitat", Version: "1.6.826", MetaData: []MetaData{{Platform: "linux", Architecture: "x86_64", FileName: "hab.tar.gz", SHA256: "abc"}}},
+			wantErr: "sha256 of hab.tar.gz is not a hex encoded checksum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.details.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPackageDetails_Validate(t *testing.T) {
+	valid := PackageDetails{
+		Product:  "chef",
+		Version:  "18.2.7",
+		Metadata: map[string]Platform{"linux": {"x86_64": {"deb": {Filename: "chef.deb", SHA256: validSum}}}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	missing := PackageDetails{
+		Product:  "chef",
+		Version:  "18.2.7",
+		Metadata: map[string]Platform{"linux": {"x86_64": {"deb": {SHA256: validSum}}}},
+	}
+	assert.ErrorContains(t, missing.Validate(), "chef 18.2.7 linux/x86_64/deb: filename is empty")
+}
@@ -21,4 +21,14 @@ const (
 	ErrorWhileFetchingLatestVersion     = "Error while fetching the latest version for the "
 	ErrorLogUnsupportedPackageStructure = "GetProductPackages returned unsupported package structure"
 	ErrorMsgUnsupportedPackageStructure = "Package details could not be interpreted. Please verify your request."
+
+	VersionUnsupportedError = "the requested version is not supported on the selected persona or channel"
+	NoVersionsError         = "No versions found for this product/mode"
+
+	ForbiddenError            = "Access to the requested resource is denied"
+	ThrottledError            = "Too many requests, please retry later"
+	UpstreamUnavailableError  = "A backing service is unavailable, please retry later"
+	InvalidInputError         = "Invalid request parameters"
+	UpstreamAccessDeniedError = "A backing service denied access, please retry later"
+	InternalError             = "The request could not be processed, please retry later"
 )